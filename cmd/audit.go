@@ -0,0 +1,118 @@
+package cmd
+
+import (
+	"errors"
+	"fmt"
+
+	"arbor/internal/gitgraph"
+	"arbor/internal/gitops"
+
+	"github.com/go-git/go-git/v5/plumbing/object"
+	"github.com/spf13/cobra"
+)
+
+var auditCmd = &cobra.Command{
+	Use:   "audit [<base>..[head] | <commit>]",
+	Short: "Check commits against the allowed authors and keys policy (exit status 1 if any break it)",
+	Long: `Check commits against the policy file named by audit.policy (or
+--policy): authors' email domains and addresses, and, when it lists keys,
+that each commit has a good signature from one of them, as git verifies it
+(git log --show-signature).
+
+A range such as main..HEAD checks every commit on head that base lacks, the
+way a CI job would check a pull request; a single revision checks just that
+commit. With neither, it checks the history the TUI would show.`,
+	Args:              cobra.MaximumNArgs(1),
+	ValidArgsFunction: completeRefs,
+	SilenceErrors:     true,
+	SilenceUsage:      true,
+	RunE: func(cmd *cobra.Command, args []string) error {
+		repo, path, cfg, err := setup(cmd)
+		if err != nil {
+			return err
+		}
+		if flags := cmd.Flags(); flags.Changed("policy") {
+			cfg.Audit.Policy, _ = flags.GetString("policy")
+		}
+		root := path
+		if root == "" {
+			root = gitgraph.GitDir(repo)
+		}
+		policy, err := cfg.Audit.LoadPolicy(root)
+		if err != nil {
+			return err
+		}
+		if policy == nil {
+			return errors.New("no policy: set audit.policy in the config, or pass --policy")
+		}
+
+		var commits []*object.Commit
+		switch {
+		case len(args) == 1:
+			base, head, err := resolveRange(repo, args[0])
+			if err != nil {
+				return err
+			}
+			commits = []*object.Commit{head}
+			if base != nil {
+				if _, commits, err = gitgraph.ReviewRange(repo, gitgraph.NewGenerations(repo), base.Hash, head.Hash); err != nil {
+					return err
+				}
+			}
+		default:
+			provider, err := newProvider(repo, cfg)
+			if err != nil {
+				return err
+			}
+			for provider.HasMore() {
+				if err := provider.Ensure(len(provider.Commits) + 1000); err != nil {
+					return err
+				}
+			}
+			for _, commit := range provider.Commits {
+				commits = append(commits, commit.Commit)
+			}
+		}
+
+		var sigs map[string]gitops.Signature
+		if policy.ChecksKeys() {
+			hashes := make([]string, len(commits))
+			for i, commit := range commits {
+				hashes[i] = commit.Hash.String()
+			}
+			if sigs, err = gitops.New(root).Signatures(hashes); err != nil {
+				return err
+			}
+		}
+
+		out := cmd.OutOrStdout()
+		abbrev := loadAbbrev(repo)
+		failed := 0
+		for _, commit := range commits {
+			var signer *gitgraph.Signer
+			if sig, ok := sigs[commit.Hash.String()]; ok {
+				signer = &gitgraph.Signer{Signed: sig.Signed(), Good: sig.Good(), Keys: sig.Keys}
+			}
+			problems := policy.Check(commit, signer)
+			if len(problems) == 0 {
+				continue
+			}
+			failed++
+			fmt.Fprintf(out, "%s %s\n", abbrev.Short(commit.Hash), gitgraph.FirstLine(commit.Message))
+			for _, problem := range problems {
+				fmt.Fprintf(out, "  %s\n", problem)
+			}
+		}
+		if failed > 0 {
+			fmt.Fprintf(out, "%d of %d commit(s) break the policy\n", failed, len(commits))
+			return &exitError{code: 1}
+		}
+		fmt.Fprintf(out, "%d commit(s) ok\n", len(commits))
+		return nil
+	},
+}
+
+func init() {
+	auditCmd.Flags().String("policy", "", "policy file to check against; overrides audit.policy")
+	rootCmd.AddCommand(auditCmd)
+}
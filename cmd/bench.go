@@ -0,0 +1,158 @@
+package cmd
+
+import (
+	"fmt"
+	"io"
+	"os"
+	"runtime"
+	"runtime/pprof"
+	"text/tabwriter"
+	"time"
+
+	"arbor/internal/gitgraph"
+
+	"github.com/spf13/cobra"
+)
+
+var benchCmd = &cobra.Command{
+	Use:   "bench",
+	Short: "Time walking, laying out, and diffing this repository's history",
+	Long: `Time the work behind the commit tree on this repository: walking history
+the way the TUI does (same --all, --limit, and --order), laying out the graph
+again over the walked commits, and diffing the newest commits against their
+first parents, as the sidebar does for the selected one.
+
+Each phase reports wall time, time per commit, and memory allocated. Attach
+the output, and --cpuprofile/--memprofile files if asked, to performance
+reports.`,
+	Args:          cobra.NoArgs,
+	SilenceErrors: true,
+	SilenceUsage:  true,
+	RunE: func(cmd *cobra.Command, args []string) error {
+		repo, _, cfg, err := setup(cmd)
+		if err != nil {
+			return err
+		}
+		diffs, _ := cmd.Flags().GetInt("diffs")
+		cpuFile, _ := cmd.Flags().GetString("cpuprofile")
+		memFile, _ := cmd.Flags().GetString("memprofile")
+
+		if cpuFile != "" {
+			f, err := os.Create(cpuFile)
+			if err != nil {
+				return err
+			}
+			defer f.Close()
+			if err := pprof.StartCPUProfile(f); err != nil {
+				return err
+			}
+			defer pprof.StopCPUProfile()
+		}
+
+		var results []benchResult
+		var commits []*gitgraph.CommitInfo
+		walk, err := measure("walk", func() (int, error) {
+			provider, err := newProvider(repo, cfg)
+			if err != nil {
+				return 0, err
+			}
+			for provider.HasMore() {
+				if err := provider.Ensure(len(provider.Commits) + 1000); err != nil {
+					return 0, err
+				}
+			}
+			commits = provider.Commits
+			return len(commits), nil
+		})
+		if err != nil {
+			return err
+		}
+		results = append(results, walk)
+
+		layout, _ := measure("layout", func() (int, error) {
+			gitgraph.Relayout(commits)
+			return len(commits), nil
+		})
+		results = append(results, layout)
+
+		diff, err := measure("diff", func() (int, error) {
+			n := min(diffs, len(commits))
+			attrs := gitgraph.LoadAttributes(repo)
+			for _, commit := range commits[:n] {
+				if _, err := gitgraph.CommitDiffStat(attrs, commit.Commit); err != nil {
+					return 0, fmt.Errorf("diff %s: %w", commit.ShortHash(), err)
+				}
+			}
+			return n, nil
+		})
+		if err != nil {
+			return err
+		}
+		results = append(results, diff)
+
+		writeBench(cmd.OutOrStdout(), results)
+
+		if memFile != "" {
+			f, err := os.Create(memFile)
+			if err != nil {
+				return err
+			}
+			defer f.Close()
+			runtime.GC()
+			if err := pprof.WriteHeapProfile(f); err != nil {
+				return err
+			}
+		}
+		return nil
+	},
+}
+
+type benchResult struct {
+	phase   string
+	commits int
+	took    time.Duration
+	alloc   uint64
+	allocs  uint64
+}
+
+// measure runs one phase, which reports how many commits it went through.
+func measure(phase string, run func() (int, error)) (benchResult, error) {
+	var before, after runtime.MemStats
+	runtime.GC()
+	runtime.ReadMemStats(&before)
+	start := time.Now()
+	n, err := run()
+	took := time.Since(start)
+	runtime.ReadMemStats(&after)
+	return benchResult{
+		phase:   phase,
+		commits: n,
+		took:    took,
+		alloc:   after.TotalAlloc - before.TotalAlloc,
+		allocs:  after.Mallocs - before.Mallocs,
+	}, err
+}
+
+func writeBench(out io.Writer, results []benchResult) {
+	tw := tabwriter.NewWriter(out, 0, 0, 2, ' ', tabwriter.AlignRight)
+	fmt.Fprintln(tw, "phase\tcommits\ttime\tper commit\tallocated\tallocs\t")
+	for _, r := range results {
+		per := time.Duration(0)
+		if r.commits > 0 {
+			per = r.took / time.Duration(r.commits)
+		}
+		fmt.Fprintf(tw, "%s\t%d\t%s\t%s\t%s\t%d\t\n", r.phase, r.commits,
+			r.took.Round(time.Microsecond), per.Round(time.Nanosecond*100), gitgraph.FormatSize(int64(r.alloc)), r.allocs)
+	}
+	tw.Flush()
+	var mem runtime.MemStats
+	runtime.ReadMemStats(&mem)
+	fmt.Fprintf(out, "\nheap in use %s, %s, %d CPUs\n", gitgraph.FormatSize(int64(mem.HeapInuse)), runtime.Version(), runtime.NumCPU())
+}
+
+func init() {
+	benchCmd.Flags().Int("diffs", 200, "how many of the newest commits to diff")
+	benchCmd.Flags().String("cpuprofile", "", "write a CPU profile (go tool pprof) to this file")
+	benchCmd.Flags().String("memprofile", "", "write a heap profile to this file after the run")
+	rootCmd.AddCommand(benchCmd)
+}
@@ -0,0 +1,81 @@
+package cmd
+
+import (
+	"errors"
+	"fmt"
+	"path/filepath"
+	"strings"
+
+	"arbor/internal/gitgraph"
+
+	git "github.com/go-git/go-git/v5"
+	"github.com/spf13/cobra"
+)
+
+var blameCmd = &cobra.Command{
+	Use:   "blame <file>",
+	Short: "Print each line of a file with the commit that last changed it",
+	Args:  cobra.ExactArgs(1),
+	RunE: func(cmd *cobra.Command, args []string) error {
+		rev, _ := cmd.Flags().GetString("rev")
+		repo, root, err := openRepo()
+		if err != nil {
+			return err
+		}
+		path, err := repoPath(root, args[0])
+		if err != nil {
+			return err
+		}
+		commit, err := gitgraph.ResolveCommit(repo, rev)
+		if err != nil {
+			return err
+		}
+		abbrev := loadAbbrev(repo)
+		if _, err := commit.File(path); err != nil {
+			return fmt.Errorf("%s is not a file in %s", path, abbrev.Short(commit.Hash))
+		}
+		result, err := git.Blame(commit, path)
+		if err != nil {
+			return err
+		}
+		width := 0
+		for _, line := range result.Lines {
+			width = max(width, len(line.AuthorName))
+		}
+		out := cmd.OutOrStdout()
+		for i, line := range result.Lines {
+			fmt.Fprintf(out, "%s (%-*s %s %*d) %s\n", abbrev.Short(line.Hash), width, line.AuthorName,
+				line.Date.Format("2006-01-02"), len(fmt.Sprint(len(result.Lines))), i+1, line.Text)
+		}
+		return nil
+	},
+}
+
+// repoPath makes a path given on the command line relative to the top of
+// the repository, as go-git wants it; in a bare repository it already is.
+func repoPath(root, path string) (string, error) {
+	if root == "" {
+		return filepath.ToSlash(filepath.Clean(path)), nil
+	}
+	abs, err := filepath.Abs(path)
+	if err != nil {
+		return "", err
+	}
+	if resolved, err := filepath.EvalSymlinks(root); err == nil {
+		root = resolved
+	}
+	if dir, err := filepath.EvalSymlinks(filepath.Dir(abs)); err == nil {
+		abs = filepath.Join(dir, filepath.Base(abs))
+	}
+	rel, err := filepath.Rel(root, abs)
+	if err != nil || rel == ".." || strings.HasPrefix(rel, ".."+string(filepath.Separator)) {
+		return "", errors.New(path + " is outside the repository")
+	}
+	return filepath.ToSlash(rel), nil
+}
+
+func init() {
+	blameCmd.Flags().String("rev", "HEAD", "blame the file as of this commit")
+	_ = blameCmd.RegisterFlagCompletionFunc("rev", completeRefs)
+	rootCmd.AddCommand(blameCmd)
+}
@@ -0,0 +1,174 @@
+package cmd
+
+import (
+	"fmt"
+	"strings"
+
+	"arbor/internal/config"
+	"arbor/internal/gitgraph"
+
+	tea "github.com/charmbracelet/bubbletea"
+	git "github.com/go-git/go-git/v5"
+	"github.com/go-git/go-git/v5/plumbing/object"
+	"github.com/spf13/cobra"
+)
+
+// setup is what most commands start with: the repository around the
+// working directory, its worktree root ("" when bare), and the config with
+// cmd's flags applied.
+func setup(cmd *cobra.Command) (*git.Repository, string, *config.Config, error) {
+	repo, path, err := openRepo()
+	if err != nil {
+		return nil, "", nil, err
+	}
+	cfg, err := loadConfig(cmd, path)
+	if err != nil {
+		return nil, "", nil, err
+	}
+	return repo, path, cfg, nil
+}
+
+// resolveRange reads a revision argument: base..head, where an empty head
+// is HEAD, or a lone revision, for which base is nil.
+func resolveRange(repo *git.Repository, arg string) (base, head *object.Commit, err error) {
+	baseRev, headRev, isRange := strings.Cut(arg, "..")
+	if !isRange {
+		head, err = gitgraph.ResolveCommit(repo, arg)
+		return nil, head, err
+	}
+	if headRev == "" {
+		headRev = "HEAD"
+	}
+	if base, err = gitgraph.ResolveCommit(repo, baseRev); err != nil {
+		return nil, nil, err
+	}
+	if head, err = gitgraph.ResolveCommit(repo, headRev); err != nil {
+		return nil, nil, err
+	}
+	return base, head, nil
+}
+
+// loadAbbrev reads repo's abbreviation index before returning, so printed
+// hashes are as long as they need to be.
+func loadAbbrev(repo *git.Repository) *gitgraph.Abbrev {
+	abbrev := gitgraph.NewAbbrev(repo)
+	abbrev.Wait()
+	return abbrev
+}
+
+func openRepo() (*git.Repository, string, error) {
+	repo, err := git.PlainOpenWithOptions(".", &git.PlainOpenOptions{DetectDotGit: true, EnableDotGitCommonDir: true})
+	if err != nil {
+		return nil, "", fmt.Errorf("open git repository: %w", err)
+	}
+	wt, err := repo.Worktree()
+	if err != nil {
+		return repo, "", nil
+	}
+	return repo, wt.Filesystem.Root(), nil
+}
+
+func loadConfig(cmd *cobra.Command, repoRoot string) (*config.Config, error) {
+	cfg, sources, err := config.Load(repoRoot)
+	if err != nil {
+		return nil, err
+	}
+	for _, src := range sources {
+		for _, ignored := range src.Ignored {
+			fmt.Fprintf(cmd.ErrOrStderr(), "arbor: %s: ignoring %s\n", src.Path, ignored)
+		}
+	}
+	name, _ := cmd.Flags().GetString("profile")
+	if _, err := cfg.ApplyProfile(name, repoRoot); err != nil {
+		return nil, err
+	}
+	applyFlags(cmd, cfg)
+	if errs := cfg.Validate(); len(errs) > 0 {
+		return nil, fmt.Errorf("invalid config: %w (run `arbor config validate` for details)", errs[0])
+	}
+	return cfg, nil
+}
+
+// newProvider walks the history cfg selects: --all, --limit, --order, pull
+// request heads, --first-parent, and the parent count filters.
+func newProvider(repo *git.Repository, cfg *config.Config) (*gitgraph.CommitProvider, error) {
+	provider, err := gitgraph.NewCommitProvider(repo, cfg.All, cfg.Limit)
+	if err != nil {
+		return nil, err
+	}
+	provider.FilterParents(gitgraph.ParentFilter{Min: cfg.MinParents, Max: cfg.MaxParents})
+	provider.SortBy(gitgraph.Order(cfg.Order))
+	if cfg.FirstParent {
+		provider.FollowFirstParent()
+	}
+	if cfg.PullRequests {
+		if err := provider.IncludePullRequests(); err != nil {
+			return nil, err
+		}
+	}
+	return provider, nil
+}
+
+// pathFilter parses the pathspecs given after --, nil for none.
+func pathFilter(args []string) (*gitgraph.Pathspec, error) {
+	if len(args) == 0 {
+		return nil, nil
+	}
+	return gitgraph.ParsePathspec(args)
+}
+
+func applyFlags(cmd *cobra.Command, cfg *config.Config) {
+	flags := cmd.Flags()
+	if flags.Changed("all") {
+		cfg.All, _ = flags.GetBool("all")
+	}
+	if flags.Changed("pull-requests") {
+		cfg.PullRequests, _ = flags.GetBool("pull-requests")
+	}
+	if flags.Changed("limit") {
+		cfg.Limit, _ = flags.GetInt("limit")
+	}
+	if flags.Changed("min-parents") {
+		cfg.MinParents, _ = flags.GetInt("min-parents")
+	}
+	if flags.Changed("max-parents") {
+		cfg.MaxParents, _ = flags.GetInt("max-parents")
+	}
+	if merges, _ := flags.GetBool("merges"); merges {
+		cfg.MinParents = 2
+	}
+	if noMerges, _ := flags.GetBool("no-merges"); noMerges {
+		cfg.MaxParents = 1
+	}
+	if flags.Changed("first-parent") {
+		cfg.FirstParent, _ = flags.GetBool("first-parent")
+	}
+	if flags.Changed("order") {
+		cfg.Order, _ = flags.GetString("order")
+	}
+	if flags.Changed("start") {
+		cfg.Start, _ = flags.GetString("start")
+	}
+	if flags.Changed("mine") {
+		cfg.Mine, _ = flags.GetBool("mine")
+	}
+	if flags.Changed("watch") {
+		cfg.Watch, _ = flags.GetBool("watch")
+	}
+	if flags.Changed("theme") {
+		cfg.Theme, _ = flags.GetString("theme")
+	}
+	if flags.Changed("read-only") {
+		cfg.ReadOnly, _ = flags.GetBool("read-only")
+	}
+}
+
+// runTUI runs model full screen until it quits, then closes it if it
+// holds anything open, such as a provider's prefetcher.
+func runTUI(model tea.Model, opts ...tea.ProgramOption) error {
+	_, err := tea.NewProgram(model, append([]tea.ProgramOption{tea.WithAltScreen()}, opts...)...).Run()
+	if closer, ok := model.(interface{ Close() }); ok {
+		closer.Close()
+	}
+	return err
+}
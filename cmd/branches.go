@@ -0,0 +1,60 @@
+package cmd
+
+import (
+	"fmt"
+	"strings"
+	"text/tabwriter"
+
+	"arbor/internal/gitgraph"
+
+	"github.com/spf13/cobra"
+)
+
+var branchesCmd = &cobra.Command{
+	Use:   "branches",
+	Short: "Show ahead/behind counts for every local branch vs. its upstream and main",
+	Args:  cobra.NoArgs,
+	RunE: func(cmd *cobra.Command, args []string) error {
+		sortKey, _ := cmd.Flags().GetString("sort")
+		repo, _, err := openRepo()
+		if err != nil {
+			return err
+		}
+		statuses, err := gitgraph.BranchStatuses(repo, gitgraph.NewGenerations(repo))
+		if err != nil {
+			return err
+		}
+		if !gitgraph.SortBranchStatuses(statuses, sortKey) {
+			return fmt.Errorf("unknown sort key %q (use %s)", sortKey, strings.Join(gitgraph.BranchSortKeys, ", "))
+		}
+
+		base := "MAIN"
+		if len(statuses) > 0 && statuses[0].Base != "" {
+			base = strings.ToUpper(statuses[0].Base)
+		}
+		w := tabwriter.NewWriter(cmd.OutOrStdout(), 0, 4, 2, ' ', 0)
+		fmt.Fprintf(w, "BRANCH\tUPSTREAM\t↑\t↓\t%s ↑\t%s ↓\tLAST COMMIT\n", base, base)
+		for _, s := range statuses {
+			upstream, upAhead, upBehind := "-", "", ""
+			switch {
+			case s.UpstreamGone:
+				upstream = s.Upstream + " (gone)"
+			case s.Upstream != "":
+				upstream = s.Upstream
+				upAhead, upBehind = fmt.Sprint(s.UpstreamAhead), fmt.Sprint(s.UpstreamBehind)
+			}
+			baseAhead, baseBehind := "", ""
+			if s.Base != "" {
+				baseAhead, baseBehind = fmt.Sprint(s.BaseAhead), fmt.Sprint(s.BaseBehind)
+			}
+			fmt.Fprintf(w, "%s\t%s\t%s\t%s\t%s\t%s\t%s\n", s.Branch, upstream, upAhead, upBehind,
+				baseAhead, baseBehind, s.Tip.Committer.When.Format("2006-01-02"))
+		}
+		return w.Flush()
+	},
+}
+
+func init() {
+	branchesCmd.Flags().String("sort", "name", "order by name, date, ahead, behind, unpushed, or unpulled")
+	rootCmd.AddCommand(branchesCmd)
+}
@@ -0,0 +1,57 @@
+package cmd
+
+import (
+	"errors"
+	"fmt"
+	"path/filepath"
+
+	"arbor/internal/gitgraph"
+	"arbor/internal/gitops"
+
+	"github.com/spf13/cobra"
+)
+
+var bundleCmd = &cobra.Command{
+	Use:   "bundle <range>... -o <file>",
+	Short: "Write the commits of a range or branch to a git bundle file",
+	Long: `Write the commits a range selects, and the branches and tags it names, to
+a file made by git bundle create, to carry to a clone without network
+access. There, fetch from it like a remote, or check it first with
+arbor compare-remote:
+
+  arbor bundle origin/main..feature -o feature.bundle
+  git fetch /mnt/usb/feature.bundle feature:feature
+
+The receiving clone needs the commits the range leaves out (origin/main
+above); a bundle of a whole branch has no such requirement.`,
+	Args:              cobra.MinimumNArgs(1),
+	ValidArgsFunction: completeRefs,
+	RunE: func(cmd *cobra.Command, args []string) error {
+		output, _ := cmd.Flags().GetString("output")
+		if output == "" {
+			return errors.New("name the bundle file with -o")
+		}
+		file, err := filepath.Abs(output)
+		if err != nil {
+			return err
+		}
+		repo, path, err := openRepo()
+		if err != nil {
+			return err
+		}
+		if path == "" {
+			path = gitgraph.GitDir(repo)
+		}
+		count, err := gitops.New(path).CreateBundle(file, args...)
+		if err != nil {
+			return err
+		}
+		fmt.Fprintf(cmd.OutOrStdout(), "Wrote %d commits to %s\n", count, output)
+		return nil
+	},
+}
+
+func init() {
+	bundleCmd.Flags().StringP("output", "o", "", "bundle file to write")
+	rootCmd.AddCommand(bundleCmd)
+}
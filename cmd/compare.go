@@ -0,0 +1,76 @@
+package cmd
+
+import (
+	"fmt"
+	"io"
+
+	"arbor/internal/gitgraph"
+
+	"github.com/spf13/cobra"
+)
+
+var compareRemoteCmd = &cobra.Command{
+	Use:   "compare-remote <path-or-bundle>",
+	Short: "Show how another clone or a git bundle has diverged from this repository",
+	Long: `Compare this repository's branches and tags with another clone's, or with
+the refs in a file made by git bundle create, and list the commits only one
+side has. Nothing is fetched: the other side's objects are read in place,
+so it works for checking an air-gapped copy before or after carrying a
+bundle across.
+
+  arbor compare-remote /mnt/usb/project.bundle
+  arbor compare-remote ../project-mirror
+
+Branch markers are as in arbor branches: ↑ counts the commits only here,
+↓ those only there. A bundle has to build on commits this repository has,
+as git bundle verify requires.`,
+	Args: cobra.ExactArgs(1),
+	RunE: func(cmd *cobra.Command, args []string) error {
+		repo, _, err := openRepo()
+		if err != nil {
+			return err
+		}
+		other, err := gitgraph.OpenOther(repo, args[0])
+		if err != nil {
+			return err
+		}
+		diff, err := gitgraph.CompareOther(repo, other)
+		if err != nil {
+			return err
+		}
+		writeComparison(cmd.OutOrStdout(), loadAbbrev(repo), args[0], diff)
+		return nil
+	},
+}
+
+// writeComparison prints the refs that differ, then the commits only
+// there and only here, noting local commits the other side rewrote.
+func writeComparison(w io.Writer, abbrev *gitgraph.Abbrev, there string, diff *gitgraph.PositionDiff) {
+	if len(diff.Moved) == 0 {
+		fmt.Fprintf(w, "Branches and tags match %s\n", there)
+		return
+	}
+	fmt.Fprintf(w, "Refs that differ (here → %s)\n", there)
+	width := 0
+	for _, move := range diff.Moved {
+		width = max(width, len(shortRefName(move.Name)))
+	}
+	for _, move := range diff.Moved {
+		marker := ""
+		switch {
+		case move.Old.IsZero():
+			marker = "only there"
+		case move.New.IsZero():
+			marker = "only here"
+		default:
+			marker = gitgraph.RemoteTip{Ahead: move.Behind, Behind: move.Ahead}.Marker()
+		}
+		fmt.Fprintf(w, "  %-*s  %s → %s  %s\n", width, shortRefName(move.Name), shortHash(abbrev, move.Old), shortHash(abbrev, move.New), marker)
+	}
+	writeCommitList(w, abbrev, "Only there", "+", diff.New, nil)
+	writeCommitList(w, abbrev, "Only here", "-", diff.Dropped, diff.Rewritten)
+}
+
+func init() {
+	rootCmd.AddCommand(compareRemoteCmd)
+}
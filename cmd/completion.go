@@ -0,0 +1,76 @@
+package cmd
+
+import (
+	"fmt"
+	"os"
+	"strings"
+
+	git "github.com/go-git/go-git/v5"
+	"github.com/go-git/go-git/v5/plumbing"
+	"github.com/spf13/cobra"
+	"github.com/spf13/cobra/doc"
+)
+
+var manCmd = &cobra.Command{
+	Use:   "man",
+	Short: "Generate man pages (stdout for arbor(1), or a full tree with --dir)",
+	Args:  cobra.NoArgs,
+	RunE: func(cmd *cobra.Command, args []string) error {
+		dir, _ := cmd.Flags().GetString("dir")
+		header := &doc.GenManHeader{Title: "ARBOR", Section: "1", Source: "arbor"}
+		if dir == "" {
+			return doc.GenMan(rootCmd, header, cmd.OutOrStdout())
+		}
+		if err := os.MkdirAll(dir, 0o755); err != nil {
+			return err
+		}
+		if err := doc.GenManTree(rootCmd, header, dir); err != nil {
+			return err
+		}
+		fmt.Fprintf(cmd.OutOrStdout(), "wrote man pages to %s\n", dir)
+		return nil
+	},
+}
+
+// completeRefs offers branch, remote-tracking, and tag names for arguments
+// and flags that accept a revision.
+func completeRefs(cmd *cobra.Command, args []string, toComplete string) ([]string, cobra.ShellCompDirective) {
+	repo, err := git.PlainOpenWithOptions(".", &git.PlainOpenOptions{DetectDotGit: true, EnableDotGitCommonDir: true})
+	if err != nil {
+		return nil, cobra.ShellCompDirectiveNoFileComp
+	}
+	iter, err := repo.References()
+	if err != nil {
+		return nil, cobra.ShellCompDirectiveNoFileComp
+	}
+	defer iter.Close()
+
+	var names []string
+	_ = iter.ForEach(func(ref *plumbing.Reference) error {
+		name := ref.Name()
+		if !name.IsBranch() && !name.IsRemote() && !name.IsTag() {
+			return nil
+		}
+		short := name.Short()
+		if strings.HasPrefix(short, toComplete) {
+			names = append(names, short)
+		}
+		return nil
+	})
+	return names, cobra.ShellCompDirectiveNoFileComp
+}
+
+func completeRefArgs(n int) cobra.CompletionFunc {
+	return func(cmd *cobra.Command, args []string, toComplete string) ([]string, cobra.ShellCompDirective) {
+		if len(args) >= n {
+			return nil, cobra.ShellCompDirectiveNoFileComp
+		}
+		return completeRefs(cmd, args, toComplete)
+	}
+}
+
+func init() {
+	manCmd.Flags().String("dir", "", "write one page per command into this directory")
+	_ = manCmd.MarkFlagDirname("dir")
+	rootCmd.AddCommand(manCmd)
+}
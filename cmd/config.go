@@ -0,0 +1,163 @@
+package cmd
+
+import (
+	"errors"
+	"fmt"
+	"io/fs"
+	"os"
+	"path/filepath"
+
+	"arbor/internal/config"
+
+	"github.com/spf13/cobra"
+)
+
+var configCmd = &cobra.Command{
+	Use:   "config",
+	Short: "Create, check, and inspect arbor configuration",
+}
+
+var configInitCmd = &cobra.Command{
+	Use:   "init",
+	Short: "Write a default config file",
+	Args:  cobra.NoArgs,
+	RunE: func(cmd *cobra.Command, args []string) error {
+		repoLocal, _ := cmd.Flags().GetBool("repo")
+		force, _ := cmd.Flags().GetBool("force")
+
+		var path string
+		if repoLocal {
+			_, root, err := openRepo()
+			if err != nil {
+				return err
+			}
+			if root == "" {
+				return fmt.Errorf("repository has no worktree for %s", config.RepoFileName)
+			}
+			path = config.RepoPath(root)
+		} else {
+			global, err := config.GlobalPath()
+			if err != nil {
+				return fmt.Errorf("locate config directory: %w", err)
+			}
+			path = global
+		}
+
+		if _, err := os.Stat(path); err == nil && !force {
+			return fmt.Errorf("%s already exists (use --force to overwrite)", path)
+		}
+		if err := os.MkdirAll(filepath.Dir(path), 0o755); err != nil {
+			return err
+		}
+		if err := os.WriteFile(path, []byte(config.Template), 0o644); err != nil {
+			return err
+		}
+		fmt.Fprintf(cmd.OutOrStdout(), "wrote %s\n", path)
+		return nil
+	},
+}
+
+var configValidateCmd = &cobra.Command{
+	Use:           "validate [file...]",
+	Short:         "Check config files for errors (defaults to the global and repo-local files)",
+	SilenceErrors: true,
+	SilenceUsage:  true,
+	RunE: func(cmd *cobra.Command, args []string) error {
+		paths := args
+		if len(paths) == 0 {
+			paths = defaultConfigPaths()
+		}
+
+		out := cmd.OutOrStdout()
+		failed := false
+		checked := 0
+		for _, path := range paths {
+			if _, err := os.Stat(path); errors.Is(err, fs.ErrNotExist) {
+				if len(args) > 0 {
+					fmt.Fprintf(out, "%s: not found\n", path)
+					failed = true
+				}
+				continue
+			}
+			checked++
+			errs := config.ValidateFile(path)
+			if len(errs) == 0 {
+				fmt.Fprintf(out, "%s: ok\n", path)
+				continue
+			}
+			failed = true
+			fmt.Fprintf(out, "%s:\n", path)
+			for _, err := range errs {
+				fmt.Fprintf(out, "  - %v\n", err)
+			}
+		}
+		if checked == 0 && !failed {
+			fmt.Fprintln(out, "no config files found (run `arbor config init`)")
+		}
+		if failed {
+			return &exitError{code: 1}
+		}
+		return nil
+	},
+}
+
+var configShowCmd = &cobra.Command{
+	Use:   "show",
+	Short: "Print the effective configuration (global + repo-local + profile + flags)",
+	Args:  cobra.NoArgs,
+	RunE: func(cmd *cobra.Command, args []string) error {
+		root := ""
+		if _, path, err := openRepo(); err == nil {
+			root = path
+		}
+		cfg, sources, err := config.Load(root)
+		if err != nil {
+			return err
+		}
+		name, _ := cmd.Flags().GetString("profile")
+		profile, err := cfg.ApplyProfile(name, root)
+		if err != nil {
+			return err
+		}
+		applyFlags(cmd, cfg)
+
+		out := cmd.OutOrStdout()
+		for _, src := range sources {
+			state := "not found"
+			if src.Loaded {
+				state = "loaded"
+			}
+			fmt.Fprintf(out, "# %s (%s)\n", src.Path, state)
+			for _, ignored := range src.Ignored {
+				fmt.Fprintf(out, "#   ignoring %s\n", ignored)
+			}
+		}
+		if profile != nil {
+			fmt.Fprintf(out, "# profile %s\n", profile.Name)
+		}
+		encoded, err := cfg.Encode()
+		if err != nil {
+			return err
+		}
+		fmt.Fprint(out, encoded)
+		return nil
+	},
+}
+
+func defaultConfigPaths() []string {
+	var paths []string
+	if global, err := config.GlobalPath(); err == nil {
+		paths = append(paths, global)
+	}
+	if _, root, err := openRepo(); err == nil && root != "" {
+		paths = append(paths, config.RepoPath(root))
+	}
+	return paths
+}
+
+func init() {
+	configInitCmd.Flags().Bool("repo", false, "write "+config.RepoFileName+" in the current repository instead of the global config")
+	configInitCmd.Flags().Bool("force", false, "overwrite an existing config file")
+	configCmd.AddCommand(configInitCmd, configValidateCmd, configShowCmd)
+	rootCmd.AddCommand(configCmd)
+}
@@ -0,0 +1,71 @@
+package cmd
+
+import (
+	"fmt"
+	"os"
+
+	"arbor/internal/config"
+	"arbor/internal/tui"
+
+	"github.com/spf13/cobra"
+)
+
+var dashboardCmd = &cobra.Command{
+	Use:   "dashboard [workspace]",
+	Short: "Summarize a workspace's repositories side by side, and open arbor on one",
+	Long: `Show a table of the repositories a [[workspaces]] entry in the config
+lists (the first entry, or the one named): each one's branch, uncommitted
+changes to tracked files, ahead/behind its upstream, and last commit.
+
+enter opens the full arbor view on the selected repository, with its own
+config and profile; quitting it returns to the dashboard, refreshed.`,
+	Args:          cobra.MaximumNArgs(1),
+	SilenceErrors: true,
+	SilenceUsage:  true,
+	RunE: func(cmd *cobra.Command, args []string) error {
+		cfg, _, err := config.Load("")
+		if err != nil {
+			return err
+		}
+		applyFlags(cmd, cfg)
+		if errs := cfg.Validate(); len(errs) > 0 {
+			return fmt.Errorf("invalid config: %w (run `arbor config validate` for details)", errs[0])
+		}
+		name := ""
+		if len(args) == 1 {
+			name = args[0]
+		}
+		workspace, err := cfg.Workspace(name)
+		if err != nil {
+			return err
+		}
+		repos := workspace.Paths()
+		start, err := os.Getwd()
+		if err != nil {
+			return err
+		}
+		defer os.Chdir(start)
+
+		selected := ""
+		for {
+			dashboard := tui.NewDashboardModel(cfg, workspace.Name, repos, selected)
+			if err := runTUI(dashboard); err != nil {
+				return err
+			}
+			selected = dashboard.Picked()
+			if selected == "" {
+				return nil
+			}
+			if err := os.Chdir(selected); err != nil {
+				return err
+			}
+			if err := runLog(cmd, nil); err != nil {
+				return err
+			}
+		}
+	},
+}
+
+func init() {
+	rootCmd.AddCommand(dashboardCmd)
+}
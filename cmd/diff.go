@@ -0,0 +1,92 @@
+package cmd
+
+import (
+	"fmt"
+	"io"
+	"text/tabwriter"
+
+	"arbor/internal/gitgraph"
+
+	"github.com/go-git/go-git/v5/plumbing/object"
+	"github.com/spf13/cobra"
+)
+
+var diffCmd = &cobra.Command{
+	Use:   "diff [commit] [other]",
+	Short: "Print a commit's change against its first parent, or the change from one commit to another",
+	Long: `Print the patch a commit made against its first parent (default HEAD), or
+with two commits, the change from the first to the second, as the sidebar's
+file diffs show it, files with a textconv diff driver compared as its
+output. Headers follow diff.noprefix, diff.srcPrefix and
+diff.dstPrefix, and hunks diff.context, as git show's do.`,
+	Args:              cobra.MaximumNArgs(2),
+	ValidArgsFunction: completeRefArgs(2),
+	RunE: func(cmd *cobra.Command, args []string) error {
+		stat, _ := cmd.Flags().GetBool("stat")
+		repo, _, err := openRepo()
+		if err != nil {
+			return err
+		}
+		rev := "HEAD"
+		if len(args) > 0 {
+			rev = args[0]
+		}
+		commit, err := gitgraph.ResolveCommit(repo, rev)
+		if err != nil {
+			return err
+		}
+		var changes object.Changes
+		if len(args) == 2 {
+			to, err := gitgraph.ResolveCommit(repo, args[1])
+			if err != nil {
+				return err
+			}
+			changes, err = gitgraph.TreeChanges(commit, to)
+			if err != nil {
+				return err
+			}
+		} else if changes, err = gitgraph.CommitChanges(commit); err != nil {
+			return err
+		}
+		attrs := gitgraph.LoadAttributes(repo)
+		if stat {
+			stats, err := attrs.NumStat(changes)
+			if err != nil {
+				return err
+			}
+			return writeDiffStat(cmd.OutOrStdout(), stats)
+		}
+		patch, err := attrs.Patch(changes)
+		if err != nil {
+			return err
+		}
+		_, err = io.WriteString(cmd.OutOrStdout(), gitgraph.LoadDiffFormat(repo).Format(patch))
+		return err
+	},
+}
+
+// writeDiffStat lists each file with its lines added and deleted, "-" for
+// a binary file, then the totals, as git diff --stat does in numbers.
+func writeDiffStat(out io.Writer, stats []gitgraph.FileStat) error {
+	w := tabwriter.NewWriter(out, 0, 4, 2, ' ', tabwriter.AlignRight)
+	added, deleted := 0, 0
+	for _, s := range stats {
+		if s.Binary {
+			fmt.Fprintf(w, "-\t-\t %s\n", s.Path)
+			continue
+		}
+		fmt.Fprintf(w, "+%d\t-%d\t %s\n", s.Added, s.Deleted, s.Path)
+		added += s.Added
+		deleted += s.Deleted
+	}
+	if err := w.Flush(); err != nil {
+		return err
+	}
+	_, err := fmt.Fprintf(out, "%d files changed, %d insertions(+), %d deletions(-)\n", len(stats), added, deleted)
+	return err
+}
+
+func init() {
+	diffCmd.Flags().Bool("stat", false, "list the files changed with their line counts instead of the patch")
+	rootCmd.AddCommand(diffCmd)
+}
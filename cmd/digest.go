@@ -0,0 +1,132 @@
+package cmd
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"slices"
+	"strings"
+	"time"
+
+	"arbor/internal/digest"
+	"arbor/internal/gitgraph"
+
+	git "github.com/go-git/go-git/v5"
+	"github.com/spf13/cobra"
+)
+
+var digestCmd = &cobra.Command{
+	Use:   "digest",
+	Short: "Summarize recent commits by author and area for team updates",
+	Args:  cobra.NoArgs,
+	RunE: func(cmd *cobra.Command, args []string) error {
+		since, _ := cmd.Flags().GetString("since")
+		sinceTag, _ := cmd.Flags().GetBool("since-tag")
+		format, _ := cmd.Flags().GetString("format")
+		depth, _ := cmd.Flags().GetInt("depth")
+		output, _ := cmd.Flags().GetString("output")
+		groupBy, _ := cmd.Flags().GetString("group-by")
+		if format != "md" && format != "html" {
+			return fmt.Errorf("unknown format %q (use md or html)", format)
+		}
+		if !slices.Contains(digest.GroupByNames, groupBy) {
+			return fmt.Errorf("unknown grouping %q (use %s)", groupBy, strings.Join(digest.GroupByNames, ", "))
+		}
+
+		now := time.Now()
+		repo, path, err := openRepo()
+		if err != nil {
+			return err
+		}
+		var cutoff time.Time
+		if sinceTag {
+			cutoff, err = lastTagTime(repo)
+		} else {
+			cutoff, err = gitgraph.ParseSince(since, now)
+		}
+		if err != nil {
+			return err
+		}
+		cfg, err := loadConfig(cmd, path)
+		if err != nil {
+			return err
+		}
+		if groupBy == "team" && len(cfg.Teams) == 0 {
+			return fmt.Errorf("--group-by team needs [[teams]] in the config")
+		}
+		provider, err := newProvider(repo, cfg)
+		if err != nil {
+			return err
+		}
+		// The window of the same length before cutoff gives the trends.
+		span, err := provider.Since(cutoff.Add(-now.Sub(cutoff)))
+		if err != nil {
+			return err
+		}
+		commits, previous := []*gitgraph.CommitInfo{}, []*gitgraph.CommitInfo{}
+		for _, commit := range span {
+			if commit.When.Before(cutoff) {
+				previous = append(previous, commit)
+			} else {
+				commits = append(commits, commit)
+			}
+		}
+		d, err := digest.Build(filepath.Base(path), cutoff, now, commits, previous, digest.Options{
+			Depth:   depth,
+			GroupBy: groupBy,
+			Teams:   cfg.TeamList(),
+		})
+		if err != nil {
+			return err
+		}
+
+		out := cmd.OutOrStdout()
+		if output != "" {
+			f, err := os.Create(output)
+			if err != nil {
+				return err
+			}
+			defer f.Close()
+			out = f
+		}
+		if format == "html" {
+			return d.WriteHTML(out)
+		}
+		return d.WriteMarkdown(out)
+	},
+}
+
+// lastTagTime is when the tag nearest HEAD was committed, so a digest can
+// cover everything since the last release.
+func lastTagTime(repo *git.Repository) (time.Time, error) {
+	head, err := gitgraph.ResolveCommit(repo, "HEAD")
+	if err != nil {
+		return time.Time{}, err
+	}
+	ix, err := gitgraph.NewTagIndex(repo, nil, gitgraph.NewGenerations(repo))
+	if err != nil {
+		return time.Time{}, err
+	}
+	desc, ok, err := ix.Describe(head.Hash)
+	if err != nil {
+		return time.Time{}, err
+	}
+	if !ok {
+		return time.Time{}, fmt.Errorf("no tag is reachable from HEAD")
+	}
+	tagged, err := repo.CommitObject(desc.TagHash)
+	if err != nil {
+		return time.Time{}, err
+	}
+	return tagged.Committer.When, nil
+}
+
+func init() {
+	digestCmd.Flags().String("since", "1w", "how far back to look (e.g. 3d, 1w, 1mo, or 2006-01-02)")
+	digestCmd.Flags().Bool("since-tag", false, "cover the commits since the nearest tag reachable from HEAD")
+	digestCmd.Flags().String("format", "md", "output format: md or html")
+	digestCmd.Flags().String("group-by", "author", "group people by author, domain (of their email), or team ([[teams]] in the config)")
+	digestCmd.Flags().Int("depth", 1, "directory depth used to group files into areas")
+	digestCmd.Flags().StringP("output", "o", "", "write to a file instead of stdout")
+	rootCmd.AddCommand(digestCmd)
+}
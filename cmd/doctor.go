@@ -0,0 +1,40 @@
+package cmd
+
+import (
+	"fmt"
+
+	"arbor/internal/doctor"
+
+	"github.com/spf13/cobra"
+)
+
+var doctorCmd = &cobra.Command{
+	Use:           "doctor",
+	Short:         "Report repository maintenance issues (exit status 1 if any)",
+	Args:          cobra.NoArgs,
+	SilenceErrors: true,
+	SilenceUsage:  true,
+	RunE: func(cmd *cobra.Command, args []string) error {
+		repo, _, err := openRepo()
+		if err != nil {
+			return err
+		}
+		issues, err := doctor.Check(repo)
+		if err != nil {
+			return err
+		}
+		out := cmd.OutOrStdout()
+		if len(issues) == 0 {
+			fmt.Fprintln(out, "no maintenance issues found")
+			return nil
+		}
+		for _, issue := range issues {
+			fmt.Fprintf(out, "%s [%s]\n  %s\n  fix: %s\n", issue.Summary, issue.Code, issue.Detail, issue.Fix)
+		}
+		return &exitError{code: 1}
+	},
+}
+
+func init() {
+	rootCmd.AddCommand(doctorCmd)
+}
@@ -0,0 +1,71 @@
+package cmd
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"time"
+
+	"arbor/internal/export"
+	"arbor/internal/gitgraph"
+
+	"github.com/spf13/cobra"
+)
+
+var exportCmd = &cobra.Command{
+	Use:   "export",
+	Short: "Write the commit tree as a standalone, searchable HTML page",
+	Long: `Write the commit tree as a single HTML file with the graph, refs, each
+commit's message (click a row), and a search box, for sharing history with
+people who do not use a terminal. It needs no server or network access.
+
+The history is the one the TUI would show: --all, --limit, --order, and the
+parent filters apply.`,
+	Args:          cobra.NoArgs,
+	SilenceErrors: true,
+	SilenceUsage:  true,
+	RunE: func(cmd *cobra.Command, args []string) error {
+		format, _ := cmd.Flags().GetString("format")
+		output, _ := cmd.Flags().GetString("output")
+		if format != "html" {
+			return fmt.Errorf("unknown format %q (use html)", format)
+		}
+		repo, path, cfg, err := setup(cmd)
+		if err != nil {
+			return err
+		}
+		provider, err := newProvider(repo, cfg)
+		if err != nil {
+			return err
+		}
+		for provider.HasMore() {
+			if err := provider.Ensure(len(provider.Commits) + 1000); err != nil {
+				return err
+			}
+		}
+		refs, err := gitgraph.ListRefs(repo)
+		if err != nil {
+			return err
+		}
+		refs = gitgraph.LoadDecorationFilter(repo, cfg.Decorations.Include, cfg.Decorations.Exclude).Refs(refs)
+		complete := cfg.Limit == 0 || len(provider.Commits) < cfg.Limit
+		page := export.Build(filepath.Base(path), provider.Commits, refs, complete, time.Now())
+
+		out := cmd.OutOrStdout()
+		if output != "" {
+			f, err := os.Create(output)
+			if err != nil {
+				return err
+			}
+			defer f.Close()
+			out = f
+		}
+		return page.WriteHTML(out)
+	},
+}
+
+func init() {
+	exportCmd.Flags().String("format", "html", "output format: html")
+	exportCmd.Flags().StringP("output", "o", "", "write to a file instead of stdout")
+	rootCmd.AddCommand(exportCmd)
+}
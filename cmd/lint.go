@@ -0,0 +1,79 @@
+package cmd
+
+import (
+	"fmt"
+
+	"arbor/internal/gitgraph"
+
+	"github.com/go-git/go-git/v5/plumbing/object"
+	"github.com/spf13/cobra"
+)
+
+var lintCmd = &cobra.Command{
+	Use:   "lint <base>..[head] | <commit>",
+	Short: "Check commit messages against the [lint] rules (exit status 1 if any fail)",
+	Long: `Check commit messages against the [lint] rules in the config.
+
+A range such as main..HEAD checks every commit on head that base lacks, the
+way a CI job would check a pull request; a single revision checks just that
+commit. Merge commits are skipped.`,
+	Args:          cobra.ExactArgs(1),
+	SilenceErrors: true,
+	SilenceUsage:  true,
+	RunE: func(cmd *cobra.Command, args []string) error {
+		repo, _, cfg, err := setup(cmd)
+		if err != nil {
+			return err
+		}
+		flags := cmd.Flags()
+		if flags.Changed("max-subject") {
+			cfg.Lint.MaxSubject, _ = flags.GetInt("max-subject")
+		}
+		if flags.Changed("conventional") {
+			cfg.Lint.Conventional, _ = flags.GetBool("conventional")
+		}
+		opts := cfg.Lint.Options()
+
+		base, head, err := resolveRange(repo, args[0])
+		if err != nil {
+			return err
+		}
+		commits := []*object.Commit{head}
+		if base != nil {
+			if _, commits, err = gitgraph.ReviewRange(repo, gitgraph.NewGenerations(repo), base.Hash, head.Hash); err != nil {
+				return err
+			}
+		}
+
+		out := cmd.OutOrStdout()
+		abbrev := loadAbbrev(repo)
+		failed, checked := 0, 0
+		for _, commit := range commits {
+			if commit.NumParents() > 1 {
+				continue
+			}
+			checked++
+			problems := gitgraph.LintMessage(commit.Message, opts)
+			if len(problems) == 0 {
+				continue
+			}
+			failed++
+			fmt.Fprintf(out, "%s %s\n", abbrev.Short(commit.Hash), gitgraph.FirstLine(commit.Message))
+			for _, problem := range problems {
+				fmt.Fprintf(out, "  %s\n", problem)
+			}
+		}
+		if failed > 0 {
+			fmt.Fprintf(out, "%d of %d commit(s) failed\n", failed, checked)
+			return &exitError{code: 1}
+		}
+		fmt.Fprintf(out, "%d commit(s) ok\n", checked)
+		return nil
+	},
+}
+
+func init() {
+	lintCmd.Flags().Int("max-subject", 0, "subject length limit; overrides lint.max_subject (0 = off)")
+	lintCmd.Flags().Bool("conventional", false, "require Conventional Commits subjects; overrides lint.conventional")
+	rootCmd.AddCommand(lintCmd)
+}
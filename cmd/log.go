@@ -0,0 +1,138 @@
+package cmd
+
+import (
+	"fmt"
+	"os"
+
+	"arbor/internal/config"
+	"arbor/internal/gitgraph"
+	"arbor/internal/server"
+	"arbor/internal/tui"
+
+	tea "github.com/charmbracelet/bubbletea"
+	git "github.com/go-git/go-git/v5"
+	"github.com/spf13/cobra"
+)
+
+// logCmd is what arbor runs with no command: the graph TUI.
+var logCmd = &cobra.Command{
+	Use:   "log [-- <pathspec>...]",
+	Short: "Open the commit graph (what arbor runs with no command)",
+	Long: `Open the commit graph. This is what arbor runs with no command.
+
+Pathspecs after -- list only the commits changing the paths they select, as
+in git log: "docs/", "*.go", ':(exclude)vendor/', ':(glob)src/**/*.ts'.
+They are relative to the top of the repository.`,
+	Args: pathspecArgs,
+	RunE: runLog,
+}
+
+// pathspecArgs accepts arguments only after --, so a mistyped command is
+// not taken for a pathspec.
+func pathspecArgs(cmd *cobra.Command, args []string) error {
+	if dash := cmd.ArgsLenAtDash(); dash != 0 && len(args) > 0 {
+		return fmt.Errorf("unknown command %q for %q; put pathspecs after --", args[0], cmd.CommandPath())
+	}
+	return nil
+}
+
+func runLog(cmd *cobra.Command, args []string) error {
+	fromStdin, _ := cmd.Flags().GetBool("stdin")
+	headless, _ := cmd.Flags().GetBool("headless")
+	if fromStdin && headless {
+		return fmt.Errorf("--stdin and --headless both read stdin; use one")
+	}
+	if mine, _ := cmd.Flags().GetBool("mine"); mine && headless {
+		return fmt.Errorf("--mine only applies to the TUI")
+	}
+
+	if headless {
+		repo, _, cfg, err := setup(cmd)
+		if err != nil {
+			return err
+		}
+		provider, err := logProvider(repo, cfg, args, false)
+		if err != nil {
+			return err
+		}
+		return server.New(repo, provider).ServeLines(os.Stdin, cmd.OutOrStdout())
+	}
+
+	// Opening a very large repository takes a while; the TUI starts first
+	// and shows each step as it runs.
+	var (
+		repo     *git.Repository
+		path     string
+		cfg      *config.Config
+		provider *gitgraph.CommitProvider
+		model    tea.Model
+	)
+	steps := []tui.BootStep{
+		{Label: "Opening repository", Run: func() (err error) {
+			repo, path, cfg, err = setup(cmd)
+			return err
+		}},
+		{Label: "Scanning refs", Run: func() (err error) {
+			provider, err = logProvider(repo, cfg, args, fromStdin)
+			return err
+		}},
+		{Label: "Seeding graph", Run: func() error {
+			model = tui.LoadModel(path, provider, gitgraph.HeadLabel(repo), cfg)
+			return nil
+		}},
+	}
+	boot := tui.NewBootModel(steps, func() tea.Model {
+		tui.UseTheme(cfg)
+		return model
+	})
+	var opts []tea.ProgramOption
+	if fromStdin {
+		opts = append(opts, tea.WithInputTTY())
+	}
+	if err := runTUI(boot, opts...); err != nil {
+		return err
+	}
+	return boot.Err()
+}
+
+// logProvider walks the history the graph shows: the commits listed on
+// stdin or those cfg selects, limited to the pathspecs in args or, with
+// none, the configured paths.
+func logProvider(repo *git.Repository, cfg *config.Config, args []string, fromStdin bool) (*gitgraph.CommitProvider, error) {
+	if len(args) == 0 {
+		args = cfg.Paths
+	}
+	paths, err := pathFilter(args)
+	if err != nil {
+		return nil, err
+	}
+	var provider *gitgraph.CommitProvider
+	if fromStdin {
+		hashes, err := gitgraph.ReadRevList(repo, os.Stdin)
+		if err != nil {
+			return nil, err
+		}
+		provider, err = gitgraph.NewListedCommitProvider(repo, hashes, cfg.Limit)
+		if err != nil {
+			return nil, err
+		}
+		provider.FilterParents(gitgraph.ParentFilter{Min: cfg.MinParents, Max: cfg.MaxParents})
+	} else if provider, err = newProvider(repo, cfg); err != nil {
+		return nil, err
+	}
+	if paths != nil {
+		provider.FilterPaths(paths)
+	}
+	return provider, nil
+}
+
+// addLogFlags adds the flags only the graph takes, to arbor and arbor log.
+func addLogFlags(cmd *cobra.Command) {
+	cmd.Flags().Bool("headless", false, "answer JSON requests on stdin, one per line, instead of starting the TUI (for editor plugins)")
+	cmd.Flags().Bool("stdin", false, "read the commits to show, in order, from stdin (e.g. git rev-list output)")
+}
+
+func init() {
+	addLogFlags(logCmd)
+	rootCmd.AddCommand(logCmd)
+}
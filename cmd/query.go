@@ -0,0 +1,86 @@
+package cmd
+
+import (
+	"fmt"
+
+	"arbor/internal/gitgraph"
+
+	"github.com/spf13/cobra"
+)
+
+var queryCmd = &cobra.Command{
+	Use:   "query",
+	Short: "Answer ancestry questions about commits",
+}
+
+var queryAncestorCmd = &cobra.Command{
+	Use:               "ancestor <a> <b>",
+	Short:             "Report whether commit A is an ancestor of commit B (exit status 1 if not)",
+	Args:              cobra.ExactArgs(2),
+	ValidArgsFunction: completeRefArgs(2),
+	SilenceErrors:     true,
+	SilenceUsage:      true,
+	RunE: func(cmd *cobra.Command, args []string) error {
+		repo, _, err := openRepo()
+		if err != nil {
+			return err
+		}
+		a, err := gitgraph.ResolveCommit(repo, args[0])
+		if err != nil {
+			return err
+		}
+		b, err := gitgraph.ResolveCommit(repo, args[1])
+		if err != nil {
+			return err
+		}
+		ok, err := gitgraph.IsAncestor(gitgraph.NewGenerations(repo), a, b)
+		if err != nil {
+			return err
+		}
+		if !ok {
+			fmt.Fprintln(cmd.OutOrStdout(), "no")
+			return &exitError{code: 1}
+		}
+		fmt.Fprintln(cmd.OutOrStdout(), "yes")
+		return nil
+	},
+}
+
+var queryMergeBaseCmd = &cobra.Command{
+	Use:               "merge-base <a> <b>",
+	Short:             "Print the merge base(s) of commits A and B",
+	Args:              cobra.ExactArgs(2),
+	ValidArgsFunction: completeRefArgs(2),
+	SilenceErrors:     true,
+	SilenceUsage:      true,
+	RunE: func(cmd *cobra.Command, args []string) error {
+		repo, _, err := openRepo()
+		if err != nil {
+			return err
+		}
+		a, err := gitgraph.ResolveCommit(repo, args[0])
+		if err != nil {
+			return err
+		}
+		b, err := gitgraph.ResolveCommit(repo, args[1])
+		if err != nil {
+			return err
+		}
+		bases, err := gitgraph.MergeBases(repo, gitgraph.NewGenerations(repo), a, b)
+		if err != nil {
+			return err
+		}
+		if len(bases) == 0 {
+			return &exitError{code: 1}
+		}
+		for _, base := range bases {
+			fmt.Fprintln(cmd.OutOrStdout(), base.Hash.String())
+		}
+		return nil
+	},
+}
+
+func init() {
+	queryCmd.AddCommand(queryAncestorCmd, queryMergeBaseCmd)
+	rootCmd.AddCommand(queryCmd)
+}
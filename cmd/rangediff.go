@@ -0,0 +1,76 @@
+package cmd
+
+import (
+	"fmt"
+	"io"
+
+	"arbor/internal/gitgraph"
+
+	"github.com/go-git/go-git/v5/plumbing/object"
+	"github.com/spf13/cobra"
+)
+
+var rangeDiffCmd = &cobra.Command{
+	Use:               "range-diff [old] [new]",
+	Short:             "Compare two versions of a branch, e.g. before and after a rebase (default @{1} vs HEAD)",
+	Args:              cobra.MaximumNArgs(2),
+	ValidArgsFunction: completeRefArgs(2),
+	RunE: func(cmd *cobra.Command, args []string) error {
+		oldRev, newRev := "@{1}", "HEAD"
+		if len(args) > 0 {
+			oldRev = args[0]
+		}
+		if len(args) > 1 {
+			newRev = args[1]
+		}
+		noPatch, _ := cmd.Flags().GetBool("no-patch")
+		repo, _, err := openRepo()
+		if err != nil {
+			return err
+		}
+		old, err := gitgraph.ResolveCommit(repo, oldRev)
+		if err != nil {
+			return err
+		}
+		new, err := gitgraph.ResolveCommit(repo, newRev)
+		if err != nil {
+			return err
+		}
+		pairs, err := gitgraph.RangeDiff(repo, gitgraph.NewGenerations(repo), old.Hash, new.Hash)
+		if err != nil {
+			return err
+		}
+		out := cmd.OutOrStdout()
+		if len(pairs) == 0 {
+			fmt.Fprintf(out, "%s and %s point at the same history\n", oldRev, newRev)
+			return nil
+		}
+		for _, pair := range pairs {
+			fmt.Fprintln(out, gitgraph.FormatRangePair(pair))
+			if noPatch || pair.Status != gitgraph.RangeModified {
+				continue
+			}
+			if err := writeInterdiff(out, pair.Old, pair.New); err != nil {
+				return err
+			}
+		}
+		return nil
+	},
+}
+
+func writeInterdiff(w io.Writer, old, new *object.Commit) error {
+	lines, err := gitgraph.Interdiff(old, new, 3)
+	if err != nil {
+		return err
+	}
+	for _, line := range lines {
+		fmt.Fprintf(w, "    %s\n", line)
+	}
+	fmt.Fprintln(w)
+	return nil
+}
+
+func init() {
+	rangeDiffCmd.Flags().BoolP("no-patch", "s", false, "list the pairs without showing how modified commits changed")
+	rootCmd.AddCommand(rangeDiffCmd)
+}
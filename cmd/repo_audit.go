@@ -0,0 +1,73 @@
+package cmd
+
+import (
+	"fmt"
+
+	"arbor/internal/gitgraph"
+
+	"github.com/spf13/cobra"
+)
+
+var repoAuditCmd = &cobra.Command{
+	Use:           "repo-audit",
+	Short:         "List commits that add large files or likely secrets (exit status 1 if any)",
+	Args:          cobra.NoArgs,
+	SilenceErrors: true,
+	SilenceUsage:  true,
+	RunE: func(cmd *cobra.Command, args []string) error {
+		repo, _, cfg, err := setup(cmd)
+		if err != nil {
+			return err
+		}
+		if flags := cmd.Flags(); flags.Changed("max-size") {
+			cfg.Audit.MaxFileSize, _ = flags.GetString("max-size")
+		}
+		if noSecrets, _ := cmd.Flags().GetBool("no-secrets"); noSecrets {
+			cfg.Audit.Secrets = false
+		}
+		opts, err := cfg.Audit.Options()
+		if err != nil {
+			return err
+		}
+		provider, err := newProvider(repo, cfg)
+		if err != nil {
+			return err
+		}
+
+		out := cmd.OutOrStdout()
+		offenders := 0
+		for i := 0; ; i++ {
+			if err := provider.Ensure(i); err != nil {
+				return err
+			}
+			if i >= len(provider.Commits) {
+				break
+			}
+			commit := provider.Commits[i]
+			findings, err := gitgraph.AuditCommit(commit.Commit, opts)
+			if err != nil {
+				return fmt.Errorf("audit %s: %w", commit.ShortHash(), err)
+			}
+			if len(findings) == 0 {
+				continue
+			}
+			offenders++
+			fmt.Fprintf(out, "%s %s\n", commit.ShortHash(), commit.Subject)
+			for _, finding := range findings {
+				fmt.Fprintf(out, "  %s\n", finding)
+			}
+		}
+		if offenders > 0 {
+			fmt.Fprintf(out, "%d commit(s) flagged\n", offenders)
+			return &exitError{code: 1}
+		}
+		fmt.Fprintln(out, "no findings")
+		return nil
+	},
+}
+
+func init() {
+	repoAuditCmd.Flags().String("max-size", "", "flag files larger than this (e.g. 500KB, 5MB); overrides audit.max_file_size")
+	repoAuditCmd.Flags().Bool("no-secrets", false, "skip the secret pattern scan")
+	rootCmd.AddCommand(repoAuditCmd)
+}
@@ -0,0 +1,88 @@
+package cmd
+
+import (
+	"fmt"
+
+	"arbor/internal/gitgraph"
+	"arbor/internal/gitops"
+	"arbor/internal/review"
+	"arbor/internal/tui"
+
+	"github.com/go-git/go-git/v5/plumbing"
+	"github.com/spf13/cobra"
+)
+
+var reviewCmd = &cobra.Command{
+	Use:               "review <base>",
+	Short:             "Review the commits on HEAD that are not on base, with a checklist kept between sessions",
+	Args:              cobra.ExactArgs(1),
+	ValidArgsFunction: completeRefArgs(1),
+	RunE: func(cmd *cobra.Command, args []string) error {
+		status, _ := cmd.Flags().GetBool("status")
+		reset, _ := cmd.Flags().GetBool("reset")
+		repo, path, cfg, err := setup(cmd)
+		if err != nil {
+			return err
+		}
+		base, err := gitgraph.ResolveCommit(repo, args[0])
+		if err != nil {
+			return err
+		}
+		head, err := gitgraph.ResolveCommit(repo, "HEAD")
+		if err != nil {
+			return err
+		}
+		commonDir, err := gitops.New(path).CommonDir()
+		if err != nil {
+			return err
+		}
+		headName := gitgraph.HeadLabel(repo)
+		session, err := review.Open(repo, commonDir, args[0], headName, base.Hash, head.Hash)
+		if err != nil {
+			return err
+		}
+
+		out := cmd.OutOrStdout()
+		if reset {
+			if err := session.State.Reset(); err != nil {
+				return err
+			}
+			fmt.Fprintf(out, "Forgot the review checklist for %s against %s\n", headName, args[0])
+			return nil
+		}
+		if len(session.Commits) == 0 {
+			fmt.Fprintf(out, "%s has no commits that %s lacks\n", headName, args[0])
+			return nil
+		}
+		if status {
+			done, total := session.Progress()
+			fmt.Fprintf(out, "%s against %s: %d/%d commits reviewed\n", headName, args[0], done, total)
+			abbrev := loadAbbrev(repo)
+			for _, commit := range session.Commits {
+				check := " "
+				if session.IsReviewed(commit.Hash) {
+					check = "x"
+				}
+				fmt.Fprintf(out, "[%s] %s %s\n", check, abbrev.Short(commit.Hash), gitgraph.FirstLine(commit.Message))
+			}
+			return nil
+		}
+
+		hashes := make([]plumbing.Hash, 0, len(session.Commits))
+		for _, commit := range session.Commits {
+			hashes = append(hashes, commit.Hash)
+		}
+		provider, err := gitgraph.NewListedCommitProvider(repo, hashes, 0)
+		if err != nil {
+			return err
+		}
+		model := tui.NewReviewModel(path, provider, headName, cfg, session)
+		return runTUI(model)
+	},
+}
+
+func init() {
+	reviewCmd.Flags().Bool("status", false, "print the checklist and progress instead of opening the TUI")
+	reviewCmd.Flags().Bool("reset", false, "forget which commits were reviewed")
+	rootCmd.AddCommand(reviewCmd)
+}
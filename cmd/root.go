@@ -1,77 +1,97 @@
 package cmd
 
 import (
+	"errors"
 	"fmt"
 	"os"
 
-	"arbor/internal/gitgraph"
-	"arbor/internal/tui"
+	"arbor/internal/debuglog"
+	"arbor/internal/gitops"
 
-	tea "github.com/charmbracelet/bubbletea"
-	git "github.com/go-git/go-git/v5"
 	"github.com/spf13/cobra"
 )
 
 var rootCmd = &cobra.Command{
-	Use:   "arbor",
+	Use:   "arbor [-- <pathspec>...]",
 	Short: "Visualize Git commit history as an interactive tree",
-	RunE: func(cmd *cobra.Command, args []string) error {
-		includeAll, _ := cmd.Flags().GetBool("all")
-		limit, _ := cmd.Flags().GetInt("limit")
+	Long: `Visualize Git commit history as an interactive tree.
 
-		repo, path, err := openRepo()
-		if err != nil {
+Pathspecs after -- list only the commits changing the paths they select, as
+in git log: "docs/", "*.go", ':(exclude)vendor/', ':(glob)src/**/*.ts'.
+They are relative to the top of the repository.`,
+	Args: pathspecArgs,
+	PersistentPreRunE: func(cmd *cobra.Command, args []string) error {
+		if err := startDebugLog(cmd); err != nil {
 			return err
 		}
-
-		provider, err := gitgraph.NewCommitProvider(repo, includeAll, limit)
-		if err != nil {
-			return err
+		// Set for git subprocesses too, so every view of history agrees.
+		if off, _ := cmd.Flags().GetBool("no-replace-objects"); off {
+			return os.Setenv("GIT_NO_REPLACE_OBJECTS", "1")
 		}
-
-		headName := headLabel(repo)
-		model := tui.NewModel(path, provider, headName)
-		program := tea.NewProgram(model, tea.WithAltScreen())
-		_, err = program.Run()
-		return err
+		return nil
 	},
+	RunE: runLog,
 }
 
-func Execute() {
-	if err := rootCmd.Execute(); err != nil {
-		fmt.Fprintln(os.Stderr, err)
-		os.Exit(1)
+// startDebugLog turns on the --debug log. It goes to a file, since the
+// terminal belongs to the TUI.
+func startDebugLog(cmd *cobra.Command) error {
+	file, _ := cmd.Flags().GetString("debug-file")
+	if debug, _ := cmd.Flags().GetBool("debug"); !debug && file == "" {
+		return nil
+	}
+	if file == "" {
+		var err error
+		if file, err = debuglog.DefaultPath(); err != nil {
+			return fmt.Errorf("debug log: %w", err)
+		}
 	}
+	if err := debuglog.Enable(file); err != nil {
+		return fmt.Errorf("debug log: %w", err)
+	}
+	return nil
 }
 
-func init() {
-	rootCmd.Flags().Bool("all", false, "include all local and remote branches")
-	rootCmd.Flags().Int("limit", 0, "limit the number of commits to parse (0 = no limit)")
+type exitError struct {
+	code int
 }
 
-func openRepo() (*git.Repository, string, error) {
-	repo, err := git.PlainOpenWithOptions(".", &git.PlainOpenOptions{DetectDotGit: true})
-	if err != nil {
-		return nil, "", fmt.Errorf("open git repository: %w", err)
-	}
-	wt, err := repo.Worktree()
-	if err != nil {
-		return repo, "", nil
-	}
-	return repo, wt.Filesystem.Root(), nil
+func (e *exitError) Error() string {
+	return fmt.Sprintf("exit status %d", e.code)
 }
 
-func headLabel(repo *git.Repository) string {
-	head, err := repo.Head()
-	if err != nil {
-		return ""
-	}
-	if head.Name().IsBranch() {
-		return head.Name().Short()
+func Execute() {
+	if gitops.IsAskpass() {
+		os.Exit(gitops.RunAskpass(os.Args[1:], os.Stdout))
 	}
-	hash := head.Hash()
-	if hash.IsZero() {
-		return "detached"
+	if err := rootCmd.Execute(); err != nil {
+		var exit *exitError
+		if errors.As(err, &exit) {
+			os.Exit(exit.code)
+		}
+		fmt.Fprintln(os.Stderr, err)
+		os.Exit(1)
 	}
-	return fmt.Sprintf("detached@%s", hash.String()[:7])
+}
+
+func init() {
+	rootCmd.PersistentFlags().Bool("all", false, "include all local and remote branches")
+	rootCmd.PersistentFlags().Int("limit", 0, "limit the number of commits to parse (0 = no limit)")
+	rootCmd.PersistentFlags().Bool("read-only", false, "refuse every action that changes the repository")
+	rootCmd.PersistentFlags().Bool("pull-requests", false, "include fetched pull/merge request heads (refs/pull/*/head, refs/merge-requests/*/head)")
+	rootCmd.PersistentFlags().Bool("no-replace-objects", false, "show history as stored, ignoring refs/replace/ and info/grafts")
+	rootCmd.PersistentFlags().Bool("debug", false, "log git commands, history loading, and key presses to a file (see --debug-file)")
+	rootCmd.PersistentFlags().String("debug-file", "", "where --debug writes (default: arbor/debug.log in the user cache directory)")
+	rootCmd.PersistentFlags().Bool("merges", false, "list only merge commits (same as --min-parents 2)")
+	rootCmd.PersistentFlags().Bool("no-merges", false, "list no merge commits (same as --max-parents 1)")
+	rootCmd.PersistentFlags().Int("min-parents", 0, "list only commits with at least this many parents")
+	rootCmd.PersistentFlags().Int("max-parents", -1, "list only commits with at most this many parents (-1 = no maximum)")
+	rootCmd.PersistentFlags().Bool("first-parent", false, "follow only the first parent of merges, as git log --first-parent does")
+	rootCmd.PersistentFlags().String("order", "date", "how branches interleave: date or topo")
+	rootCmd.PersistentFlags().String("start", "", "ref to put the cursor on at startup, e.g. develop")
+	rootCmd.PersistentFlags().Bool("mine", false, "list only commits authored as user.email or an [[identities]] email, through .mailmap")
+	rootCmd.PersistentFlags().Bool("watch", false, "reload as commits are made outside arbor, keeping the newest on the current branch selected")
+	rootCmd.PersistentFlags().String("profile", "", "use this [[profiles]] entry instead of the one matching the repository path")
+	rootCmd.PersistentFlags().String("theme", "forest", "color theme: forest, colorblind, or high-contrast")
+	addLogFlags(rootCmd)
 }
@@ -0,0 +1,47 @@
+package cmd
+
+import (
+	"fmt"
+	"net/http"
+	"time"
+
+	"arbor/internal/server"
+
+	"github.com/spf13/cobra"
+)
+
+var serveCmd = &cobra.Command{
+	Use:   "serve",
+	Short: "Serve the commit graph, diffs, and refs as JSON over HTTP",
+	Args:  cobra.NoArgs,
+	RunE: func(cmd *cobra.Command, args []string) error {
+		addr, _ := cmd.Flags().GetString("addr")
+
+		repo, path, cfg, err := setup(cmd)
+		if err != nil {
+			return err
+		}
+		provider, err := newProvider(repo, cfg)
+		if err != nil {
+			return err
+		}
+
+		fmt.Fprintf(cmd.OutOrStdout(), "arbor serving %s on %s\n", path, addr)
+		// The timeouts keep a slow or stalled client from holding a
+		// connection open; writes allow for blaming a long file.
+		srv := &http.Server{
+			Addr:              addr,
+			Handler:           server.New(repo, provider).Handler(),
+			ReadHeaderTimeout: 10 * time.Second,
+			ReadTimeout:       30 * time.Second,
+			WriteTimeout:      2 * time.Minute,
+			IdleTimeout:       2 * time.Minute,
+		}
+		return srv.ListenAndServe()
+	},
+}
+
+func init() {
+	serveCmd.Flags().String("addr", ":7373", "address to listen on")
+	rootCmd.AddCommand(serveCmd)
+}
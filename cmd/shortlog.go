@@ -0,0 +1,104 @@
+package cmd
+
+import (
+	"fmt"
+
+	"arbor/internal/gitgraph"
+
+	"github.com/go-git/go-git/v5/plumbing"
+	"github.com/go-git/go-git/v5/plumbing/object"
+	"github.com/spf13/cobra"
+)
+
+var shortlogCmd = &cobra.Command{
+	Use:   "shortlog [<base>..[head] | <commit>] [-- <pathspec>...]",
+	Short: "Count commits per author, like git shortlog -sn",
+	Long: `Count commits per author, most first, like git shortlog -sn, with names
+and emails mapped through .mailmap and mailmap.file.
+
+With no revision it counts HEAD's history, or every branch's with --all; a
+range such as v1.0..HEAD counts the commits on head that base lacks.
+Pathspecs after -- count only the commits changing those paths. History is
+read one commit at a time, so whole histories stream through.`,
+	Args: func(cmd *cobra.Command, args []string) error {
+		if dash := cmd.ArgsLenAtDash(); dash > 1 || (dash < 0 && len(args) > 1) {
+			return fmt.Errorf("shortlog takes one revision or range; put pathspecs after --")
+		}
+		return nil
+	},
+	ValidArgsFunction: completeRefArgs(1),
+	RunE: func(cmd *cobra.Command, args []string) error {
+		email, _ := cmd.Flags().GetBool("email")
+		repo, _, cfg, err := setup(cmd)
+		if err != nil {
+			return err
+		}
+		rev, specs := "", args
+		if dash := cmd.ArgsLenAtDash(); dash != 0 && len(args) > 0 {
+			rev, specs = args[0], args[1:]
+		}
+		paths, err := pathFilter(specs)
+		if err != nil {
+			return err
+		}
+		mailmap, err := gitgraph.LoadMailmap(repo)
+		if err != nil {
+			return err
+		}
+
+		parents := gitgraph.ParentFilter{Min: cfg.MinParents, Max: cfg.MaxParents}
+		shortlog := gitgraph.NewShortlog(mailmap, email)
+		count := func(commit *object.Commit) error {
+			if !parents.Match(commit.NumParents()) {
+				return nil
+			}
+			if paths != nil {
+				if touches, err := paths.Touches(commit); err != nil || !touches {
+					return err
+				}
+			}
+			shortlog.Add(commit)
+			return nil
+		}
+
+		if rev == "" {
+			scope := gitgraph.ScopeHead
+			if cfg.All {
+				scope = gitgraph.ScopeAll
+			}
+			tips, err := gitgraph.ScopeTips(repo, scope)
+			if err != nil {
+				return err
+			}
+			err = gitgraph.WalkHistory(repo, tips, count)
+		} else {
+			base, head, rangeErr := resolveRange(repo, rev)
+			if rangeErr != nil {
+				return rangeErr
+			}
+			if base != nil {
+				err = gitgraph.WalkRange(repo, gitgraph.NewGenerations(repo), base.Hash, head.Hash, count)
+			} else {
+				err = gitgraph.WalkHistory(repo, []plumbing.Hash{head.Hash}, count)
+			}
+		}
+		if err != nil {
+			return err
+		}
+
+		out := cmd.OutOrStdout()
+		for _, author := range shortlog.Authors() {
+			if email {
+				fmt.Fprintf(out, "%6d\t%s <%s>\n", author.Commits, author.Name, author.Email)
+			} else {
+				fmt.Fprintf(out, "%6d\t%s\n", author.Commits, author.Name)
+			}
+		}
+		return nil
+	},
+}
+
+func init() {
+	shortlogCmd.Flags().BoolP("email", "e", false, "count each email separately and show it")
+	rootCmd.AddCommand(shortlogCmd)
+}
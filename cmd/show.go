@@ -0,0 +1,51 @@
+package cmd
+
+import (
+	"fmt"
+
+	"arbor/internal/gitgraph"
+	"arbor/internal/tui"
+
+	"github.com/spf13/cobra"
+)
+
+var showCmd = &cobra.Command{
+	Use:   "show <commit>",
+	Short: "Open the graph around one commit: its nearest ancestors and descendants",
+	Long: `Open the graph around one commit, with the cursor on it: the --context
+nearest ancestors and the --context nearest descendants, on any branch or
+tag that contains it. Handy for a commit named in a CI failure.`,
+	Args:              cobra.ExactArgs(1),
+	ValidArgsFunction: completeRefArgs(1),
+	RunE: func(cmd *cobra.Command, args []string) error {
+		context, _ := cmd.Flags().GetInt("context")
+		if context < 0 {
+			return fmt.Errorf("--context must be 0 or more")
+		}
+		repo, path, cfg, err := setup(cmd)
+		if err != nil {
+			return err
+		}
+		commit, err := gitgraph.ResolveCommit(repo, args[0])
+		if err != nil {
+			return err
+		}
+		hashes, err := gitgraph.Context(repo, gitgraph.NewGenerations(repo), commit.Hash, context)
+		if err != nil {
+			return err
+		}
+		provider, err := gitgraph.NewListedCommitProvider(repo, hashes, 0)
+		if err != nil {
+			return err
+		}
+		provider.FilterParents(gitgraph.ParentFilter{Min: cfg.MinParents, Max: cfg.MaxParents})
+		cfg.Start = commit.Hash.String()
+		model := tui.NewModel(path, provider, gitgraph.HeadLabel(repo), cfg)
+		return runTUI(model)
+	},
+}
+
+func init() {
+	showCmd.Flags().Int("context", 50, "how many ancestors, and how many descendants, to show")
+	rootCmd.AddCommand(showCmd)
+}
@@ -0,0 +1,227 @@
+package cmd
+
+import (
+	"errors"
+	"fmt"
+	"io"
+	"strings"
+
+	"arbor/internal/gitgraph"
+	"arbor/internal/gitops"
+
+	"github.com/go-git/go-git/v5/plumbing"
+	"github.com/go-git/go-git/v5/plumbing/object"
+	"github.com/spf13/cobra"
+)
+
+var snapshotCmd = &cobra.Command{
+	Use:   "snapshot",
+	Short: "Save ref positions and later see what changed since",
+	Long: `Save where HEAD and every branch, remote-tracking branch, and tag point,
+then compare against it later, e.g. around a rebase:
+
+  arbor snapshot save pre-rebase
+  git rebase -i main
+  arbor snapshot diff pre-rebase
+
+Snapshots are refs under ` + gitops.SnapshotRefPrefix + `, so the commits they
+point at are kept until the snapshot is dropped.`,
+}
+
+var snapshotSaveCmd = &cobra.Command{
+	Use:   "save <name>",
+	Short: "Record the current ref positions as name, replacing an earlier snapshot of that name",
+	Args:  cobra.ExactArgs(1),
+	RunE: func(cmd *cobra.Command, args []string) error {
+		git, err := snapshotRepo(cmd)
+		if err != nil {
+			return err
+		}
+		n, err := git.SaveSnapshot(args[0])
+		if err != nil {
+			return err
+		}
+		fmt.Fprintf(cmd.OutOrStdout(), "Saved snapshot %s (%d refs)\n", args[0], n)
+		return nil
+	},
+}
+
+var snapshotDiffCmd = &cobra.Command{
+	Use:               "diff <name> [other]",
+	Short:             "Show refs that moved and commits added or dropped since a snapshot (or between two)",
+	Args:              cobra.RangeArgs(1, 2),
+	ValidArgsFunction: completeSnapshots(2),
+	RunE: func(cmd *cobra.Command, args []string) error {
+		repo, _, err := openRepo()
+		if err != nil {
+			return err
+		}
+		old, err := gitgraph.SavedPositions(repo, gitops.SnapshotRefPrefix+args[0]+"/")
+		if err != nil {
+			return err
+		}
+		if len(old) == 0 {
+			return fmt.Errorf("no snapshot named %q", args[0])
+		}
+		label := "now"
+		var new map[string]plumbing.Hash
+		if len(args) > 1 {
+			label = args[1]
+			if new, err = gitgraph.SavedPositions(repo, gitops.SnapshotRefPrefix+args[1]+"/"); err == nil && len(new) == 0 {
+				err = fmt.Errorf("no snapshot named %q", args[1])
+			}
+		} else {
+			new, err = gitgraph.RefPositions(repo)
+		}
+		if err != nil {
+			return err
+		}
+		diff, err := gitgraph.DiffPositions(repo, gitgraph.NewGenerations(repo), old, new)
+		if err != nil {
+			return err
+		}
+		writePositionDiff(cmd.OutOrStdout(), loadAbbrev(repo), args[0], label, diff)
+		return nil
+	},
+}
+
+var snapshotListCmd = &cobra.Command{
+	Use:   "list",
+	Short: "List saved snapshots",
+	Args:  cobra.NoArgs,
+	RunE: func(cmd *cobra.Command, args []string) error {
+		git, err := snapshotRepo(cmd)
+		if err != nil {
+			return err
+		}
+		names, err := git.Snapshots()
+		if err != nil {
+			return err
+		}
+		out := cmd.OutOrStdout()
+		if len(names) == 0 {
+			fmt.Fprintln(out, "No snapshots saved")
+		}
+		for _, name := range names {
+			fmt.Fprintln(out, name)
+		}
+		return nil
+	},
+}
+
+var snapshotDropCmd = &cobra.Command{
+	Use:               "drop <name>...",
+	Short:             "Delete snapshots, letting the commits only they kept be pruned",
+	Args:              cobra.MinimumNArgs(1),
+	ValidArgsFunction: completeSnapshots(-1),
+	RunE: func(cmd *cobra.Command, args []string) error {
+		git, err := snapshotRepo(cmd)
+		if err != nil {
+			return err
+		}
+		for _, name := range args {
+			if err := git.DropSnapshot(name); err != nil {
+				return err
+			}
+			fmt.Fprintf(cmd.OutOrStdout(), "Dropped snapshot %s\n", name)
+		}
+		return nil
+	},
+}
+
+func snapshotRepo(cmd *cobra.Command) (*gitops.Repo, error) {
+	_, path, err := openRepo()
+	if err != nil {
+		return nil, err
+	}
+	if path == "" {
+		return nil, errors.New("snapshots need a working tree; the repository is bare")
+	}
+	cfg, err := loadConfig(cmd, path)
+	if err != nil {
+		return nil, err
+	}
+	git := gitops.New(path)
+	git.ReadOnly = cfg.ReadOnly
+	git.Protected = cfg.Protect.Branches
+	return git, nil
+}
+
+// completeSnapshots offers snapshot names for the first n arguments, or
+// every argument when n is negative.
+func completeSnapshots(n int) cobra.CompletionFunc {
+	return func(cmd *cobra.Command, args []string, toComplete string) ([]string, cobra.ShellCompDirective) {
+		if n >= 0 && len(args) >= n {
+			return nil, cobra.ShellCompDirectiveNoFileComp
+		}
+		git, err := snapshotRepo(cmd)
+		if err != nil {
+			return nil, cobra.ShellCompDirectiveNoFileComp
+		}
+		names, _ := git.Snapshots()
+		return names, cobra.ShellCompDirectiveNoFileComp
+	}
+}
+
+// writePositionDiff prints moved refs with ↑new ↓dropped counts, then the
+// commits added and dropped, noting dropped ones a rebase rewrote.
+func writePositionDiff(w io.Writer, abbrev *gitgraph.Abbrev, from, to string, diff *gitgraph.PositionDiff) {
+	if len(diff.Moved) == 0 {
+		fmt.Fprintf(w, "Nothing moved between %s and %s\n", from, to)
+		return
+	}
+	fmt.Fprintf(w, "Moved refs (%s → %s)\n", from, to)
+	width := 0
+	for _, move := range diff.Moved {
+		width = max(width, len(shortRefName(move.Name)))
+	}
+	for _, move := range diff.Moved {
+		marker := ""
+		switch {
+		case move.Old.IsZero():
+			marker = "created"
+		case move.New.IsZero():
+			marker = "deleted"
+		default:
+			marker = fmt.Sprintf("↑%d ↓%d", move.Ahead, move.Behind)
+		}
+		fmt.Fprintf(w, "  %-*s  %s → %s  %s\n", width, shortRefName(move.Name), shortHash(abbrev, move.Old), shortHash(abbrev, move.New), marker)
+	}
+	writeCommitList(w, abbrev, "New commits", "+", diff.New, nil)
+	writeCommitList(w, abbrev, "Dropped commits", "-", diff.Dropped, diff.Rewritten)
+}
+
+func writeCommitList(w io.Writer, abbrev *gitgraph.Abbrev, title, sign string, commits []*object.Commit, rewritten map[plumbing.Hash]plumbing.Hash) {
+	if len(commits) == 0 {
+		return
+	}
+	fmt.Fprintf(w, "\n%s (%d)\n", title, len(commits))
+	for _, commit := range commits {
+		line := fmt.Sprintf("  %s %s %s %s", sign, abbrev.Short(commit.Hash), commit.Author.When.Format("2006-01-02"), gitgraph.FirstLine(commit.Message))
+		if hash, ok := rewritten[commit.Hash]; ok {
+			line += fmt.Sprintf("  (rewritten as %s)", abbrev.Short(hash))
+		}
+		fmt.Fprintln(w, line)
+	}
+}
+
+func shortRefName(name string) string {
+	for _, prefix := range []string{"refs/heads/", "refs/remotes/", "refs/"} {
+		if rest, ok := strings.CutPrefix(name, prefix); ok {
+			return rest
+		}
+	}
+	return name
+}
+
+func shortHash(abbrev *gitgraph.Abbrev, hash plumbing.Hash) string {
+	if hash.IsZero() {
+		return "(none)"
+	}
+	return abbrev.Short(hash)
+}
+
+func init() {
+	snapshotCmd.AddCommand(snapshotSaveCmd, snapshotDiffCmd, snapshotListCmd, snapshotDropCmd)
+	rootCmd.AddCommand(snapshotCmd)
+}
@@ -0,0 +1,86 @@
+package cmd
+
+import (
+	"errors"
+	"fmt"
+
+	"arbor/internal/gitops"
+	"arbor/internal/journal"
+
+	"github.com/spf13/cobra"
+)
+
+var undoCmd = &cobra.Command{
+	Use:   "undo",
+	Short: "Revert the last operation arbor performed (commit, rebase, reset, branch deletion)",
+	Long: `Revert the last operation arbor performed.
+
+Every branch-moving action arbor takes is recorded in a journal under the
+common git directory, with the old and new value of each ref it moved and,
+for hard resets, a stash of the working tree it discarded. Undo puts those
+refs back, but only if none of them has moved since.`,
+	Args: cobra.NoArgs,
+	RunE: func(cmd *cobra.Command, args []string) error {
+		list, _ := cmd.Flags().GetBool("list")
+		_, path, err := openRepo()
+		if err != nil {
+			return err
+		}
+		if path == "" {
+			return errors.New("undo needs a working tree; the repository is bare")
+		}
+		cfg, err := loadConfig(cmd, path)
+		if err != nil {
+			return err
+		}
+		git := gitops.New(path)
+		git.ReadOnly = cfg.ReadOnly
+		git.Protected = cfg.Protect.Branches
+
+		out := cmd.OutOrStdout()
+		if list {
+			commonDir, err := git.CommonDir()
+			if err != nil {
+				return err
+			}
+			j, err := journal.Load(commonDir)
+			if err != nil {
+				return err
+			}
+			if len(j.Entries) == 0 {
+				fmt.Fprintln(out, "No operations recorded")
+			}
+			for i := len(j.Entries) - 1; i >= 0; i-- {
+				e := j.Entries[i]
+				state := ""
+				if e.Undone {
+					state = " (undone)"
+				}
+				fmt.Fprintf(out, "%s  %s%s\n", e.Time.Format("2006-01-02 15:04"), e.Title, state)
+				for _, change := range e.Refs {
+					fmt.Fprintf(out, "  %s\n", change)
+				}
+			}
+			return nil
+		}
+
+		undone, err := git.Undo()
+		if errors.Is(err, gitops.ErrNothingToUndo) {
+			fmt.Fprintln(out, "Nothing to undo")
+			return nil
+		}
+		if err != nil {
+			return err
+		}
+		fmt.Fprintf(out, "Undid %q from %s\n", undone.Title, undone.Time.Format("2006-01-02 15:04"))
+		for _, change := range undone.Refs {
+			fmt.Fprintf(out, "  %s\n", change.Reversed())
+		}
+		return nil
+	},
+}
+
+func init() {
+	undoCmd.Flags().Bool("list", false, "show the journal, newest first, instead of undoing")
+	rootCmd.AddCommand(undoCmd)
+}
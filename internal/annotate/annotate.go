@@ -0,0 +1,61 @@
+package annotate
+
+import (
+	"bufio"
+	"bytes"
+	"context"
+	"fmt"
+	"os/exec"
+	"strings"
+	"time"
+)
+
+// Timeout bounds one batch; a slow provider must not pile up behind the UI.
+const Timeout = 10 * time.Second
+
+// Run asks an external annotation provider to label commits, which keeps
+// integrations such as ticket IDs or deploy markers outside arbor. It feeds
+// hashes to command on stdin, one full hash per line, and reads
+// back "<hash> <label>" lines. The hash may be abbreviated; lines for
+// hashes that were not asked about are ignored, as are commits the command
+// leaves out. The command runs through sh in dir.
+func Run(dir, command string, hashes []string) (map[string]string, error) {
+	ctx, cancel := context.WithTimeout(context.Background(), Timeout)
+	defer cancel()
+	cmd := exec.CommandContext(ctx, "sh", "-c", command)
+	cmd.Dir = dir
+	cmd.Stdin = strings.NewReader(strings.Join(hashes, "\n") + "\n")
+	var stdout, stderr bytes.Buffer
+	cmd.Stdout = &stdout
+	cmd.Stderr = &stderr
+	if err := cmd.Run(); err != nil {
+		if ctx.Err() != nil {
+			return nil, fmt.Errorf("annotations: %q timed out after %s", command, Timeout)
+		}
+		if msg := strings.TrimSpace(stderr.String()); msg != "" {
+			return nil, fmt.Errorf("annotations: %s", msg)
+		}
+		return nil, fmt.Errorf("annotations: %w", err)
+	}
+	return parse(stdout.String(), hashes), nil
+}
+
+// parse matches output lines to the requested hashes by prefix.
+func parse(output string, hashes []string) map[string]string {
+	labels := make(map[string]string)
+	scanner := bufio.NewScanner(strings.NewReader(output))
+	for scanner.Scan() {
+		prefix, label, ok := strings.Cut(strings.TrimSpace(scanner.Text()), " ")
+		label = strings.TrimSpace(label)
+		if !ok || len(prefix) < 4 || label == "" {
+			continue
+		}
+		for _, hash := range hashes {
+			if strings.HasPrefix(hash, strings.ToLower(prefix)) {
+				labels[hash] = label
+				break
+			}
+		}
+	}
+	return labels
+}
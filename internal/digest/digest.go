@@ -0,0 +1,169 @@
+package digest
+
+import (
+	"fmt"
+	"html/template"
+	"io"
+	"strings"
+	"time"
+
+	"arbor/internal/gitgraph"
+)
+
+// GroupByNames are the ways Options.GroupBy can bucket the people section.
+var GroupByNames = []string{"author", "domain", "team"}
+
+type Options struct {
+	// Depth is the directory depth files are grouped into areas by.
+	Depth int
+	// GroupBy is one of GroupByNames; "team" buckets authors by Teams.
+	GroupBy string
+	Teams   []gitgraph.Team
+}
+
+type Digest struct {
+	Repo    string
+	From    time.Time
+	To      time.Time
+	Commits []*gitgraph.CommitInfo
+	Authors []gitgraph.Group
+	Areas   []gitgraph.Group
+	// People is Authors, or the commits by email domain or team, per
+	// Options.GroupBy, under the heading By.
+	People []gitgraph.Group
+	By     string
+	// previous counts each People group's commits in the window of the same
+	// length before From; nil when there was nothing to compare with.
+	previous map[string]int
+}
+
+// Build summarizes commits. previous holds the commits from the window of
+// the same length before from, for trends; it may be nil.
+func Build(repo string, from, to time.Time, commits, previous []*gitgraph.CommitInfo, opts Options) (*Digest, error) {
+	areas, err := gitgraph.GroupByArea(commits, opts.Depth)
+	if err != nil {
+		return nil, err
+	}
+	d := &Digest{
+		Repo:    repo,
+		From:    from,
+		To:      to,
+		Commits: commits,
+		Authors: gitgraph.GroupByAuthor(commits),
+		Areas:   areas,
+	}
+	group := func(commits []*gitgraph.CommitInfo) []gitgraph.Group {
+		switch opts.GroupBy {
+		case "domain":
+			return gitgraph.GroupByDomain(commits)
+		case "team":
+			return gitgraph.GroupByTeam(commits, opts.Teams)
+		}
+		return gitgraph.GroupByAuthor(commits)
+	}
+	d.People = group(commits)
+	d.By = map[string]string{"domain": "By email domain", "team": "By team"}[opts.GroupBy]
+	if d.By == "" {
+		d.By = "By author"
+	}
+	if previous != nil {
+		d.previous = make(map[string]int)
+		for _, g := range group(previous) {
+			d.previous[g.Key] = len(g.Commits)
+		}
+	}
+	return d, nil
+}
+
+// Trend compares a People group with the window before, e.g. "+3 vs. the
+// previous period", or "" when there is no previous window.
+func (d *Digest) Trend(g gitgraph.Group) string {
+	if d.previous == nil {
+		return ""
+	}
+	switch delta := len(g.Commits) - d.previous[g.Key]; {
+	case delta > 0:
+		return fmt.Sprintf("+%d vs. the previous period", delta)
+	case delta < 0:
+		return fmt.Sprintf("%d vs. the previous period", delta)
+	}
+	return "same as the previous period"
+}
+
+func (d *Digest) Title() string {
+	return fmt.Sprintf("Digest for %s, %s to %s", d.Repo, d.From.Format("Jan 2"), d.To.Format("Jan 2, 2006"))
+}
+
+func (d *Digest) Summary() string {
+	return fmt.Sprintf("%d %s by %d %s across %d %s.",
+		len(d.Commits), plural(len(d.Commits), "commit", "commits"),
+		len(d.Authors), plural(len(d.Authors), "author", "authors"),
+		len(d.Areas), plural(len(d.Areas), "area", "areas"))
+}
+
+func (d *Digest) WriteMarkdown(w io.Writer) error {
+	var b strings.Builder
+	fmt.Fprintf(&b, "# %s\n\n%s\n", d.Title(), d.Summary())
+	writeGroups := func(heading string, groups []gitgraph.Group, trends bool) {
+		fmt.Fprintf(&b, "\n## %s\n", heading)
+		for _, g := range groups {
+			trend := ""
+			if trends && d.Trend(g) != "" {
+				trend = ", " + d.Trend(g)
+			}
+			fmt.Fprintf(&b, "\n### %s (%d%s)\n\n", g.Key, len(g.Commits), trend)
+			for _, c := range g.Commits {
+				fmt.Fprintf(&b, "- `%s` %s — %s, %s\n", c.ShortHash(), c.Subject, c.Author, c.When.Format("Mon Jan 2"))
+			}
+		}
+	}
+	writeGroups(d.By, d.People, true)
+	writeGroups("By area", d.Areas, false)
+	_, err := io.WriteString(w, b.String())
+	return err
+}
+
+func (d *Digest) WriteHTML(w io.Writer) error {
+	return htmlTemplate.Execute(w, d)
+}
+
+func plural(n int, one, many string) string {
+	if n == 1 {
+		return one
+	}
+	return many
+}
+
+var htmlTemplate = template.Must(template.New("digest").Funcs(template.FuncMap{
+	"day": func(t time.Time) string { return t.Format("Mon Jan 2") },
+}).Parse(`<!DOCTYPE html>
+<html>
+<head>
+<meta charset="utf-8">
+<title>{{.Title}}</title>
+<style>
+body { font-family: -apple-system, "Segoe UI", sans-serif; color: #2a271f; max-width: 52rem; margin: 2rem auto; }
+h1 { color: #2f6d4b; }
+h2 { border-bottom: 1px solid #c9bda8; padding-bottom: .2rem; }
+h3 { color: #7a5a2a; margin-bottom: .3rem; }
+code { color: #2f6d4b; }
+.meta { color: #8a8171; }
+ul { margin-top: 0; }
+</style>
+</head>
+<body>
+<h1>{{.Title}}</h1>
+<p>{{.Summary}}</p>
+<h2>{{.By}}</h2>
+{{range .People}}<h3>{{.Key}} ({{len .Commits}}{{with $.Trend .}}, {{.}}{{end}})</h3>
+<ul>
+{{range .Commits}}<li><code>{{.ShortHash}}</code> {{.Subject}} <span class="meta">— {{.Author}}, {{day .When}}</span></li>
+{{end}}</ul>
+{{end}}<h2>By area</h2>
+{{range .Areas}}<h3>{{.Key}} ({{len .Commits}})</h3>
+<ul>
+{{range .Commits}}<li><code>{{.ShortHash}}</code> {{.Subject}} <span class="meta">— {{.Author}}, {{day .When}}</span></li>
+{{end}}</ul>
+{{end}}</body>
+</html>
+`))
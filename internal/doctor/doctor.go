@@ -0,0 +1,174 @@
+package doctor
+
+import (
+	"encoding/binary"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+
+	"arbor/internal/gitgraph"
+
+	git "github.com/go-git/go-git/v5"
+)
+
+const (
+	// looseObjectLimit matches git's default gc.auto threshold.
+	looseObjectLimit = 6700
+	packLimit        = 50
+	// commitGraphMinObjects keeps small repositories from being nagged
+	// about a commit-graph they would not notice.
+	commitGraphMinObjects = 20000
+)
+
+type Issue struct {
+	Code    string
+	Summary string
+	Detail  string
+	Fix     string
+}
+
+func Check(repo *git.Repository) ([]Issue, error) {
+	gitDir := gitgraph.GitDir(repo)
+	if gitDir == "" {
+		return nil, nil
+	}
+	objectsDir := filepath.Join(gitDir, "objects")
+
+	var issues []Issue
+	loose, err := countLooseObjects(objectsDir)
+	if err != nil {
+		return nil, err
+	}
+	if loose > looseObjectLimit {
+		issues = append(issues, Issue{
+			Code:    "loose-objects",
+			Summary: fmt.Sprintf("%d loose objects", loose),
+			Detail:  fmt.Sprintf("More than %d unpacked objects slow down history traversal.", looseObjectLimit),
+			Fix:     "git gc",
+		})
+	}
+
+	packs, packed, err := inspectPacks(objectsDir)
+	if err != nil {
+		return nil, err
+	}
+	if packs > packLimit {
+		issues = append(issues, Issue{
+			Code:    "many-packs",
+			Summary: fmt.Sprintf("%d packfiles", packs),
+			Detail:  "Object lookups have to search every pack index.",
+			Fix:     "git repack -d",
+		})
+	}
+
+	if packed+loose >= commitGraphMinObjects && !hasCommitGraph(objectsDir) {
+		issues = append(issues, Issue{
+			Code:    "no-commit-graph",
+			Summary: "no commit-graph",
+			Detail:  fmt.Sprintf("The repository holds about %d objects but has no commit-graph file to speed up walks.", packed+loose),
+			Fix:     "git commit-graph write --reachable",
+		})
+	}
+
+	stale, err := staleRemoteRefs(repo)
+	if err != nil {
+		return nil, err
+	}
+	if len(stale) > 0 {
+		issues = append(issues, Issue{
+			Code:    "stale-remote-refs",
+			Summary: fmt.Sprintf("%d stale remote-tracking refs", len(stale)),
+			Detail:  "Refs for remotes that are no longer configured: " + strings.Join(stale, ", "),
+			Fix:     "git update-ref -d <ref> for each, or re-add the remote",
+		})
+	}
+	return issues, nil
+}
+
+func countLooseObjects(objectsDir string) (int, error) {
+	entries, err := os.ReadDir(objectsDir)
+	if err != nil {
+		return 0, err
+	}
+	count := 0
+	for _, entry := range entries {
+		name := entry.Name()
+		if !entry.IsDir() || len(name) != 2 || !isHex(name) {
+			continue
+		}
+		objects, err := os.ReadDir(filepath.Join(objectsDir, name))
+		if err != nil {
+			continue
+		}
+		count += len(objects)
+	}
+	return count, nil
+}
+
+// inspectPacks counts packfiles and the objects they hold, reading the
+// total from the last fanout entry of each version 2 pack index.
+func inspectPacks(objectsDir string) (int, int, error) {
+	indexes, err := filepath.Glob(filepath.Join(objectsDir, "pack", "*.idx"))
+	if err != nil {
+		return 0, 0, err
+	}
+	objects := 0
+	for _, idx := range indexes {
+		f, err := os.Open(idx)
+		if err != nil {
+			continue
+		}
+		buf := make([]byte, 4)
+		if _, err := f.ReadAt(buf, 8+255*4); err == nil {
+			objects += int(binary.BigEndian.Uint32(buf))
+		}
+		f.Close()
+	}
+	return len(indexes), objects, nil
+}
+
+func hasCommitGraph(objectsDir string) bool {
+	for _, path := range []string{
+		filepath.Join(objectsDir, "info", "commit-graph"),
+		filepath.Join(objectsDir, "info", "commit-graphs", "commit-graph-chain"),
+	} {
+		if _, err := os.Stat(path); err == nil {
+			return true
+		}
+	}
+	return false
+}
+
+func staleRemoteRefs(repo *git.Repository) ([]string, error) {
+	cfg, err := repo.Config()
+	if err != nil {
+		return nil, err
+	}
+	refs, err := gitgraph.ListRefs(repo)
+	if err != nil {
+		return nil, err
+	}
+	var stale []string
+	for _, ref := range refs {
+		if ref.Kind != gitgraph.RefRemote {
+			continue
+		}
+		remote, _, _ := strings.Cut(strings.TrimPrefix(ref.Name, "refs/remotes/"), "/")
+		if _, ok := cfg.Remotes[remote]; !ok {
+			stale = append(stale, ref.Short)
+		}
+	}
+	sort.Strings(stale)
+	return stale, nil
+}
+
+func isHex(s string) bool {
+	for _, r := range s {
+		if !strings.ContainsRune("0123456789abcdef", r) {
+			return false
+		}
+	}
+	return true
+}
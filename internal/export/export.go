@@ -0,0 +1,175 @@
+package export
+
+import (
+	"fmt"
+	"html/template"
+	"io"
+	"strings"
+	"time"
+
+	"arbor/internal/gitgraph"
+)
+
+// laneColors match the forest theme's lanes on a light background.
+var laneColors = []string{"#2f6d4b", "#4f8a5b", "#7a5a2a", "#4d7f75", "#4f6f8a", "#9a6b2f", "#6e8b3d", "#3f5a4a", "#6c7a74"}
+
+// Page is a snapshot of the commit tree as a standalone HTML file: the graph,
+// each commit's metadata and message, and a search box that filters rows in
+// the browser.
+type Page struct {
+	Repo      string
+	Generated time.Time
+	Rows      []Row
+	Complete  bool
+}
+
+type Row struct {
+	Hash      string
+	ShortHash string
+	Subject   string
+	Author    string
+	Email     string
+	When      time.Time
+	Parents   []string
+	Refs      []gitgraph.Ref
+	Message   string
+	Graph     []Cell
+}
+
+type Cell struct {
+	Ch    string
+	Color int
+}
+
+// Build lays out commits with their refs. complete reports whether they are
+// the whole history or were cut off by --limit.
+func Build(repo string, commits []*gitgraph.CommitInfo, refs []gitgraph.Ref, complete bool, now time.Time) *Page {
+	byTarget := make(map[string][]gitgraph.Ref)
+	for _, ref := range refs {
+		key := ref.Target.String()
+		byTarget[key] = append(byTarget[key], ref)
+	}
+	page := &Page{Repo: repo, Generated: now, Complete: complete}
+	for _, commit := range commits {
+		row := Row{
+			Hash:      commit.Hash.String(),
+			ShortHash: commit.ShortHash(),
+			Subject:   commit.Subject,
+			Author:    commit.Author,
+			When:      commit.When,
+			Refs:      byTarget[commit.Hash.String()],
+		}
+		if c := commit.Commit; c != nil {
+			row.Email = c.Author.Email
+			row.Message = strings.TrimRight(commit.Message(), "\n")
+			for _, parent := range c.ParentHashes {
+				row.Parents = append(row.Parents, parent.String())
+			}
+		}
+		for _, cell := range commit.Graph {
+			row.Graph = append(row.Graph, Cell{Ch: cell.Ch, Color: cell.Color % len(laneColors)})
+		}
+		page.Rows = append(page.Rows, row)
+	}
+	return page
+}
+
+func (p *Page) WriteHTML(w io.Writer) error {
+	return htmlTemplate.Execute(w, p)
+}
+
+// search is the text a row matches in the search box.
+func (r Row) search() string {
+	parts := []string{r.Hash, r.Subject, r.Author, r.Email}
+	for _, ref := range r.Refs {
+		parts = append(parts, ref.Short)
+	}
+	return strings.ToLower(strings.Join(parts, " "))
+}
+
+func lanesCSS() template.CSS {
+	var b strings.Builder
+	for i, color := range laneColors {
+		fmt.Fprintf(&b, ".l%d { color: %s; }\n", i, color)
+	}
+	return template.CSS(b.String())
+}
+
+var htmlTemplate = template.Must(template.New("export").Funcs(template.FuncMap{
+	"date":   func(t time.Time) string { return t.Format("2006-01-02 15:04") },
+	"search": Row.search,
+	"lanes":  lanesCSS,
+}).Parse(`<!DOCTYPE html>
+<html>
+<head>
+<meta charset="utf-8">
+<title>{{.Repo}} history</title>
+<style>
+body { font-family: -apple-system, "Segoe UI", sans-serif; color: #2a271f; background: #f7f4ee; margin: 0; }
+header { position: sticky; top: 0; background: #e9efe6; padding: .6rem 1rem; border-bottom: 1px solid #c9bda8; display: flex; gap: 1rem; align-items: center; }
+header h1 { font-size: 1.1rem; color: #2f6d4b; margin: 0; }
+header input { flex: 1; max-width: 28rem; padding: .3rem .5rem; border: 1px solid #c9bda8; border-radius: 4px; }
+.meta { color: #8a8171; font-size: .85rem; }
+main { display: flex; align-items: flex-start; }
+table { border-collapse: collapse; flex: 1; }
+tr { cursor: pointer; }
+tr:hover { background: #efe9df; }
+tr.selected { background: #d8efe2; }
+td { padding: 0 .5rem; white-space: nowrap; line-height: 1.35rem; }
+td.graph { font-family: ui-monospace, Menlo, Consolas, monospace; white-space: pre; padding-left: 1rem; }
+td.hash { font-family: ui-monospace, Menlo, Consolas, monospace; color: #2f6d4b; }
+td.subject { white-space: normal; }
+td.author, td.when { color: #5e5648; font-size: .9rem; }
+.ref { display: inline-block; font-size: .75rem; padding: 0 .35rem; margin-right: .3rem; border-radius: 3px; background: #efe9df; color: #7a5a2a; }
+.ref.head, .ref.branch { color: #2f6d4b; }
+aside { position: sticky; top: 3.2rem; width: 32rem; max-height: calc(100vh - 4rem); overflow: auto; margin: .5rem 1rem; padding: .5rem 1rem; background: #f2eee6; border: 1px solid #c9bda8; border-radius: 6px; }
+aside pre { white-space: pre-wrap; font-family: ui-monospace, Menlo, Consolas, monospace; font-size: .85rem; }
+aside:empty { display: none; }
+{{lanes}}</style>
+</head>
+<body>
+<header>
+<h1>{{.Repo}}</h1>
+<input id="search" type="search" placeholder="Search hash, subject, author, or ref" autofocus>
+<span class="meta"><span id="count">{{len .Rows}}</span> commits{{if not .Complete}} (newest only){{end}}, exported {{date .Generated}}</span>
+</header>
+<main>
+<table>
+{{range .Rows}}<tr data-search="{{search .}}" data-hash="{{.Hash}}">
+<td class="graph">{{range .Graph}}<span class="l{{.Color}}">{{.Ch}}</span>{{end}}</td>
+<td class="hash">{{.ShortHash}}</td>
+<td class="subject">{{range .Refs}}<span class="ref {{.Kind}}">{{.Short}}</span>{{end}}{{.Subject}}</td>
+<td class="author">{{.Author}}</td>
+<td class="when">{{date .When}}</td>
+</tr>
+<template id="c-{{.Hash}}"><h3>{{.ShortHash}} {{.Subject}}</h3>
+<p class="meta">{{.Author}}{{with .Email}} &lt;{{.}}&gt;{{end}}, {{date .When}}<br>{{.Hash}}{{range .Parents}}<br>parent {{.}}{{end}}</p>
+<pre>{{.Message}}</pre></template>
+{{end}}</table>
+<aside id="detail"></aside>
+</main>
+<script>
+const rows = Array.from(document.querySelectorAll("tr[data-search]"));
+const count = document.getElementById("count");
+const detail = document.getElementById("detail");
+document.getElementById("search").addEventListener("input", (e) => {
+  const words = e.target.value.toLowerCase().split(/\s+/).filter(Boolean);
+  let shown = 0;
+  for (const row of rows) {
+    const match = words.every((w) => row.dataset.search.includes(w));
+    row.hidden = !match;
+    if (match) shown++;
+  }
+  count.textContent = shown;
+});
+for (const row of rows) {
+  row.addEventListener("click", () => {
+    rows.forEach((r) => r.classList.remove("selected"));
+    row.classList.add("selected");
+    detail.replaceChildren(document.getElementById("c-" + row.dataset.hash).content.cloneNode(true));
+  });
+}
+</script>
+</body>
+</html>
+`))
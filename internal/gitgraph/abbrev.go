@@ -0,0 +1,327 @@
+package gitgraph
+
+import (
+	"bufio"
+	"encoding/binary"
+	"encoding/hex"
+	"io"
+	"math/bits"
+	"path"
+	"path/filepath"
+	"slices"
+	"strconv"
+	"strings"
+	"sync"
+	"sync/atomic"
+
+	"github.com/go-git/go-billy/v5"
+	"github.com/go-git/go-billy/v5/osfs"
+	"github.com/go-git/go-billy/v5/util"
+	git "github.com/go-git/go-git/v5"
+	"github.com/go-git/go-git/v5/plumbing"
+	"github.com/go-git/go-git/v5/storage/filesystem"
+)
+
+// defaultAbbrev is git's shortest automatic abbreviation.
+const defaultAbbrev = 7
+
+// Abbrev shortens hashes the way git does: to core.abbrev characters, by
+// default a length that grows with the number of packed objects, and
+// further for a hash whose prefix another object shares.
+//
+// Ambiguity is checked against an index of the first 8 bytes of every
+// object name, in the repository and its alternates, which the first
+// Short starts reading from the pack indexes and loose objects in the
+// background; until it is ready, and for objects written since, hashes
+// are only as long as core.abbrev says. Callers that keep hashes rather
+// than print them at once should shorten them as they show them.
+type Abbrev struct {
+	repo *git.Repository
+	once sync.Once
+	min  int
+	// built is closed once prefixes is read.
+	built    chan struct{}
+	prefixes []uint64
+	// stale is the read index Refresh replaced this one with, answered
+	// from until prefixes is read.
+	stale atomic.Pointer[Abbrev]
+}
+
+// NewAbbrev returns an abbreviation index for repo that reads nothing
+// until it is first used.
+func NewAbbrev(repo *git.Repository) *Abbrev {
+	return &Abbrev{repo: repo}
+}
+
+// start reads the length to abbreviate to and starts the index.
+func (a *Abbrev) start() {
+	a.once.Do(func() {
+		a.built = make(chan struct{})
+		dirs := repoObjectDirs(a.repo)
+		a.min = abbrevLength(GitConfig(a.repo).Section("core").Option("abbrev"), packedObjects(dirs))
+		go func() {
+			a.prefixes = objectPrefixes(dirs)
+			a.stale.Store(nil)
+			close(a.built)
+		}()
+	})
+}
+
+// ready starts the index and reports whether it is read.
+func (a *Abbrev) ready() bool {
+	a.start()
+	select {
+	case <-a.built:
+		return true
+	default:
+		return false
+	}
+}
+
+// Refresh returns an index for the same repository that reads its object
+// names again, for objects written since a was read. Until it has, Short
+// goes on answering from a.
+func (a *Abbrev) Refresh() *Abbrev {
+	if a == nil {
+		return nil
+	}
+	next := &Abbrev{repo: a.repo}
+	if a.ready() {
+		next.stale.Store(a)
+	} else {
+		next.stale.Store(a.stale.Load())
+	}
+	return next
+}
+
+// Wait blocks until the ambiguity index is read, for output that must
+// not change with how soon it is printed.
+func (a *Abbrev) Wait() {
+	if a == nil {
+		return
+	}
+	a.start()
+	<-a.built
+}
+
+// ShortLength is how long Short makes a hash with no other object sharing
+// its prefix, without the index: for a one-off hash, such as HEAD's.
+func ShortLength(repo *git.Repository) int {
+	return abbrevLength(GitConfig(repo).Section("core").Option("abbrev"), packedObjects(repoObjectDirs(repo)))
+}
+
+// abbrevLength is the length core.abbrev asks for, given how many objects
+// the repository has packed for "auto".
+func abbrevLength(setting string, objects int) int {
+	switch setting {
+	case "", "auto":
+		if objects > 0 {
+			return max(defaultAbbrev, (bits.Len(uint(objects))+1)/2)
+		}
+	case "no", "false":
+		return 40
+	default:
+		if n, err := strconv.Atoi(setting); err == nil {
+			return min(max(n, 4), 40)
+		}
+	}
+	return defaultAbbrev
+}
+
+// Short is the shortest prefix of hash, at least the configured length,
+// that names no other object. A nil Abbrev gives git's default length.
+func (a *Abbrev) Short(hash plumbing.Hash) string {
+	full := hash.String()
+	if a == nil {
+		return full[:defaultAbbrev]
+	}
+	if a.ready() {
+		return full[:shortLength(a.prefixes, a.min, hash)]
+	}
+	if stale := a.stale.Load(); stale != nil {
+		return full[:shortLength(stale.prefixes, a.min, hash)]
+	}
+	return full[:a.min]
+}
+
+// shortLength is how many hex digits of hash, at least n, tell it apart
+// from its neighbors in the sorted prefixes.
+func shortLength(prefixes []uint64, n int, hash plumbing.Hash) int {
+	prefix := binary.BigEndian.Uint64(hash[:8])
+	i, found := slices.BinarySearch(prefixes, prefix)
+	var neighbors []uint64
+	if i > 0 {
+		neighbors = append(neighbors, prefixes[i-1])
+	}
+	if found {
+		i++
+	}
+	if i < len(prefixes) {
+		neighbors = append(neighbors, prefixes[i])
+	}
+	for _, other := range neighbors {
+		// Shared leading hex digits, plus one to tell them apart.
+		n = max(n, bits.LeadingZeros64(prefix^other)/4+1)
+	}
+	return min(n, 40)
+}
+
+// ShortString is Short for a hash in hex; other strings, such as a
+// symbolic name, come back unchanged.
+func (a *Abbrev) ShortString(hash string) string {
+	if len(hash) != 40 {
+		return hash
+	}
+	if _, err := hex.DecodeString(hash); err != nil {
+		return hash
+	}
+	return a.Short(plumbing.NewHash(hash))
+}
+
+// objectDir is an object directory: the repository's own, or one of its
+// alternates.
+type objectDir struct {
+	fs   billy.Filesystem
+	path string
+}
+
+// maxAlternateDepth is how deep git follows alternates of alternates.
+const maxAlternateDepth = 5
+
+func repoObjectDirs(repo *git.Repository) []objectDir {
+	if fs, ok := repo.Storer.(*filesystem.Storage); ok {
+		return objectDirs(fs.Filesystem())
+	}
+	return nil
+}
+
+// objectDirs lists the repository's object directory, at objects in fs,
+// then those objects/info/alternates names, and theirs in turn.
+func objectDirs(fs billy.Filesystem) []objectDir {
+	dirs := []objectDir{{fs: fs, path: "objects"}}
+	seen := map[string]bool{}
+	for i := 0; i < len(dirs) && i <= maxAlternateDepth; i++ {
+		dir := dirs[i]
+		data, err := util.ReadFile(dir.fs, path.Join(dir.path, "info/alternates"))
+		if err != nil {
+			continue
+		}
+		base := filepath.Join(dir.fs.Root(), dir.path)
+		for _, line := range strings.Split(string(data), "\n") {
+			line = strings.TrimSpace(line)
+			if line == "" || strings.HasPrefix(line, "#") {
+				continue
+			}
+			if !filepath.IsAbs(line) {
+				line = filepath.Join(base, line)
+			}
+			if line = filepath.Clean(line); !seen[line] {
+				seen[line] = true
+				dirs = append(dirs, objectDir{fs: osfs.New(line), path: "."})
+			}
+		}
+	}
+	return dirs
+}
+
+// packedObjects counts the objects in dirs' packs from their index
+// headers, as git estimates the size of a repository.
+func packedObjects(dirs []objectDir) int {
+	count := 0
+	for _, dir := range dirs {
+		for _, idx := range packIndexes(dir) {
+			if header, ok := readIdxHeader(dir.fs, idx); ok {
+				count += int(binary.BigEndian.Uint32(header[len(header)-4:]))
+			}
+		}
+	}
+	return count
+}
+
+func packIndexes(dir objectDir) []string {
+	packs, err := dir.fs.ReadDir(path.Join(dir.path, "pack"))
+	if err != nil {
+		return nil
+	}
+	var names []string
+	for _, pack := range packs {
+		if strings.HasSuffix(pack.Name(), ".idx") {
+			names = append(names, path.Join(dir.path, "pack", pack.Name()))
+		}
+	}
+	return names
+}
+
+// objectPrefixes lists the first 8 bytes of every object name in dirs,
+// sorted, without duplicates. It returns what it could read.
+func objectPrefixes(dirs []objectDir) []uint64 {
+	var prefixes []uint64
+	for _, dir := range dirs {
+		for _, idx := range packIndexes(dir) {
+			prefixes = appendIdxPrefixes(prefixes, dir.fs, idx)
+		}
+		for n := 0; n < 256; n++ {
+			name := hex.EncodeToString([]byte{byte(n)})
+			loose, err := dir.fs.ReadDir(path.Join(dir.path, name))
+			if err != nil {
+				continue
+			}
+			for _, object := range loose {
+				raw, err := hex.DecodeString(name + object.Name())
+				if err == nil && len(raw) == 20 {
+					prefixes = append(prefixes, binary.BigEndian.Uint64(raw[:8]))
+				}
+			}
+		}
+	}
+	slices.Sort(prefixes)
+	return slices.Compact(prefixes)
+}
+
+// idxHeader is a version 2 pack index's header: its magic number and
+// version, then a 256-entry fan-out table whose last entry is the object
+// count.
+const idxHeader = 8 + 256*4
+
+// readIdxHeader reads the header of the version 2 pack index name.
+func readIdxHeader(fs billy.Filesystem, name string) ([]byte, bool) {
+	f, err := fs.Open(name)
+	if err != nil {
+		return nil, false
+	}
+	defer f.Close()
+	header := make([]byte, idxHeader)
+	if _, err := io.ReadFull(f, header); err != nil || !isIdxV2(header) {
+		return nil, false
+	}
+	return header, true
+}
+
+func isIdxV2(header []byte) bool {
+	return string(header[:4]) == "\xfftOc" && binary.BigEndian.Uint32(header[4:8]) == 2
+}
+
+// appendIdxPrefixes reads the sorted object names of a version 2 pack
+// index, the 20-byte names that follow its header.
+func appendIdxPrefixes(prefixes []uint64, fs billy.Filesystem, name string) []uint64 {
+	f, err := fs.Open(name)
+	if err != nil {
+		return prefixes
+	}
+	defer f.Close()
+	r := bufio.NewReaderSize(f, 64*1024)
+	header := make([]byte, idxHeader)
+	if _, err := io.ReadFull(r, header); err != nil || !isIdxV2(header) {
+		return prefixes
+	}
+	count := binary.BigEndian.Uint32(header[len(header)-4:])
+	prefixes = slices.Grow(prefixes, int(count))
+	var object [20]byte
+	for range count {
+		if _, err := io.ReadFull(r, object[:]); err != nil {
+			break
+		}
+		prefixes = append(prefixes, binary.BigEndian.Uint64(object[:8]))
+	}
+	return prefixes
+}
@@ -0,0 +1,215 @@
+package gitgraph
+
+import (
+	"encoding/binary"
+	"os"
+	"path/filepath"
+	"slices"
+	"testing"
+
+	"github.com/go-git/go-billy/v5/memfs"
+	"github.com/go-git/go-billy/v5/osfs"
+	"github.com/go-git/go-billy/v5/util"
+	"github.com/go-git/go-git/v5/plumbing"
+)
+
+// idx builds a pack index with the given version and object names; count,
+// when not -1, overrides the object count in the fan-out table.
+func idx(version uint32, count int, names ...string) []byte {
+	data := []byte("\xfftOc")
+	data = binary.BigEndian.AppendUint32(data, version)
+	if count < 0 {
+		count = len(names)
+	}
+	for i := range 256 {
+		n := 0
+		for _, name := range names {
+			if int(plumbing.NewHash(name)[0]) <= i {
+				n++
+			}
+		}
+		if i == 255 {
+			n = count
+		}
+		data = binary.BigEndian.AppendUint32(data, uint32(n))
+	}
+	for _, name := range names {
+		hash := plumbing.NewHash(name)
+		data = append(data, hash[:]...)
+	}
+	return data
+}
+
+func prefixOf(name string) uint64 {
+	hash := plumbing.NewHash(name)
+	return binary.BigEndian.Uint64(hash[:8])
+}
+
+func TestAppendIdxPrefixes(t *testing.T) {
+	a := "0123456789abcdef0123456789abcdef01234567"
+	b := "89abcdef0123456789abcdef0123456789abcdef"
+	tests := []struct {
+		name string
+		data []byte
+		want []uint64
+	}{
+		{"v2", idx(2, -1, a, b), []uint64{prefixOf(a), prefixOf(b)}},
+		{"empty", idx(2, -1), nil},
+		{"v1", idx(1, -1, a, b), nil},
+		{"bad magic", append([]byte("PACK"), idx(2, -1, a)[4:]...), nil},
+		{"short header", idx(2, -1)[:100], nil},
+		{"truncated names", idx(2, 3, a, b), []uint64{prefixOf(a), prefixOf(b)}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			fs := memfs.New()
+			if err := util.WriteFile(fs, "pack.idx", tt.data, 0o644); err != nil {
+				t.Fatal(err)
+			}
+			if got := appendIdxPrefixes(nil, fs, "pack.idx"); !slices.Equal(got, tt.want) {
+				t.Errorf("appendIdxPrefixes = %x, want %x", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestObjectPrefixes(t *testing.T) {
+	packed := "0123456789abcdef0123456789abcdef01234567"
+	loose := "0123456789abcdef0123456789abcdef89abcdef"
+	fs := memfs.New()
+	if err := util.WriteFile(fs, "objects/pack/pack-1.idx", idx(2, -1, packed), 0o644); err != nil {
+		t.Fatal(err)
+	}
+	if err := util.WriteFile(fs, "objects/01/"+loose[2:], nil, 0o644); err != nil {
+		t.Fatal(err)
+	}
+	dirs := []objectDir{{fs: fs, path: "objects"}}
+	// The two names share their first 8 bytes, which are all it keeps.
+	if got, want := objectPrefixes(dirs), []uint64{prefixOf(packed)}; !slices.Equal(got, want) {
+		t.Errorf("objectPrefixes = %x, want %x", got, want)
+	}
+	if got := packedObjects(dirs); got != 1 {
+		t.Errorf("packedObjects = %d, want 1", got)
+	}
+}
+
+func TestShort(t *testing.T) {
+	hash := "abcdef0123456789abcdef0123456789abcdef01"
+	tests := []struct {
+		name     string
+		min      int
+		prefixes []string
+		want     string
+	}{
+		{"alone", 7, []string{hash}, "abcdef0"},
+		{"no index", 7, nil, "abcdef0"},
+		{"longer minimum", 10, []string{hash}, "abcdef0123"},
+		{"shares 8 digits before", 7, []string{"abcdef01ffffffffffffffffffffffffffffffff", hash}, "abcdef012"},
+		{"shares 9 digits after", 7, []string{hash, "abcdef012fffffffffffffffffffffffffffffff"}, "abcdef0123"},
+		{"closest neighbor wins", 7, []string{"abcdef0000000000000000000000000000000000", "abcdef01230000000000000000000000000000ff", hash, "abcdef1000000000000000000000000000000000"}, "abcdef01234"},
+		{"not in the index", 7, []string{"abcdef0000000000000000000000000000000000"}, "abcdef01"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			a := &Abbrev{min: tt.min, built: make(chan struct{})}
+			a.once.Do(func() {})
+			for _, name := range tt.prefixes {
+				a.prefixes = append(a.prefixes, prefixOf(name))
+			}
+			slices.Sort(a.prefixes)
+			a.prefixes = slices.Compact(a.prefixes)
+			close(a.built)
+			if got := a.Short(plumbing.NewHash(hash)); got != tt.want {
+				t.Errorf("Short = %q, want %q", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestShortNil(t *testing.T) {
+	var a *Abbrev
+	if got := a.Short(plumbing.NewHash("abcdef0123456789abcdef0123456789abcdef01")); got != "abcdef0" {
+		t.Errorf("Short = %q, want %q", got, "abcdef0")
+	}
+}
+
+func TestShortAfterIndex(t *testing.T) {
+	hash := plumbing.NewHash("abcdef0123456789abcdef0123456789abcdef01")
+	a := &Abbrev{min: 7, built: make(chan struct{})}
+	a.once.Do(func() {})
+	info := &CommitInfo{Hash: hash, provider: &CommitProvider{abbrev: a}}
+	if got := info.ShortHash(); got != "abcdef0" {
+		t.Fatalf("ShortHash before the index = %q, want %q", got, "abcdef0")
+	}
+	// A row loaded before the index was read grows once it has.
+	a.prefixes = []uint64{prefixOf("abcdef01ffffffffffffffffffffffffffffffff"), prefixOf(hash.String())}
+	close(a.built)
+	if got := info.ShortHash(); got != "abcdef012" {
+		t.Errorf("ShortHash after the index = %q, want %q", got, "abcdef012")
+	}
+
+	// A refreshed index answers from the one it replaces until it is read.
+	next := a.Refresh()
+	next.once.Do(func() {
+		next.min = 7
+		next.built = make(chan struct{})
+	})
+	if got := next.Short(hash); got != "abcdef012" {
+		t.Errorf("Short while refreshing = %q, want %q", got, "abcdef012")
+	}
+	next.prefixes = []uint64{prefixOf(hash.String())}
+	next.stale.Store(nil)
+	close(next.built)
+	if got := next.Short(hash); got != "abcdef0" {
+		t.Errorf("Short once refreshed = %q, want %q", got, "abcdef0")
+	}
+}
+
+func TestAbbrevLength(t *testing.T) {
+	tests := []struct {
+		setting string
+		objects int
+		want    int
+	}{
+		{"", 0, 7},
+		{"auto", 100, 7},
+		{"", 1 << 20, 11},
+		{"", 1 << 30, 16},
+		{"12", 0, 12},
+		{"2", 0, 4},
+		{"99", 0, 40},
+		{"no", 0, 40},
+		{"false", 1 << 20, 40},
+		{"bogus", 0, 7},
+	}
+	for _, tt := range tests {
+		if got := abbrevLength(tt.setting, tt.objects); got != tt.want {
+			t.Errorf("abbrevLength(%q, %d) = %d, want %d", tt.setting, tt.objects, got, tt.want)
+		}
+	}
+}
+
+func TestObjectDirsAlternates(t *testing.T) {
+	root := t.TempDir()
+	fs := osfs.New(filepath.Join(root, "repo.git"))
+	alt := filepath.Join(root, "alt.git", "objects")
+	other := filepath.Join(root, "other.git", "objects")
+	if err := util.WriteFile(fs, "objects/info/alternates", []byte("# shared\n../../alt.git/objects\n"), 0o644); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.MkdirAll(filepath.Join(alt, "info"), 0o755); err != nil {
+		t.Fatal(err)
+	}
+	// An alternate's own alternates are followed, and each is read once.
+	if err := os.WriteFile(filepath.Join(alt, "info", "alternates"), []byte(other+"\n"+alt+"\n"), 0o644); err != nil {
+		t.Fatal(err)
+	}
+	var got []string
+	for _, dir := range objectDirs(fs) {
+		got = append(got, filepath.Join(dir.fs.Root(), dir.path))
+	}
+	want := []string{filepath.Join(root, "repo.git", "objects"), alt, other}
+	if !slices.Equal(got, want) {
+		t.Errorf("objectDirs = %q, want %q", got, want)
+	}
+}
@@ -0,0 +1,177 @@
+package gitgraph
+
+import (
+	"bufio"
+	"io"
+	"os"
+	"path"
+	"path/filepath"
+	"strings"
+
+	git "github.com/go-git/go-git/v5"
+	"github.com/go-git/go-git/v5/plumbing/format/config"
+	"github.com/go-git/go-git/v5/plumbing/format/gitattributes"
+	"github.com/go-git/go-git/v5/plumbing/object"
+)
+
+// Attributes looks up .gitattributes for paths the way git diff does:
+// core.attributesFile, then each .gitattributes from the root down to the
+// path's directory, read from the worktree or from HEAD in a bare
+// repository, then info/attributes, each winning over those before it.
+type Attributes struct {
+	worktree func(name string) (io.ReadCloser, error)
+	global   []gitattributes.MatchAttribute
+	info     []gitattributes.MatchAttribute
+	dirs     map[string][]gitattributes.MatchAttribute
+	macros   map[string][]gitattributes.Attribute
+	config   *config.Config
+	// root is where textconv commands run, as git runs them at the top of
+	// the worktree.
+	root string
+	// converted caches textconv output, when the Attributes come from a
+	// provider.
+	converted *lru[textconvKey, string]
+}
+
+// LoadAttributes reads the attribute files that apply to every path;
+// those of subdirectories are read as paths in them are looked up. An
+// Attributes is not safe for concurrent use.
+func LoadAttributes(repo *git.Repository) *Attributes {
+	a := &Attributes{
+		dirs:   make(map[string][]gitattributes.MatchAttribute),
+		macros: map[string][]gitattributes.Attribute{},
+	}
+	// binary is built in: a file that is neither diffed, merged nor
+	// converted as text.
+	if builtin, err := gitattributes.ParseAttributesLine("[attr]binary -diff -merge -text", nil, true); err == nil {
+		a.macros[builtin.Name] = builtin.Attributes
+	}
+	if wt, err := repo.Worktree(); err == nil {
+		a.worktree = func(name string) (io.ReadCloser, error) { return wt.Filesystem.Open(name) }
+		a.root = wt.Filesystem.Root()
+	} else if head, err := ResolveCommit(repo, "HEAD"); err == nil {
+		if tree, err := head.Tree(); err == nil {
+			a.worktree = func(name string) (io.ReadCloser, error) { return treeReader(tree, name) }
+		}
+	}
+	a.config = GitConfig(repo)
+	if name := a.config.Section("core").Option("attributesfile"); name != "" {
+		if strings.HasPrefix(name, "~/") {
+			if home, err := os.UserHomeDir(); err == nil {
+				name = filepath.Join(home, name[2:])
+			}
+		}
+		if f, err := os.Open(name); err == nil {
+			a.global = a.parse(f, nil)
+			f.Close()
+		}
+	}
+	if gitDir := GitDir(repo); gitDir != "" {
+		if a.root == "" {
+			a.root = gitDir
+		}
+		if f, err := os.Open(filepath.Join(gitDir, "info", "attributes")); err == nil {
+			a.info = a.parse(f, nil)
+			f.Close()
+		}
+	}
+	return a
+}
+
+func treeReader(tree *object.Tree, name string) (io.ReadCloser, error) {
+	file, err := tree.File(name)
+	if err != nil {
+		return nil, err
+	}
+	return file.Reader()
+}
+
+// parse reads one attribute file whose patterns are relative to domain.
+// Lines git would reject are skipped, as git skips them with a warning;
+// macros are recorded as they are defined.
+func (a *Attributes) parse(r io.Reader, domain []string) []gitattributes.MatchAttribute {
+	var rules []gitattributes.MatchAttribute
+	scanner := bufio.NewScanner(r)
+	for scanner.Scan() {
+		rule, err := gitattributes.ParseAttributesLine(scanner.Text(), domain, len(domain) == 0)
+		if err != nil || rule.Name == "" {
+			continue
+		}
+		if rule.Pattern == nil {
+			a.macros[rule.Name] = rule.Attributes
+			continue
+		}
+		rules = append(rules, rule)
+	}
+	return rules
+}
+
+// dir is the rules of dir's .gitattributes, "" being the root.
+func (a *Attributes) dir(dir string) []gitattributes.MatchAttribute {
+	if rules, ok := a.dirs[dir]; ok {
+		return rules
+	}
+	var rules []gitattributes.MatchAttribute
+	if a.worktree != nil {
+		var domain []string
+		if dir != "" {
+			domain = strings.Split(dir, "/")
+		}
+		if f, err := a.worktree(path.Join(dir, ".gitattributes")); err == nil {
+			rules = a.parse(f, domain)
+			f.Close()
+		}
+	}
+	a.dirs[dir] = rules
+	return rules
+}
+
+// Lookup resolves the named attributes for file: the last rule to
+// mention one decides it, and a set macro, such as binary, sets the
+// attributes it stands for unless a later rule decides them. Attributes
+// no rule mentions are missing from the result.
+func (a *Attributes) Lookup(file string, names ...string) map[string]gitattributes.Attribute {
+	stack := [][]gitattributes.MatchAttribute{a.global}
+	dir := ""
+	for _, part := range strings.Split(path.Dir(file), "/") {
+		stack = append(stack, a.dir(dir))
+		if part != "." {
+			dir = path.Join(dir, part)
+		}
+	}
+	if dir != "" {
+		stack = append(stack, a.dir(dir))
+	}
+	stack = append(stack, a.info)
+
+	wanted := make(map[string]bool, len(names))
+	for _, name := range names {
+		wanted[name] = true
+	}
+	results := make(map[string]gitattributes.Attribute, len(names))
+	decide := func(attr gitattributes.Attribute) {
+		if _, done := results[attr.Name()]; !done && wanted[attr.Name()] {
+			results[attr.Name()] = attr
+		}
+	}
+	parts := strings.Split(file, "/")
+	for i := len(stack) - 1; i >= 0; i-- {
+		rules := stack[i]
+		for j := len(rules) - 1; j >= 0; j-- {
+			if !rules[j].Pattern.Match(parts) {
+				continue
+			}
+			attrs := rules[j].Attributes
+			for k := len(attrs) - 1; k >= 0; k-- {
+				decide(attrs[k])
+				if attrs[k].IsSet() {
+					macro := a.macros[attrs[k].Name()]
+					for m := len(macro) - 1; m >= 0; m-- {
+						decide(macro[m])
+					}
+				}
+			}
+		}
+	}
+	return results
+}
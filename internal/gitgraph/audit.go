@@ -0,0 +1,121 @@
+package gitgraph
+
+import (
+	"fmt"
+	"regexp"
+	"strings"
+
+	"github.com/go-git/go-git/v5/plumbing/format/diff"
+	"github.com/go-git/go-git/v5/plumbing/object"
+)
+
+type FindingKind string
+
+const (
+	FindingLargeFile FindingKind = "large"
+	FindingSecret    FindingKind = "secret"
+)
+
+type Finding struct {
+	Kind   FindingKind
+	Path   string
+	Size   int64
+	Detail string
+}
+
+func (f Finding) String() string {
+	if f.Kind == FindingLargeFile {
+		return fmt.Sprintf("large file %s (%s)", f.Path, FormatSize(f.Size))
+	}
+	return fmt.Sprintf("possible secret in %s: %s", f.Path, f.Detail)
+}
+
+type SecretPattern struct {
+	Name string
+	Re   *regexp.Regexp
+}
+
+var DefaultSecretPatterns = []SecretPattern{
+	{"private key", regexp.MustCompile(`-----BEGIN (RSA |EC |DSA |OPENSSH |PGP )?PRIVATE KEY`)},
+	{"AWS access key", regexp.MustCompile(`\b(AKIA|ASIA)[0-9A-Z]{16}\b`)},
+	{"GitHub token", regexp.MustCompile(`\bgh[pousr]_[A-Za-z0-9]{36,}\b`)},
+	{"Slack token", regexp.MustCompile(`\bxox[abposr]-[A-Za-z0-9-]{10,}`)},
+	{"credential assignment", regexp.MustCompile(`(?i)\b(api[_-]?key|secret|passw(or)?d|token)\b["']?\s*[:=]\s*["'][^"'\s]{12,}["']`)},
+}
+
+type AuditOptions struct {
+	MaxFileSize    int64
+	ScanSecrets    bool
+	SecretPatterns []SecretPattern
+}
+
+// maxScanSize bounds how much of a single added blob is searched for
+// secrets; anything bigger is reported by the size check instead.
+const maxScanSize = 1 << 20
+
+// AuditCommit reports files the commit adds or grows past the size limit and
+// added lines matching a secret pattern. Lines already present in the parent
+// are not reported again.
+func AuditCommit(commit *object.Commit, opts AuditOptions) ([]Finding, error) {
+	changes, err := CommitChanges(commit)
+	if err != nil {
+		return nil, err
+	}
+	var findings []Finding
+	for _, change := range changes {
+		from, to, err := change.Files()
+		if err != nil || to == nil {
+			continue
+		}
+		if opts.MaxFileSize > 0 && to.Size > opts.MaxFileSize && (from == nil || from.Size <= opts.MaxFileSize) {
+			findings = append(findings, Finding{Kind: FindingLargeFile, Path: to.Name, Size: to.Size})
+		}
+		if !opts.ScanSecrets || len(opts.SecretPatterns) == 0 || to.Size > maxScanSize {
+			continue
+		}
+		if binary, err := to.IsBinary(); err != nil || binary {
+			continue
+		}
+		patch, err := change.Patch()
+		if err != nil {
+			continue
+		}
+		findings = append(findings, scanAddedLines(to.Name, patch, opts.SecretPatterns)...)
+	}
+	return findings, nil
+}
+
+func scanAddedLines(path string, patch *object.Patch, patterns []SecretPattern) []Finding {
+	var findings []Finding
+	reported := make(map[string]bool)
+	for _, fp := range patch.FilePatches() {
+		for _, chunk := range fp.Chunks() {
+			if chunk.Type() != diff.Add {
+				continue
+			}
+			for _, line := range strings.Split(chunk.Content(), "\n") {
+				for _, pattern := range patterns {
+					if reported[pattern.Name] || !pattern.Re.MatchString(line) {
+						continue
+					}
+					reported[pattern.Name] = true
+					findings = append(findings, Finding{Kind: FindingSecret, Path: path, Detail: pattern.Name})
+				}
+			}
+		}
+	}
+	return findings
+}
+
+func FormatSize(n int64) string {
+	const unit = 1024
+	if n < unit {
+		return fmt.Sprintf("%d B", n)
+	}
+	div, exp := int64(unit), 0
+	for v := n / unit; v >= unit; v /= unit {
+		div *= unit
+		exp++
+	}
+	return fmt.Sprintf("%.1f %ciB", float64(n)/float64(div), "KMGTPE"[exp])
+}
@@ -0,0 +1,162 @@
+package gitgraph
+
+import (
+	"sort"
+	"strings"
+
+	git "github.com/go-git/go-git/v5"
+	"github.com/go-git/go-git/v5/plumbing"
+	"github.com/go-git/go-git/v5/plumbing/object"
+)
+
+type BranchStatus struct {
+	Branch string
+	Tip    *object.Commit
+
+	// Upstream is the short name of the configured upstream, "" when none.
+	Upstream       string
+	UpstreamGone   bool
+	UpstreamAhead  int
+	UpstreamBehind int
+
+	// Base is the default branch the counts below compare against, "" when
+	// the repository has neither main nor master.
+	Base       string
+	BaseAhead  int
+	BaseBehind int
+}
+
+// DefaultBranch returns main, or master when there is no main.
+func DefaultBranch(repo *git.Repository) (string, *object.Commit, bool) {
+	for _, name := range []string{"main", "master"} {
+		ref, err := repo.Reference(plumbing.NewBranchReferenceName(name), true)
+		if err != nil {
+			continue
+		}
+		if commit, err := repo.CommitObject(ref.Hash()); err == nil {
+			return name, commit, true
+		}
+	}
+	return "", nil, false
+}
+
+// UpstreamRef returns the full ref name of branch's configured upstream.
+func UpstreamRef(repo *git.Repository, branch string) (string, bool) {
+	cfg, err := repo.Config()
+	if err != nil {
+		return "", false
+	}
+	b, ok := cfg.Branches[branch]
+	if !ok || b.Remote == "" || b.Merge == "" {
+		return "", false
+	}
+	if b.Remote == "." {
+		return b.Merge.String(), true
+	}
+	return "refs/remotes/" + b.Remote + "/" + strings.TrimPrefix(b.Merge.String(), "refs/heads/"), true
+}
+
+// BranchStatuses computes ahead/behind counts for every local branch against
+// its upstream and against the default branch.
+func BranchStatuses(repo *git.Repository, gens *Generations) ([]BranchStatus, error) {
+	refs, err := ListRefs(repo)
+	if err != nil {
+		return nil, err
+	}
+	base, baseCommit, hasBase := DefaultBranch(repo)
+
+	var statuses []BranchStatus
+	for _, ref := range refs {
+		if ref.Kind != RefBranch {
+			continue
+		}
+		tip, err := gens.Commit(ref.Hash)
+		if err != nil {
+			continue
+		}
+		status := BranchStatus{Branch: ref.Short, Tip: tip}
+		if upstream, ok := UpstreamRef(repo, ref.Short); ok {
+			status.Upstream = plumbing.ReferenceName(upstream).Short()
+			if up, err := repo.Reference(plumbing.ReferenceName(upstream), true); err != nil {
+				status.UpstreamGone = true
+			} else if status.UpstreamAhead, status.UpstreamBehind, err = AheadBehind(gens, tip.Hash, up.Hash()); err != nil {
+				return nil, err
+			}
+		}
+		if hasBase {
+			status.Base = base
+			if status.BaseAhead, status.BaseBehind, err = AheadBehind(gens, tip.Hash, baseCommit.Hash); err != nil {
+				return nil, err
+			}
+		}
+		statuses = append(statuses, status)
+	}
+	sort.Slice(statuses, func(i, j int) bool { return statuses[i].Branch < statuses[j].Branch })
+	return statuses, nil
+}
+
+// AheadBehind counts commits reachable from a but not b (ahead) and from b
+// but not a (behind).
+func AheadBehind(gens *Generations, a, b plumbing.Hash) (int, int, error) {
+	onlyA, onlyB, err := gens.SymmetricDifference(a, b)
+	return len(onlyA), len(onlyB), err
+}
+
+// SymmetricDifference returns the commits reachable from only a and from only
+// b, newest first (the a...b range).
+func SymmetricDifference(repo *git.Repository, gens *Generations, a, b plumbing.Hash) ([]*object.Commit, []*object.Commit, error) {
+	hashesA, hashesB, err := gens.SymmetricDifference(a, b)
+	if err != nil {
+		return nil, nil, err
+	}
+	onlyA, err := gens.commits(hashesA)
+	if err != nil {
+		return nil, nil, err
+	}
+	onlyB, err := gens.commits(hashesB)
+	if err != nil {
+		return nil, nil, err
+	}
+	sortNewestFirst(onlyA)
+	sortNewestFirst(onlyB)
+	return onlyA, onlyB, nil
+}
+
+func sortNewestFirst(commits []*object.Commit) {
+	sort.Slice(commits, func(i, j int) bool { return commitHeap(commits).Less(i, j) })
+}
+
+// BranchSortKeys are the orderings SortBranchStatuses understands: ahead and
+// behind compare against the default branch, unpushed and unpulled against
+// the upstream.
+var BranchSortKeys = []string{"name", "date", "ahead", "behind", "unpushed", "unpulled"}
+
+// SortBranchStatuses orders by key, largest or newest first for everything
+// but name, breaking ties by name.
+func SortBranchStatuses(statuses []BranchStatus, key string) bool {
+	var value func(s BranchStatus) int64
+	switch key {
+	case "name":
+		value = func(BranchStatus) int64 { return 0 }
+	case "date":
+		value = func(s BranchStatus) int64 { return s.Tip.Committer.When.Unix() }
+	case "ahead":
+		value = func(s BranchStatus) int64 { return int64(s.BaseAhead) }
+	case "behind":
+		value = func(s BranchStatus) int64 { return int64(s.BaseBehind) }
+	case "unpushed":
+		value = func(s BranchStatus) int64 { return int64(s.UpstreamAhead) }
+	case "unpulled":
+		value = func(s BranchStatus) int64 { return int64(s.UpstreamBehind) }
+	default:
+		return false
+	}
+	sort.SliceStable(statuses, func(i, j int) bool {
+		a, b := value(statuses[i]), value(statuses[j])
+		if a != b {
+			return a > b
+		}
+		return statuses[i].Branch < statuses[j].Branch
+	})
+	return true
+}
@@ -0,0 +1,201 @@
+package gitgraph
+
+import (
+	"bufio"
+	"errors"
+	"fmt"
+	"os"
+	"strings"
+
+	git "github.com/go-git/go-git/v5"
+	"github.com/go-git/go-git/v5/plumbing"
+	"github.com/go-git/go-git/v5/plumbing/format/packfile"
+	"github.com/go-git/go-git/v5/plumbing/storer"
+	"github.com/go-git/go-git/v5/storage"
+	"github.com/go-git/go-git/v5/storage/memory"
+)
+
+// Other is another copy of the repository to compare with: a clone on
+// disk or a bundle file.
+type Other struct {
+	// Positions are its branches and tags, keyed as in RefPositions.
+	Positions map[string]plumbing.Hash
+	// Repo reads its objects and, for those it lacks, this repository's,
+	// so commits from either side can be walked together.
+	Repo *git.Repository
+}
+
+// OpenOther loads the refs and objects of source, a clone's directory or
+// a bundle made by git bundle create, without changing repo. A bundle's
+// prerequisite commits have to be in repo, as git bundle verify checks.
+func OpenOther(repo *git.Repository, source string) (*Other, error) {
+	info, err := os.Stat(source)
+	if err != nil {
+		return nil, err
+	}
+	if info.IsDir() {
+		return openClone(repo, source)
+	}
+	return openBundle(repo, source)
+}
+
+func openClone(repo *git.Repository, dir string) (*Other, error) {
+	clone, err := git.PlainOpenWithOptions(dir, &git.PlainOpenOptions{EnableDotGitCommonDir: true})
+	if err != nil {
+		return nil, fmt.Errorf("open %s: %w", dir, err)
+	}
+	positions, err := RefPositions(clone)
+	if err != nil {
+		return nil, err
+	}
+	combined, err := git.Open(&overlay{Storer: clone.Storer, base: repo.Storer}, nil)
+	if err != nil {
+		return nil, err
+	}
+	return &Other{Positions: BranchesAndTags(positions), Repo: combined}, nil
+}
+
+func openBundle(repo *git.Repository, name string) (*Other, error) {
+	f, err := os.Open(name)
+	if err != nil {
+		return nil, err
+	}
+	defer f.Close()
+	r := bufio.NewReader(f)
+	header, err := readBundleHeader(r)
+	if err != nil {
+		return nil, fmt.Errorf("%s: %w", name, err)
+	}
+	for _, hash := range header.prerequisites {
+		if _, err := repo.CommitObject(hash); err != nil {
+			return nil, fmt.Errorf("%s needs commit %s, which this repository does not have", name, hash)
+		}
+	}
+	objects := &overlay{Storer: memory.NewStorage(), base: repo.Storer}
+	// Bundles are thin packs: deltas may be against objects only the
+	// prerequisites' side has, which the overlay reads from repo.
+	if err := packfile.UpdateObjectStorage(objects, r); err != nil {
+		return nil, fmt.Errorf("read %s: %w", name, err)
+	}
+	// git.Open wants a HEAD; a bundle need not list one.
+	head := plumbing.NewSymbolicReference(plumbing.HEAD, plumbing.Master)
+	if hash, ok := header.refs["HEAD"]; ok {
+		head = plumbing.NewHashReference(plumbing.HEAD, hash)
+	}
+	if err := objects.SetReference(head); err != nil {
+		return nil, err
+	}
+	combined, err := git.Open(objects, nil)
+	if err != nil {
+		return nil, err
+	}
+	positions := make(map[string]plumbing.Hash, len(header.refs))
+	for ref, hash := range header.refs {
+		addPosition(combined, positions, ref, hash)
+	}
+	return &Other{Positions: BranchesAndTags(positions), Repo: combined}, nil
+}
+
+type bundleHeader struct {
+	prerequisites []plumbing.Hash
+	refs          map[string]plumbing.Hash
+}
+
+// readBundleHeader reads a v2 or v3 bundle up to the blank line before
+// its pack.
+func readBundleHeader(r *bufio.Reader) (*bundleHeader, error) {
+	signature, err := r.ReadString('\n')
+	if err != nil {
+		return nil, errors.New("not a git bundle")
+	}
+	switch strings.TrimSpace(signature) {
+	case "# v2 git bundle", "# v3 git bundle":
+	default:
+		return nil, errors.New("not a git bundle")
+	}
+	header := &bundleHeader{refs: make(map[string]plumbing.Hash)}
+	for {
+		line, err := r.ReadString('\n')
+		if err != nil {
+			return nil, errors.New("truncated bundle header")
+		}
+		line = strings.TrimSuffix(line, "\n")
+		switch {
+		case line == "":
+			return header, nil
+		case strings.HasPrefix(line, "@"):
+			capability, value, _ := strings.Cut(line[1:], "=")
+			switch {
+			case capability == "object-format" && value != "sha1":
+				return nil, fmt.Errorf("bundle uses %s object names, which are not supported", value)
+			case capability == "filter":
+				return nil, errors.New("partial bundles (made with --filter) are not supported")
+			}
+		case strings.HasPrefix(line, "-"):
+			hash, _, _ := strings.Cut(line[1:], " ")
+			if !plumbing.IsHash(hash) {
+				return nil, fmt.Errorf("bad prerequisite %q", line)
+			}
+			header.prerequisites = append(header.prerequisites, plumbing.NewHash(hash))
+		default:
+			hash, ref, ok := strings.Cut(line, " ")
+			if !ok || !plumbing.IsHash(hash) {
+				return nil, fmt.Errorf("bad ref line %q", line)
+			}
+			header.refs[ref] = plumbing.NewHash(hash)
+		}
+	}
+}
+
+// BranchesAndTags keeps the positions of branches and tags, dropping
+// HEAD and remote-tracking branches, which differ from clone to clone.
+func BranchesAndTags(positions map[string]plumbing.Hash) map[string]plumbing.Hash {
+	kept := make(map[string]plumbing.Hash, len(positions))
+	for name, hash := range positions {
+		if strings.HasPrefix(name, "refs/heads/") || strings.HasPrefix(name, "refs/tags/") {
+			kept[name] = hash
+		}
+	}
+	return kept
+}
+
+// CompareOther compares repo's branches and tags with other's: moves go
+// from here to there, so New commits are the ones only other has and
+// Dropped the ones only repo has.
+func CompareOther(repo *git.Repository, other *Other) (*PositionDiff, error) {
+	here, err := RefPositions(repo)
+	if err != nil {
+		return nil, err
+	}
+	return DiffPositions(other.Repo, NewGenerations(other.Repo), BranchesAndTags(here), other.Positions)
+}
+
+// overlay reads objects from its Storer and then from base, where it
+// writes only to its Storer.
+type overlay struct {
+	storage.Storer
+	base storer.EncodedObjectStorer
+}
+
+func (o *overlay) EncodedObject(t plumbing.ObjectType, hash plumbing.Hash) (plumbing.EncodedObject, error) {
+	obj, err := o.Storer.EncodedObject(t, hash)
+	if errors.Is(err, plumbing.ErrObjectNotFound) {
+		return o.base.EncodedObject(t, hash)
+	}
+	return obj, err
+}
+
+func (o *overlay) HasEncodedObject(hash plumbing.Hash) error {
+	if err := o.Storer.HasEncodedObject(hash); !errors.Is(err, plumbing.ErrObjectNotFound) {
+		return err
+	}
+	return o.base.HasEncodedObject(hash)
+}
+
+func (o *overlay) EncodedObjectSize(hash plumbing.Hash) (int64, error) {
+	size, err := o.Storer.EncodedObjectSize(hash)
+	if errors.Is(err, plumbing.ErrObjectNotFound) {
+		return o.base.EncodedObjectSize(hash)
+	}
+	return size, err
+}
@@ -0,0 +1,97 @@
+package gitgraph
+
+import (
+	"strings"
+
+	git "github.com/go-git/go-git/v5"
+	"github.com/go-git/go-git/v5/plumbing"
+)
+
+// ContainingRefs lists the branches, remote branches, and tags whose history
+// includes hash, like `git branch -a --contains` and `git tag --contains`
+// together. One memo is shared across refs, and nothing at or below hash's
+// generation is walked, so the cost stays near one pass over the newer
+// history.
+func ContainingRefs(repo *git.Repository, gens *Generations, hash plumbing.Hash) ([]Ref, error) {
+	if _, err := gens.Commit(hash); err != nil {
+		return nil, err
+	}
+	refs, err := ListRefs(repo)
+	if err != nil {
+		return nil, err
+	}
+	floor, err := gens.Of(hash)
+	if err != nil {
+		return nil, err
+	}
+	reaches := map[plumbing.Hash]bool{hash: true}
+
+	var contained []Ref
+	for _, ref := range refs {
+		if ref.Kind == RefHead || strings.HasSuffix(ref.Name, "/HEAD") {
+			continue
+		}
+		ok, err := reachesTarget(gens, ref.Target, floor, reaches)
+		if err != nil {
+			return nil, err
+		}
+		if ok {
+			contained = append(contained, ref)
+		}
+	}
+	return contained, nil
+}
+
+// reachesTarget reports whether start has the target among its ancestors,
+// filling memo with the answer for every commit it settles. Commits at or
+// below the target's generation, floor, cannot reach it.
+func reachesTarget(gens *Generations, start plumbing.Hash, floor uint64, memo map[plumbing.Hash]bool) (bool, error) {
+	if result, ok := memo[start]; ok {
+		return result, nil
+	}
+	type frame struct {
+		hash    plumbing.Hash
+		parents []plumbing.Hash
+		next    int
+		found   bool
+	}
+	expand := func(hash plumbing.Hash) (*frame, error) {
+		f := &frame{hash: hash}
+		gen, err := gens.Of(hash)
+		if err != nil || gen <= floor {
+			return f, err
+		}
+		f.parents, err = gens.Parents(hash)
+		return f, err
+	}
+
+	root, err := expand(start)
+	if err != nil {
+		return false, err
+	}
+	stack := []*frame{root}
+	for len(stack) > 0 {
+		top := stack[len(stack)-1]
+		if top.found || top.next == len(top.parents) {
+			memo[top.hash] = top.found
+			stack = stack[:len(stack)-1]
+			if len(stack) > 0 && top.found {
+				stack[len(stack)-1].found = true
+			}
+			continue
+		}
+		parent := top.parents[top.next]
+		top.next++
+		if result, ok := memo[parent]; ok {
+			top.found = top.found || result
+			continue
+		}
+		memo[parent] = false // in progress; a later visit sees no path
+		f, err := expand(parent)
+		if err != nil {
+			return false, err
+		}
+		stack = append(stack, f)
+	}
+	return memo[start], nil
+}
@@ -0,0 +1,154 @@
+package gitgraph
+
+import (
+	"container/heap"
+	"slices"
+	"sort"
+
+	git "github.com/go-git/go-git/v5"
+	"github.com/go-git/go-git/v5/plumbing"
+)
+
+// Context lists center with up to n of its nearest ancestors and n of its
+// nearest descendants, newest first and never a parent before its
+// children, for a provider from NewListedCommitProvider.
+//
+// Descendants need a child index, which git does not keep: it is built by
+// walking from every ref down to center's generation, the history newer
+// than center, so a commit no ref reaches has no descendants here.
+func Context(repo *git.Repository, gens *Generations, center plumbing.Hash, n int) ([]plumbing.Hash, error) {
+	if _, err := gens.Commit(center); err != nil {
+		return nil, err
+	}
+	floor, err := gens.Of(center)
+	if err != nil {
+		return nil, err
+	}
+	gen := map[plumbing.Hash]uint64{center: floor}
+
+	// Ancestors, highest generation first, so the nearest come first.
+	ancestors := &generationHeap{}
+	queued := map[plumbing.Hash]bool{center: true}
+	queue := func(hash plumbing.Hash) error {
+		parents, err := gens.Parents(hash)
+		if err != nil {
+			return err
+		}
+		for _, parent := range parents {
+			if queued[parent] {
+				continue
+			}
+			queued[parent] = true
+			g, err := gens.Of(parent)
+			if err != nil {
+				return err
+			}
+			gen[parent] = g
+			heap.Push(ancestors, generationItem{hash: parent, gen: g})
+		}
+		return nil
+	}
+	if err := queue(center); err != nil {
+		return nil, err
+	}
+	hashes := []plumbing.Hash{center}
+	for found := 0; found < n && ancestors.Len() > 0; found++ {
+		item := heap.Pop(ancestors).(generationItem)
+		hashes = append(hashes, item.hash)
+		if err := queue(item.hash); err != nil {
+			return nil, err
+		}
+	}
+
+	children, err := childIndex(repo, gens, floor)
+	if err != nil {
+		return nil, err
+	}
+	// Descendants, lowest generation first, so the nearest come first.
+	var descendants []generationItem
+	seen := map[plumbing.Hash]bool{center: true}
+	for found, next := 0, center; found < n; found++ {
+		for _, child := range children[next] {
+			if seen[child] {
+				continue
+			}
+			seen[child] = true
+			g, err := gens.Of(child)
+			if err != nil {
+				return nil, err
+			}
+			gen[child] = g
+			descendants = append(descendants, generationItem{hash: child, gen: g})
+		}
+		if len(descendants) == 0 {
+			break
+		}
+		lowest := 0
+		for i, item := range descendants {
+			if item.gen < descendants[lowest].gen {
+				lowest = i
+			}
+		}
+		next = descendants[lowest].hash
+		descendants = slices.Delete(descendants, lowest, lowest+1)
+		hashes = append(hashes, next)
+	}
+
+	commits, err := gens.commits(hashes)
+	if err != nil {
+		return nil, err
+	}
+	sort.SliceStable(commits, func(i, j int) bool {
+		gi, gj := gen[commits[i].Hash], gen[commits[j].Hash]
+		if gi != gj {
+			return gi > gj
+		}
+		return dateOrder(commits[i], commits[j])
+	})
+	for i, commit := range commits {
+		hashes[i] = commit.Hash
+	}
+	return hashes, nil
+}
+
+// childIndex maps each commit above generation floor that a ref reaches,
+// and each commit at floor, to its children.
+func childIndex(repo *git.Repository, gens *Generations, floor uint64) (map[plumbing.Hash][]plumbing.Hash, error) {
+	refs, err := ListRefs(repo)
+	if err != nil {
+		return nil, err
+	}
+	children := make(map[plumbing.Hash][]plumbing.Hash)
+	walked := make(map[plumbing.Hash]bool)
+	var stack []plumbing.Hash
+	for _, ref := range refs {
+		if !walked[ref.Target] {
+			walked[ref.Target] = true
+			stack = append(stack, ref.Target)
+		}
+	}
+	for len(stack) > 0 {
+		hash := stack[len(stack)-1]
+		stack = stack[:len(stack)-1]
+		g, err := gens.Of(hash)
+		if err != nil {
+			// A ref to a tree or blob, say; it has no place in history.
+			continue
+		}
+		if g <= floor {
+			continue
+		}
+		parents, err := gens.Parents(hash)
+		if err != nil {
+			return nil, err
+		}
+		for _, parent := range parents {
+			children[parent] = append(children[parent], hash)
+			if !walked[parent] {
+				walked[parent] = true
+				stack = append(stack, parent)
+			}
+		}
+	}
+	return children, nil
+}
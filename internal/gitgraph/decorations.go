@@ -0,0 +1,85 @@
+package gitgraph
+
+import (
+	"path"
+	"strings"
+
+	git "github.com/go-git/go-git/v5"
+)
+
+// DecorationFilter picks the refs that label commits, as git log's
+// --decorate-refs and --decorate-refs-exclude do. A pattern not starting
+// with "refs/" (or HEAD) gets it prefixed; one without glob characters
+// matches that ref and everything under it, and a glob also matches
+// everything under a ref it matches, so "refs/remotes/*/dependabot" hides
+// all of dependabot's branches. Exclude wins over Include, and an empty
+// Include shows every ref not excluded.
+type DecorationFilter struct {
+	Include []string
+	Exclude []string
+}
+
+// LoadDecorationFilter is include and exclude plus the repository's
+// log.excludeDecoration patterns, which, as in git, an include list
+// overrides.
+func LoadDecorationFilter(repo *git.Repository, include, exclude []string) DecorationFilter {
+	f := DecorationFilter{Include: include, Exclude: exclude}
+	if len(include) > 0 {
+		return f
+	}
+	f.Exclude = append(f.Exclude[:len(f.Exclude):len(f.Exclude)], GitConfig(repo).Section("log").Options.GetAll("excludeDecoration")...)
+	return f
+}
+
+// Shows reports whether the ref with the full name (refs/heads/main, HEAD)
+// decorates its commit.
+func (f DecorationFilter) Shows(name string) bool {
+	for _, pattern := range f.Exclude {
+		if matchDecoration(pattern, name) {
+			return false
+		}
+	}
+	if len(f.Include) == 0 {
+		return true
+	}
+	for _, pattern := range f.Include {
+		if matchDecoration(pattern, name) {
+			return true
+		}
+	}
+	return false
+}
+
+// Refs keeps the refs that Shows.
+func (f DecorationFilter) Refs(refs []Ref) []Ref {
+	if len(f.Include) == 0 && len(f.Exclude) == 0 {
+		return refs
+	}
+	kept := make([]Ref, 0, len(refs))
+	for _, ref := range refs {
+		if f.Shows(ref.Name) {
+			kept = append(kept, ref)
+		}
+	}
+	return kept
+}
+
+func matchDecoration(pattern, name string) bool {
+	pattern = strings.TrimSuffix(pattern, "/")
+	if !strings.HasPrefix(pattern, "refs/") && pattern != "HEAD" {
+		pattern = "refs/" + pattern
+	}
+	if !strings.ContainsAny(pattern, "*?[") {
+		return name == pattern || strings.HasPrefix(name, pattern+"/")
+	}
+	for prefix := name; ; {
+		if ok, _ := path.Match(pattern, prefix); ok {
+			return true
+		}
+		i := strings.LastIndex(prefix, "/")
+		if i < 0 {
+			return false
+		}
+		prefix = prefix[:i]
+	}
+}
@@ -0,0 +1,96 @@
+package gitgraph
+
+import (
+	"bufio"
+	"sort"
+	"strings"
+
+	git "github.com/go-git/go-git/v5"
+	"github.com/go-git/go-git/v5/plumbing"
+	"github.com/go-git/go-git/v5/plumbing/object"
+)
+
+// DeployMarkers maps commits to the environments running them. An
+// environment is either a ref under refPrefix (refs/deploys/prod names
+// "prod") or a line of a note on the commit in notesRef. Either source may
+// be empty to skip it.
+func DeployMarkers(repo *git.Repository, refPrefix, notesRef string) (map[plumbing.Hash][]string, error) {
+	markers := make(map[plumbing.Hash][]string)
+	add := func(hash plumbing.Hash, env string) {
+		for _, existing := range markers[hash] {
+			if existing == env {
+				return
+			}
+		}
+		markers[hash] = append(markers[hash], env)
+	}
+
+	if refPrefix != "" {
+		iter, err := repo.References()
+		if err != nil {
+			return nil, err
+		}
+		err = iter.ForEach(func(ref *plumbing.Reference) error {
+			name := ref.Name().String()
+			if ref.Type() != plumbing.HashReference || !strings.HasPrefix(name, refPrefix) {
+				return nil
+			}
+			add(peel(repo, ref.Hash()), strings.TrimPrefix(name, refPrefix))
+			return nil
+		})
+		iter.Close()
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	if notesRef != "" {
+		if err := readDeployNotes(repo, notesRef, add); err != nil {
+			return nil, err
+		}
+	}
+
+	for _, envs := range markers {
+		sort.Strings(envs)
+	}
+	return markers, nil
+}
+
+// readDeployNotes walks a notes tree, where each file is named after the
+// annotated commit (possibly split into fan-out directories like ab/cdef…)
+// and holds one environment per line.
+func readDeployNotes(repo *git.Repository, notesRef string, add func(plumbing.Hash, string)) error {
+	ref, err := repo.Reference(plumbing.ReferenceName(notesRef), true)
+	if err == plumbing.ErrReferenceNotFound {
+		return nil
+	}
+	if err != nil {
+		return err
+	}
+	commit, err := repo.CommitObject(ref.Hash())
+	if err != nil {
+		return err
+	}
+	tree, err := commit.Tree()
+	if err != nil {
+		return err
+	}
+	return tree.Files().ForEach(func(f *object.File) error {
+		name := strings.ReplaceAll(f.Name, "/", "")
+		if len(name) != 40 || !plumbing.IsHash(name) {
+			return nil
+		}
+		reader, err := f.Reader()
+		if err != nil {
+			return err
+		}
+		defer reader.Close()
+		scanner := bufio.NewScanner(reader)
+		for scanner.Scan() {
+			if env := strings.TrimSpace(scanner.Text()); env != "" {
+				add(plumbing.NewHash(name), env)
+			}
+		}
+		return scanner.Err()
+	})
+}
@@ -0,0 +1,163 @@
+package gitgraph
+
+import (
+	"container/heap"
+	"fmt"
+	"slices"
+	"sort"
+
+	git "github.com/go-git/go-git/v5"
+	"github.com/go-git/go-git/v5/plumbing"
+	"github.com/go-git/go-git/v5/plumbing/object"
+)
+
+// describeMaxWalk bounds the search for a tag, like git's own describe
+// giving up on histories with no tag in reach.
+const describeMaxWalk = 100000
+
+// TagIndex maps commits to the tags pointing at them, lightweight and
+// annotated alike, so nearest-tag lookups need no ref scans.
+type TagIndex struct {
+	repo   *git.Repository
+	abbrev *Abbrev
+	gens   *Generations
+	tags   map[plumbing.Hash][]string
+	// objects holds the tag object each annotated tag ref points at.
+	objects map[string]plumbing.Hash
+}
+
+// NewTagIndex indexes repo's tags; descriptions shorten hashes with
+// abbrev, which may be nil, and count commits walking by gens.
+func NewTagIndex(repo *git.Repository, abbrev *Abbrev, gens *Generations) (*TagIndex, error) {
+	iter, err := repo.Tags()
+	if err != nil {
+		return nil, err
+	}
+	defer iter.Close()
+	ix := &TagIndex{repo: repo, abbrev: abbrev, gens: gens, tags: make(map[plumbing.Hash][]string), objects: make(map[string]plumbing.Hash)}
+	err = iter.ForEach(func(ref *plumbing.Reference) error {
+		target := peel(repo, ref.Hash())
+		ix.tags[target] = append(ix.tags[target], ref.Name().Short())
+		if target != ref.Hash() {
+			ix.objects[ref.Name().Short()] = ref.Hash()
+		}
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+	for _, names := range ix.tags {
+		sort.Strings(names)
+	}
+	return ix, nil
+}
+
+// Tags returns the tags on hash, sorted.
+func (ix *TagIndex) Tags(hash plumbing.Hash) []string {
+	return ix.tags[hash]
+}
+
+// Description is `git describe --tags` output: the nearest tag and how many
+// commits the described one is past it.
+type Description struct {
+	Tag      string
+	TagHash  plumbing.Hash
+	Hash     plumbing.Hash
+	Distance int
+	// Short is Hash abbreviated for the repository.
+	Short string
+}
+
+func (d Description) String() string {
+	if d.Distance == 0 {
+		return d.Tag
+	}
+	return fmt.Sprintf("%s-%d-g%s", d.Tag, d.Distance, d.Short)
+}
+
+// Describe finds the newest tagged ancestor of hash (hash itself included)
+// by walking history newest first; ok is false when no tag is reachable.
+// Among several tags on one commit the highest name wins.
+func (ix *TagIndex) Describe(hash plumbing.Hash) (Description, bool, error) {
+	if len(ix.tags) == 0 {
+		return Description{}, false, nil
+	}
+	start, err := ix.gens.Commit(hash)
+	if err != nil {
+		return Description{}, false, err
+	}
+	var queue commitHeap
+	seen := map[plumbing.Hash]bool{hash: true}
+	heap.Push(&queue, start)
+	for walked := 0; queue.Len() > 0 && walked < describeMaxWalk; walked++ {
+		commit := heap.Pop(&queue).(*object.Commit)
+		if names := ix.tags[commit.Hash]; len(names) > 0 {
+			ahead, _, err := AheadBehind(ix.gens, hash, commit.Hash)
+			if err != nil {
+				return Description{}, false, err
+			}
+			return Description{Tag: names[len(names)-1], TagHash: commit.Hash, Hash: hash, Distance: ahead, Short: ix.abbrev.Short(hash)}, true, nil
+		}
+		for _, parent := range commit.ParentHashes {
+			if seen[parent] {
+				continue
+			}
+			seen[parent] = true
+			if p, err := ix.gens.Commit(parent); err == nil {
+				heap.Push(&queue, p)
+			}
+		}
+	}
+	return Description{}, false, nil
+}
+
+// Releases maps each commit a tag accepted by match contains to the oldest
+// such tag, by tagged commit date: the release it first shipped in. Commits
+// no matching tag contains are left out.
+func (ix *TagIndex) Releases(match func(tag string) bool) map[plumbing.Hash]string {
+	type release struct {
+		name   string
+		commit *object.Commit
+	}
+	var releases []release
+	for hash, names := range ix.tags {
+		i := slices.IndexFunc(names, match)
+		if i < 0 {
+			continue
+		}
+		commit, err := ix.gens.Commit(hash)
+		if err != nil {
+			continue
+		}
+		releases = append(releases, release{name: names[i], commit: commit})
+	}
+	sort.Slice(releases, func(i, j int) bool {
+		a, b := releases[i].commit.Committer.When, releases[j].commit.Committer.When
+		if !a.Equal(b) {
+			return a.Before(b)
+		}
+		return releases[i].name < releases[j].name
+	})
+	shipped := make(map[plumbing.Hash]string)
+	for _, r := range releases {
+		if _, done := shipped[r.commit.Hash]; done {
+			continue
+		}
+		shipped[r.commit.Hash] = r.name
+		stack := []*object.Commit{r.commit}
+		for len(stack) > 0 {
+			commit := stack[len(stack)-1]
+			stack = stack[:len(stack)-1]
+			for _, parent := range commit.ParentHashes {
+				if _, done := shipped[parent]; done {
+					continue
+				}
+				shipped[parent] = r.name
+				if p, err := ix.gens.Commit(parent); err == nil {
+					stack = append(stack, p)
+				}
+			}
+		}
+	}
+	return shipped
+}
@@ -0,0 +1,230 @@
+package gitgraph
+
+import (
+	"sort"
+	"strconv"
+	"strings"
+
+	git "github.com/go-git/go-git/v5"
+	"github.com/go-git/go-git/v5/plumbing/format/config"
+	"github.com/go-git/go-git/v5/plumbing/format/diff"
+	"github.com/go-git/go-git/v5/plumbing/object"
+	"github.com/go-git/go-git/v5/plumbing/storer"
+)
+
+// CommitPatch diffs a commit against its first parent, or against the empty
+// tree for root commits.
+func CommitPatch(commit *object.Commit) (*object.Patch, error) {
+	changes, err := CommitChanges(commit)
+	if err != nil {
+		return nil, err
+	}
+	return changes.Patch()
+}
+
+func CommitChanges(commit *object.Commit) (object.Changes, error) {
+	var parentTree *object.Tree
+	if commit.NumParents() > 0 {
+		parent, err := commit.Parent(0)
+		if err != nil {
+			return nil, err
+		}
+		parentTree, err = parent.Tree()
+		if err != nil {
+			return nil, err
+		}
+	}
+	tree, err := commit.Tree()
+	if err != nil {
+		return nil, err
+	}
+	return object.DiffTree(parentTree, tree)
+}
+
+// DiffStat is the size of a commit's change against its first parent, as
+// in git show --shortstat.
+type DiffStat struct {
+	Files   int
+	Added   int
+	Deleted int
+}
+
+// CommitDiffStat counts a commit's change with attrs, as NumStat does.
+func CommitDiffStat(attrs *Attributes, commit *object.Commit) (DiffStat, error) {
+	changes, err := CommitChanges(commit)
+	if err != nil {
+		return DiffStat{}, err
+	}
+	stats, err := attrs.NumStat(changes)
+	if err != nil {
+		return DiffStat{}, err
+	}
+	var stat DiffStat
+	for _, file := range stats {
+		stat.Files++
+		stat.Added += file.Added
+		stat.Deleted += file.Deleted
+	}
+	return stat, nil
+}
+
+func ChangedFiles(commit *object.Commit) ([]string, error) {
+	patch, err := CommitPatch(commit)
+	if err != nil {
+		return nil, err
+	}
+	paths := make([]string, 0, len(patch.FilePatches()))
+	for _, filePatch := range patch.FilePatches() {
+		from, to := filePatch.Files()
+		if to != nil {
+			paths = append(paths, to.Path())
+			continue
+		}
+		if from != nil {
+			paths = append(paths, from.Path())
+		}
+	}
+	sort.Strings(paths)
+	return paths, nil
+}
+
+// TreePatch diffs the trees of two commits, such as a branch's merge base and
+// its tip; a nil from diffs against the empty tree.
+func TreePatch(from, to *object.Commit) (*object.Patch, error) {
+	changes, err := TreeChanges(from, to)
+	if err != nil {
+		return nil, err
+	}
+	return changes.Patch()
+}
+
+// TreeChanges are the files that differ between two commits' trees; a nil
+// from compares against the empty tree.
+func TreeChanges(from, to *object.Commit) (object.Changes, error) {
+	var fromTree *object.Tree
+	if from != nil {
+		tree, err := from.Tree()
+		if err != nil {
+			return nil, err
+		}
+		fromTree = tree
+	}
+	toTree, err := to.Tree()
+	if err != nil {
+		return nil, err
+	}
+	return object.DiffTree(fromTree, toTree)
+}
+
+// LargeDiffBytes is the size, both versions of a file together, past which
+// a FileDiff is large: diffing a multi-megabyte generated or vendored file
+// takes seconds, and its patch as much memory again.
+const LargeDiffBytes = 1 << 20
+
+// FileDiff is one file's part of a diff, computed only when asked for, so
+// the files of a huge change can be listed without diffing them all.
+type FileDiff struct {
+	// Path is the file's path after the change, or before it if deleted.
+	Path string
+	// Size is the bytes in both versions together.
+	Size   int64
+	change *object.Change
+}
+
+// FileDiffs splits changes into their files, in path order, without
+// diffing any. Sizes are read from the object headers in s, leaving the
+// blobs unread.
+func FileDiffs(s storer.EncodedObjectStorer, changes object.Changes) []*FileDiff {
+	diffs := make([]*FileDiff, 0, len(changes))
+	for _, change := range changes {
+		diff := &FileDiff{Path: change.To.Name, change: change}
+		if diff.Path == "" {
+			diff.Path = change.From.Name
+		}
+		for _, entry := range []object.ChangeEntry{change.From, change.To} {
+			if entry.TreeEntry.Hash.IsZero() || !entry.TreeEntry.Mode.IsFile() {
+				continue
+			}
+			if size, err := s.EncodedObjectSize(entry.TreeEntry.Hash); err == nil {
+				diff.Size += size
+			}
+		}
+		diffs = append(diffs, diff)
+	}
+	sort.SliceStable(diffs, func(i, j int) bool { return diffs[i].Path < diffs[j].Path })
+	return diffs
+}
+
+// Large reports whether the file is big enough that diffing it can stall.
+func (d *FileDiff) Large() bool {
+	return d.Size > LargeDiffBytes
+}
+
+// Patch diffs the file, through its textconv driver in attrs if it has
+// one.
+func (d *FileDiff) Patch(attrs *Attributes) (diff.Patch, error) {
+	return attrs.Patch(object.Changes{d.change})
+}
+
+// ConvertedPatch diffs the file through command, its textconv driver's as
+// attrs.Textconv gives it. Conversions can take long, so this is the part
+// to run in the background.
+func (d *FileDiff) ConvertedPatch(attrs *Attributes, command string) (diff.Patch, error) {
+	file, err := attrs.TextconvPatch(command, d.change)
+	if err != nil {
+		return nil, err
+	}
+	return filePatches{file}, nil
+}
+
+// DiffFormat is how git diff and git show write a patch here: the header
+// path prefixes from diff.noprefix, or diff.srcPrefix and diff.dstPrefix,
+// and diff.context lines around each change. diff.mnemonicPrefix only
+// renames the sides of diffs against the index or worktree, so patches
+// between commits keep a/ and b/, as they do in git show.
+type DiffFormat struct {
+	SrcPrefix string
+	DstPrefix string
+	Context   int
+}
+
+// DefaultDiffFormat is git's, without any diff settings.
+var DefaultDiffFormat = DiffFormat{SrcPrefix: "a/", DstPrefix: "b/", Context: 3}
+
+// LoadDiffFormat reads the repository's diff settings.
+func LoadDiffFormat(repo *git.Repository) DiffFormat {
+	f := DefaultDiffFormat
+	section := GitConfig(repo).Section("diff")
+	if section.HasOption("srcPrefix") {
+		f.SrcPrefix = section.Option("srcPrefix")
+	}
+	if section.HasOption("dstPrefix") {
+		f.DstPrefix = section.Option("dstPrefix")
+	}
+	if configBool(section, "noprefix") {
+		f.SrcPrefix, f.DstPrefix = "", ""
+	}
+	if n, err := strconv.Atoi(section.Option("context")); err == nil && n >= 0 {
+		f.Context = n
+	}
+	return f
+}
+
+// Format writes patch as git would with these settings.
+func (f DiffFormat) Format(patch diff.Patch) string {
+	var b strings.Builder
+	diff.NewUnifiedEncoder(&b, f.Context).SetSrcPrefix(f.SrcPrefix).SetDstPrefix(f.DstPrefix).Encode(patch)
+	return b.String()
+}
+
+// configBool reads a git boolean; a key given without a value is true.
+func configBool(section *config.Section, key string) bool {
+	if !section.HasOption(key) {
+		return false
+	}
+	switch strings.ToLower(section.Option(key)) {
+	case "", "true", "yes", "on", "1":
+		return true
+	}
+	return false
+}
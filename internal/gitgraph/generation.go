@@ -0,0 +1,397 @@
+package gitgraph
+
+import (
+	"container/heap"
+	"sync"
+
+	git "github.com/go-git/go-git/v5"
+	"github.com/go-git/go-git/v5/plumbing"
+	commitgraph "github.com/go-git/go-git/v5/plumbing/format/commitgraph/v2"
+	"github.com/go-git/go-git/v5/plumbing/object"
+	"github.com/go-git/go-git/v5/storage/filesystem"
+)
+
+// generationV1Max is the largest generation a commit-graph file stores;
+// commits above it are recorded as exactly this and computed instead.
+const generationV1Max = 0x3FFFFFFF
+
+// Generations numbers commits for reachability queries: a root commit is
+// generation 1 and every other commit one more than its highest parent,
+// so a commit never reaches another of the same or a higher generation.
+// Walks visit commits by generation and stop at the generation of what
+// they look for, where walking by date has to guess at clock skew.
+//
+// Commits are read through Replacements, so replace refs, grafts and a
+// shallow clone's boundary shape the history walked as they do git's.
+// Numbers come from the commit-graph file (git commit-graph write) for the
+// commits it covers, unless any of those apply, as git then sets the file
+// aside too, and are computed once for the rest. A missing commit counts
+// as a root, but only for the query at hand, so nothing resting on it is
+// kept.
+type Generations struct {
+	repo    *git.Repository
+	graph   commitgraph.Index
+	replace *Replacements
+
+	mu      sync.Mutex
+	gen     map[plumbing.Hash]uint64
+	parents map[plumbing.Hash][]plumbing.Hash
+}
+
+// NewGenerations numbers repo's commits as they are asked for. Keep one for
+// as long as the repository's replacements and shallow boundary stay put,
+// as a CommitProvider does. Replacements it cannot read leave history as
+// stored, as an unreadable commit-graph is passed over.
+func NewGenerations(repo *git.Repository) *Generations {
+	g := &Generations{
+		repo:    repo,
+		gen:     make(map[plumbing.Hash]uint64),
+		parents: make(map[plumbing.Hash][]plumbing.Hash),
+	}
+	g.replace, _ = LoadReplacements(repo)
+	if fs, ok := repo.Storer.(*filesystem.Storage); ok && g.replace == nil {
+		if index, err := commitgraph.OpenChainOrFileIndex(fs.Filesystem()); err == nil {
+			g.graph = index
+		}
+	}
+	return g
+}
+
+// Of is hash's generation.
+func (g *Generations) Of(hash plumbing.Hash) (uint64, error) {
+	g.mu.Lock()
+	defer g.mu.Unlock()
+	return g.of(hash)
+}
+
+// Parents are hash's parents, read from the commit-graph when it has them.
+// The commits a shallow clone was cut at, and missing ones, have none.
+func (g *Generations) Parents(hash plumbing.Hash) ([]plumbing.Hash, error) {
+	g.mu.Lock()
+	defer g.mu.Unlock()
+	parents, _, _, err := g.lookup(hash)
+	return parents, err
+}
+
+func (g *Generations) of(hash plumbing.Hash) (uint64, error) {
+	if n, ok := g.gen[hash]; ok {
+		return n, nil
+	}
+	// provisional holds the numbers that rest on a missing commit, which
+	// are good for this query only.
+	provisional := make(map[plumbing.Hash]uint64)
+	stack := []plumbing.Hash{hash}
+	for len(stack) > 0 {
+		top := stack[len(stack)-1]
+		if _, ok := g.gen[top]; ok {
+			stack = stack[:len(stack)-1]
+			continue
+		}
+		if _, ok := provisional[top]; ok {
+			stack = stack[:len(stack)-1]
+			continue
+		}
+		parents, stored, missing, err := g.lookup(top)
+		if err != nil {
+			return 0, err
+		}
+		if missing {
+			provisional[top] = 1
+			stack = stack[:len(stack)-1]
+			continue
+		}
+		if stored > 0 {
+			g.gen[top] = stored
+			stack = stack[:len(stack)-1]
+			continue
+		}
+		highest, pending, uncertain := uint64(0), false, false
+		for _, parent := range parents {
+			if n, ok := g.gen[parent]; ok {
+				highest = max(highest, n)
+			} else if n, ok := provisional[parent]; ok {
+				highest = max(highest, n)
+				uncertain = true
+			} else {
+				stack = append(stack, parent)
+				pending = true
+			}
+		}
+		if pending {
+			continue
+		}
+		if uncertain {
+			provisional[top] = highest + 1
+		} else {
+			g.gen[top] = highest + 1
+		}
+		stack = stack[:len(stack)-1]
+	}
+	if n, ok := g.gen[hash]; ok {
+		return n, nil
+	}
+	return provisional[hash], nil
+}
+
+// lookup returns hash's parents and, when the commit-graph has a usable
+// one, its stored generation. missing reports a commit the repository
+// does not have, which is not remembered, as it may yet be fetched.
+func (g *Generations) lookup(hash plumbing.Hash) (parents []plumbing.Hash, stored uint64, missing bool, err error) {
+	if parents, ok := g.parents[hash]; ok {
+		return parents, 0, false, nil
+	}
+	if g.graph != nil {
+		if i, err := g.graph.GetIndexByHash(hash); err == nil {
+			if data, err := g.graph.GetCommitDataByIndex(i); err == nil {
+				g.parents[hash] = data.ParentHashes
+				if data.Generation > 0 && data.Generation < generationV1Max {
+					return data.ParentHashes, data.Generation, false, nil
+				}
+				return data.ParentHashes, 0, false, nil
+			}
+		}
+	}
+	commit, err := g.replace.Commit(g.repo, hash)
+	if err == plumbing.ErrObjectNotFound {
+		return nil, 0, true, nil
+	}
+	if err != nil {
+		return nil, 0, false, err
+	}
+	g.parents[hash] = commit.ParentHashes
+	return commit.ParentHashes, 0, false, nil
+}
+
+// Reaches reports whether ancestor is from or one of its ancestors, walking
+// back from from no further than ancestor's generation.
+func (g *Generations) Reaches(from, ancestor plumbing.Hash) (bool, error) {
+	if from == ancestor {
+		return true, nil
+	}
+	floor, err := g.Of(ancestor)
+	if err != nil {
+		return false, err
+	}
+	seen := map[plumbing.Hash]bool{from: true}
+	stack := []plumbing.Hash{from}
+	for len(stack) > 0 {
+		hash := stack[len(stack)-1]
+		stack = stack[:len(stack)-1]
+		n, err := g.Of(hash)
+		if err != nil {
+			return false, err
+		}
+		if n <= floor {
+			continue
+		}
+		parents, err := g.Parents(hash)
+		if err != nil {
+			return false, err
+		}
+		for _, parent := range parents {
+			if parent == ancestor {
+				return true, nil
+			}
+			if !seen[parent] {
+				seen[parent] = true
+				stack = append(stack, parent)
+			}
+		}
+	}
+	return false, nil
+}
+
+// paint walks the a and b sides together, highest generation first,
+// marking every commit with the sides it is reachable from, until only
+// commits reachable from both are left to visit. bases, when asked for,
+// also collects the common commits no other common commit reaches (the
+// merge-base candidates) and stops walking below them.
+func (g *Generations) paint(a, b []plumbing.Hash, bases bool) (map[plumbing.Hash]uint8, []plumbing.Hash, error) {
+	const fromA, fromB, both, stale = 1, 2, 3, 4
+	flags := make(map[plumbing.Hash]uint8)
+	for _, hash := range a {
+		flags[hash] |= fromA
+	}
+	for _, hash := range b {
+		flags[hash] |= fromB
+	}
+	// A commit is settled once the walk no longer needs its flags: when it
+	// is reachable from both sides, or for bases, below a candidate. Every
+	// child of a commit pops before it, so a commit is queued once, and
+	// the walk ends when none of those queued is unsettled.
+	settled := func(hash plumbing.Hash) bool {
+		if bases {
+			return flags[hash]&stale != 0
+		}
+		return flags[hash]&both == both
+	}
+	queue := &generationHeap{}
+	queued := make(map[plumbing.Hash]bool)
+	unsettled := 0
+	push := func(hash plumbing.Hash) error {
+		n, err := g.Of(hash)
+		if err != nil {
+			return err
+		}
+		heap.Push(queue, generationItem{hash, n})
+		queued[hash] = true
+		if !settled(hash) {
+			unsettled++
+		}
+		return nil
+	}
+	for hash := range flags {
+		if err := push(hash); err != nil {
+			return nil, nil, err
+		}
+	}
+
+	var candidates []plumbing.Hash
+	for queue.Len() > 0 && unsettled > 0 {
+		item := heap.Pop(queue).(generationItem)
+		delete(queued, item.hash)
+		if !settled(item.hash) {
+			unsettled--
+		}
+		flag := flags[item.hash]
+		if bases && flag&both == both && flag&stale == 0 {
+			candidates = append(candidates, item.hash)
+			flag |= stale
+			flags[item.hash] = flag
+		}
+		parents, err := g.Parents(item.hash)
+		if err != nil {
+			return nil, nil, err
+		}
+		for _, parent := range parents {
+			if flags[parent]|flag == flags[parent] {
+				continue
+			}
+			if !queued[parent] {
+				flags[parent] |= flag
+				if err := push(parent); err != nil {
+					return nil, nil, err
+				}
+				continue
+			}
+			was := settled(parent)
+			flags[parent] |= flag
+			if !was && settled(parent) {
+				unsettled--
+			}
+		}
+	}
+	return flags, candidates, nil
+}
+
+// MergeBases are the best common ancestors of a and b, as in git
+// merge-base --all: common ancestors no other common ancestor descends
+// from, highest generation first.
+func (g *Generations) MergeBases(a, b plumbing.Hash) ([]plumbing.Hash, error) {
+	_, candidates, err := g.paint([]plumbing.Hash{a}, []plumbing.Hash{b}, true)
+	if err != nil || len(candidates) < 2 {
+		return candidates, err
+	}
+	var bases []plumbing.Hash
+	for i, candidate := range candidates {
+		redundant := false
+		for j, other := range candidates {
+			if i == j {
+				continue
+			}
+			if redundant, err = g.Reaches(other, candidate); err != nil {
+				return nil, err
+			}
+			if redundant {
+				break
+			}
+		}
+		if !redundant {
+			bases = append(bases, candidate)
+		}
+	}
+	return bases, nil
+}
+
+// SymmetricDifference returns the hashes reachable from only a and from
+// only b (the a...b range), in no particular order.
+func (g *Generations) SymmetricDifference(a, b plumbing.Hash) ([]plumbing.Hash, []plumbing.Hash, error) {
+	if a == b {
+		return nil, nil, nil
+	}
+	flags, _, err := g.paint([]plumbing.Hash{a}, []plumbing.Hash{b}, false)
+	if err != nil {
+		return nil, nil, err
+	}
+	var onlyA, onlyB []plumbing.Hash
+	for hash, flag := range flags {
+		switch flag {
+		case 1:
+			onlyA = append(onlyA, hash)
+		case 2:
+			onlyB = append(onlyB, hash)
+		}
+	}
+	return onlyA, onlyB, nil
+}
+
+// Exclusive returns the hashes reachable from any of tips but none of
+// excluded, in no particular order, as in git rev-list tips --not excluded.
+func (g *Generations) Exclusive(tips, excluded []plumbing.Hash) ([]plumbing.Hash, error) {
+	flags, _, err := g.paint(tips, excluded, false)
+	if err != nil {
+		return nil, err
+	}
+	var only []plumbing.Hash
+	for hash, flag := range flags {
+		if flag == 1 {
+			only = append(only, hash)
+		}
+	}
+	return only, nil
+}
+
+type generationItem struct {
+	hash plumbing.Hash
+	gen  uint64
+}
+
+// generationHeap pops the highest generation first, ties by hash so walks
+// are repeatable.
+type generationHeap []generationItem
+
+func (h generationHeap) Len() int { return len(h) }
+func (h generationHeap) Less(i, j int) bool {
+	if h[i].gen == h[j].gen {
+		return h[i].hash.String() > h[j].hash.String()
+	}
+	return h[i].gen > h[j].gen
+}
+func (h generationHeap) Swap(i, j int) { h[i], h[j] = h[j], h[i] }
+func (h *generationHeap) Push(x interface{}) {
+	*h = append(*h, x.(generationItem))
+}
+func (h *generationHeap) Pop() interface{} {
+	old := *h
+	n := len(old)
+	item := old[n-1]
+	*h = old[:n-1]
+	return item
+}
+
+// Commit loads hash as the walks see it, with replacements applied.
+func (g *Generations) Commit(hash plumbing.Hash) (*object.Commit, error) {
+	return g.replace.Commit(g.repo, hash)
+}
+
+func (g *Generations) commits(hashes []plumbing.Hash) ([]*object.Commit, error) {
+	commits := make([]*object.Commit, 0, len(hashes))
+	for _, hash := range hashes {
+		commit, err := g.Commit(hash)
+		if err != nil {
+			return nil, err
+		}
+		commits = append(commits, commit)
+	}
+	return commits, nil
+}
@@ -0,0 +1,209 @@
+package gitgraph
+
+import (
+	"slices"
+	"testing"
+	"time"
+
+	git "github.com/go-git/go-git/v5"
+	"github.com/go-git/go-git/v5/plumbing"
+	"github.com/go-git/go-git/v5/plumbing/object"
+	"github.com/go-git/go-git/v5/storage/memory"
+)
+
+// history builds commits in memory, each named by its message.
+type history struct {
+	t      *testing.T
+	store  *memory.Storage
+	repo   *git.Repository
+	hashes map[string]plumbing.Hash
+}
+
+func newHistory(t *testing.T) *history {
+	store := memory.NewStorage()
+	repo, err := git.Init(store, nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return &history{t: t, store: store, repo: repo, hashes: make(map[string]plumbing.Hash)}
+}
+
+// commit makes name on parents; stored false leaves it out of the
+// repository, as a commit yet to be fetched.
+func (h *history) commit(name string, stored bool, parents ...string) plumbing.Hash {
+	sig := object.Signature{Name: "A", Email: "a@example.com", When: time.Unix(1700000000, 0)}
+	commit := &object.Commit{Author: sig, Committer: sig, Message: name}
+	for _, parent := range parents {
+		commit.ParentHashes = append(commit.ParentHashes, h.hashes[parent])
+	}
+	obj := h.store.NewEncodedObject()
+	if err := commit.Encode(obj); err != nil {
+		h.t.Fatal(err)
+	}
+	if stored {
+		if _, err := h.store.SetEncodedObject(obj); err != nil {
+			h.t.Fatal(err)
+		}
+	}
+	h.hashes[name] = obj.Hash()
+	return obj.Hash()
+}
+
+func (h *history) names(hashes []plumbing.Hash) []string {
+	var names []string
+	for _, hash := range hashes {
+		for name, named := range h.hashes {
+			if named == hash {
+				names = append(names, name)
+			}
+		}
+	}
+	slices.Sort(names)
+	return names
+}
+
+func TestGenerationNumbers(t *testing.T) {
+	h := newHistory(t)
+	h.commit("a", true)
+	h.commit("b", true, "a")
+	h.commit("c", true, "b")
+	h.commit("d", true, "a")
+	h.commit("m", true, "c", "d")
+	gens := NewGenerations(h.repo)
+	for name, want := range map[string]uint64{"a": 1, "b": 2, "c": 3, "d": 2, "m": 4} {
+		if got, err := gens.Of(h.hashes[name]); err != nil || got != want {
+			t.Errorf("Of(%s) = %d, %v, want %d", name, got, err, want)
+		}
+	}
+}
+
+func TestGenerationsQueries(t *testing.T) {
+	h := newHistory(t)
+	h.commit("a", true)
+	h.commit("b", true, "a")
+	h.commit("c", true, "a")
+	h.commit("d", true, "c")
+	// Criss-cross merges have two best common ancestors.
+	h.commit("m1", true, "b", "d")
+	h.commit("m2", true, "d", "b")
+	gens := NewGenerations(h.repo)
+
+	reaches := []struct {
+		from, ancestor string
+		want           bool
+	}{
+		{"m1", "a", true},
+		{"d", "a", true},
+		{"d", "b", false},
+		{"b", "d", false},
+		{"a", "a", true},
+		{"a", "m1", false},
+	}
+	for _, tt := range reaches {
+		if got, err := gens.Reaches(h.hashes[tt.from], h.hashes[tt.ancestor]); err != nil || got != tt.want {
+			t.Errorf("Reaches(%s, %s) = %v, %v, want %v", tt.from, tt.ancestor, got, err, tt.want)
+		}
+	}
+
+	bases, err := gens.MergeBases(h.hashes["m1"], h.hashes["m2"])
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got, want := h.names(bases), []string{"b", "d"}; !slices.Equal(got, want) {
+		t.Errorf("MergeBases(m1, m2) = %v, want %v", got, want)
+	}
+	bases, err = gens.MergeBases(h.hashes["b"], h.hashes["d"])
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got, want := h.names(bases), []string{"a"}; !slices.Equal(got, want) {
+		t.Errorf("MergeBases(b, d) = %v, want %v", got, want)
+	}
+
+	onlyB, onlyD, err := gens.SymmetricDifference(h.hashes["b"], h.hashes["d"])
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got, want := h.names(onlyB), []string{"b"}; !slices.Equal(got, want) {
+		t.Errorf("SymmetricDifference(b, d) only b = %v, want %v", got, want)
+	}
+	if got, want := h.names(onlyD), []string{"c", "d"}; !slices.Equal(got, want) {
+		t.Errorf("SymmetricDifference(b, d) only d = %v, want %v", got, want)
+	}
+
+	only, err := gens.Exclusive([]plumbing.Hash{h.hashes["m1"]}, []plumbing.Hash{h.hashes["c"]})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got, want := h.names(only), []string{"b", "d", "m1"}; !slices.Equal(got, want) {
+		t.Errorf("Exclusive(m1, c) = %v, want %v", got, want)
+	}
+}
+
+func TestGenerationsMissingNotKept(t *testing.T) {
+	h := newHistory(t)
+	h.commit("a", true)
+	h.commit("b", true, "a")
+	h.commit("p", false, "b")
+	h.commit("x", true, "p")
+	gens := NewGenerations(h.repo)
+
+	// p is not there yet, so it counts as a root for now.
+	if got, err := gens.Of(h.hashes["x"]); err != nil || got != 2 {
+		t.Fatalf("Of(x) with p missing = %d, %v, want 2", got, err)
+	}
+	h.commit("p", true, "b")
+	if got, err := gens.Of(h.hashes["x"]); err != nil || got != 4 {
+		t.Errorf("Of(x) once p arrives = %d, %v, want 4", got, err)
+	}
+	parents, err := gens.Parents(h.hashes["p"])
+	if err != nil || len(parents) != 1 || parents[0] != h.hashes["b"] {
+		t.Errorf("Parents(p) = %v, %v, want [b]", parents, err)
+	}
+}
+
+func TestGenerationsShallow(t *testing.T) {
+	h := newHistory(t)
+	h.commit("a", true)
+	h.commit("b", true, "a")
+	h.commit("c", true, "b")
+	if err := h.store.SetShallow([]plumbing.Hash{h.hashes["b"]}); err != nil {
+		t.Fatal(err)
+	}
+	gens := NewGenerations(h.repo)
+	for name, want := range map[string]uint64{"b": 1, "c": 2} {
+		if got, err := gens.Of(h.hashes[name]); err != nil || got != want {
+			t.Errorf("Of(%s) = %d, %v, want %d", name, got, err, want)
+		}
+	}
+	if parents, err := gens.Parents(h.hashes["b"]); err != nil || len(parents) != 0 {
+		t.Errorf("Parents(b) = %v, %v, want none past the shallow boundary", parents, err)
+	}
+	if ok, err := gens.Reaches(h.hashes["c"], h.hashes["a"]); err != nil || ok {
+		t.Errorf("Reaches(c, a) = %v, %v, want false past the shallow boundary", ok, err)
+	}
+}
+
+func TestGenerationsReplaced(t *testing.T) {
+	h := newHistory(t)
+	h.commit("a", true)
+	h.commit("b", true, "a")
+	h.commit("c", true, "b")
+	h.commit("d", true, "c")
+	h.commit("c2", true, "a")
+	ref := plumbing.NewHashReference(plumbing.ReferenceName(replacePrefix+h.hashes["c"].String()), h.hashes["c2"])
+	if err := h.store.SetReference(ref); err != nil {
+		t.Fatal(err)
+	}
+	gens := NewGenerations(h.repo)
+	if got, err := gens.Of(h.hashes["d"]); err != nil || got != 3 {
+		t.Errorf("Of(d) = %d, %v, want 3 through the replacement", got, err)
+	}
+	if ok, err := gens.Reaches(h.hashes["d"], h.hashes["b"]); err != nil || ok {
+		t.Errorf("Reaches(d, b) = %v, %v, want false once c is replaced", ok, err)
+	}
+	commit, err := gens.Commit(h.hashes["c"])
+	if err != nil || commit.Hash != h.hashes["c"] || commit.Message != "c2" {
+		t.Errorf("Commit(c) = %v, %v, want c2's content under c's hash", commit, err)
+	}
+}
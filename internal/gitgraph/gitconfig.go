@@ -0,0 +1,43 @@
+package gitgraph
+
+import (
+	git "github.com/go-git/go-git/v5"
+	gitconfig "github.com/go-git/go-git/v5/config"
+	"github.com/go-git/go-git/v5/plumbing/format/config"
+)
+
+// GitConfig is the repository's git configuration as git itself reads it:
+// the system file, then the user's global one, then the repository's own,
+// each later value winning. repo.ConfigScoped merges only the structured
+// fields such as user, so settings read from Raw need this instead.
+// include.path directives are not followed.
+func GitConfig(repo *git.Repository) *config.Config {
+	merged := config.New()
+	for _, scope := range []gitconfig.Scope{gitconfig.SystemScope, gitconfig.GlobalScope} {
+		if cfg, err := gitconfig.LoadConfig(scope); err == nil {
+			mergeConfig(merged, cfg.Raw)
+		}
+	}
+	if cfg, err := repo.Config(); err == nil {
+		mergeConfig(merged, cfg.Raw)
+	}
+	return merged
+}
+
+// mergeConfig appends every option of src to dst, so Option, which reads
+// the last value given, sees src's over dst's.
+func mergeConfig(dst, src *config.Config) {
+	if src == nil {
+		return
+	}
+	for _, section := range src.Sections {
+		for _, option := range section.Options {
+			dst.AddOption(section.Name, config.NoSubsection, option.Key, option.Value)
+		}
+		for _, sub := range section.Subsections {
+			for _, option := range sub.Options {
+				dst.AddOption(section.Name, sub.Name, option.Key, option.Value)
+			}
+		}
+	}
+}
@@ -0,0 +1,90 @@
+package gitgraph
+
+import (
+	"sort"
+	"strings"
+)
+
+// HeatNode is a directory or file in a heatmap with the number of commits
+// that changed it. A directory counts a commit once however many files under
+// it changed; its Path ends in "/", and the root's is "".
+type HeatNode struct {
+	Name     string
+	Path     string
+	Count    int
+	Children []*HeatNode
+
+	index map[string]*HeatNode
+}
+
+func (n *HeatNode) Dir() bool {
+	return n.Path == "" || strings.HasSuffix(n.Path, "/")
+}
+
+// BuildHeatmap counts the commits changing each path, against the first
+// parent. Merges are skipped, as in git log without -m, so a merged branch's
+// changes are not counted twice. Children are sorted by count, then name.
+func BuildHeatmap(commits []*CommitInfo) (*HeatNode, error) {
+	root := &HeatNode{}
+	for _, c := range commits {
+		if c.Commit == nil || c.Commit.NumParents() > 1 {
+			continue
+		}
+		changes, err := CommitChanges(c.Commit)
+		if err != nil {
+			return nil, err
+		}
+		seen := make(map[*HeatNode]bool)
+		for _, change := range changes {
+			name := change.To.Name
+			if name == "" {
+				name = change.From.Name
+			}
+			for _, node := range root.walk(name) {
+				if !seen[node] {
+					seen[node] = true
+					node.Count++
+				}
+			}
+		}
+	}
+	root.sort()
+	return root, nil
+}
+
+// walk returns the nodes along file, root included, creating missing ones.
+func (n *HeatNode) walk(file string) []*HeatNode {
+	nodes := []*HeatNode{n}
+	parts := strings.Split(file, "/")
+	for i, part := range parts {
+		p := strings.Join(parts[:i+1], "/")
+		if i < len(parts)-1 {
+			p += "/"
+		}
+		child := n.index[p]
+		if child == nil {
+			if n.index == nil {
+				n.index = make(map[string]*HeatNode)
+			}
+			child = &HeatNode{Name: part, Path: p}
+			n.index[p] = child
+			n.Children = append(n.Children, child)
+		}
+		nodes = append(nodes, child)
+		n = child
+	}
+	return nodes
+}
+
+func (n *HeatNode) sort() {
+	sort.Slice(n.Children, func(i, j int) bool {
+		a, b := n.Children[i], n.Children[j]
+		if a.Count != b.Count {
+			return a.Count > b.Count
+		}
+		return a.Path < b.Path
+	})
+	for _, child := range n.Children {
+		child.sort()
+	}
+}
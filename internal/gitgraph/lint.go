@@ -0,0 +1,70 @@
+package gitgraph
+
+import (
+	"fmt"
+	"regexp"
+	"slices"
+	"strings"
+	"unicode/utf8"
+)
+
+// DefaultConventionalTypes are the Conventional Commits types accepted
+// unless the config lists its own.
+var DefaultConventionalTypes = []string{"feat", "fix", "docs", "style", "refactor", "perf", "test", "build", "ci", "chore", "revert"}
+
+var conventionalRe = regexp.MustCompile(`^([a-z]+)(\([^()]+\))?!?: \S`)
+
+// LintOptions are the message rules; a zero limit turns its check off.
+type LintOptions struct {
+	MaxSubject        int
+	Conventional      bool
+	ConventionalTypes []string
+	BodyWrap          int
+}
+
+type LintProblem struct {
+	Rule   string
+	Detail string
+}
+
+func (p LintProblem) String() string {
+	return p.Rule + ": " + p.Detail
+}
+
+// LintMessage checks a commit message against opts. fixup!/squash!/amend!
+// subjects are left alone; they are meant to disappear in a rebase.
+func LintMessage(message string, opts LintOptions) []LintProblem {
+	subject := FirstLine(message)
+	for _, prefix := range []string{"fixup! ", "squash! ", "amend! "} {
+		if strings.HasPrefix(subject, prefix) {
+			return nil
+		}
+	}
+	var problems []LintProblem
+	if n := utf8.RuneCountInString(subject); opts.MaxSubject > 0 && n > opts.MaxSubject {
+		problems = append(problems, LintProblem{"subject-length", fmt.Sprintf("subject is %d characters, limit %d", n, opts.MaxSubject)})
+	}
+	if opts.Conventional {
+		match := conventionalRe.FindStringSubmatch(subject)
+		switch {
+		case match == nil:
+			problems = append(problems, LintProblem{"conventional", `subject is not "type(scope): description"`})
+		case len(opts.ConventionalTypes) > 0 && !slices.Contains(opts.ConventionalTypes, match[1]):
+			problems = append(problems, LintProblem{"conventional", fmt.Sprintf("unknown type %q", match[1])})
+		}
+	}
+	lines := strings.Split(strings.TrimRight(message, "\n"), "\n")
+	if len(lines) > 1 && strings.TrimSpace(lines[1]) != "" {
+		problems = append(problems, LintProblem{"body-separator", "no blank line between subject and body"})
+	}
+	if opts.BodyWrap > 0 {
+		for i, line := range lines[1:] {
+			// A long unbroken token, such as a URL, cannot be wrapped.
+			if n := utf8.RuneCountInString(line); n > opts.BodyWrap && strings.Contains(strings.TrimSpace(line), " ") {
+				problems = append(problems, LintProblem{"body-wrap", fmt.Sprintf("line %d is %d characters, limit %d", i+2, n, opts.BodyWrap)})
+				break
+			}
+		}
+	}
+	return problems
+}
@@ -0,0 +1,66 @@
+package gitgraph
+
+import (
+	"container/list"
+	"sync"
+)
+
+// lru is a cache that drops its least recently used entries once their
+// cost adds up to more than limit. It is safe for concurrent use.
+type lru[K comparable, V any] struct {
+	mu      sync.Mutex
+	limit   int
+	cost    func(V) int
+	total   int
+	order   *list.List
+	entries map[K]*list.Element
+}
+
+type lruEntry[K comparable, V any] struct {
+	key   K
+	value V
+	cost  int
+}
+
+// newLRU caches up to limit by cost; a nil cost counts every entry as one.
+func newLRU[K comparable, V any](limit int, cost func(V) int) *lru[K, V] {
+	if cost == nil {
+		cost = func(V) int { return 1 }
+	}
+	return &lru[K, V]{limit: limit, cost: cost, order: list.New(), entries: make(map[K]*list.Element)}
+}
+
+func (c *lru[K, V]) Get(key K) (V, bool) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	e, ok := c.entries[key]
+	if !ok {
+		var zero V
+		return zero, false
+	}
+	c.order.MoveToFront(e)
+	return e.Value.(*lruEntry[K, V]).value, true
+}
+
+// Add caches value, unless it alone costs more than the whole cache holds.
+func (c *lru[K, V]) Add(key K, value V) {
+	cost := c.cost(value)
+	if cost > c.limit {
+		return
+	}
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	if e, ok := c.entries[key]; ok {
+		c.total -= e.Value.(*lruEntry[K, V]).cost
+		c.order.Remove(e)
+	}
+	c.entries[key] = c.order.PushFront(&lruEntry[K, V]{key: key, value: value, cost: cost})
+	c.total += cost
+	for c.total > c.limit {
+		last := c.order.Back()
+		entry := last.Value.(*lruEntry[K, V])
+		c.order.Remove(last)
+		delete(c.entries, entry.key)
+		c.total -= entry.cost
+	}
+}
@@ -0,0 +1,125 @@
+package gitgraph
+
+import (
+	"bufio"
+	"io"
+	"os"
+	"path/filepath"
+	"strings"
+
+	git "github.com/go-git/go-git/v5"
+)
+
+// Mailmap maps the names and emails commits were made with to the ones
+// their authors go by, from .mailmap and the file mailmap.file names, as
+// git log's %aN and %aE do.
+type Mailmap struct {
+	// byNameEmail holds entries for one name at an email, byEmail those
+	// for every name at it; keys are lowercase.
+	byNameEmail map[[2]string]Identity
+	byEmail     map[string]Identity
+}
+
+// Identity is a name and email as they appear in a commit.
+type Identity struct {
+	Name  string
+	Email string
+}
+
+// LoadMailmap reads .mailmap from the worktree, or from HEAD in a bare
+// repository, then mailmap.file, whose entries win. Missing files are
+// not an error.
+func LoadMailmap(repo *git.Repository) (*Mailmap, error) {
+	m := &Mailmap{byNameEmail: make(map[[2]string]Identity), byEmail: make(map[string]Identity)}
+	if wt, err := repo.Worktree(); err == nil {
+		if f, err := wt.Filesystem.Open(".mailmap"); err == nil {
+			m.parse(f)
+			f.Close()
+		}
+	} else if head, err := ResolveCommit(repo, "HEAD"); err == nil {
+		if file, err := head.File(".mailmap"); err == nil {
+			if r, err := file.Reader(); err == nil {
+				m.parse(r)
+				r.Close()
+			}
+		}
+	}
+	if name := GitConfig(repo).Section("mailmap").Option("file"); name != "" {
+		if strings.HasPrefix(name, "~/") {
+			if home, err := os.UserHomeDir(); err == nil {
+				name = filepath.Join(home, name[2:])
+			}
+		}
+		if f, err := os.Open(name); err == nil {
+			m.parse(f)
+			f.Close()
+		} else if !os.IsNotExist(err) {
+			return nil, err
+		}
+	}
+	return m, nil
+}
+
+// parse reads entries in the forms git accepts:
+//
+//	Proper Name <commit@email>
+//	<proper@email> <commit@email>
+//	Proper Name <proper@email> <commit@email>
+//	Proper Name <proper@email> Commit Name <commit@email>
+func (m *Mailmap) parse(r io.Reader) {
+	scanner := bufio.NewScanner(r)
+	for scanner.Scan() {
+		line, _, _ := strings.Cut(scanner.Text(), "#")
+		var names, emails []string
+		for {
+			open := strings.IndexByte(line, '<')
+			end := strings.IndexByte(line, '>')
+			if open < 0 || end < open {
+				break
+			}
+			names = append(names, strings.TrimSpace(line[:open]))
+			emails = append(emails, strings.TrimSpace(line[open+1:end]))
+			line = line[end+1:]
+		}
+		var proper Identity
+		var name, email string
+		switch len(emails) {
+		case 1:
+			proper = Identity{Name: names[0]}
+			email = emails[0]
+		case 2:
+			proper = Identity{Name: names[0], Email: emails[0]}
+			name, email = names[1], emails[1]
+		default:
+			continue
+		}
+		if name != "" {
+			m.byNameEmail[[2]string{strings.ToLower(name), strings.ToLower(email)}] = proper
+		} else {
+			m.byEmail[strings.ToLower(email)] = proper
+		}
+	}
+}
+
+// Map is who id goes by: an entry for its name and email, or else for its
+// email, replaces the name and email the entry gives.
+func (m *Mailmap) Map(id Identity) Identity {
+	if m == nil {
+		return id
+	}
+	email := strings.ToLower(id.Email)
+	proper, ok := m.byNameEmail[[2]string{strings.ToLower(id.Name), email}]
+	if !ok {
+		proper, ok = m.byEmail[email]
+	}
+	if !ok {
+		return id
+	}
+	if proper.Name != "" {
+		id.Name = proper.Name
+	}
+	if proper.Email != "" {
+		id.Email = proper.Email
+	}
+	return id
+}
@@ -0,0 +1,133 @@
+package gitgraph
+
+import (
+	"strings"
+
+	"github.com/go-git/go-git/v5/plumbing/object"
+	"github.com/go-git/go-git/v5/utils/diff"
+	"github.com/sergi/go-diff/diffmatchpatch"
+)
+
+// binarySniff is how much of a file git reads looking for a NUL byte,
+// which makes it binary unless its attributes say otherwise.
+const binarySniff = 8000
+
+// FileStat is one file's lines added and deleted, as in git diff --numstat.
+type FileStat struct {
+	// Path is the file's path after the change, or before it if deleted.
+	Path    string
+	Added   int
+	Deleted int
+	// Binary files have no lines to count; git shows "-" for them.
+	Binary bool
+}
+
+// NumStat counts the lines each change adds and deletes the way git
+// does rather than by comparing raw bytes: a file is binary if its diff
+// attribute is unset, by -diff or binary, or names a driver with
+// diff.<driver>.binary set, or else if either version has a NUL byte
+// near its start, unless diff is set. A file git converts as text, by
+// text, text=auto, eol or crlf, is compared with CRLF line endings read
+// as LF, so renormalizing its endings counts only the lines that really
+// changed. A file with a textconv driver counts the lines of its
+// converted text, as arbor diffs it, where git diff --stat calls it
+// binary. Stats are in the order of changes.
+func (a *Attributes) NumStat(changes object.Changes) ([]FileStat, error) {
+	stats := make([]FileStat, 0, len(changes))
+	for _, change := range changes {
+		stat, err := a.fileStat(change)
+		if err != nil {
+			return nil, err
+		}
+		stats = append(stats, stat)
+	}
+	return stats, nil
+}
+
+func (a *Attributes) fileStat(change *object.Change) (FileStat, error) {
+	stat := FileStat{Path: change.To.Name}
+	if stat.Path == "" {
+		stat.Path = change.From.Name
+	}
+	if command := a.Textconv(stat.Path); command != "" {
+		texts, err := a.convertChange(command, change)
+		if err != nil {
+			return FileStat{}, err
+		}
+		stat.Added, stat.Deleted = countChanges(texts[0], texts[1])
+		return stat, nil
+	}
+	from, to, err := change.Files()
+	if err != nil {
+		return FileStat{}, err
+	}
+	var contents [2]string
+	for i, file := range []*object.File{from, to} {
+		if file == nil {
+			continue
+		}
+		if contents[i], err = file.Contents(); err != nil {
+			return FileStat{}, err
+		}
+	}
+
+	attrs := a.Lookup(stat.Path, "diff", "text", "eol", "crlf")
+	switch d := attrs["diff"]; {
+	case d == nil || d.IsUnspecified():
+		stat.Binary = looksBinary(contents[0]) || looksBinary(contents[1])
+	case d.IsUnset():
+		stat.Binary = true
+	case d.IsValueSet():
+		stat.Binary = a.binaryDriver(d.Value()) || looksBinary(contents[0]) || looksBinary(contents[1])
+	}
+	if stat.Binary {
+		return stat, nil
+	}
+	if text := attrs["text"]; text != nil && (text.IsSet() || text.IsValueSet()) ||
+		attrs["eol"] != nil && attrs["eol"].IsValueSet() ||
+		attrs["crlf"] != nil && (attrs["crlf"].IsSet() || attrs["crlf"].IsValueSet()) {
+		contents[0] = strings.ReplaceAll(contents[0], "\r\n", "\n")
+		contents[1] = strings.ReplaceAll(contents[1], "\r\n", "\n")
+	}
+	stat.Added, stat.Deleted = countChanges(contents[0], contents[1])
+	return stat, nil
+}
+
+// countChanges counts the lines that turning from into to adds and
+// deletes.
+func countChanges(from, to string) (added, deleted int) {
+	for _, chunk := range diff.Do(from, to) {
+		switch chunk.Type {
+		case diffmatchpatch.DiffInsert:
+			added += countLines(chunk.Text)
+		case diffmatchpatch.DiffDelete:
+			deleted += countLines(chunk.Text)
+		}
+	}
+	return added, deleted
+}
+
+// binaryDriver reports whether diff driver name is configured binary.
+func (a *Attributes) binaryDriver(name string) bool {
+	if a.config == nil {
+		return false
+	}
+	switch strings.ToLower(a.config.Section("diff").Subsection(name).Option("binary")) {
+	case "true", "yes", "on", "1":
+		return true
+	}
+	return false
+}
+
+func looksBinary(content string) bool {
+	return strings.IndexByte(content[:min(len(content), binarySniff)], 0) >= 0
+}
+
+// countLines counts text's lines, a last one without a newline included.
+func countLines(text string) int {
+	n := strings.Count(text, "\n")
+	if text != "" && !strings.HasSuffix(text, "\n") {
+		n++
+	}
+	return n
+}
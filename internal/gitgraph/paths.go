@@ -0,0 +1,383 @@
+package gitgraph
+
+import (
+	"errors"
+	"fmt"
+	"path"
+	"regexp"
+	"strings"
+
+	"github.com/go-git/go-git/v5/plumbing"
+	"github.com/go-git/go-git/v5/plumbing/filemode"
+	"github.com/go-git/go-git/v5/plumbing/object"
+	"github.com/go-git/go-git/v5/plumbing/storer"
+)
+
+// TouchesPath reports whether commit changes anything at p, a file or a
+// directory ending in "/". It compares tree entries instead of diffing, so it
+// is cheap enough to scan deep history. Like git log -- <path>, a merge
+// counts only when it differs from every parent there.
+func TouchesPath(commit *object.Commit, p string) (bool, error) {
+	own, err := pathHash(commit, p)
+	if err != nil {
+		return false, err
+	}
+	if commit.NumParents() == 0 {
+		return !own.IsZero(), nil
+	}
+	parents := commit.Parents()
+	defer parents.Close()
+	touched := true
+	err = parents.ForEach(func(parent *object.Commit) error {
+		theirs, err := pathHash(parent, p)
+		if err != nil {
+			return err
+		}
+		if theirs == own {
+			touched = false
+		}
+		return nil
+	})
+	return touched, err
+}
+
+// pathHash is the object at p in commit's tree, or the zero hash if there is
+// none.
+func pathHash(commit *object.Commit, p string) (plumbing.Hash, error) {
+	tree, err := commit.Tree()
+	if err != nil {
+		return plumbing.ZeroHash, err
+	}
+	entry, err := tree.FindEntry(strings.TrimSuffix(p, "/"))
+	if errors.Is(err, object.ErrEntryNotFound) || errors.Is(err, object.ErrDirectoryNotFound) {
+		return plumbing.ZeroHash, nil
+	}
+	if err != nil {
+		return plumbing.ZeroHash, err
+	}
+	return entry.Hash, nil
+}
+
+// Pathspec selects paths the way git pathspecs do, relative to the top of
+// the repository: "main.go" a file or everything under a directory of
+// that name, "docs/" a directory only, "*.go" a wildcard whose * also
+// crosses "/", and the magic prefixes :(exclude) (or :! and :^), :(glob)
+// (where only ** crosses "/"), :(literal), :(icase), and :(top) (or :/),
+// which is the default here anyway.
+type Pathspec struct {
+	specs   []string
+	include []pathPattern
+	exclude []pathPattern
+}
+
+type pathPattern struct {
+	// literal is the pattern when it has no wildcards, and otherwise the
+	// part before the first one, which limits the directories to look in.
+	literal  string
+	wildcard *regexp.Regexp
+	icase    bool
+	// dir is set by a trailing "/": the pattern then matches directories
+	// only, selecting the paths under them.
+	dir bool
+}
+
+// ParsePathspec reads pathspecs as given after -- on a git command line.
+// Only excludes, or none at all, select every path but the excluded ones.
+func ParsePathspec(specs []string) (*Pathspec, error) {
+	s := &Pathspec{specs: specs}
+	for _, spec := range specs {
+		pattern, exclude, err := parsePathPattern(spec)
+		if err != nil {
+			return nil, err
+		}
+		if exclude {
+			s.exclude = append(s.exclude, pattern)
+		} else {
+			s.include = append(s.include, pattern)
+		}
+	}
+	return s, nil
+}
+
+func parsePathPattern(spec string) (pattern pathPattern, exclude bool, err error) {
+	rest := spec
+	glob, literal := false, false
+	var magic []string
+	switch {
+	case strings.HasPrefix(rest, ":("):
+		end := strings.IndexByte(rest, ')')
+		if end < 0 {
+			return pattern, false, fmt.Errorf("pathspec %q: missing ) after magic", spec)
+		}
+		magic = strings.Split(rest[2:end], ",")
+		rest = rest[end+1:]
+	case strings.HasPrefix(rest, ":"):
+		rest = rest[1:]
+		for rest != "" && strings.ContainsRune("!^/", rune(rest[0])) {
+			if rest[0] == '/' {
+				magic = append(magic, "top")
+			} else {
+				magic = append(magic, "exclude")
+			}
+			rest = rest[1:]
+		}
+		rest = strings.TrimPrefix(rest, ":")
+	}
+	for _, word := range magic {
+		switch strings.TrimSpace(word) {
+		case "top", "":
+		case "exclude":
+			exclude = true
+		case "glob":
+			glob = true
+		case "literal":
+			literal = true
+		case "icase":
+			pattern.icase = true
+		default:
+			return pattern, false, fmt.Errorf("pathspec %q: unsupported magic %q (use exclude, glob, literal, icase, or top)", spec, word)
+		}
+	}
+	dir := strings.HasSuffix(rest, "/")
+	rest = strings.TrimPrefix(path.Clean("/"+rest), "/")
+	pattern.dir = dir && rest != ""
+	if pattern.icase {
+		rest = strings.ToLower(rest)
+	}
+	wild := strings.IndexAny(rest, `*?[\`)
+	if literal || wild < 0 {
+		pattern.literal = rest
+		return pattern, exclude, nil
+	}
+	pattern.literal = rest[:wild]
+	pattern.wildcard, err = wildcardRegexp(rest, glob)
+	if err != nil {
+		return pattern, false, fmt.Errorf("pathspec %q: %w", spec, err)
+	}
+	return pattern, exclude, nil
+}
+
+// wildcardRegexp translates a pathspec wildcard: * and ? match any
+// character, "/" too, unless glob is set, when only ** crosses
+// directories.
+func wildcardRegexp(pattern string, glob bool) (*regexp.Regexp, error) {
+	var b strings.Builder
+	b.WriteString("^")
+	for i := 0; i < len(pattern); i++ {
+		switch c := pattern[i]; c {
+		case '*':
+			switch {
+			case !glob:
+				b.WriteString(".*")
+			case strings.HasPrefix(pattern[i:], "**/"):
+				b.WriteString("(?:.*/)?")
+				i += 2
+			case strings.HasPrefix(pattern[i:], "**"):
+				b.WriteString(".*")
+				i++
+			default:
+				b.WriteString("[^/]*")
+			}
+		case '?':
+			if glob {
+				b.WriteString("[^/]")
+			} else {
+				b.WriteString(".")
+			}
+		case '[':
+			end := strings.IndexByte(pattern[i+1:], ']')
+			if end < 0 {
+				return nil, errors.New("unterminated [")
+			}
+			class := pattern[i+1 : i+1+end]
+			if strings.HasPrefix(class, "!") {
+				class = "^" + class[1:]
+			}
+			b.WriteString("[" + strings.ReplaceAll(class, `\`, `\\`) + "]")
+			i += end + 1
+		case '\\':
+			if i+1 < len(pattern) {
+				i++
+				b.WriteString(regexp.QuoteMeta(pattern[i : i+1]))
+			}
+		default:
+			b.WriteString(regexp.QuoteMeta(string(c)))
+		}
+	}
+	b.WriteString("$")
+	return regexp.Compile(b.String())
+}
+
+func (p pathPattern) match(name string) bool {
+	if p.icase {
+		name = strings.ToLower(name)
+	}
+	if p.wildcard != nil && p.dir {
+		for i := strings.IndexByte(name, '/'); i >= 0; i = nextSlash(name, i) {
+			if p.wildcard.MatchString(name[:i]) {
+				return true
+			}
+		}
+		return false
+	}
+	if p.wildcard != nil {
+		return p.wildcard.MatchString(name)
+	}
+	return p.literal == "" || name == p.literal && !p.dir || strings.HasPrefix(name, p.literal+"/")
+}
+
+// nextSlash is the index of the first "/" in name after i, or -1.
+func nextSlash(name string, i int) int {
+	if j := strings.IndexByte(name[i+1:], '/'); j >= 0 {
+		return i + 1 + j
+	}
+	return -1
+}
+
+// coversDir reports whether the pattern matches everything under dir, a
+// directory path ending in "/".
+func (p pathPattern) coversDir(dir string) bool {
+	if p.icase {
+		dir = strings.ToLower(dir)
+	}
+	return p.wildcard == nil && (p.literal == "" || strings.HasPrefix(dir, p.literal+"/"))
+}
+
+// mayMatchUnder reports whether the pattern could match a path under dir.
+func (p pathPattern) mayMatchUnder(dir string) bool {
+	if p.icase {
+		dir = strings.ToLower(dir)
+	}
+	if p.wildcard == nil {
+		return p.literal == "" || strings.HasPrefix(p.literal+"/", dir) || strings.HasPrefix(dir, p.literal+"/")
+	}
+	return strings.HasPrefix(p.literal, dir) || strings.HasPrefix(dir, p.literal)
+}
+
+// Match reports whether name, a path from the top of the repository, is
+// selected.
+func (s *Pathspec) Match(name string) bool {
+	for _, p := range s.exclude {
+		if p.match(name) {
+			return false
+		}
+	}
+	if len(s.include) == 0 {
+		return true
+	}
+	for _, p := range s.include {
+		if p.match(name) {
+			return true
+		}
+	}
+	return false
+}
+
+// mayMatchUnder reports whether anything under dir could be selected, so
+// unchanged and unrelated directories are never read.
+func (s *Pathspec) mayMatchUnder(dir string) bool {
+	for _, p := range s.exclude {
+		if p.coversDir(dir) {
+			return false
+		}
+	}
+	if len(s.include) == 0 {
+		return true
+	}
+	for _, p := range s.include {
+		if p.mayMatchUnder(dir) {
+			return true
+		}
+	}
+	return false
+}
+
+func (s *Pathspec) String() string {
+	return strings.Join(s.specs, " ")
+}
+
+// Touches reports whether commit changes a selected path. Like TouchesPath
+// it walks only the subtrees that differ, and a merge counts only when it
+// differs from every parent in them.
+func (s *Pathspec) Touches(commit *object.Commit) (bool, error) {
+	tree, err := commit.Tree()
+	if err != nil {
+		return false, err
+	}
+	if commit.NumParents() == 0 {
+		return s.treesDiffer(nil, tree, "")
+	}
+	parents := commit.Parents()
+	defer parents.Close()
+	touched := true
+	err = parents.ForEach(func(parent *object.Commit) error {
+		theirs, err := parent.Tree()
+		if err != nil {
+			return err
+		}
+		differs, err := s.treesDiffer(theirs, tree, "")
+		if err != nil {
+			return err
+		}
+		if !differs {
+			touched = false
+			return storer.ErrStop
+		}
+		return nil
+	})
+	return touched, err
+}
+
+// treesDiffer reports whether a selected path under dir differs between
+// trees a and b, either of which may be nil for an empty tree.
+func (s *Pathspec) treesDiffer(a, b *object.Tree, dir string) (bool, error) {
+	theirs := make(map[string]*object.TreeEntry)
+	if a != nil {
+		for i := range a.Entries {
+			theirs[a.Entries[i].Name] = &a.Entries[i]
+		}
+	}
+	compare := func(name string, old, cur *object.TreeEntry) (bool, error) {
+		if old != nil && cur != nil && old.Hash == cur.Hash && old.Mode == cur.Mode {
+			return false, nil
+		}
+		full := dir + name
+		oldDir := old != nil && old.Mode == filemode.Dir
+		curDir := cur != nil && cur.Mode == filemode.Dir
+		if (old != nil && !oldDir || cur != nil && !curDir) && s.Match(full) {
+			return true, nil
+		}
+		if !oldDir && !curDir || !s.mayMatchUnder(full+"/") {
+			return false, nil
+		}
+		var oldTree, curTree *object.Tree
+		var err error
+		if oldDir {
+			if oldTree, err = a.Tree(name); err != nil {
+				return false, err
+			}
+		}
+		if curDir {
+			if curTree, err = b.Tree(name); err != nil {
+				return false, err
+			}
+		}
+		return s.treesDiffer(oldTree, curTree, full+"/")
+	}
+	if b != nil {
+		for i := range b.Entries {
+			cur := &b.Entries[i]
+			old := theirs[cur.Name]
+			delete(theirs, cur.Name)
+			if differs, err := compare(cur.Name, old, cur); err != nil || differs {
+				return differs, err
+			}
+		}
+	}
+	for name, old := range theirs {
+		if differs, err := compare(name, old, nil); err != nil || differs {
+			return differs, err
+		}
+	}
+	return false, nil
+}
@@ -0,0 +1,136 @@
+package gitgraph
+
+import (
+	"fmt"
+	"path"
+	"strings"
+
+	"github.com/go-git/go-git/v5/plumbing/object"
+)
+
+// Policy says who may commit: authors whose email is in one of Domains
+// (or a subdomain) or matches one of Emails, and, with Keys, commits
+// signed by one of those keys. An empty list leaves that check off.
+// Committers holds committers to the same rules as authors.
+type Policy struct {
+	Domains    []string
+	Emails     []string
+	Keys       []string
+	Committers bool
+}
+
+// minKeyID is the fewest hex digits an allowed GPG key may give: a long
+// key ID. Short, 8-digit IDs are easy to collide with a key of one's own.
+const minKeyID = 16
+
+// Signer is what git verified of a commit's signature: whether it has
+// one, Good for a good one whatever the key's trust, and the key's ID and
+// fingerprints.
+type Signer struct {
+	Signed bool
+	Good   bool
+	Keys   []string
+}
+
+// ChecksAuthors reports whether the policy restricts who commits.
+func (p *Policy) ChecksAuthors() bool {
+	return len(p.Domains) > 0 || len(p.Emails) > 0
+}
+
+// ChecksKeys reports whether the policy needs signatures, which only git
+// can verify.
+func (p *Policy) ChecksKeys() bool {
+	return len(p.Keys) > 0
+}
+
+// Validate reports the first of Keys that names a GPG key by a short ID,
+// which trusts would never match.
+func (p *Policy) Validate() error {
+	for _, key := range p.Keys {
+		if key := strings.ReplaceAll(key, " ", ""); isHex(key) && len(key) < minKeyID {
+			return fmt.Errorf("keys: %q is a short key ID; give the %d-digit long ID or the full fingerprint", key, minKeyID)
+		}
+	}
+	return nil
+}
+
+// Check lists how commit breaks the policy. signer is nil when the
+// signature was not checked, which breaks Keys: a commit is only allowed
+// on a signature git verified.
+func (p *Policy) Check(commit *object.Commit, signer *Signer) []string {
+	var problems []string
+	if p.ChecksAuthors() {
+		if !p.allows(commit.Author.Email) {
+			problems = append(problems, fmt.Sprintf("author %s is not allowed", commit.Author.Email))
+		}
+		if p.Committers && commit.Committer.Email != commit.Author.Email && !p.allows(commit.Committer.Email) {
+			problems = append(problems, fmt.Sprintf("committer %s is not allowed", commit.Committer.Email))
+		}
+	}
+	if p.ChecksKeys() {
+		switch {
+		case signer == nil:
+			problems = append(problems, "signature not verified")
+		case !signer.Signed:
+			problems = append(problems, "not signed")
+		case !signer.Good:
+			problems = append(problems, "signature does not verify")
+		case !p.trusts(signer.Keys):
+			key := "an unknown key"
+			if len(signer.Keys) > 0 {
+				key = signer.Keys[0]
+			}
+			problems = append(problems, fmt.Sprintf("signed by %s, which is not an allowed key", key))
+		}
+	}
+	return problems
+}
+
+func (p *Policy) allows(email string) bool {
+	email = strings.ToLower(email)
+	_, domain, _ := strings.Cut(email, "@")
+	for _, allowed := range p.Domains {
+		allowed = strings.ToLower(strings.TrimPrefix(allowed, "@"))
+		if domain == allowed || strings.HasSuffix(domain, "."+allowed) {
+			return true
+		}
+	}
+	for _, pattern := range p.Emails {
+		if ok, _ := path.Match(strings.ToLower(pattern), email); ok {
+			return true
+		}
+	}
+	return false
+}
+
+// trusts matches keys, as git reports them, against the allowed ones: an
+// SSH fingerprint exactly, a GPG long key ID or fingerprint as the end of
+// the signing key's fingerprint, in any case. A short key ID matches
+// nothing.
+func (p *Policy) trusts(keys []string) bool {
+	for _, allowed := range p.Keys {
+		allowed = strings.ReplaceAll(allowed, " ", "")
+		gpg := isHex(allowed)
+		if gpg && len(allowed) < minKeyID {
+			continue
+		}
+		for _, key := range keys {
+			if key == allowed || gpg && strings.HasSuffix(strings.ToUpper(key), strings.ToUpper(allowed)) {
+				return true
+			}
+		}
+	}
+	return false
+}
+
+func isHex(s string) bool {
+	if s == "" {
+		return false
+	}
+	for _, r := range strings.ToLower(s) {
+		if (r < '0' || r > '9') && (r < 'a' || r > 'f') {
+			return false
+		}
+	}
+	return true
+}
@@ -2,13 +2,22 @@ package gitgraph
 
 import (
 	"container/heap"
+	"errors"
 	"fmt"
+	"maps"
+	"slices"
 	"strings"
+	"sync"
 	"time"
 
+	"arbor/internal/debuglog"
+
 	git "github.com/go-git/go-git/v5"
 	"github.com/go-git/go-git/v5/plumbing"
+	"github.com/go-git/go-git/v5/plumbing/cache"
 	"github.com/go-git/go-git/v5/plumbing/object"
+	"github.com/go-git/go-git/v5/plumbing/storer"
+	"github.com/go-git/go-git/v5/storage/filesystem"
 )
 
 type GraphCell struct {
@@ -17,35 +26,223 @@ type GraphCell struct {
 }
 
 type CommitInfo struct {
-	Hash      plumbing.Hash
-	ShortHash string
-	Subject   string
-	Author    string
-	When      time.Time
-	Graph     []GraphCell
-	Commit    *object.Commit
+	Hash    plumbing.Hash
+	Subject string
+	Author  string
+	When    time.Time
+	Graph   []GraphCell
+	// Commit holds only the first line of the message for commits from a
+	// walk; Message reads the rest back.
+	Commit   *object.Commit
+	provider *CommitProvider
+}
+
+// messageCacheBytes bounds the commit messages a provider keeps read
+// back.
+const messageCacheBytes = 4 << 20
+
+// Message is the full commit message. A walk keeps just the subject of
+// each commit in memory, since bodies add up on message-heavy histories,
+// so the whole message is read back from the object store, through
+// replace refs, when asked for; the provider keeps the latest read.
+func (c *CommitInfo) Message() string {
+	if c.Commit == nil {
+		return ""
+	}
+	if c.provider == nil {
+		return c.Commit.Message
+	}
+	return c.provider.message(c.Commit)
+}
+
+// ShortHash abbreviates the hash as the provider's Abbrev does now, so a
+// row loaded before the ambiguity index was read still grows to the length
+// it needs once it has.
+func (c *CommitInfo) ShortHash() string {
+	var abbrev *Abbrev
+	if c.provider != nil {
+		abbrev = c.provider.abbrev
+	}
+	return abbrev.Short(c.Hash)
+}
+
+func (p *CommitProvider) message(commit *object.Commit) string {
+	if message, ok := p.messages.Get(commit.Hash); ok {
+		return message
+	}
+	full, err := p.replace.Commit(p.repo, commit.Hash)
+	if err != nil {
+		return commit.Message
+	}
+	p.messages.Add(commit.Hash, full.Message)
+	return full.Message
+}
+
+// Scope is which refs the walk starts from.
+type Scope int
+
+const (
+	ScopeHead  Scope = iota // HEAD alone
+	ScopeLocal              // HEAD and local branches
+	ScopeAll                // also remote-tracking branches
+)
+
+func (s Scope) String() string {
+	switch s {
+	case ScopeHead:
+		return "HEAD only"
+	case ScopeAll:
+		return "all branches"
+	}
+	return "local branches"
+}
+
+// Next cycles HEAD only → local branches → all branches.
+func (s Scope) Next() Scope {
+	return (s + 1) % (ScopeAll + 1)
+}
+
+// ParentFilter keeps commits with at least Min parents and, unless Max is
+// negative, at most Max, like git log --min-parents and --max-parents.
+type ParentFilter struct {
+	Min int
+	Max int
+}
+
+func (f ParentFilter) Match(parents int) bool {
+	return parents >= f.Min && (f.Max < 0 || parents <= f.Max)
+}
+
+// Any reports whether the filter lets every commit through.
+func (f ParentFilter) Any() bool {
+	return f.Min <= 0 && f.Max < 0
+}
+
+func (f ParentFilter) String() string {
+	switch {
+	case f.Any():
+		return "any parents"
+	case f.Min == 2 && f.Max < 0:
+		return "merges only"
+	case f.Min <= 0 && f.Max == 1:
+		return "no merges"
+	case f.Min <= 0 && f.Max == 0:
+		return "root commits"
+	case f.Max < 0:
+		return fmt.Sprintf("%d+ parents", f.Min)
+	case f.Min <= 0:
+		return fmt.Sprintf("at most %d parents", f.Max)
+	case f.Min == f.Max:
+		return fmt.Sprintf("%d parents", f.Min)
+	}
+	return fmt.Sprintf("%d-%d parents", f.Min, f.Max)
 }
 
+// Order is how the walk interleaves commits from different branches.
+type Order string
+
+const (
+	// OrderDate lists newest committer date first.
+	OrderDate Order = "date"
+	// OrderTopo never lists a parent before all its children, even when
+	// clocks were skewed; it numbers the whole history first, which the
+	// commit-graph file makes cheap.
+	OrderTopo Order = "topo"
+)
+
+var OrderNames = []string{string(OrderDate), string(OrderTopo)}
+
+// ErrListedScope is returned for a scope change on a provider showing a
+// fixed list of commits, which has no refs to choose from.
+var ErrListedScope = errors.New("the commits were listed on stdin, so there are no refs to scope")
+
+// CommitProvider walks history into Commits as Ensure asks for more.
+// Commits, Ensure, HasMore, Want, and Close belong to one goroutine at a
+// time; Prefetch adds another that walks ahead under mu, leaving what it
+// walked in ready for Ensure to take.
 type CommitProvider struct {
-	repo     *git.Repository
-	all      bool
+	repo *git.Repository
+	// walkRepo reads the commits the walk visits, under mu: a handle of
+	// its own on the same repository, since the prefetcher walks while
+	// other goroutines read through repo, and go-git's storage is not
+	// safe for that.
+	walkRepo *git.Repository
+	scope    Scope
+	pulls    bool
+	first    bool
+	parents  ParentFilter
+	paths    *Pathspec
+	sort     Order
 	limit    int
 	seen     map[plumbing.Hash]bool
-	heap     commitHeap
+	heap     walkHeap
 	graph    graphState
 	Commits  []*CommitInfo
 	complete bool
+
+	order  []plumbing.Hash
+	listed map[plumbing.Hash]bool
+	next   int
+
+	replace *Replacements
+	abbrev  *Abbrev
+	// gens numbers commits for reachability queries on repo. It is not
+	// passed on, as a fetch may have deepened a shallow clone.
+	gens *Generations
+	// textconvs caches textconv output for the Attributes the provider
+	// hands out; Reload and WithScope pass it on.
+	textconvs *lru[textconvKey, string]
+	// messages caches what Message reads back. It is not passed on, as
+	// replace refs may have moved.
+	messages *lru[plumbing.Hash, string]
+
+	// children is nil unless IndexChildren was called.
+	children map[plumbing.Hash][]plumbing.Hash
+
+	// mu guards the walk: heap, seen, graph, next, children, and the
+	// fields below.
+	mu sync.Mutex
+	// ready are commits walked but not yet in Commits; walked counts them
+	// with Commits, for the limit.
+	ready  []*CommitInfo
+	walked int
+	// err is a failed load on the prefetcher, for Ensure to report.
+	err  error
+	want chan int
+	done chan struct{}
 }
 
 func NewCommitProvider(repo *git.Repository, includeAll bool, limit int) (*CommitProvider, error) {
+	scope := ScopeLocal
+	if includeAll {
+		scope = ScopeAll
+	}
+	return NewScopedCommitProvider(repo, scope, limit)
+}
+
+func NewScopedCommitProvider(repo *git.Repository, scope Scope, limit int) (*CommitProvider, error) {
+	start := time.Now()
 	p := &CommitProvider{
-		repo:  repo,
-		all:   includeAll,
-		limit: limit,
-		seen:  make(map[plumbing.Hash]bool),
+		repo:      repo,
+		walkRepo:  ownHandle(repo),
+		scope:     scope,
+		parents:   ParentFilter{Max: -1},
+		sort:      OrderDate,
+		limit:     limit,
+		seen:      make(map[plumbing.Hash]bool),
+		heap:      walkHeap{less: dateOrder},
+		abbrev:    NewAbbrev(repo),
+		gens:      NewGenerations(repo),
+		textconvs: newTextconvCache(),
+		messages:  newLRU[plumbing.Hash](messageCacheBytes, func(message string) int { return len(message) }),
+	}
+	replace, err := LoadReplacements(repo)
+	if err != nil {
+		return nil, err
 	}
+	p.replace = replace
 
-	tips, err := gatherTips(repo, includeAll)
+	tips, err := gatherTips(repo, scope)
 	if err != nil {
 		return nil, err
 	}
@@ -56,52 +253,387 @@ func NewCommitProvider(repo *git.Repository, includeAll bool, limit int) (*Commi
 		if p.seen[h] {
 			continue
 		}
-		commit, err := repo.CommitObject(h)
+		commit, err := p.replace.Commit(p.walkRepo, h)
 		if err != nil {
 			continue
 		}
 		p.seen[h] = true
 		heap.Push(&p.heap, commit)
 	}
+	debuglog.Timed(start, "walk started", "scope", scope.String(), "tips", len(tips), "limit", limit)
 	return p, nil
 }
 
+// NewListedCommitProvider renders exactly the given commits in the given
+// order, as produced by an external `git rev-list`. Parents outside the list
+// are left out of the graph so lanes close where the selection ends.
+func NewListedCommitProvider(repo *git.Repository, hashes []plumbing.Hash, limit int) (*CommitProvider, error) {
+	p := &CommitProvider{
+		repo:      repo,
+		walkRepo:  ownHandle(repo),
+		parents:   ParentFilter{Max: -1},
+		limit:     limit,
+		seen:      make(map[plumbing.Hash]bool),
+		listed:    make(map[plumbing.Hash]bool, len(hashes)),
+		order:     make([]plumbing.Hash, 0, len(hashes)),
+		abbrev:    NewAbbrev(repo),
+		gens:      NewGenerations(repo),
+		textconvs: newTextconvCache(),
+		messages:  newLRU[plumbing.Hash](messageCacheBytes, func(message string) int { return len(message) }),
+	}
+	for _, h := range hashes {
+		if p.listed[h] {
+			continue
+		}
+		p.listed[h] = true
+		p.order = append(p.order, h)
+	}
+	if len(p.order) == 0 {
+		return nil, fmt.Errorf("no commits found")
+	}
+	replace, err := LoadReplacements(repo)
+	if err != nil {
+		return nil, err
+	}
+	p.replace = replace
+	return p, nil
+}
+
+// Reload starts a fresh walk with the same settings, picking up refs that
+// moved since the provider was created. Lanes carry over from this walk (see
+// openLane), so fetched or freshly committed work does not shift the graph
+// the user was looking at.
+func (p *CommitProvider) Reload() (*CommitProvider, error) {
+	var next *CommitProvider
+	var err error
+	if p.order != nil {
+		next, err = NewListedCommitProvider(p.repo, p.order, p.limit)
+	} else {
+		next, err = NewScopedCommitProvider(p.repo, p.scope, p.limit)
+	}
+	if err != nil {
+		return nil, err
+	}
+	if p.pulls {
+		if err := next.IncludePullRequests(); err != nil {
+			return nil, err
+		}
+	}
+	next.parents = p.parents
+	next.paths = p.paths
+	next.first = p.first
+	next.textconvs = p.textconvs
+	next.abbrev = p.abbrev.Refresh()
+	next.SortBy(p.sort)
+	p.mu.Lock()
+	next.graph.lanes = maps.Clone(p.graph.opened)
+	indexed := p.children != nil
+	p.mu.Unlock()
+	if indexed {
+		next.IndexChildren()
+	}
+	return next, nil
+}
+
+// WithScope starts a walk from a different set of refs. Unlike Reload the
+// lanes are laid out afresh, since the branches beside them come and go.
+func (p *CommitProvider) WithScope(scope Scope) (*CommitProvider, error) {
+	if p.order != nil {
+		return nil, ErrListedScope
+	}
+	next, err := NewScopedCommitProvider(p.repo, scope, p.limit)
+	if err != nil {
+		return nil, err
+	}
+	if p.pulls {
+		err = next.IncludePullRequests()
+	}
+	next.parents = p.parents
+	next.paths = p.paths
+	next.first = p.first
+	next.textconvs = p.textconvs
+	next.abbrev = p.abbrev.Refresh()
+	next.SortBy(p.sort)
+	p.mu.Lock()
+	indexed := p.children != nil
+	p.mu.Unlock()
+	if indexed {
+		next.IndexChildren()
+	}
+	return next, err
+}
+
+// IncludePullRequests also walks from fetched pull and merge request heads
+// (see PullRequestNumber), in every scope but HEAD only. Call it before
+// loading any commits.
+func (p *CommitProvider) IncludePullRequests() error {
+	if p.order != nil {
+		return ErrListedScope
+	}
+	p.pulls = true
+	if p.scope == ScopeHead {
+		return nil
+	}
+	refs, err := ListRefs(p.repo)
+	if err != nil {
+		return err
+	}
+	for _, ref := range refs {
+		if ref.Kind != RefPull || p.seen[ref.Target] {
+			continue
+		}
+		commit, err := p.replace.Commit(p.walkRepo, ref.Target)
+		if err != nil {
+			continue
+		}
+		p.seen[ref.Target] = true
+		heap.Push(&p.heap, commit)
+	}
+	return nil
+}
+
+// FilterParents lists only the commits filter matches. The walk still
+// passes through the others, so lanes run on past them and --limit counts
+// only the commits listed. Call it before loading any commits.
+func (p *CommitProvider) FilterParents(filter ParentFilter) {
+	p.parents = filter
+}
+
+func (p *CommitProvider) ParentFilter() ParentFilter {
+	return p.parents
+}
+
+// FilterPaths lists only the commits that change a path spec selects, as
+// git log -- <pathspec> does, with lanes running on past the others like
+// FilterParents. Call it before loading any commits.
+func (p *CommitProvider) FilterPaths(spec *Pathspec) {
+	p.paths = spec
+}
+
+// PathFilter is the spec set by FilterPaths, or nil.
+func (p *CommitProvider) PathFilter() *Pathspec {
+	return p.paths
+}
+
+// FollowFirstParent walks only the first parent of each merge, as git log
+// --first-parent does, so a mainline with many merged branches reads as
+// one line and the walk skips the branches' commits. Call it before
+// loading any commits.
+func (p *CommitProvider) FollowFirstParent() {
+	p.first = true
+}
+
+// lists reports whether commit passes the parent and path filters.
+func (p *CommitProvider) lists(commit *object.Commit, parents int) bool {
+	if !p.parents.Match(parents) {
+		return false
+	}
+	if p.paths == nil {
+		return true
+	}
+	touched, err := p.paths.Touches(commit)
+	return err == nil && touched
+}
+
+// SortBy sets the order the walk lists commits in. Listed providers keep
+// the order they were given. Call it before loading any commits.
+func (p *CommitProvider) SortBy(order Order) {
+	if p.order != nil {
+		return
+	}
+	p.sort = order
+	switch order {
+	case OrderTopo:
+		generations := NewGenerations(p.walkRepo)
+		p.heap.less = func(a, b *object.Commit) bool {
+			ga, _ := generations.Of(a.Hash)
+			gb, _ := generations.Of(b.Hash)
+			if ga != gb {
+				return ga > gb
+			}
+			return dateOrder(a, b)
+		}
+	default:
+		p.heap.less = dateOrder
+	}
+	heap.Init(&p.heap)
+}
+
+func (p *CommitProvider) Order() Order {
+	return p.sort
+}
+
+func (p *CommitProvider) Scope() Scope {
+	return p.scope
+}
+
+func (p *CommitProvider) Repo() *git.Repository {
+	return p.repo
+}
+
+// Abbrev is the abbreviation index the provider shortens hashes with.
+func (p *CommitProvider) Abbrev() *Abbrev {
+	return p.abbrev
+}
+
+// Generations numbers Repo's commits, keeping them while the provider
+// lives.
+func (p *CommitProvider) Generations() *Generations {
+	return p.gens
+}
+
+// Attributes loads the repository's attributes, as LoadAttributes does,
+// keeping what their textconv drivers convert for as long as the provider
+// and those Reload makes from it.
+func (p *CommitProvider) Attributes() *Attributes {
+	a := LoadAttributes(p.repo)
+	a.converted = p.textconvs
+	return a
+}
+
 func (p *CommitProvider) HasMore() bool {
 	if p.limit > 0 && len(p.Commits) >= p.limit {
 		return false
 	}
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	return len(p.ready) > 0 || p.walkable()
+}
+
+func (p *CommitProvider) pending() bool {
+	if p.order != nil {
+		return p.next < len(p.order)
+	}
 	return p.heap.Len() > 0
 }
 
+// walkable reports whether the walk can list more within the limit.
+func (p *CommitProvider) walkable() bool {
+	return p.pending() && (p.limit == 0 || p.walked < p.limit)
+}
+
 func (p *CommitProvider) Ensure(index int) error {
 	if index < 0 {
 		return nil
 	}
-	for len(p.Commits) <= index && p.HasMore() {
+	if len(p.Commits) <= index && p.HasMore() {
+		start, from := time.Now(), len(p.Commits)
+		defer func() {
+			debuglog.Timed(start, "commits loaded", "from", from, "to", len(p.Commits), "order", p.sort)
+		}()
+	}
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	for len(p.Commits) <= index {
+		// Take only what was asked for, so Commits grows the same with or
+		// without a prefetcher.
+		if n := min(len(p.ready), index+1-len(p.Commits)); n > 0 {
+			p.Commits = append(p.Commits, p.ready[:n]...)
+			p.ready = p.ready[n:]
+			continue
+		}
+		if err := p.err; err != nil {
+			p.err = nil
+			return err
+		}
+		if !p.walkable() {
+			break
+		}
 		if err := p.loadNext(); err != nil {
 			return err
 		}
 	}
-	if p.heap.Len() == 0 && (p.limit == 0 || len(p.Commits) < p.limit) {
+	if !p.pending() && len(p.ready) == 0 && (p.limit == 0 || len(p.Commits) < p.limit) {
 		p.complete = true
 	}
 	return nil
 }
 
+// Prefetch starts a goroutine that keeps the walk up to ahead commits past
+// the last index passed to Want, so scrolling does not wait on the disk
+// for each page. Set the walk up (filters, order) before calling it, and
+// Close the provider when done with it.
+func (p *CommitProvider) Prefetch(ahead int) {
+	if ahead <= 0 || p.want != nil {
+		return
+	}
+	p.want, p.done = make(chan int, 1), make(chan struct{})
+	go p.prefetch(p.want, p.done, ahead)
+	p.Want(0)
+}
+
+// Want tells the prefetcher which commit is about to be shown; it does
+// not wait for it.
+func (p *CommitProvider) Want(index int) {
+	if p.want == nil {
+		return
+	}
+	select {
+	case <-p.want:
+	default:
+	}
+	p.want <- index
+}
+
+// Close stops the prefetcher, waiting for it to finish its commit.
+func (p *CommitProvider) Close() {
+	if p.want == nil {
+		return
+	}
+	close(p.want)
+	<-p.done
+	p.want = nil
+}
+
+func (p *CommitProvider) prefetch(want <-chan int, done chan<- struct{}, ahead int) {
+	defer close(done)
+	for index := range want {
+		for len(want) == 0 && p.walkAhead(index+ahead) {
+		}
+	}
+}
+
+// walkAhead lists one more commit if fewer than target are walked,
+// reporting whether it did. The lock is held a commit at a time, so
+// Ensure is never kept waiting for long.
+func (p *CommitProvider) walkAhead(target int) bool {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	if p.walked > target || p.err != nil || !p.walkable() {
+		return false
+	}
+	if err := p.loadNext(); err != nil {
+		p.err = err
+		return false
+	}
+	return true
+}
+
 func (p *CommitProvider) loadNext() error {
+	if p.order != nil {
+		return p.loadNextListed()
+	}
 	commit := heap.Pop(&p.heap).(*object.Commit)
-	info := buildCommitInfo(commit, &p.graph)
-	p.Commits = append(p.Commits, info)
+	parents := commit.ParentHashes
+	if p.first && len(parents) > 1 {
+		parents = parents[:1]
+	}
+	cells := p.graph.Render(commit.Hash, parents)
+	p.indexChild(commit.Hash, parents)
+	if p.lists(commit, len(commit.ParentHashes)) {
+		p.ready = append(p.ready, p.buildCommitInfo(commit, cells))
+		p.walked++
+	}
 
-	if p.limit > 0 && len(p.Commits) >= p.limit {
+	if p.limit > 0 && p.walked >= p.limit {
 		return nil
 	}
 
-	for _, parent := range commit.ParentHashes {
+	for _, parent := range parents {
 		if p.seen[parent] {
 			continue
 		}
-		parentCommit, err := p.repo.CommitObject(parent)
+		parentCommit, err := p.replace.Commit(p.walkRepo, parent)
 		if err != nil {
 			continue
 		}
@@ -111,8 +643,37 @@ func (p *CommitProvider) loadNext() error {
 	return nil
 }
 
-func gatherTips(repo *git.Repository, includeAll bool) ([]plumbing.Hash, error) {
+func (p *CommitProvider) loadNextListed() error {
+	hash := p.order[p.next]
+	p.next++
+	commit, err := p.replace.Commit(p.walkRepo, hash)
+	if err != nil {
+		return fmt.Errorf("load commit %s: %w", hash, err)
+	}
+	parents := make([]plumbing.Hash, 0, len(commit.ParentHashes))
+	for _, parent := range commit.ParentHashes {
+		if p.listed[parent] {
+			parents = append(parents, parent)
+		}
+	}
+	cells := p.graph.Render(hash, parents)
+	p.indexChild(hash, parents)
+	if p.lists(commit, len(commit.ParentHashes)) {
+		p.ready = append(p.ready, p.buildCommitInfo(commit, cells))
+		p.walked++
+	}
+	return nil
+}
+
+func gatherTips(repo *git.Repository, scope Scope) ([]plumbing.Hash, error) {
 	var tips []plumbing.Hash
+	if scope == ScopeHead {
+		head, err := repo.Head()
+		if err != nil {
+			return nil, nil
+		}
+		return []plumbing.Hash{head.Hash()}, nil
+	}
 	iter, err := repo.References()
 	if err != nil {
 		return nil, err
@@ -121,7 +682,7 @@ func gatherTips(repo *git.Repository, includeAll bool) ([]plumbing.Hash, error)
 
 	_ = iter.ForEach(func(ref *plumbing.Reference) error {
 		name := ref.Name()
-		if !includeAll {
+		if scope != ScopeAll {
 			if !name.IsBranch() && name != plumbing.HEAD {
 				return nil
 			}
@@ -144,90 +705,255 @@ func gatherTips(repo *git.Repository, includeAll bool) ([]plumbing.Hash, error)
 	return tips, nil
 }
 
-func buildCommitInfo(commit *object.Commit, graph *graphState) *CommitInfo {
-	subject := firstLine(commit.Message)
-	cells := graph.Render(commit)
+// IndexChildren records, as history loads, which commits name each commit
+// as a parent, so Children can walk history forwards. Commits loaded
+// before the call are indexed too, except those the parent filter hid.
+func (p *CommitProvider) IndexChildren() {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	if p.children != nil {
+		return
+	}
+	p.children = make(map[plumbing.Hash][]plumbing.Hash)
+	for _, commit := range slices.Concat(p.Commits, p.ready) {
+		if commit.Commit == nil {
+			continue
+		}
+		parents := commit.Commit.ParentHashes
+		if p.listed != nil {
+			parents = slices.DeleteFunc(slices.Clone(parents), func(h plumbing.Hash) bool { return !p.listed[h] })
+		}
+		p.indexChild(commit.Hash, parents)
+	}
+}
+
+// Children are the loaded commits that have hash as a parent, in the order
+// they loaded, or nil without IndexChildren. The walk reaches a commit's
+// children before the commit itself, except across skewed clocks in date
+// order, so they are known by the time it is listed; commits no walked ref
+// reaches are never among them.
+func (p *CommitProvider) Children(hash plumbing.Hash) []plumbing.Hash {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	return slices.Clone(p.children[hash])
+}
+
+func (p *CommitProvider) indexChild(hash plumbing.Hash, parents []plumbing.Hash) {
+	if p.children == nil {
+		return
+	}
+	for _, parent := range parents {
+		p.children[parent] = append(p.children[parent], hash)
+	}
+}
+
+func (p *CommitProvider) buildCommitInfo(commit *object.Commit, cells []GraphCell) *CommitInfo {
+	// Clone, or the substrings keep the whole message alive.
+	subject := strings.Clone(FirstLine(commit.Message))
+	first, _, _ := strings.Cut(commit.Message, "\n")
+	commit.Message = strings.Clone(first)
+	commit.PGPSignature = ""
+	if p.walkRepo != p.repo {
+		commit = rebind(commit, p.repo.Storer)
+	}
 	return &CommitInfo{
-		Hash:      commit.Hash,
-		ShortHash: commit.Hash.String()[:7],
-		Subject:   subject,
-		Author:    commit.Author.Name,
-		When:      commit.Committer.When,
-		Graph:     cells,
-		Commit:    commit,
+		Hash:     commit.Hash,
+		Subject:  subject,
+		Author:   commit.Author.Name,
+		When:     commit.Committer.When,
+		Graph:    cells,
+		Commit:   commit,
+		provider: p,
+	}
+}
+
+// ownHandle opens repo's object store again, for a goroutine to read
+// through alone. Storage that is not on disk is shared.
+func ownHandle(repo *git.Repository) *git.Repository {
+	fs, ok := repo.Storer.(*filesystem.Storage)
+	if !ok {
+		return repo
 	}
+	own, err := git.Open(filesystem.NewStorage(fs.Filesystem(), cache.NewObjectLRUDefault()), nil)
+	if err != nil {
+		return repo
+	}
+	return own
+}
+
+// rebind decodes commit again on storer s, so what is read through it
+// later, such as its tree, goes through s and not the handle that read
+// it. The hash is kept, for a replaced commit.
+func rebind(commit *object.Commit, s storer.EncodedObjectStorer) *object.Commit {
+	obj := &plumbing.MemoryObject{}
+	if err := commit.Encode(obj); err != nil {
+		return commit
+	}
+	bound, err := object.DecodeCommit(s, obj)
+	if err != nil {
+		return commit
+	}
+	bound.Hash = commit.Hash
+	return bound
 }
 
-func firstLine(message string) string {
+func FirstLine(message string) string {
 	parts := strings.SplitN(message, "\n", 2)
 	return strings.TrimSpace(parts[0])
 }
 
+// Relayout lays the commits out again in the order given, the way the walk
+// did, so layout can be timed apart from traversal (arbor bench).
+func Relayout(commits []*CommitInfo) [][]GraphCell {
+	var g graphState
+	cells := make([][]GraphCell, len(commits))
+	for i, commit := range commits {
+		var parents []plumbing.Hash
+		if commit.Commit != nil {
+			parents = commit.Commit.ParentHashes
+		}
+		cells[i] = g.Render(commit.Hash, parents)
+	}
+	return cells
+}
+
+// graphState assigns lanes as fixed slots: a lane keeps its column until
+// it ends, and ended lanes leave a gap that the next new lane reuses, so
+// nothing shifts sideways as history loads.
 type graphState struct {
 	columns []plumbing.Hash
+	// opened records the slot each lane-opening commit (a tip) got; lanes
+	// is the previous walk's record, which a Reload replays.
+	opened map[plumbing.Hash]int
+	lanes  map[plumbing.Hash]int
 }
 
-func (g *graphState) Render(commit *object.Commit) []GraphCell {
-	idx := indexOfHash(g.columns, commit.Hash)
+func (g *graphState) Render(hash plumbing.Hash, parents []plumbing.Hash) []GraphCell {
+	idx := indexOfHash(g.columns, hash)
 	if idx == -1 {
-		g.columns = append([]plumbing.Hash{commit.Hash}, g.columns...)
-		idx = 0
+		idx = g.openLane(hash, parents)
 	}
-	parents := commit.ParentHashes
 	preLen := len(g.columns)
-	postLen := preLen
-	if len(parents) > 1 {
-		postLen = preLen + (len(parents) - 1)
-	}
-	cells := make([]GraphCell, postLen)
-	for i := 0; i < postLen; i++ {
-		cells[i] = GraphCell{Ch: "|", Color: i}
-	}
-	if idx < len(cells) {
-		cells[idx].Ch = "*"
-	}
-	if len(parents) > 1 {
-		for i := 1; i < len(parents); i++ {
-			pos := idx + i
-			if pos < len(cells) {
-				cells[pos].Ch = "\\"
+
+	var merges []int
+	if len(parents) > 0 {
+		g.columns[idx] = parents[0]
+		for _, parent := range parents[1:] {
+			if indexOfHash(g.columns, parent) >= 0 {
+				continue
 			}
+			slot := g.freeSlot(idx+1, nil)
+			g.columns[slot] = parent
+			merges = append(merges, slot)
 		}
+	} else {
+		g.columns[idx] = plumbing.ZeroHash
 	}
 
-	if len(parents) == 0 {
-		g.columns = append(g.columns[:idx], g.columns[idx+1:]...)
-	} else {
-		g.columns[idx] = parents[0]
-		for i := 1; i < len(parents); i++ {
-			insertAt := idx + i
-			g.columns = append(g.columns[:insertAt], append([]plumbing.Hash{parents[i]}, g.columns[insertAt:]...)...)
+	cells := make([]GraphCell, max(preLen, len(g.columns)))
+	for i := range cells {
+		cells[i] = GraphCell{Ch: " ", Color: i}
+		if i < preLen && !g.columns[i].IsZero() || i == idx {
+			cells[i].Ch = "|"
 		}
 	}
-	g.columns = dedupeHashes(g.columns)
+	for _, slot := range merges {
+		cells[slot].Ch = "\\"
+	}
+	cells[idx].Ch = "*"
+
+	g.dedupe()
 	return cells
 }
 
-func indexOfHash(list []plumbing.Hash, target plumbing.Hash) int {
-	for i, h := range list {
-		if h == target {
+// openLane places a commit no child has claimed a column for. After a
+// Reload, a tip the old walk knew reopens in its old slot, and a new commit
+// on an old tip takes that tip's slot; any other new lane avoids the slots
+// the old walk used, so existing lanes do not move.
+func (g *graphState) openLane(hash plumbing.Hash, parents []plumbing.Hash) int {
+	slot, ok := g.lanes[hash]
+	if !ok && len(parents) > 0 {
+		slot, ok = g.lanes[parents[0]]
+	}
+	if !ok || slot < len(g.columns) && !g.columns[slot].IsZero() {
+		slot = g.freeSlot(0, g.lanes)
+	}
+	for len(g.columns) <= slot {
+		g.columns = append(g.columns, plumbing.ZeroHash)
+	}
+	g.columns[slot] = hash
+	if g.opened == nil {
+		g.opened = make(map[plumbing.Hash]int)
+	}
+	g.opened[hash] = slot
+	return slot
+}
+
+// freeSlot returns the first empty column at or after from that no tip in
+// reserved claims, growing the columns if needed.
+func (g *graphState) freeSlot(from int, reserved map[plumbing.Hash]int) int {
+	taken := make(map[int]bool, len(reserved))
+	for _, slot := range reserved {
+		taken[slot] = true
+	}
+	for i := from; ; i++ {
+		if i >= len(g.columns) {
+			g.columns = append(g.columns, plumbing.ZeroHash)
+		}
+		if g.columns[i].IsZero() && !taken[i] {
 			return i
 		}
 	}
-	return -1
 }
 
-func dedupeHashes(list []plumbing.Hash) []plumbing.Hash {
-	seen := make(map[plumbing.Hash]bool, len(list))
-	out := make([]plumbing.Hash, 0, len(list))
-	for _, h := range list {
-		if seen[h] {
+// dedupe ends lanes that now lead to a commit another lane already
+// reaches, and trims empty columns off the right edge.
+func (g *graphState) dedupe() {
+	seen := make(map[plumbing.Hash]bool, len(g.columns))
+	for i, h := range g.columns {
+		if h.IsZero() {
 			continue
 		}
+		if seen[h] {
+			g.columns[i] = plumbing.ZeroHash
+		}
 		seen[h] = true
-		out = append(out, h)
 	}
-	return out
+	for n := len(g.columns); n > 0 && g.columns[n-1].IsZero(); n-- {
+		g.columns = g.columns[:n-1]
+	}
+}
+
+func indexOfHash(list []plumbing.Hash, target plumbing.Hash) int {
+	for i, h := range list {
+		if h == target {
+			return i
+		}
+	}
+	return -1
+}
+
+// walkHeap pops the next commit the walk lists, by less.
+type walkHeap struct {
+	commits []*object.Commit
+	less    func(a, b *object.Commit) bool
+}
+
+func (h walkHeap) Len() int           { return len(h.commits) }
+func (h walkHeap) Less(i, j int) bool { return h.less(h.commits[i], h.commits[j]) }
+func (h walkHeap) Swap(i, j int)      { h.commits[i], h.commits[j] = h.commits[j], h.commits[i] }
+func (h *walkHeap) Push(x interface{}) {
+	h.commits = append(h.commits, x.(*object.Commit))
+}
+func (h *walkHeap) Pop() interface{} {
+	n := len(h.commits)
+	item := h.commits[n-1]
+	h.commits = h.commits[:n-1]
+	return item
+}
+
+func dateOrder(a, b *object.Commit) bool {
+	return commitHeap{a, b}.Less(0, 1)
 }
 
 type commitHeap []*object.Commit
@@ -0,0 +1,74 @@
+package gitgraph
+
+import (
+	"sort"
+
+	git "github.com/go-git/go-git/v5"
+	"github.com/go-git/go-git/v5/plumbing"
+	"github.com/go-git/go-git/v5/plumbing/object"
+)
+
+type pruneBase struct {
+	name   string
+	commit *object.Commit
+}
+
+type PruneCandidate struct {
+	Branch string
+	Tip    *object.Commit
+	Reason string
+}
+
+// PruneCandidates lists local branches that are fully merged into main (or
+// master) or HEAD, or whose configured upstream no longer exists. The
+// checked-out branch and the base branches themselves are never offered.
+func PruneCandidates(repo *git.Repository, gens *Generations) ([]PruneCandidate, error) {
+	head, err := repo.Head()
+	if err != nil {
+		return nil, err
+	}
+	headCommit, err := gens.Commit(head.Hash())
+	if err != nil {
+		return nil, err
+	}
+	var bases []pruneBase
+	if name, commit, ok := DefaultBranch(repo); ok {
+		bases = append(bases, pruneBase{name, commit})
+	}
+	bases = append(bases, pruneBase{"HEAD", headCommit})
+
+	refs, err := ListRefs(repo)
+	if err != nil {
+		return nil, err
+	}
+
+	var candidates []PruneCandidate
+	for _, ref := range refs {
+		if ref.Kind != RefBranch || ref.Name == head.Name().String() || ref.Short == "main" || ref.Short == "master" {
+			continue
+		}
+		tip, err := gens.Commit(ref.Hash)
+		if err != nil {
+			continue
+		}
+		reason := ""
+		for _, base := range bases {
+			if merged, err := IsAncestor(gens, tip, base.commit); err == nil && merged {
+				reason = "merged into " + base.name
+				break
+			}
+		}
+		if reason == "" {
+			if upstream, ok := UpstreamRef(repo, ref.Short); ok {
+				if _, err := repo.Reference(plumbing.ReferenceName(upstream), true); err != nil {
+					reason = "upstream " + plumbing.ReferenceName(upstream).Short() + " is gone"
+				}
+			}
+		}
+		if reason != "" {
+			candidates = append(candidates, PruneCandidate{Branch: ref.Short, Tip: tip, Reason: reason})
+		}
+	}
+	sort.Slice(candidates, func(i, j int) bool { return candidates[i].Branch < candidates[j].Branch })
+	return candidates, nil
+}
@@ -0,0 +1,60 @@
+package gitgraph
+
+import (
+	"fmt"
+
+	git "github.com/go-git/go-git/v5"
+	"github.com/go-git/go-git/v5/plumbing"
+	"github.com/go-git/go-git/v5/plumbing/object"
+)
+
+type AncestryResult struct {
+	A          *object.Commit
+	B          *object.Commit
+	IsAncestor bool
+	MergeBases []*object.Commit
+}
+
+func ResolveCommit(repo *git.Repository, rev string) (*object.Commit, error) {
+	hash, ok, err := resolveReflogRev(repo, rev)
+	if !ok {
+		var resolved *plumbing.Hash
+		resolved, err = repo.ResolveRevision(plumbing.Revision(rev))
+		if resolved != nil {
+			hash = *resolved
+		}
+	}
+	if err != nil {
+		return nil, fmt.Errorf("resolve %q: %w", rev, err)
+	}
+	commit, err := repo.CommitObject(hash)
+	if err != nil {
+		return nil, fmt.Errorf("load commit %s: %w", hash, err)
+	}
+	return commit, nil
+}
+
+// IsAncestor reports whether a is b or one of b's ancestors.
+func IsAncestor(gens *Generations, a, b *object.Commit) (bool, error) {
+	return gens.Reaches(b.Hash, a.Hash)
+}
+
+func MergeBases(repo *git.Repository, gens *Generations, a, b *object.Commit) ([]*object.Commit, error) {
+	hashes, err := gens.MergeBases(a.Hash, b.Hash)
+	if err != nil {
+		return nil, err
+	}
+	return gens.commits(hashes)
+}
+
+func QueryAncestry(repo *git.Repository, gens *Generations, a, b *object.Commit) (*AncestryResult, error) {
+	ancestor, err := IsAncestor(gens, a, b)
+	if err != nil {
+		return nil, err
+	}
+	bases, err := MergeBases(repo, gens, a, b)
+	if err != nil {
+		return nil, err
+	}
+	return &AncestryResult{A: a, B: b, IsAncestor: ancestor, MergeBases: bases}, nil
+}
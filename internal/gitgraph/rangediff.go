@@ -0,0 +1,261 @@
+package gitgraph
+
+import (
+	"crypto/sha1"
+	"fmt"
+	"strings"
+
+	git "github.com/go-git/go-git/v5"
+	"github.com/go-git/go-git/v5/plumbing"
+	"github.com/go-git/go-git/v5/plumbing/object"
+	"github.com/sergi/go-diff/diffmatchpatch"
+)
+
+type RangeStatus string
+
+const (
+	RangeSame     RangeStatus = "="
+	RangeModified RangeStatus = "!"
+	RangeDropped  RangeStatus = "<"
+	RangeAdded    RangeStatus = ">"
+)
+
+// RangePair is one line of a range-diff. Old or New is nil for dropped and
+// added commits; the indexes are 1-based positions within each range.
+type RangePair struct {
+	Status   RangeStatus
+	Old      *object.Commit
+	New      *object.Commit
+	OldIndex int
+	NewIndex int
+}
+
+type rangeCommit struct {
+	commit  *object.Commit
+	patchID [sha1.Size]byte
+	matched bool
+}
+
+// RangeDiff compares the commits unique to old with those unique to new, the
+// way `git range-diff old...new` does. Commits pair up by identical patch,
+// then by subject; a pair is modified unless patch and message both match.
+func RangeDiff(repo *git.Repository, gens *Generations, old, new plumbing.Hash) ([]RangePair, error) {
+	oldOnly, newOnly, err := SymmetricDifference(repo, gens, old, new)
+	if err != nil {
+		return nil, err
+	}
+	oldRange, err := rangeCommits(oldOnly)
+	if err != nil {
+		return nil, err
+	}
+	newRange, err := rangeCommits(newOnly)
+	if err != nil {
+		return nil, err
+	}
+
+	match := make([]int, len(newRange))
+	status := make([]RangeStatus, len(newRange))
+	for i := range match {
+		match[i] = -1
+	}
+	for i, n := range newRange {
+		for j, o := range oldRange {
+			if !o.matched && o.patchID == n.patchID {
+				o.matched, match[i], status[i] = true, j, RangeSame
+				if strings.TrimSpace(o.commit.Message) != strings.TrimSpace(n.commit.Message) {
+					status[i] = RangeModified
+				}
+				break
+			}
+		}
+	}
+	for i, n := range newRange {
+		if match[i] >= 0 {
+			continue
+		}
+		subject := FirstLine(n.commit.Message)
+		for j, o := range oldRange {
+			if !o.matched && FirstLine(o.commit.Message) == subject {
+				o.matched, match[i], status[i] = true, j, RangeModified
+				break
+			}
+		}
+	}
+
+	var pairs []RangePair
+	emitted := 0
+	dropUpTo := func(limit int) {
+		for ; emitted < limit; emitted++ {
+			if o := oldRange[emitted]; !o.matched {
+				pairs = append(pairs, RangePair{Status: RangeDropped, Old: o.commit, OldIndex: emitted + 1})
+			}
+		}
+	}
+	for i, n := range newRange {
+		if match[i] < 0 {
+			pairs = append(pairs, RangePair{Status: RangeAdded, New: n.commit, NewIndex: i + 1})
+			continue
+		}
+		dropUpTo(match[i])
+		pairs = append(pairs, RangePair{
+			Status:   status[i],
+			Old:      oldRange[match[i]].commit,
+			New:      n.commit,
+			OldIndex: match[i] + 1,
+			NewIndex: i + 1,
+		})
+	}
+	dropUpTo(len(oldRange))
+	return pairs, nil
+}
+
+// rangeCommits orders commits parents-first and normalizes their patches.
+func rangeCommits(commits []*object.Commit) ([]*rangeCommit, error) {
+	out := make([]*rangeCommit, 0, len(commits))
+	for _, commit := range topoOldestFirst(commits) {
+		patch, err := normalizedPatch(commit)
+		if err != nil {
+			return nil, err
+		}
+		out = append(out, &rangeCommit{commit: commit, patchID: sha1.Sum([]byte(patch))})
+	}
+	return out, nil
+}
+
+// topoOldestFirst sorts newest-first commits so every commit follows its
+// parents within the set; commit dates alone break down for commits made
+// within the same second, as rebases produce.
+func topoOldestFirst(commits []*object.Commit) []*object.Commit {
+	inSet := make(map[plumbing.Hash]*object.Commit, len(commits))
+	for _, commit := range commits {
+		inSet[commit.Hash] = commit
+	}
+	done := make(map[plumbing.Hash]bool, len(commits))
+	out := make([]*object.Commit, 0, len(commits))
+	var visit func(commit *object.Commit)
+	visit = func(commit *object.Commit) {
+		if done[commit.Hash] {
+			return
+		}
+		done[commit.Hash] = true
+		for _, parent := range commit.ParentHashes {
+			if p, ok := inSet[parent]; ok {
+				visit(p)
+			}
+		}
+		out = append(out, commit)
+	}
+	for i := len(commits) - 1; i >= 0; i-- {
+		visit(commits[i])
+	}
+	return out
+}
+
+// normalizedPatch drops blob ids and hunk line numbers so a commit replayed
+// onto a different base still produces the same text.
+func normalizedPatch(commit *object.Commit) (string, error) {
+	patch, err := CommitPatch(commit)
+	if err != nil {
+		return "", err
+	}
+	lines := strings.Split(patch.String(), "\n")
+	out := lines[:0]
+	for _, line := range lines {
+		switch {
+		case strings.HasPrefix(line, "index "):
+			continue
+		case strings.HasPrefix(line, "@@"):
+			if end := strings.Index(line[2:], "@@"); end >= 0 {
+				line = "@@" + line[end+4:]
+			}
+		}
+		out = append(out, line)
+	}
+	return strings.Join(out, "\n"), nil
+}
+
+// Interdiff shows how the patch of a modified pair changed: a line diff of
+// the two normalized patches with context lines around each change.
+func Interdiff(old, new *object.Commit, context int) ([]string, error) {
+	oldPatch, err := normalizedPatch(old)
+	if err != nil {
+		return nil, err
+	}
+	newPatch, err := normalizedPatch(new)
+	if err != nil {
+		return nil, err
+	}
+	message := messageDiff(old.Message, new.Message)
+
+	dmp := diffmatchpatch.New()
+	a, b, lines := dmp.DiffLinesToRunes(oldPatch, newPatch)
+	diffs := dmp.DiffCharsToLines(dmp.DiffMainRunes(a, b, false), lines)
+
+	var all []string
+	var changed []bool
+	for _, d := range diffs {
+		prefix := " "
+		switch d.Type {
+		case diffmatchpatch.DiffDelete:
+			prefix = "-"
+		case diffmatchpatch.DiffInsert:
+			prefix = "+"
+		}
+		for _, line := range strings.Split(strings.TrimSuffix(d.Text, "\n"), "\n") {
+			all = append(all, prefix+line)
+			changed = append(changed, prefix != " ")
+		}
+	}
+
+	out := message
+	last := -1
+	for i := range all {
+		keep := false
+		for j := max(0, i-context); j <= min(len(all)-1, i+context); j++ {
+			if changed[j] {
+				keep = true
+				break
+			}
+		}
+		if !keep {
+			continue
+		}
+		if last >= 0 && i > last+1 {
+			out = append(out, "@@")
+		}
+		out = append(out, all[i])
+		last = i
+	}
+	return out, nil
+}
+
+func messageDiff(old, new string) []string {
+	if strings.TrimSpace(old) == strings.TrimSpace(new) {
+		return nil
+	}
+	var out []string
+	for _, line := range strings.Split(strings.TrimSpace(old), "\n") {
+		out = append(out, "-"+line)
+	}
+	for _, line := range strings.Split(strings.TrimSpace(new), "\n") {
+		out = append(out, "+"+line)
+	}
+	return append(out, "")
+}
+
+// FormatRangePair renders a pair like git: "1: abc1234 ! 1: def5678 subject".
+func FormatRangePair(pair RangePair) string {
+	side := func(index int, commit *object.Commit) string {
+		if commit == nil {
+			return "-: -------"
+		}
+		return fmt.Sprintf("%d: %s", index, commit.Hash.String()[:7])
+	}
+	subject := ""
+	if pair.New != nil {
+		subject = FirstLine(pair.New.Message)
+	} else if pair.Old != nil {
+		subject = FirstLine(pair.Old.Message)
+	}
+	return fmt.Sprintf("%s %s %s %s", side(pair.OldIndex, pair.Old), pair.Status, side(pair.NewIndex, pair.New), subject)
+}
@@ -0,0 +1,137 @@
+package gitgraph
+
+import (
+	"bufio"
+	"fmt"
+	"os"
+	"path/filepath"
+	"regexp"
+	"strconv"
+	"strings"
+	"time"
+
+	git "github.com/go-git/go-git/v5"
+	"github.com/go-git/go-git/v5/plumbing"
+)
+
+type ReflogEntry struct {
+	Old     plumbing.Hash
+	New     plumbing.Hash
+	Name    string
+	Email   string
+	When    time.Time
+	Message string
+}
+
+// ReadReflog parses the reflog of a full ref name (or HEAD), newest entry
+// first. go-git does not read reflogs, so this goes to the files directly.
+func ReadReflog(repo *git.Repository, refName string) ([]ReflogEntry, error) {
+	gitDir := GitDir(repo)
+	if gitDir == "" {
+		return nil, fmt.Errorf("reflog: repository has no on-disk git directory")
+	}
+	f, err := os.Open(filepath.Join(gitDir, "logs", filepath.FromSlash(refName)))
+	if os.IsNotExist(err) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	defer f.Close()
+
+	var entries []ReflogEntry
+	scanner := bufio.NewScanner(f)
+	scanner.Buffer(make([]byte, 64*1024), 1024*1024)
+	for scanner.Scan() {
+		if entry, ok := parseReflogLine(scanner.Text()); ok {
+			entries = append(entries, entry)
+		}
+	}
+	if err := scanner.Err(); err != nil {
+		return nil, err
+	}
+	for i, j := 0, len(entries)-1; i < j; i, j = i+1, j-1 {
+		entries[i], entries[j] = entries[j], entries[i]
+	}
+	return entries, nil
+}
+
+// parseReflogLine reads "<old> <new> Name <email> <unix> <tz>\t<message>".
+func parseReflogLine(line string) (ReflogEntry, bool) {
+	head, message, _ := strings.Cut(line, "\t")
+	if len(head) < 82 {
+		return ReflogEntry{}, false
+	}
+	entry := ReflogEntry{
+		Old:     plumbing.NewHash(head[:40]),
+		New:     plumbing.NewHash(head[41:81]),
+		Message: message,
+	}
+	who := head[82:]
+	open, close := strings.LastIndex(who, "<"), strings.LastIndex(who, ">")
+	if open < 0 || close < open {
+		return ReflogEntry{}, false
+	}
+	entry.Name = strings.TrimSpace(who[:open])
+	entry.Email = who[open+1 : close]
+	if fields := strings.Fields(who[close+1:]); len(fields) == 2 {
+		if secs, err := strconv.ParseInt(fields[0], 10, 64); err == nil {
+			entry.When = time.Unix(secs, 0)
+			if tz, err := time.Parse("-0700", fields[1]); err == nil {
+				entry.When = entry.When.In(tz.Location())
+			}
+		}
+	}
+	return entry, true
+}
+
+var reflogRevRe = regexp.MustCompile(`^(.*)@\{(\d+)\}$`)
+
+// resolveReflogRev handles "<ref>@{n}", which go-git's revision parser does
+// not: the value the ref had n updates ago. An empty ref means HEAD's branch.
+func resolveReflogRev(repo *git.Repository, rev string) (plumbing.Hash, bool, error) {
+	match := reflogRevRe.FindStringSubmatch(rev)
+	if match == nil {
+		return plumbing.ZeroHash, false, nil
+	}
+	n, _ := strconv.Atoi(match[2])
+	refName, err := fullRefName(repo, match[1])
+	if err != nil {
+		return plumbing.ZeroHash, true, err
+	}
+	entries, err := ReadReflog(repo, refName)
+	if err != nil {
+		return plumbing.ZeroHash, true, err
+	}
+	if n == 0 && len(entries) > 0 {
+		return entries[0].New, true, nil
+	}
+	if n > len(entries) || n == 0 {
+		return plumbing.ZeroHash, true, fmt.Errorf("%s has only %d reflog entries", rev, len(entries))
+	}
+	return entries[n-1].Old, true, nil
+}
+
+func fullRefName(repo *git.Repository, name string) (string, error) {
+	switch name {
+	case "":
+		head, err := repo.Head()
+		if err != nil {
+			return "", err
+		}
+		return head.Name().String(), nil
+	case "HEAD":
+		return "HEAD", nil
+	}
+	for _, candidate := range []plumbing.ReferenceName{
+		plumbing.ReferenceName(name),
+		plumbing.NewBranchReferenceName(name),
+		plumbing.ReferenceName("refs/remotes/" + name),
+		plumbing.NewTagReferenceName(name),
+	} {
+		if _, err := repo.Reference(candidate, false); err == nil {
+			return candidate.String(), nil
+		}
+	}
+	return "", fmt.Errorf("unknown ref %q", name)
+}
@@ -0,0 +1,173 @@
+package gitgraph
+
+import (
+	"fmt"
+	"hash/fnv"
+	"sort"
+	"strings"
+
+	git "github.com/go-git/go-git/v5"
+	"github.com/go-git/go-git/v5/plumbing"
+	"github.com/go-git/go-git/v5/storage/filesystem"
+)
+
+type RefKind string
+
+const (
+	RefHead   RefKind = "head"
+	RefBranch RefKind = "branch"
+	RefRemote RefKind = "remote"
+	RefTag    RefKind = "tag"
+	RefPull   RefKind = "pull"
+)
+
+type Ref struct {
+	Name   string
+	Short  string
+	Kind   RefKind
+	Hash   plumbing.Hash
+	Target plumbing.Hash
+}
+
+// ListRefs returns HEAD, branches, remote-tracking branches, and tags. Target
+// is the commit a ref ultimately points at, peeling annotated tags.
+func ListRefs(repo *git.Repository) ([]Ref, error) {
+	iter, err := repo.References()
+	if err != nil {
+		return nil, err
+	}
+	defer iter.Close()
+
+	var refs []Ref
+	_ = iter.ForEach(func(ref *plumbing.Reference) error {
+		name := ref.Name()
+		short := name.Short()
+		var kind RefKind
+		switch {
+		case isPullRequest(name):
+			kind = RefPull
+			number, _ := PullRequestNumber(name)
+			short = "PR #" + number
+		case name == plumbing.HEAD:
+			kind = RefHead
+		case name.IsBranch():
+			kind = RefBranch
+		case name.IsRemote():
+			kind = RefRemote
+		case name.IsTag():
+			kind = RefTag
+		default:
+			return nil
+		}
+		resolved := ref
+		if ref.Type() == plumbing.SymbolicReference {
+			r, err := repo.Reference(name, true)
+			if err != nil {
+				return nil
+			}
+			resolved = r
+		}
+		refs = append(refs, Ref{
+			Name:   name.String(),
+			Short:  short,
+			Kind:   kind,
+			Hash:   resolved.Hash(),
+			Target: peel(repo, resolved.Hash()),
+		})
+		return nil
+	})
+	sort.Slice(refs, func(i, j int) bool { return refs[i].Name < refs[j].Name })
+	return refs, nil
+}
+
+// PullRequestNumber recognizes the head of a pull request fetched from
+// GitHub (refs/pull/123/head) or a merge request from GitLab
+// (refs/merge-requests/123/head), also when a refspec maps them under a
+// remote, as in refs/remotes/origin/pull/123/head or refs/remotes/origin/pr/123.
+func PullRequestNumber(name plumbing.ReferenceName) (string, bool) {
+	parts := strings.Split(name.String(), "/")
+	n := len(parts)
+	switch {
+	case n >= 4 && parts[n-1] == "head" && (parts[n-3] == "pull" || parts[n-3] == "merge-requests"):
+		return parts[n-2], isNumber(parts[n-2])
+	case n >= 5 && parts[1] == "remotes" && (parts[n-2] == "pr" || parts[n-2] == "mr"):
+		return parts[n-1], isNumber(parts[n-1])
+	}
+	return "", false
+}
+
+func isPullRequest(name plumbing.ReferenceName) bool {
+	_, ok := PullRequestNumber(name)
+	return ok
+}
+
+func isNumber(s string) bool {
+	if s == "" {
+		return false
+	}
+	for _, r := range s {
+		if r < '0' || r > '9' {
+			return false
+		}
+	}
+	return true
+}
+
+func peel(repo *git.Repository, hash plumbing.Hash) plumbing.Hash {
+	for i := 0; i < 8; i++ {
+		tag, err := repo.TagObject(hash)
+		if err != nil {
+			return hash
+		}
+		hash = tag.Target
+	}
+	return hash
+}
+
+// GitDir returns the on-disk .git directory, or "" for in-memory storage.
+func GitDir(repo *git.Repository) string {
+	fs, ok := repo.Storer.(*filesystem.Storage)
+	if !ok {
+		return ""
+	}
+	return fs.Filesystem().Root()
+}
+
+// HeadLabel names what HEAD points at for display: the branch, or the
+// abbreviated commit when detached.
+func HeadLabel(repo *git.Repository) string {
+	head, err := repo.Head()
+	if err != nil {
+		return ""
+	}
+	if head.Name().IsBranch() {
+		return head.Name().Short()
+	}
+	hash := head.Hash()
+	if hash.IsZero() {
+		return "detached"
+	}
+	return "detached@" + hash.String()[:ShortLength(repo)]
+}
+
+// RefState summarizes where HEAD and every ref point; it changes whenever
+// one of them moves, is created, or is deleted, as when commits are made
+// outside arbor.
+func RefState(repo *git.Repository) string {
+	iter, err := repo.References()
+	if err != nil {
+		return ""
+	}
+	defer iter.Close()
+	var refs []string
+	_ = iter.ForEach(func(ref *plumbing.Reference) error {
+		refs = append(refs, ref.String())
+		return nil
+	})
+	sort.Strings(refs)
+	sum := fnv.New64a()
+	for _, ref := range refs {
+		sum.Write([]byte(ref + "\n"))
+	}
+	return fmt.Sprintf("%x", sum.Sum64())
+}
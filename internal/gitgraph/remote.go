@@ -0,0 +1,136 @@
+package gitgraph
+
+import (
+	"fmt"
+	"net/url"
+	"sort"
+	"strings"
+
+	git "github.com/go-git/go-git/v5"
+	"github.com/go-git/go-git/v5/plumbing"
+)
+
+// WebURL turns a remote's fetch URL (https, ssh://, or scp-style) into the
+// https address of the repository's web page.
+func WebURL(remote string) (string, bool) {
+	remote = strings.TrimSpace(remote)
+	var host, path string
+	if u, err := url.Parse(remote); err == nil && u.Scheme != "" && u.Host != "" {
+		switch u.Scheme {
+		case "http", "https", "ssh", "git", "git+ssh", "ssh+git":
+		default:
+			return "", false
+		}
+		host, path = u.Hostname(), u.Path
+	} else if at, rest, ok := strings.Cut(remote, ":"); ok && len(at) > 1 && !strings.Contains(at, "/") {
+		host = at[strings.LastIndex(at, "@")+1:]
+		path = rest
+	} else {
+		return "", false
+	}
+	path = strings.TrimSuffix(strings.Trim(path, "/"), ".git")
+	if host == "" || path == "" {
+		return "", false
+	}
+	return "https://" + host + "/" + path, true
+}
+
+// CommitURL links to hash on the web UI of origin, or of the first remote
+// by name when there is no origin.
+func CommitURL(repo *git.Repository, hash plumbing.Hash) (string, error) {
+	remotes, err := repo.Remotes()
+	if err != nil {
+		return "", err
+	}
+	if len(remotes) == 0 {
+		return "", fmt.Errorf("no remotes configured")
+	}
+	sort.Slice(remotes, func(i, j int) bool {
+		a, b := remotes[i].Config().Name, remotes[j].Config().Name
+		if (a == "origin") != (b == "origin") {
+			return a == "origin"
+		}
+		return a < b
+	})
+	cfg := remotes[0].Config()
+	if len(cfg.URLs) == 0 {
+		return "", fmt.Errorf("remote %s has no URL", cfg.Name)
+	}
+	base, ok := WebURL(cfg.URLs[0])
+	if !ok {
+		return "", fmt.Errorf("remote %s (%s) has no web address", cfg.Name, cfg.URLs[0])
+	}
+	switch {
+	case strings.Contains(base, "gitlab"):
+		return base + "/-/commit/" + hash.String(), nil
+	case strings.Contains(base, "bitbucket.org"):
+		return base + "/commits/" + hash.String(), nil
+	}
+	return base + "/commit/" + hash.String(), nil
+}
+
+// RemoteTip is one remote's copy of a branch, compared with the local
+// branch: Ahead counts local commits the remote lacks, Behind the remote's
+// commits not merged locally.
+type RemoteTip struct {
+	Remote string
+	Branch string
+	Hash   plumbing.Hash
+	Ahead  int
+	Behind int
+}
+
+func (t RemoteTip) Name() string {
+	return t.Remote + "/" + t.Branch
+}
+
+// Marker is the compact comparison: ✓ when the remote has exactly the local
+// branch, otherwise ↑ahead and ↓behind, as in "↑3" or "↑1↓2".
+func (t RemoteTip) Marker() string {
+	if t.Ahead == 0 && t.Behind == 0 {
+		return "✓"
+	}
+	marker := ""
+	if t.Ahead > 0 {
+		marker += fmt.Sprintf("↑%d", t.Ahead)
+	}
+	if t.Behind > 0 {
+		marker += fmt.Sprintf("↓%d", t.Behind)
+	}
+	return marker
+}
+
+// RemoteTips finds branch on every remote that has it, origin first and the
+// rest by name. It returns nothing when there is no local branch to compare
+// with.
+func RemoteTips(repo *git.Repository, gens *Generations, branch string) ([]RemoteTip, error) {
+	local, err := repo.Reference(plumbing.NewBranchReferenceName(branch), true)
+	if err != nil {
+		return nil, nil
+	}
+	remotes, err := repo.Remotes()
+	if err != nil {
+		return nil, err
+	}
+	var tips []RemoteTip
+	for _, remote := range remotes {
+		name := remote.Config().Name
+		ref, err := repo.Reference(plumbing.NewRemoteReferenceName(name, branch), true)
+		if err != nil {
+			continue
+		}
+		tip := RemoteTip{Remote: name, Branch: branch, Hash: ref.Hash()}
+		if tip.Ahead, tip.Behind, err = AheadBehind(gens, local.Hash(), ref.Hash()); err != nil {
+			return nil, err
+		}
+		tips = append(tips, tip)
+	}
+	sort.Slice(tips, func(i, j int) bool {
+		a, b := tips[i].Remote, tips[j].Remote
+		if (a == "origin") != (b == "origin") {
+			return a == "origin"
+		}
+		return a < b
+	})
+	return tips, nil
+}
@@ -0,0 +1,133 @@
+package gitgraph
+
+import (
+	"bufio"
+	"os"
+	"strings"
+
+	git "github.com/go-git/go-git/v5"
+	"github.com/go-git/go-git/v5/plumbing"
+	"github.com/go-git/go-git/v5/plumbing/object"
+	"github.com/go-git/go-git/v5/storage/filesystem"
+)
+
+const replacePrefix = "refs/replace/"
+
+// Replacements rewrites history the way git does when reading it: commits
+// with a refs/replace/<hash> ref show the replacement's content under the
+// original hash, info/grafts overrides parents, and the commits a shallow
+// clone was cut at have none. go-git knows none of these.
+type Replacements struct {
+	replace map[plumbing.Hash]plumbing.Hash
+	grafts  map[plumbing.Hash][]plumbing.Hash
+}
+
+// LoadReplacements reads replace refs, grafts and the shallow boundary, or
+// returns nil when there are none. GIT_NO_REPLACE_OBJECTS, which git itself
+// honors, leaves out replace refs and grafts; a shallow boundary stays, as
+// the history past it is not there to show.
+func LoadReplacements(repo *git.Repository) (*Replacements, error) {
+	r := &Replacements{replace: make(map[plumbing.Hash]plumbing.Hash), grafts: make(map[plumbing.Hash][]plumbing.Hash)}
+	if os.Getenv("GIT_NO_REPLACE_OBJECTS") == "" {
+		if err := r.readReplaceRefs(repo); err != nil {
+			return nil, err
+		}
+		if err := r.readGrafts(repo); err != nil {
+			return nil, err
+		}
+	}
+	shallow, err := repo.Storer.Shallow()
+	if err != nil {
+		return nil, err
+	}
+	for _, hash := range shallow {
+		if _, grafted := r.grafts[hash]; !grafted {
+			r.grafts[hash] = nil
+		}
+	}
+	if len(r.replace) == 0 && len(r.grafts) == 0 {
+		return nil, nil
+	}
+	return r, nil
+}
+
+// readReplaceRefs maps each refs/replace/<hash> to its replacement.
+func (r *Replacements) readReplaceRefs(repo *git.Repository) error {
+	iter, err := repo.References()
+	if err != nil {
+		return err
+	}
+	defer iter.Close()
+	return iter.ForEach(func(ref *plumbing.Reference) error {
+		name := ref.Name().String()
+		if ref.Type() != plumbing.HashReference || !strings.HasPrefix(name, replacePrefix) {
+			return nil
+		}
+		if orig := strings.TrimPrefix(name, replacePrefix); plumbing.IsHash(orig) {
+			r.replace[plumbing.NewHash(orig)] = ref.Hash()
+		}
+		return nil
+	})
+}
+
+// readGrafts parses info/grafts: "<commit> [<parent>...]" per line.
+func (r *Replacements) readGrafts(repo *git.Repository) error {
+	fs, ok := repo.Storer.(*filesystem.Storage)
+	if !ok {
+		return nil
+	}
+	f, err := fs.Filesystem().Open("info/grafts")
+	if os.IsNotExist(err) {
+		return nil
+	}
+	if err != nil {
+		return err
+	}
+	defer f.Close()
+	scanner := bufio.NewScanner(f)
+	for scanner.Scan() {
+		fields := strings.Fields(scanner.Text())
+		if len(fields) == 0 || strings.HasPrefix(fields[0], "#") || !plumbing.IsHash(fields[0]) {
+			continue
+		}
+		parents := make([]plumbing.Hash, 0, len(fields)-1)
+		for _, field := range fields[1:] {
+			if plumbing.IsHash(field) {
+				parents = append(parents, plumbing.NewHash(field))
+			}
+		}
+		r.grafts[plumbing.NewHash(fields[0])] = parents
+	}
+	return scanner.Err()
+}
+
+// Commit loads hash with replacements applied. A nil Replacements loads it
+// as stored.
+func (r *Replacements) Commit(repo *git.Repository, hash plumbing.Hash) (*object.Commit, error) {
+	if r == nil {
+		return repo.CommitObject(hash)
+	}
+	target := hash
+	// Replacements can chain; git follows them, bounded against cycles.
+	for i := 0; i < 8; i++ {
+		next, ok := r.replace[target]
+		if !ok {
+			break
+		}
+		target = next
+	}
+	commit, err := repo.CommitObject(target)
+	if err != nil {
+		return nil, err
+	}
+	parents, grafted := r.grafts[hash]
+	if target == hash && !grafted {
+		return commit, nil
+	}
+	replaced := *commit
+	replaced.Hash = hash
+	if grafted {
+		replaced.ParentHashes = parents
+	}
+	return &replaced, nil
+}
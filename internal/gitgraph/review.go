@@ -0,0 +1,46 @@
+package gitgraph
+
+import (
+	"crypto/sha1"
+	"encoding/hex"
+
+	git "github.com/go-git/go-git/v5"
+	"github.com/go-git/go-git/v5/plumbing"
+	"github.com/go-git/go-git/v5/plumbing/object"
+)
+
+// PatchID identifies a commit by its normalized patch, as range-diff pairs
+// commits, so a change keeps its identity when the branch is rebased.
+func PatchID(commit *object.Commit) (string, error) {
+	patch, err := normalizedPatch(commit)
+	if err != nil {
+		return "", err
+	}
+	sum := sha1.Sum([]byte(patch))
+	return hex.EncodeToString(sum[:]), nil
+}
+
+// ReviewRange returns the commits on head that base lacks, newest first,
+// and the merge base they branch from (nil for unrelated histories).
+func ReviewRange(repo *git.Repository, gens *Generations, base, head plumbing.Hash) (*object.Commit, []*object.Commit, error) {
+	_, commits, err := SymmetricDifference(repo, gens, base, head)
+	if err != nil {
+		return nil, nil, err
+	}
+	baseCommit, err := gens.Commit(base)
+	if err != nil {
+		return nil, nil, err
+	}
+	headCommit, err := gens.Commit(head)
+	if err != nil {
+		return nil, nil, err
+	}
+	bases, err := MergeBases(repo, gens, baseCommit, headCommit)
+	if err != nil {
+		return nil, nil, err
+	}
+	if len(bases) == 0 {
+		return nil, commits, nil
+	}
+	return bases[0], commits, nil
+}
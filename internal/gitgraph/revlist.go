@@ -0,0 +1,46 @@
+package gitgraph
+
+import (
+	"bufio"
+	"fmt"
+	"io"
+	"strings"
+
+	git "github.com/go-git/go-git/v5"
+	"github.com/go-git/go-git/v5/plumbing"
+)
+
+// ReadRevList parses `git rev-list`/`git log --format=%H` style output: one
+// commit per line, first field only, so `--parents` output and
+// `--boundary` markers are accepted too.
+func ReadRevList(repo *git.Repository, r io.Reader) ([]plumbing.Hash, error) {
+	var hashes []plumbing.Hash
+	scanner := bufio.NewScanner(r)
+	scanner.Buffer(make([]byte, 64*1024), 1024*1024)
+	line := 0
+	for scanner.Scan() {
+		line++
+		fields := strings.Fields(scanner.Text())
+		if len(fields) == 0 {
+			continue
+		}
+		token := fields[0]
+		if token == "commit" && len(fields) > 1 {
+			token = fields[1]
+		}
+		token = strings.TrimLeft(token, "-^>")
+		if plumbing.IsHash(token) {
+			hashes = append(hashes, plumbing.NewHash(token))
+			continue
+		}
+		hash, err := repo.ResolveRevision(plumbing.Revision(token))
+		if err != nil {
+			return nil, fmt.Errorf("stdin line %d: resolve %q: %w", line, token, err)
+		}
+		hashes = append(hashes, *hash)
+	}
+	if err := scanner.Err(); err != nil {
+		return nil, fmt.Errorf("read stdin: %w", err)
+	}
+	return hashes, nil
+}
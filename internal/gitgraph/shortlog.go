@@ -0,0 +1,118 @@
+package gitgraph
+
+import (
+	"sort"
+
+	git "github.com/go-git/go-git/v5"
+	"github.com/go-git/go-git/v5/plumbing"
+	"github.com/go-git/go-git/v5/plumbing/object"
+)
+
+// AuthorCount is how many commits one author made, as git shortlog -s
+// counts them.
+type AuthorCount struct {
+	Identity
+	Commits int
+}
+
+// Shortlog tallies commits by author, after the mailmap, by name alone or
+// by name and email.
+type Shortlog struct {
+	mailmap *Mailmap
+	byEmail bool
+	index   map[Identity]int
+	authors []AuthorCount
+}
+
+func NewShortlog(mailmap *Mailmap, byEmail bool) *Shortlog {
+	return &Shortlog{mailmap: mailmap, byEmail: byEmail, index: make(map[Identity]int)}
+}
+
+func (s *Shortlog) Add(commit *object.Commit) {
+	id := s.mailmap.Map(Identity{Name: commit.Author.Name, Email: commit.Author.Email})
+	if !s.byEmail {
+		id.Email = ""
+	}
+	i, ok := s.index[id]
+	if !ok {
+		i = len(s.authors)
+		s.index[id] = i
+		s.authors = append(s.authors, AuthorCount{Identity: id})
+	}
+	s.authors[i].Commits++
+}
+
+// Authors are the tallies, most commits first, then by name.
+func (s *Shortlog) Authors() []AuthorCount {
+	authors := append([]AuthorCount(nil), s.authors...)
+	sort.Slice(authors, func(i, j int) bool {
+		if authors[i].Commits != authors[j].Commits {
+			return authors[i].Commits > authors[j].Commits
+		}
+		if authors[i].Name != authors[j].Name {
+			return authors[i].Name < authors[j].Name
+		}
+		return authors[i].Email < authors[j].Email
+	})
+	return authors
+}
+
+// ScopeTips are the commits a walk of scope starts from.
+func ScopeTips(repo *git.Repository, scope Scope) ([]plumbing.Hash, error) {
+	return gatherTips(repo, scope)
+}
+
+// WalkHistory calls fn with every commit reachable from tips, in no
+// particular order, with replacements applied. Commits are loaded one at a
+// time and not kept, so a whole history streams through in little memory.
+func WalkHistory(repo *git.Repository, tips []plumbing.Hash, fn func(*object.Commit) error) error {
+	replace, err := LoadReplacements(repo)
+	if err != nil {
+		return err
+	}
+	seen := make(map[plumbing.Hash]bool)
+	var stack []plumbing.Hash
+	for _, tip := range tips {
+		if !seen[tip] {
+			seen[tip] = true
+			stack = append(stack, tip)
+		}
+	}
+	for len(stack) > 0 {
+		hash := stack[len(stack)-1]
+		stack = stack[:len(stack)-1]
+		commit, err := replace.Commit(repo, hash)
+		if err != nil {
+			return err
+		}
+		if err := fn(commit); err != nil {
+			return err
+		}
+		for _, parent := range commit.ParentHashes {
+			if !seen[parent] {
+				seen[parent] = true
+				stack = append(stack, parent)
+			}
+		}
+	}
+	return nil
+}
+
+// WalkRange calls fn with every commit head reaches that base does not,
+// the base..head range, in no particular order.
+func WalkRange(repo *git.Repository, gens *Generations, base, head plumbing.Hash, fn func(*object.Commit) error) error {
+	_, hashes, err := gens.SymmetricDifference(base, head)
+	if err != nil {
+		return err
+	}
+	for _, hash := range hashes {
+		commit, err := gens.Commit(hash)
+		if err != nil {
+			return err
+		}
+		if err := fn(commit); err != nil {
+			return err
+		}
+	}
+	return nil
+}
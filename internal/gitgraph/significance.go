@@ -0,0 +1,79 @@
+package gitgraph
+
+import (
+	"path"
+	"regexp"
+	"strings"
+
+	"github.com/go-git/go-git/v5/plumbing/object"
+)
+
+// SignificanceRule flags commits worth spotting at a glance, such as
+// version bumps, reverts, or changes under migrations/. A rule matches when
+// every criterion it sets matches; Paths match if any changed file does.
+type SignificanceRule struct {
+	Name    string
+	Symbol  string
+	Subject *regexp.Regexp
+	// Paths are path.Match globs against the full path, or against the
+	// file name when they contain no "/"; a pattern ending in "/" matches
+	// everything below that directory.
+	Paths []string
+}
+
+// Significance returns the names of the rules commit matches, in rule
+// order. Changed files are only diffed when a rule needs them.
+func Significance(commit *object.Commit, rules []SignificanceRule) ([]string, error) {
+	subject, _, _ := strings.Cut(commit.Message, "\n")
+	var paths []string
+	var diffed bool
+	var names []string
+	for _, rule := range rules {
+		if rule.Subject != nil && !rule.Subject.MatchString(subject) {
+			continue
+		}
+		if len(rule.Paths) > 0 {
+			if !diffed {
+				changes, err := CommitChanges(commit)
+				if err != nil {
+					return nil, err
+				}
+				for _, change := range changes {
+					if change.To.Name != "" {
+						paths = append(paths, change.To.Name)
+					}
+					if change.From.Name != "" && change.From.Name != change.To.Name {
+						paths = append(paths, change.From.Name)
+					}
+				}
+				diffed = true
+			}
+			if !anyPathMatches(rule.Paths, paths) {
+				continue
+			}
+		}
+		names = append(names, rule.Name)
+	}
+	return names, nil
+}
+
+func anyPathMatches(patterns, paths []string) bool {
+	for _, pattern := range patterns {
+		for _, p := range paths {
+			if strings.HasSuffix(pattern, "/") {
+				if strings.HasPrefix(p, pattern) {
+					return true
+				}
+				continue
+			}
+			name := p
+			if !strings.Contains(pattern, "/") {
+				name = path.Base(p)
+			}
+			if ok, _ := path.Match(pattern, name); ok {
+				return true
+			}
+		}
+	}
+	return false
+}
@@ -0,0 +1,182 @@
+package gitgraph
+
+import (
+	"sort"
+	"strings"
+
+	git "github.com/go-git/go-git/v5"
+	"github.com/go-git/go-git/v5/plumbing"
+	"github.com/go-git/go-git/v5/plumbing/object"
+)
+
+// RefPositions are the commits HEAD and every branch, remote-tracking
+// branch, and tag point at, keyed by full ref name ("HEAD",
+// "refs/heads/main").
+func RefPositions(repo *git.Repository) (map[string]plumbing.Hash, error) {
+	iter, err := repo.References()
+	if err != nil {
+		return nil, err
+	}
+	defer iter.Close()
+	positions := make(map[string]plumbing.Hash)
+	err = iter.ForEach(func(ref *plumbing.Reference) error {
+		name := ref.Name()
+		if ref.Type() != plumbing.HashReference || !(name.IsBranch() || name.IsRemote() || name.IsTag()) {
+			return nil
+		}
+		addPosition(repo, positions, name.String(), ref.Hash())
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+	if head, err := repo.Head(); err == nil {
+		positions["HEAD"] = head.Hash()
+	}
+	return positions, nil
+}
+
+// SavedPositions reads positions saved under prefix, where "HEAD" is
+// prefix+"HEAD" and refs/heads/main is prefix+"heads/main", keyed as in
+// RefPositions.
+func SavedPositions(repo *git.Repository, prefix string) (map[string]plumbing.Hash, error) {
+	iter, err := repo.References()
+	if err != nil {
+		return nil, err
+	}
+	defer iter.Close()
+	positions := make(map[string]plumbing.Hash)
+	err = iter.ForEach(func(ref *plumbing.Reference) error {
+		rest, ok := strings.CutPrefix(ref.Name().String(), prefix)
+		if !ok || ref.Type() != plumbing.HashReference {
+			return nil
+		}
+		if rest != "HEAD" {
+			rest = "refs/" + rest
+		}
+		addPosition(repo, positions, rest, ref.Hash())
+		return nil
+	})
+	return positions, err
+}
+
+// addPosition records the commit hash leads to, skipping tags of trees and
+// blobs.
+func addPosition(repo *git.Repository, positions map[string]plumbing.Hash, name string, hash plumbing.Hash) {
+	hash = peel(repo, hash)
+	if _, err := repo.CommitObject(hash); err == nil {
+		positions[name] = hash
+	}
+}
+
+// RefMove is a ref that points somewhere else than it did. A zero Old
+// means it was created, a zero New that it was deleted. Ahead and Behind
+// count the commits New has that Old lacks and the other way round.
+type RefMove struct {
+	Name   string
+	Old    plumbing.Hash
+	New    plumbing.Hash
+	Ahead  int
+	Behind int
+}
+
+// PositionDiff is what changed between two sets of ref positions: the refs
+// that moved, the commits now reachable that were not (New), and the
+// commits no longer reachable from any ref (Dropped), newest first.
+// Rewritten pairs a dropped commit with the new one carrying the same
+// patch, as after a rebase.
+type PositionDiff struct {
+	Moved     []RefMove
+	New       []*object.Commit
+	Dropped   []*object.Commit
+	Rewritten map[plumbing.Hash]plumbing.Hash
+}
+
+// DiffPositions compares old positions with new ones.
+func DiffPositions(repo *git.Repository, gens *Generations, old, new map[string]plumbing.Hash) (*PositionDiff, error) {
+	diff := &PositionDiff{Rewritten: make(map[plumbing.Hash]plumbing.Hash)}
+	names := make(map[string]bool)
+	for name := range old {
+		names[name] = true
+	}
+	for name := range new {
+		names[name] = true
+	}
+	for name := range names {
+		move := RefMove{Name: name, Old: old[name], New: new[name]}
+		if move.Old == move.New {
+			continue
+		}
+		if !move.Old.IsZero() && !move.New.IsZero() {
+			ahead, behind, err := gens.SymmetricDifference(move.New, move.Old)
+			if err != nil {
+				return nil, err
+			}
+			move.Ahead, move.Behind = len(ahead), len(behind)
+		}
+		diff.Moved = append(diff.Moved, move)
+	}
+	sort.Slice(diff.Moved, func(i, j int) bool { return refOrder(diff.Moved[i].Name) < refOrder(diff.Moved[j].Name) })
+
+	oldTips, newTips := tips(old), tips(new)
+	added, err := gens.Exclusive(newTips, oldTips)
+	if err != nil {
+		return nil, err
+	}
+	dropped, err := gens.Exclusive(oldTips, newTips)
+	if err != nil {
+		return nil, err
+	}
+	if diff.New, err = gens.commits(added); err != nil {
+		return nil, err
+	}
+	if diff.Dropped, err = gens.commits(dropped); err != nil {
+		return nil, err
+	}
+	sortNewestFirst(diff.New)
+	sortNewestFirst(diff.Dropped)
+
+	if len(diff.Dropped) == 0 {
+		return diff, nil
+	}
+	patches := make(map[string]plumbing.Hash, len(diff.New))
+	for _, commit := range diff.New {
+		if id, err := PatchID(commit); err == nil {
+			patches[id] = commit.Hash
+		}
+	}
+	for _, commit := range diff.Dropped {
+		if id, err := PatchID(commit); err == nil {
+			if rewritten, ok := patches[id]; ok {
+				diff.Rewritten[commit.Hash] = rewritten
+			}
+		}
+	}
+	return diff, nil
+}
+
+func tips(positions map[string]plumbing.Hash) []plumbing.Hash {
+	seen := make(map[plumbing.Hash]bool)
+	var hashes []plumbing.Hash
+	for _, hash := range positions {
+		if !seen[hash] {
+			seen[hash] = true
+			hashes = append(hashes, hash)
+		}
+	}
+	return hashes
+}
+
+// refOrder lists HEAD, then branches, remote-tracking branches, and tags,
+// each by name.
+func refOrder(name string) string {
+	switch {
+	case name == "HEAD":
+		return "0"
+	case strings.HasPrefix(name, "refs/heads/"):
+		return "1" + name
+	case strings.HasPrefix(name, "refs/remotes/"):
+		return "2" + name
+	}
+	return "3" + name
+}
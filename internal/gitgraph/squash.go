@@ -0,0 +1,28 @@
+package gitgraph
+
+import (
+	"errors"
+
+	"github.com/go-git/go-git/v5/plumbing/object"
+)
+
+var ErrNotOnPath = errors.New("not on the first-parent path")
+
+// FirstParentPath walks first parents from tip down to base and returns the
+// commits passed, tip first and base last. It gives up with ErrNotOnPath
+// after limit commits or at a root.
+func FirstParentPath(tip, base *object.Commit, limit int) ([]*object.Commit, error) {
+	path := []*object.Commit{tip}
+	for commit := tip; commit.Hash != base.Hash; {
+		if len(path) > limit || commit.NumParents() == 0 {
+			return nil, ErrNotOnPath
+		}
+		parent, err := commit.Parent(0)
+		if err != nil {
+			return nil, err
+		}
+		path = append(path, parent)
+		commit = parent
+	}
+	return path, nil
+}
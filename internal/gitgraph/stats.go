@@ -0,0 +1,176 @@
+package gitgraph
+
+import (
+	"fmt"
+	"path"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+)
+
+type Group struct {
+	Key     string
+	Commits []*CommitInfo
+}
+
+func GroupByAuthor(commits []*CommitInfo) []Group {
+	return groupBy(commits, func(c *CommitInfo) []string { return []string{c.Author} })
+}
+
+// Team names a set of authors by email globs (path.Match syntax, compared
+// in lower case), e.g. "*@corp.example" or "jane@*".
+type Team struct {
+	Name   string
+	Emails []string
+}
+
+// GroupByDomain buckets commits by the domain of the author's email.
+func GroupByDomain(commits []*CommitInfo) []Group {
+	return groupBy(commits, func(c *CommitInfo) []string { return []string{EmailDomain(authorEmail(c))} })
+}
+
+// GroupByTeam buckets commits by the first team whose patterns match the
+// author's email; everyone else falls under "(no team)".
+func GroupByTeam(commits []*CommitInfo, teams []Team) []Group {
+	return groupBy(commits, func(c *CommitInfo) []string { return []string{TeamOf(authorEmail(c), teams)} })
+}
+
+func EmailDomain(email string) string {
+	_, domain, ok := strings.Cut(strings.ToLower(email), "@")
+	if !ok || domain == "" {
+		return "(no domain)"
+	}
+	return domain
+}
+
+func TeamOf(email string, teams []Team) string {
+	email = strings.ToLower(email)
+	for _, team := range teams {
+		for _, pattern := range team.Emails {
+			if ok, _ := path.Match(strings.ToLower(pattern), email); ok {
+				return team.Name
+			}
+		}
+	}
+	return "(no team)"
+}
+
+func authorEmail(c *CommitInfo) string {
+	if c.Commit == nil {
+		return ""
+	}
+	return strings.TrimSpace(c.Commit.Author.Email)
+}
+
+// GroupByArea buckets commits by the leading depth directories of the files
+// they touch. A commit touching several areas is listed under each of them;
+// files at the repository root fall under "(root)".
+func GroupByArea(commits []*CommitInfo, depth int) ([]Group, error) {
+	var firstErr error
+	groups := groupBy(commits, func(c *CommitInfo) []string {
+		files, err := ChangedFiles(c.Commit)
+		if err != nil {
+			if firstErr == nil {
+				firstErr = fmt.Errorf("files for %s: %w", c.ShortHash(), err)
+			}
+			return nil
+		}
+		seen := make(map[string]bool)
+		var areas []string
+		for _, f := range files {
+			area := AreaOf(f, depth)
+			if !seen[area] {
+				seen[area] = true
+				areas = append(areas, area)
+			}
+		}
+		return areas
+	})
+	return groups, firstErr
+}
+
+func AreaOf(file string, depth int) string {
+	dir := path.Dir(file)
+	if dir == "." {
+		return "(root)"
+	}
+	parts := strings.Split(dir, "/")
+	if depth > 0 && len(parts) > depth {
+		parts = parts[:depth]
+	}
+	return strings.Join(parts, "/") + "/"
+}
+
+func groupBy(commits []*CommitInfo, keys func(*CommitInfo) []string) []Group {
+	index := make(map[string]int)
+	var groups []Group
+	for _, c := range commits {
+		for _, key := range keys(c) {
+			i, ok := index[key]
+			if !ok {
+				i = len(groups)
+				index[key] = i
+				groups = append(groups, Group{Key: key})
+			}
+			groups[i].Commits = append(groups[i].Commits, c)
+		}
+	}
+	sort.SliceStable(groups, func(i, j int) bool {
+		if len(groups[i].Commits) != len(groups[j].Commits) {
+			return len(groups[i].Commits) > len(groups[j].Commits)
+		}
+		return groups[i].Key < groups[j].Key
+	})
+	return groups
+}
+
+// ParseSince accepts a relative span ("90m", "36h", "3d", "2w", "6mo", "1y")
+// or an absolute date ("2006-01-02") and returns the cutoff time.
+func ParseSince(s string, now time.Time) (time.Time, error) {
+	s = strings.TrimSpace(s)
+	if t, err := time.ParseInLocation("2006-01-02", s, now.Location()); err == nil {
+		return t, nil
+	}
+	units := []struct {
+		suffix string
+		apply  func(n int) time.Time
+	}{
+		{"mo", func(n int) time.Time { return now.AddDate(0, -n, 0) }},
+		{"y", func(n int) time.Time { return now.AddDate(-n, 0, 0) }},
+		{"w", func(n int) time.Time { return now.AddDate(0, 0, -7*n) }},
+		{"d", func(n int) time.Time { return now.AddDate(0, 0, -n) }},
+		{"h", func(n int) time.Time { return now.Add(-time.Duration(n) * time.Hour) }},
+		{"m", func(n int) time.Time { return now.Add(-time.Duration(n) * time.Minute) }},
+	}
+	for _, unit := range units {
+		if !strings.HasSuffix(s, unit.suffix) {
+			continue
+		}
+		n, err := strconv.Atoi(strings.TrimSuffix(s, unit.suffix))
+		if err != nil || n < 0 {
+			break
+		}
+		return unit.apply(n), nil
+	}
+	return time.Time{}, fmt.Errorf("invalid time %q (use e.g. 3d, 2w, 6mo, or 2006-01-02)", s)
+}
+
+// Since loads commits newest-first until the first one committed before
+// cutoff, mirroring how `git log --since` stops its walk.
+func (p *CommitProvider) Since(cutoff time.Time) ([]*CommitInfo, error) {
+	var out []*CommitInfo
+	for i := 0; ; i++ {
+		if err := p.Ensure(i); err != nil {
+			return nil, err
+		}
+		if i >= len(p.Commits) {
+			return out, nil
+		}
+		commit := p.Commits[i]
+		if commit.When.Before(cutoff) {
+			return out, nil
+		}
+		out = append(out, commit)
+	}
+}
@@ -0,0 +1,48 @@
+package gitgraph
+
+import (
+	"strings"
+
+	"github.com/go-git/go-git/v5/plumbing"
+	"github.com/go-git/go-git/v5/plumbing/object"
+)
+
+// TagNote is what an annotated tag adds over a lightweight one: who tagged,
+// the message (often release notes), and whether it is signed.
+type TagNote struct {
+	Name    string
+	Hash    plumbing.Hash
+	Tagger  object.Signature
+	Message string
+	Signed  bool
+}
+
+// Subject is the first line of the tag message.
+func (n TagNote) Subject() string {
+	subject, _, _ := strings.Cut(strings.TrimSpace(n.Message), "\n")
+	return subject
+}
+
+// Notes returns the annotated tags on hash, sorted by name. Tags whose
+// object cannot be read are left out, like lightweight ones.
+func (ix *TagIndex) Notes(hash plumbing.Hash) []TagNote {
+	var notes []TagNote
+	for _, name := range ix.tags[hash] {
+		object, ok := ix.objects[name]
+		if !ok {
+			continue
+		}
+		tag, err := ix.repo.TagObject(object)
+		if err != nil {
+			continue
+		}
+		notes = append(notes, TagNote{
+			Name:    name,
+			Hash:    tag.Hash,
+			Tagger:  tag.Tagger,
+			Message: tag.Message,
+			Signed:  tag.PGPSignature != "",
+		})
+	}
+	return notes
+}
@@ -0,0 +1,232 @@
+package gitgraph
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"io"
+	"os"
+	"os/exec"
+	"path"
+	"strings"
+	"time"
+
+	"arbor/internal/platform"
+
+	"github.com/go-git/go-git/v5/plumbing"
+	"github.com/go-git/go-git/v5/plumbing/filemode"
+	fdiff "github.com/go-git/go-git/v5/plumbing/format/diff"
+	"github.com/go-git/go-git/v5/plumbing/object"
+	"github.com/go-git/go-git/v5/utils/diff"
+	"github.com/sergi/go-diff/diffmatchpatch"
+)
+
+// TextconvTimeout bounds one conversion; a hung converter must not hang
+// the diff with it.
+const TextconvTimeout = 30 * time.Second
+
+// textconvCacheBytes bounds the converted text a provider keeps (see
+// CommitProvider.Attributes).
+const textconvCacheBytes = 32 << 20
+
+type textconvKey struct {
+	command string
+	blob    plumbing.Hash
+}
+
+func newTextconvCache() *lru[textconvKey, string] {
+	return newLRU[textconvKey](textconvCacheBytes, func(text string) int { return len(text) })
+}
+
+// Patch diffs changes for display the way git diff does with textconv: a
+// file whose diff attribute names a driver with diff.<driver>.textconv
+// set is compared as that command's output for each version, so a PDF or
+// an image diffs as the text describing it. Other files diff as
+// Changes.Patch does.
+func (a *Attributes) Patch(changes object.Changes) (fdiff.Patch, error) {
+	if a == nil {
+		return plainPatch(changes)
+	}
+	var files []fdiff.FilePatch
+	for _, change := range changes {
+		name := change.To.Name
+		if name == "" {
+			name = change.From.Name
+		}
+		command := a.Textconv(name)
+		if command == "" {
+			patch, err := object.Changes{change}.Patch()
+			if err != nil {
+				return nil, err
+			}
+			files = append(files, patch.FilePatches()...)
+			continue
+		}
+		file, err := a.TextconvPatch(command, change)
+		if err != nil {
+			return nil, err
+		}
+		files = append(files, file)
+	}
+	return filePatches(files), nil
+}
+
+func plainPatch(changes object.Changes) (fdiff.Patch, error) {
+	patch, err := changes.Patch()
+	if err != nil {
+		return nil, err
+	}
+	return patch, nil
+}
+
+// Textconv is the conversion command for name's diff driver, if any.
+func (a *Attributes) Textconv(name string) string {
+	if a == nil || a.config == nil {
+		return ""
+	}
+	d := a.Lookup(name, "diff")["diff"]
+	if d == nil || !d.IsValueSet() {
+		return ""
+	}
+	return a.config.Section("diff").Subsection(d.Value()).Option("textconv")
+}
+
+// TextconvPatch diffs change through command, as Patch does for a file
+// whose driver has it. Unlike Lookup, it may run on several goroutines at
+// once.
+func (a *Attributes) TextconvPatch(command string, change *object.Change) (fdiff.FilePatch, error) {
+	texts, err := a.convertChange(command, change)
+	if err != nil {
+		return nil, err
+	}
+	patch := &convertedPatch{}
+	if change.From.Name != "" {
+		patch.from = changeFile(change.From)
+	}
+	if change.To.Name != "" {
+		patch.to = changeFile(change.To)
+	}
+	for _, d := range diff.Do(texts[0], texts[1]) {
+		op := fdiff.Equal
+		switch d.Type {
+		case diffmatchpatch.DiffDelete:
+			op = fdiff.Delete
+		case diffmatchpatch.DiffInsert:
+			op = fdiff.Add
+		}
+		patch.chunks = append(patch.chunks, textChunk{content: d.Text, op: op})
+	}
+	return patch, nil
+}
+
+// convertChange converts both versions of a file, leaving "" for the side
+// of an added or deleted one.
+func (a *Attributes) convertChange(command string, change *object.Change) (texts [2]string, err error) {
+	for i, entry := range []object.ChangeEntry{change.From, change.To} {
+		if entry.Name == "" {
+			continue
+		}
+		if texts[i], err = a.convert(command, entry); err != nil {
+			return texts, err
+		}
+	}
+	return texts, nil
+}
+
+// convert runs command on one version of a file as git does: the blob is
+// written to a temporary file, named after the file so converters can go
+// by its extension, whose path the shell appends to command. The command
+// runs in a process group of its own, so a timeout also stops whatever
+// the shell started.
+func (a *Attributes) convert(command string, entry object.ChangeEntry) (string, error) {
+	key := textconvKey{command: command, blob: entry.TreeEntry.Hash}
+	if a.converted != nil {
+		if text, ok := a.converted.Get(key); ok {
+			return text, nil
+		}
+	}
+
+	file, err := entry.Tree.TreeEntryFile(&entry.TreeEntry)
+	if err != nil {
+		return "", err
+	}
+	reader, err := file.Reader()
+	if err != nil {
+		return "", err
+	}
+	defer reader.Close()
+	temp, err := os.CreateTemp("", "*_"+path.Base(entry.Name))
+	if err != nil {
+		return "", err
+	}
+	defer os.Remove(temp.Name())
+	_, err = io.Copy(temp, reader)
+	if closeErr := temp.Close(); err == nil {
+		err = closeErr
+	}
+	if err != nil {
+		return "", err
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), TextconvTimeout)
+	defer cancel()
+	cmd := exec.CommandContext(ctx, "sh", "-c", command+` "$@"`, command, temp.Name())
+	cmd.Dir = a.root
+	platform.KillGroup(cmd)
+	cmd.WaitDelay = time.Second
+	var stdout, stderr bytes.Buffer
+	cmd.Stdout = &stdout
+	cmd.Stderr = &stderr
+	if err := cmd.Run(); err != nil {
+		if ctx.Err() != nil {
+			return "", fmt.Errorf("textconv %q on %s timed out after %s", command, entry.Name, TextconvTimeout)
+		}
+		if msg := strings.TrimSpace(stderr.String()); msg != "" {
+			return "", fmt.Errorf("textconv %q on %s: %s", command, entry.Name, msg)
+		}
+		return "", fmt.Errorf("textconv %q on %s: %w", command, entry.Name, err)
+	}
+	text := stdout.String()
+	if a.converted != nil {
+		a.converted.Add(key, text)
+	}
+	return text, nil
+}
+
+type filePatches []fdiff.FilePatch
+
+func (p filePatches) FilePatches() []fdiff.FilePatch { return p }
+func (p filePatches) Message() string                { return "" }
+
+// convertedPatch is a file's diff between its converted versions; from or
+// to is nil for a file added or deleted.
+type convertedPatch struct {
+	from, to fdiff.File
+	chunks   []fdiff.Chunk
+}
+
+func (p *convertedPatch) IsBinary() bool               { return false }
+func (p *convertedPatch) Files() (from, to fdiff.File) { return p.from, p.to }
+func (p *convertedPatch) Chunks() []fdiff.Chunk        { return p.chunks }
+
+type entryFile struct {
+	hash plumbing.Hash
+	mode filemode.FileMode
+	path string
+}
+
+func changeFile(entry object.ChangeEntry) entryFile {
+	return entryFile{hash: entry.TreeEntry.Hash, mode: entry.TreeEntry.Mode, path: entry.Name}
+}
+
+func (f entryFile) Hash() plumbing.Hash     { return f.hash }
+func (f entryFile) Mode() filemode.FileMode { return f.mode }
+func (f entryFile) Path() string            { return f.path }
+
+type textChunk struct {
+	content string
+	op      fdiff.Operation
+}
+
+func (c textChunk) Content() string       { return c.content }
+func (c textChunk) Type() fdiff.Operation { return c.op }
@@ -0,0 +1,57 @@
+package gitgraph
+
+import (
+	"regexp"
+	"strings"
+)
+
+var trailerRe = regexp.MustCompile(`^([A-Za-z0-9][A-Za-z0-9-]*)[ \t]*:[ \t]*(\S.*)$`)
+
+// Trailer is one "Key: value" line from the end of a commit message, such
+// as Reviewed-by, Co-authored-by, or a ticket reference.
+type Trailer struct {
+	Key   string
+	Value string
+}
+
+func (t Trailer) String() string {
+	return t.Key + ": " + t.Value
+}
+
+// Matches compares keys and values ignoring case, as git does for keys.
+func (t Trailer) Matches(other Trailer) bool {
+	return strings.EqualFold(t.Key, other.Key) && strings.EqualFold(t.Value, other.Value)
+}
+
+// Trailers parses the trailer block: the last paragraph of the message,
+// after the subject, when every line in it is a trailer or the indented
+// continuation of one.
+func Trailers(message string) []Trailer {
+	paragraphs := strings.Split(strings.TrimSpace(strings.ReplaceAll(message, "\r\n", "\n")), "\n\n")
+	if len(paragraphs) < 2 {
+		return nil
+	}
+	var trailers []Trailer
+	for _, line := range strings.Split(strings.TrimSpace(paragraphs[len(paragraphs)-1]), "\n") {
+		if line != strings.TrimLeft(line, " \t") && len(trailers) > 0 {
+			trailers[len(trailers)-1].Value += " " + strings.TrimSpace(line)
+			continue
+		}
+		match := trailerRe.FindStringSubmatch(line)
+		if match == nil {
+			return nil
+		}
+		trailers = append(trailers, Trailer{Key: match[1], Value: strings.TrimSpace(match[2])})
+	}
+	return trailers
+}
+
+// HasTrailer reports whether message carries trailer.
+func HasTrailer(message string, trailer Trailer) bool {
+	for _, t := range Trailers(message) {
+		if t.Matches(trailer) {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,29 @@
+package gitops
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+)
+
+// CreateBundle writes the commits revs select to file with git bundle
+// create, for carrying to a clone without network access, and returns
+// how many it holds. revs are as for git rev-list and have to name at
+// least one ref, which the bundle records: "main", "v1.0..main".
+func (r *Repo) CreateBundle(file string, revs ...string) (int, error) {
+	out, err := r.run(nil, append([]string{"rev-list", "--count"}, revs...)...)
+	if err != nil {
+		return 0, err
+	}
+	count, err := strconv.Atoi(out)
+	if err != nil {
+		return 0, fmt.Errorf("git rev-list: unexpected count %q", out)
+	}
+	if count == 0 {
+		return 0, fmt.Errorf("%s selects no commits to bundle", strings.Join(revs, " "))
+	}
+	if _, err := r.run(nil, append([]string{"bundle", "create", "--quiet", file}, revs...)...); err != nil {
+		return 0, err
+	}
+	return count, nil
+}
@@ -0,0 +1,423 @@
+package gitops
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"os"
+	"os/exec"
+	"strconv"
+	"strings"
+	"time"
+
+	"arbor/internal/debuglog"
+)
+
+// PrunedRefPrefix holds the old tips of branches arbor deleted, so they stay
+// reachable and can be restored with `git branch <name> <ref>`.
+const PrunedRefPrefix = "refs/arbor/pruned/"
+
+// emptyTree is git's well-known hash of the tree with nothing in it, the
+// base to diff a root commit against.
+const emptyTree = "4b825dc642cb6eb9a060e54bf8d69288fbee4904"
+
+// Repo runs mutating git operations through the git CLI so hooks, signing,
+// and index locking behave exactly as they do for the user's own commands.
+type Repo struct {
+	Dir string
+	// ReadOnly refuses every mutation; Protected holds branch globs that
+	// must not be rewritten or deleted. See Allow.
+	ReadOnly  bool
+	Protected []string
+	// RemoteAuth configures fetch and push per remote name.
+	RemoteAuth map[string]RemoteAuth
+	identity   *Identity
+	// inJournal is set on the handle Journaled passes on, so the
+	// operations run through it are recorded once, as a group.
+	inJournal bool
+}
+
+// Identity overrides user.name and user.email for commits arbor makes.
+type Identity struct {
+	Name  string
+	Email string
+}
+
+func (id Identity) String() string {
+	return fmt.Sprintf("%s <%s>", id.Name, id.Email)
+}
+
+func New(dir string) *Repo {
+	return &Repo{Dir: dir}
+}
+
+// As returns a copy of r that authors and commits as id; nil falls back to
+// the identity git is configured with.
+func (r *Repo) As(id *Identity) *Repo {
+	c := *r
+	c.identity = id
+	return &c
+}
+
+// DefaultIdentity is who git commits as without an override.
+func (r *Repo) DefaultIdentity() (Identity, error) {
+	out, err := r.run(nil, "var", "GIT_AUTHOR_IDENT")
+	if err != nil {
+		return Identity{}, err
+	}
+	open, close := strings.LastIndex(out, "<"), strings.LastIndex(out, ">")
+	if open < 0 || close < open {
+		return Identity{}, fmt.Errorf("git var: unexpected ident %q", out)
+	}
+	return Identity{Name: strings.TrimSpace(out[:open]), Email: out[open+1 : close]}, nil
+}
+
+func (r *Repo) identityEnv() []string {
+	if r.identity == nil {
+		return nil
+	}
+	return []string{
+		"GIT_AUTHOR_NAME=" + r.identity.Name,
+		"GIT_AUTHOR_EMAIL=" + r.identity.Email,
+		"GIT_COMMITTER_NAME=" + r.identity.Name,
+		"GIT_COMMITTER_EMAIL=" + r.identity.Email,
+	}
+}
+
+func (r *Repo) run(env []string, args ...string) (string, error) {
+	return r.runInput(env, "", args...)
+}
+
+// runInput is run with input on the command's stdin, for the --stdin modes
+// of commands like update-ref.
+func (r *Repo) runInput(env []string, input string, args ...string) (string, error) {
+	cmd := exec.Command("git", args...)
+	cmd.Dir = r.Dir
+	cmd.Env = append(append(os.Environ(), r.identityEnv()...), env...)
+	cmd.Stdin = strings.NewReader(input)
+	var stdout, stderr bytes.Buffer
+	cmd.Stdout = &stdout
+	cmd.Stderr = &stderr
+	start := time.Now()
+	err := cmd.Run()
+	debuglog.Timed(start, "git", "args", args, "err", err, "stderr", strings.TrimSpace(stderr.String()))
+	out := strings.TrimSpace(stdout.String())
+	if err != nil {
+		msg := strings.TrimSpace(stderr.String())
+		if msg == "" {
+			msg = out
+		}
+		if msg == "" {
+			msg = err.Error()
+		}
+		if lock := lockError(msg); lock != nil {
+			return out, fmt.Errorf("git %s: %w", args[0], lock)
+		}
+		return out, fmt.Errorf("git %s: %s", args[0], msg)
+	}
+	return out, nil
+}
+
+// CommonDir is the git directory shared by all worktrees: .git itself, also
+// when run from a linked worktree.
+func (r *Repo) CommonDir() (string, error) {
+	return r.run(nil, "rev-parse", "--path-format=absolute", "--git-common-dir")
+}
+
+func (r *Repo) HasStagedChanges() (bool, error) {
+	cmd := exec.Command("git", "diff", "--cached", "--quiet")
+	cmd.Dir = r.Dir
+	err := cmd.Run()
+	if err == nil {
+		return false, nil
+	}
+	var exit *exec.ExitError
+	if errors.As(err, &exit) && exit.ExitCode() == 1 {
+		return true, nil
+	}
+	return false, fmt.Errorf("git diff --cached: %w", err)
+}
+
+func (r *Repo) CommitFixup(target string) (string, error) {
+	if err := r.Allow(OpCommit, ""); err != nil {
+		return "", err
+	}
+	return r.journaled("fixup commit", "soft", false, func() (string, error) {
+		return r.run(nil, "commit", "--fixup="+target)
+	})
+}
+
+// CommitEmpty records an empty commit on HEAD. --only without paths leaves
+// anything already staged out of it.
+func (r *Repo) CommitEmpty(message string) (string, error) {
+	if err := r.Allow(OpCommit, ""); err != nil {
+		return "", err
+	}
+	return r.journaled("marker commit", "soft", false, func() (string, error) {
+		return r.run(nil, "commit", "--allow-empty", "--only", "-m", message)
+	})
+}
+
+// AutosquashRebase folds fixup!/squash! commits into their targets without
+// opening the todo list. hasParent is false when target is a root commit.
+func (r *Repo) AutosquashRebase(target string, hasParent bool) (string, error) {
+	if err := r.Allow(OpRewrite, r.CurrentBranch()); err != nil {
+		return "", err
+	}
+	return r.journaled("autosquash", "soft", false, func() (string, error) {
+		return r.run([]string{"GIT_SEQUENCE_EDITOR=:"}, autosquashArgs(target, hasParent)...)
+	})
+}
+
+// AutosquashRebaseCommand is the interactive variant: git opens the todo list
+// in GIT_SEQUENCE_EDITOR (or sequence.editor, then the commit editor) for
+// review, so it must run on the terminal.
+func (r *Repo) AutosquashRebaseCommand(target string, hasParent bool) (*exec.Cmd, error) {
+	if err := r.Allow(OpRewrite, r.CurrentBranch()); err != nil {
+		return nil, err
+	}
+	cmd := exec.Command("git", autosquashArgs(target, hasParent)...)
+	cmd.Dir = r.Dir
+	cmd.Env = append(os.Environ(), r.identityEnv()...)
+	return cmd, nil
+}
+
+func autosquashArgs(target string, hasParent bool) []string {
+	args := []string{"rebase", "-i", "--autosquash"}
+	if hasParent {
+		return append(args, target+"^")
+	}
+	return append(args, "--root")
+}
+
+// SquashRange replaces the linear run oldest..newest with one commit
+// carrying newest's tree, oldest's authorship, and message, then rebases
+// whatever sits on top of newest onto it. hasParent is false when oldest is
+// a root commit.
+func (r *Repo) SquashRange(oldest, newest, message string, hasParent bool) (string, error) {
+	branch := r.CurrentBranch()
+	if err := r.Allow(OpRewrite, branch); err != nil {
+		return "", err
+	}
+	return r.journaled("squash", "soft", false, func() (string, error) {
+		return r.squashRange(oldest, newest, message, hasParent, branch)
+	})
+}
+
+func (r *Repo) squashRange(oldest, newest, message string, hasParent bool, branch string) (string, error) {
+	author, err := r.run(nil, "show", "-s", "--format=%an%n%ae%n%aI", oldest)
+	if err != nil {
+		return "", err
+	}
+	fields := strings.SplitN(author, "\n", 3)
+	if len(fields) != 3 {
+		return "", fmt.Errorf("git show: unexpected author %q", author)
+	}
+	env := []string{"GIT_AUTHOR_NAME=" + fields[0], "GIT_AUTHOR_EMAIL=" + fields[1], "GIT_AUTHOR_DATE=" + fields[2]}
+	args := []string{"commit-tree", newest + "^{tree}", "-m", message}
+	if hasParent {
+		args = append(args, "-p", oldest+"^")
+	}
+	squashed, err := r.run(env, args...)
+	if err != nil {
+		return "", err
+	}
+	args = []string{"rebase", "--rebase-merges", "--onto", squashed, newest}
+	if branch != "" {
+		args = append(args, branch)
+	}
+	return r.run(nil, args...)
+}
+
+// Amend folds the staged changes into HEAD, keeping its author. An empty
+// message keeps HEAD's message too.
+func (r *Repo) Amend(message string) (string, error) {
+	if err := r.Allow(OpRewrite, r.CurrentBranch()); err != nil {
+		return "", err
+	}
+	args := []string{"commit", "--amend", "--no-edit"}
+	if message != "" {
+		args = []string{"commit", "--amend", "-m", message}
+	}
+	return r.journaled("amend", "soft", false, func() (string, error) {
+		return r.run(nil, args...)
+	})
+}
+
+// AmendStat is the diff stat of HEAD as it is and as amending the staged
+// changes into it would make it.
+func (r *Repo) AmendStat() (before, after string, err error) {
+	before, err = r.run(nil, "show", "--stat=72", "--format=", "HEAD")
+	if err != nil {
+		return "", "", err
+	}
+	base := emptyTree
+	if _, err := r.run(nil, "rev-parse", "--verify", "--quiet", "HEAD^"); err == nil {
+		base = "HEAD^"
+	}
+	after, err = r.run(nil, "diff", "--cached", "--stat=72", base)
+	return before, after, err
+}
+
+// HeadMessage is HEAD's full commit message.
+func (r *Repo) HeadMessage() (string, error) {
+	return r.run(nil, "log", "-1", "--format=%B", "HEAD")
+}
+
+// PublishedOn lists the remote-tracking branches that already contain rev.
+func (r *Repo) PublishedOn(rev string) ([]string, error) {
+	out, err := r.run(nil, "branch", "-r", "--format=%(refname:short)", "--contains", rev)
+	if err != nil || out == "" {
+		return nil, err
+	}
+	var branches []string
+	for _, name := range strings.Split(out, "\n") {
+		if !strings.HasSuffix(name, "/HEAD") {
+			branches = append(branches, name)
+		}
+	}
+	return branches, nil
+}
+
+// Reset moves HEAD, and the branch it points to, to target. mode is one of
+// ResetModes, as in git reset --<mode>.
+func (r *Repo) Reset(target, mode string) (string, error) {
+	if err := r.Allow(OpRewrite, r.CurrentBranch()); err != nil {
+		return "", err
+	}
+	return r.journaled("reset --"+mode, mode, mode == "hard", func() (string, error) {
+		return r.run(nil, "reset", "--"+mode, target)
+	})
+}
+
+// ResetModes in the order the reset prompt offers them.
+var ResetModes = []string{"soft", "mixed", "hard"}
+
+// DirtyFiles lists tracked files with staged or unstaged changes, one
+// porcelain status line each. Untracked files are left out: no reset
+// touches them.
+func (r *Repo) DirtyFiles() ([]string, error) {
+	out, err := r.run(nil, "status", "--porcelain", "--untracked-files=no")
+	if err != nil || out == "" {
+		return nil, err
+	}
+	return strings.Split(out, "\n"), nil
+}
+
+// ShortHash abbreviates rev as git itself does, for a one-off hash not
+// worth reading an abbreviation index for.
+func (r *Repo) ShortHash(rev string) (string, error) {
+	return r.run(nil, "rev-parse", "--short", "--verify", rev+"^{commit}")
+}
+
+// CountCommits counts the commits in a revision range such as "a..b".
+func (r *Repo) CountCommits(rangeSpec string) (int, error) {
+	out, err := r.run(nil, "rev-list", "--count", rangeSpec)
+	if err != nil {
+		return 0, err
+	}
+	return strconv.Atoi(out)
+}
+
+// DeleteBranch bookmarks the branch tip under PrunedRefPrefix before
+// deleting it, so RestoreBranch (or the user) can bring it back.
+func (r *Repo) DeleteBranch(name, tip string) error {
+	if err := r.Allow(OpDelete, name); err != nil {
+		return err
+	}
+	_, err := r.journaled("delete "+name, "soft", false, func() (string, error) {
+		if out, err := r.run(nil, "update-ref", "-m", "arbor: prune "+name, PrunedRefPrefix+name, tip); err != nil {
+			return out, err
+		}
+		return r.run(nil, "branch", "-D", name)
+	})
+	return err
+}
+
+func (r *Repo) RestoreBranch(name, tip string) error {
+	if err := r.Allow(OpRestore, name); err != nil {
+		return err
+	}
+	if _, err := r.journaled("restore "+name, "soft", false, func() (string, error) {
+		return r.run(nil, "branch", name, tip)
+	}); err != nil {
+		return err
+	}
+	_, err := r.run(nil, "update-ref", "-d", PrunedRefPrefix+name)
+	return err
+}
+
+// Editor returns the editor git itself would launch, honouring GIT_EDITOR,
+// core.editor, VISUAL, and EDITOR in that order.
+func (r *Repo) Editor() (string, error) {
+	return r.run(nil, "var", "GIT_EDITOR")
+}
+
+// EditorCommand runs editor on path through the shell, as git does, so
+// values like "code --wait" work.
+func (r *Repo) EditorCommand(editor, path string) *exec.Cmd {
+	cmd := exec.Command("sh", "-c", editor+` "$@"`, editor, path)
+	cmd.Dir = r.Dir
+	return cmd
+}
+
+// autoCommentChars are what core.commentChar=auto picks from, as in git.
+const autoCommentChars = "#;@!$%^&|:"
+
+// CommentChar is what starts a comment line in a message edited for r:
+// core.commentChar, "#" by default. For "auto" it is the first of git's
+// candidates that starts no line of message.
+func (r *Repo) CommentChar(message string) string {
+	char, _ := r.run(nil, "config", "--get", "core.commentChar")
+	switch char {
+	case "":
+		return "#"
+	case "auto":
+		used := make(map[rune]bool)
+		for _, line := range strings.Split(message, "\n") {
+			if line = strings.TrimLeft(line, " \t"); line != "" {
+				used[rune(line[0])] = true
+			}
+		}
+		for _, c := range autoCommentChars {
+			if !used[c] {
+				return string(c)
+			}
+		}
+		return "#"
+	}
+	return char
+}
+
+// CleanMessage drops lines starting with comment and surrounding blank
+// lines the way git's default commit cleanup does.
+func CleanMessage(text, comment string) string {
+	var lines []string
+	for _, line := range strings.Split(text, "\n") {
+		if strings.HasPrefix(line, comment) {
+			continue
+		}
+		lines = append(lines, strings.TrimRight(line, " \t\r"))
+	}
+	return strings.TrimSpace(strings.Join(lines, "\n"))
+}
+
+// CheckoutPullRequest switches to a local branch pr/<number> at hash,
+// creating it, or just switching when it already points there. Creating
+// it is journaled, so undo deletes the branch once HEAD has left it.
+func (r *Repo) CheckoutPullRequest(number, hash string) (string, error) {
+	branch := "pr/" + number
+	if err := r.Allow(OpCommit, branch); err != nil {
+		return "", err
+	}
+	return r.journaled("check out "+branch, "soft", false, func() (string, error) {
+		existing, _ := r.run(nil, "rev-parse", "--verify", "--quiet", "refs/heads/"+branch)
+		switch existing {
+		case "":
+			return r.run(nil, "switch", "-c", branch, hash)
+		case hash:
+			return r.run(nil, "switch", branch)
+		}
+		return "", fmt.Errorf("%s already exists at %s, but PR #%s is at %s; update or delete it first", branch, existing[:7], number, hash[:7])
+	})
+}
@@ -0,0 +1,18 @@
+package gitops
+
+import "testing"
+
+func TestCleanMessage(t *testing.T) {
+	tests := []struct {
+		text, comment, want string
+	}{
+		{"subject\n\nbody  \n# hint\n", "#", "subject\n\nbody"},
+		{"\n\nsubject\n#1 is fixed\n; hint\n", ";", "subject\n#1 is fixed"},
+		{"# only comments\n", "#", ""},
+	}
+	for _, tt := range tests {
+		if got := CleanMessage(tt.text, tt.comment); got != tt.want {
+			t.Errorf("CleanMessage(%q, %q) = %q, want %q", tt.text, tt.comment, got, tt.want)
+		}
+	}
+}
@@ -0,0 +1,264 @@
+package gitops
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+)
+
+// FilePatch is one file's part of a working-tree diff: the header lines
+// git apply needs, then its hunks.
+type FilePatch struct {
+	Path   string
+	Header []string
+	Hunks  []Hunk
+	// Whole is set for a change git shows without hunks, such as a binary
+	// file or a mode change, which can only be staged as a whole.
+	Whole bool
+}
+
+// Hunk is one @@ section of a patch. Lines start with ' ', '-', '+', or
+// '\' for git's "\ No newline at end of file".
+type Hunk struct {
+	OldStart, OldLines int
+	NewStart, NewLines int
+	Lines              []string
+}
+
+func (h Hunk) Header() string {
+	return fmt.Sprintf("@@ -%d,%d +%d,%d @@", h.OldStart, h.OldLines, h.NewStart, h.NewLines)
+}
+
+// Split breaks h at the unchanged lines between its changes into hunks
+// that each apply on their own, as git add -p's split does; the unchanged
+// lines between two changes are context for both. A hunk with a single
+// run of changes comes back whole.
+func (h Hunk) Split() []Hunk {
+	// Runs of changed lines, as [start, end) indexes into h.Lines.
+	var runs [][2]int
+	for i := 0; i < len(h.Lines); i++ {
+		if c := h.Lines[i][0]; c == ' ' || c == '\\' {
+			continue
+		}
+		start := i
+		for i < len(h.Lines) && h.Lines[i][0] != ' ' {
+			i++
+		}
+		runs = append(runs, [2]int{start, i})
+	}
+	if len(runs) < 2 {
+		return []Hunk{h}
+	}
+	hunks := make([]Hunk, 0, len(runs))
+	for k := range runs {
+		from, to := 0, len(h.Lines)
+		if k > 0 {
+			from = runs[k-1][1]
+		}
+		if k < len(runs)-1 {
+			to = runs[k+1][0]
+		}
+		part := Hunk{OldStart: h.OldStart, NewStart: h.NewStart, Lines: h.Lines[from:to]}
+		for _, line := range h.Lines[:from] {
+			oldSide, newSide := sides(line)
+			part.OldStart += oldSide
+			part.NewStart += newSide
+		}
+		for _, line := range part.Lines {
+			oldSide, newSide := sides(line)
+			part.OldLines += oldSide
+			part.NewLines += newSide
+		}
+		hunks = append(hunks, part)
+	}
+	return hunks
+}
+
+// sides counts a patch line toward the old and new versions.
+func sides(line string) (oldSide, newSide int) {
+	switch line[0] {
+	case ' ':
+		return 1, 1
+	case '-':
+		return 1, 0
+	case '+':
+		return 0, 1
+	}
+	return 0, 0
+}
+
+// WorktreeDiff is the working tree's changes to tracked files against the
+// index or, with staged, the index's against HEAD.
+func (r *Repo) WorktreeDiff(staged bool) ([]FilePatch, error) {
+	// Explicit prefixes and no renames keep the patch in the one shape
+	// parsePatch reads and git apply takes back, whatever the diff config.
+	args := []string{"diff", "--no-color", "--no-ext-diff", "--no-textconv", "--no-renames",
+		"--src-prefix=a/", "--dst-prefix=b/", "--unified=3"}
+	if staged {
+		args = append(args, "--cached")
+	}
+	out, err := r.run(nil, args...)
+	if err != nil {
+		return nil, err
+	}
+	return parsePatch(out)
+}
+
+// parsePatch reads git diff output. Hunk bodies are read by the line
+// counts in their headers, so a blank context line the output lost to
+// trimming is still one.
+func parsePatch(out string) ([]FilePatch, error) {
+	var files []FilePatch
+	lines := strings.Split(out, "\n")
+	for i := 0; i < len(lines); {
+		line := lines[i]
+		if !strings.HasPrefix(line, "diff --git ") {
+			i++
+			continue
+		}
+		file := FilePatch{Header: []string{line}}
+		for i++; i < len(lines) && !strings.HasPrefix(lines[i], "@@") && !strings.HasPrefix(lines[i], "diff --git "); i++ {
+			file.Header = append(file.Header, lines[i])
+			if path, ok := strings.CutPrefix(lines[i], "+++ "); ok && path != "/dev/null" {
+				file.Path = strings.TrimPrefix(unquotePath(path), "b/")
+			} else if path, ok := strings.CutPrefix(lines[i], "--- "); ok && path != "/dev/null" && file.Path == "" {
+				file.Path = strings.TrimPrefix(unquotePath(path), "a/")
+			}
+		}
+		if file.Path == "" {
+			file.Path = headerPath(line)
+		}
+		for i < len(lines) && strings.HasPrefix(lines[i], "@@") {
+			hunk, err := parseHunkHeader(lines[i])
+			if err != nil {
+				return nil, err
+			}
+			oldLeft, newLeft := hunk.OldLines, hunk.NewLines
+			for i++; oldLeft > 0 || newLeft > 0 || i < len(lines) && strings.HasPrefix(lines[i], `\`); i++ {
+				body := " "
+				if i < len(lines) && lines[i] != "" {
+					body = lines[i]
+				}
+				oldSide, newSide := sides(body)
+				oldLeft -= oldSide
+				newLeft -= newSide
+				hunk.Lines = append(hunk.Lines, body)
+			}
+			file.Hunks = append(file.Hunks, hunk)
+		}
+		file.Whole = len(file.Hunks) == 0
+		files = append(files, file)
+	}
+	return files, nil
+}
+
+// parseHunkHeader reads "@@ -a,b +c,d @@", where a missing count is 1.
+func parseHunkHeader(line string) (Hunk, error) {
+	fields := strings.Fields(line)
+	if len(fields) < 3 || !strings.HasPrefix(fields[1], "-") || !strings.HasPrefix(fields[2], "+") {
+		return Hunk{}, fmt.Errorf("unexpected hunk header %q", line)
+	}
+	var h Hunk
+	var err error
+	if h.OldStart, h.OldLines, err = parseRange(fields[1][1:]); err != nil {
+		return Hunk{}, fmt.Errorf("unexpected hunk header %q", line)
+	}
+	if h.NewStart, h.NewLines, err = parseRange(fields[2][1:]); err != nil {
+		return Hunk{}, fmt.Errorf("unexpected hunk header %q", line)
+	}
+	return h, nil
+}
+
+func parseRange(s string) (start, count int, err error) {
+	startText, countText, hasCount := strings.Cut(s, ",")
+	if start, err = strconv.Atoi(startText); err != nil {
+		return 0, 0, err
+	}
+	if !hasCount {
+		return start, 1, nil
+	}
+	count, err = strconv.Atoi(countText)
+	return start, count, err
+}
+
+// unquotePath reads a path git quoted for having unusual characters.
+func unquotePath(path string) string {
+	if strings.HasPrefix(path, `"`) {
+		if unquoted, err := strconv.Unquote(path); err == nil {
+			return unquoted
+		}
+	}
+	return path
+}
+
+// headerPath takes the path from "diff --git a/<path> b/<path>", for
+// changes with no ---/+++ lines, where both halves are the same path.
+func headerPath(line string) string {
+	rest := strings.TrimPrefix(line, "diff --git ")
+	if half := len(rest) / 2; len(rest) > 4 && rest[half] == ' ' {
+		return strings.TrimPrefix(rest[:half], "a/")
+	}
+	return rest
+}
+
+// patch is file's header with hunk alone, for git apply.
+func (f FilePatch) patch(hunk Hunk) string {
+	lines := append(append(append([]string(nil), f.Header...), hunk.Header()), hunk.Lines...)
+	return strings.Join(lines, "\n") + "\n"
+}
+
+// StageHunk adds one hunk of file's working-tree diff, or a piece Split
+// made of one, to the index.
+func (r *Repo) StageHunk(file FilePatch, hunk Hunk) error {
+	return r.applyCached(file.patch(hunk), false)
+}
+
+// UnstageHunk takes one hunk of file's staged diff back out of the index,
+// leaving the working tree as it is.
+func (r *Repo) UnstageHunk(file FilePatch, hunk Hunk) error {
+	return r.applyCached(file.patch(hunk), true)
+}
+
+func (r *Repo) applyCached(patch string, reverse bool) error {
+	if err := r.Allow(OpCommit, ""); err != nil {
+		return err
+	}
+	if err := r.waitForLocks(); err != nil {
+		return err
+	}
+	args := []string{"apply", "--cached", "--recount", "--whitespace=nowarn"}
+	if reverse {
+		args = append(args, "--reverse")
+	}
+	_, err := r.runInput(nil, patch, append(args, "-")...)
+	return err
+}
+
+// StageFile adds all of path's changes to the index.
+func (r *Repo) StageFile(path string) error {
+	if err := r.Allow(OpCommit, ""); err != nil {
+		return err
+	}
+	if err := r.waitForLocks(); err != nil {
+		return err
+	}
+	_, err := r.run(nil, "add", "--", path)
+	return err
+}
+
+// UnstageFile puts path in the index back as HEAD has it, or takes it out
+// of the index when HEAD does not have it.
+func (r *Repo) UnstageFile(path string) error {
+	if err := r.Allow(OpCommit, ""); err != nil {
+		return err
+	}
+	if err := r.waitForLocks(); err != nil {
+		return err
+	}
+	if _, err := r.run(nil, "rev-parse", "--verify", "--quiet", "HEAD"); err != nil {
+		_, err = r.run(nil, "rm", "--cached", "--quiet", "--", path)
+		return err
+	}
+	_, err := r.run(nil, "reset", "--quiet", "HEAD", "--", path)
+	return err
+}
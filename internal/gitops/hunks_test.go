@@ -0,0 +1,260 @@
+package gitops
+
+import (
+	"reflect"
+	"strings"
+	"testing"
+)
+
+func TestParseHunkHeader(t *testing.T) {
+	tests := []struct {
+		line    string
+		want    Hunk
+		wantErr bool
+	}{
+		{line: "@@ -1,3 +1,4 @@", want: Hunk{OldStart: 1, OldLines: 3, NewStart: 1, NewLines: 4}},
+		{line: "@@ -10,7 +12,6 @@ func main() {", want: Hunk{OldStart: 10, OldLines: 7, NewStart: 12, NewLines: 6}},
+		{line: "@@ -5 +5 @@", want: Hunk{OldStart: 5, OldLines: 1, NewStart: 5, NewLines: 1}},
+		{line: "@@ -0,0 +1,2 @@", want: Hunk{OldStart: 0, OldLines: 0, NewStart: 1, NewLines: 2}},
+		{line: "@@ -1,2 +0,0 @@", want: Hunk{OldStart: 1, OldLines: 2, NewStart: 0, NewLines: 0}},
+		{line: "@@ -a,1 +1 @@", wantErr: true},
+		{line: "@@ -1,x +1 @@", wantErr: true},
+		{line: "@@ 1,1 +1 @@", wantErr: true},
+		{line: "@@", wantErr: true},
+	}
+	for _, tt := range tests {
+		got, err := parseHunkHeader(tt.line)
+		if (err != nil) != tt.wantErr {
+			t.Errorf("parseHunkHeader(%q) error = %v, want error %v", tt.line, err, tt.wantErr)
+			continue
+		}
+		if !reflect.DeepEqual(got, tt.want) {
+			t.Errorf("parseHunkHeader(%q) = %+v, want %+v", tt.line, got, tt.want)
+		}
+	}
+}
+
+func TestHeaderPath(t *testing.T) {
+	tests := []struct {
+		line, want string
+	}{
+		{"diff --git a/run.sh b/run.sh", "run.sh"},
+		{"diff --git a/docs/a b.md b/docs/a b.md", "docs/a b.md"},
+		{"diff --git a/x b/x", "x"},
+		// Halves that differ are not split; the line is returned as given.
+		{"diff --git a/old b/new1", "a/old b/new1"},
+	}
+	for _, tt := range tests {
+		if got := headerPath(tt.line); got != tt.want {
+			t.Errorf("headerPath(%q) = %q, want %q", tt.line, got, tt.want)
+		}
+	}
+}
+
+func TestParsePatch(t *testing.T) {
+	tests := []struct {
+		name string
+		out  string
+		want []FilePatch
+	}{
+		{
+			name: "modified",
+			out: `diff --git a/f b/f
+index 1111111..2222222 100644
+--- a/f
++++ b/f
+@@ -1,3 +1,3 @@
+ a
+-b
++B
+ c`,
+			want: []FilePatch{{
+				Path:   "f",
+				Header: []string{"diff --git a/f b/f", "index 1111111..2222222 100644", "--- a/f", "+++ b/f"},
+				Hunks:  []Hunk{{OldStart: 1, OldLines: 3, NewStart: 1, NewLines: 3, Lines: []string{" a", "-b", "+B", " c"}}},
+			}},
+		},
+		{
+			name: "no newline at end of file",
+			out: `diff --git a/f b/f
+index 1111111..2222222 100644
+--- a/f
++++ b/f
+@@ -1,2 +1,2 @@
+ a
+-b
+\ No newline at end of file
++b
+`,
+			want: []FilePatch{{
+				Path:   "f",
+				Header: []string{"diff --git a/f b/f", "index 1111111..2222222 100644", "--- a/f", "+++ b/f"},
+				Hunks: []Hunk{{OldStart: 1, OldLines: 2, NewStart: 1, NewLines: 2,
+					Lines: []string{" a", "-b", `\ No newline at end of file`, "+b"}}},
+			}},
+		},
+		{
+			name: "blank context line trimmed",
+			out:  "diff --git a/f b/f\n--- a/f\n+++ b/f\n@@ -1,3 +1,3 @@\n-a\n+A\n\n c\n",
+			want: []FilePatch{{
+				Path:   "f",
+				Header: []string{"diff --git a/f b/f", "--- a/f", "+++ b/f"},
+				Hunks:  []Hunk{{OldStart: 1, OldLines: 3, NewStart: 1, NewLines: 3, Lines: []string{"-a", "+A", " ", " c"}}},
+			}},
+		},
+		{
+			name: "new and deleted files",
+			out: `diff --git a/new b/new
+new file mode 100644
+index 0000000..1111111
+--- /dev/null
++++ b/new
+@@ -0,0 +1 @@
++hello
+diff --git a/gone b/gone
+deleted file mode 100644
+index 1111111..0000000
+--- a/gone
++++ /dev/null
+@@ -1 +0,0 @@
+-bye`,
+			want: []FilePatch{
+				{
+					Path:   "new",
+					Header: []string{"diff --git a/new b/new", "new file mode 100644", "index 0000000..1111111", "--- /dev/null", "+++ b/new"},
+					Hunks:  []Hunk{{OldStart: 0, OldLines: 0, NewStart: 1, NewLines: 1, Lines: []string{"+hello"}}},
+				},
+				{
+					Path:   "gone",
+					Header: []string{"diff --git a/gone b/gone", "deleted file mode 100644", "index 1111111..0000000", "--- a/gone", "+++ /dev/null"},
+					Hunks:  []Hunk{{OldStart: 1, OldLines: 1, NewStart: 0, NewLines: 0, Lines: []string{"-bye"}}},
+				},
+			},
+		},
+		{
+			name: "quoted path",
+			out: `diff --git "a/caf\303\251 \"1\".txt" "b/caf\303\251 \"1\".txt"
+--- "a/caf\303\251 \"1\".txt"
++++ "b/caf\303\251 \"1\".txt"
+@@ -1 +1 @@
+-a
++b`,
+			want: []FilePatch{{
+				Path: `café "1".txt`,
+				Header: []string{`diff --git "a/caf\303\251 \"1\".txt" "b/caf\303\251 \"1\".txt"`,
+					`--- "a/caf\303\251 \"1\".txt"`, `+++ "b/caf\303\251 \"1\".txt"`},
+				Hunks: []Hunk{{OldStart: 1, OldLines: 1, NewStart: 1, NewLines: 1, Lines: []string{"-a", "+b"}}},
+			}},
+		},
+		{
+			name: "mode change and binary",
+			out: `diff --git a/run.sh b/run.sh
+old mode 100644
+new mode 100755
+diff --git a/logo.png b/logo.png
+index 1111111..2222222 100644
+Binary files a/logo.png and b/logo.png differ`,
+			want: []FilePatch{
+				{Path: "run.sh", Header: []string{"diff --git a/run.sh b/run.sh", "old mode 100644", "new mode 100755"}, Whole: true},
+				{Path: "logo.png", Header: []string{"diff --git a/logo.png b/logo.png", "index 1111111..2222222 100644", "Binary files a/logo.png and b/logo.png differ"}, Whole: true},
+			},
+		},
+		{name: "empty", out: "", want: nil},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := parsePatch(tt.out)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("parsePatch =\n%#v\nwant\n%#v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestParsePatchBadHeader(t *testing.T) {
+	if _, err := parsePatch("diff --git a/f b/f\n--- a/f\n+++ b/f\n@@ -x +1 @@\n-a\n+b\n"); err == nil {
+		t.Error("parsePatch accepted a bad hunk header")
+	}
+}
+
+// hunk builds a hunk from its lines, counting both sides from start.
+func hunk(oldStart, newStart int, lines ...string) Hunk {
+	h := Hunk{OldStart: oldStart, NewStart: newStart, Lines: lines}
+	for _, line := range lines {
+		oldSide, newSide := sides(line)
+		h.OldLines += oldSide
+		h.NewLines += newSide
+	}
+	return h
+}
+
+func TestHunkSplit(t *testing.T) {
+	tests := []struct {
+		name string
+		hunk Hunk
+		want []Hunk
+	}{
+		{
+			name: "one run",
+			hunk: hunk(1, 1, " a", "-b", "+B", " c"),
+			want: []Hunk{hunk(1, 1, " a", "-b", "+B", " c")},
+		},
+		{
+			name: "context between two runs goes to both",
+			hunk: hunk(10, 20, " a", "-b", "+B", " c", " d", "-e", " f"),
+			want: []Hunk{
+				hunk(10, 20, " a", "-b", "+B", " c", " d"),
+				hunk(12, 22, " c", " d", "-e", " f"),
+			},
+		},
+		{
+			name: "three runs",
+			hunk: hunk(1, 1, "-a", " b", "+c", " d", "-e", "+E"),
+			want: []Hunk{
+				hunk(1, 1, "-a", " b"),
+				hunk(2, 1, " b", "+c", " d"),
+				hunk(3, 3, " d", "-e", "+E"),
+			},
+		},
+		{
+			name: "runs at the edges",
+			hunk: hunk(5, 5, "+x", " a", "-y"),
+			want: []Hunk{
+				hunk(5, 5, "+x", " a"),
+				hunk(5, 6, " a", "-y"),
+			},
+		},
+		{
+			name: "no newline marker stays with its run",
+			hunk: hunk(1, 1, "-a", "+A", " b", "-c", `\ No newline at end of file`, "+c"),
+			want: []Hunk{
+				hunk(1, 1, "-a", "+A", " b"),
+				hunk(2, 2, " b", "-c", `\ No newline at end of file`, "+c"),
+			},
+		},
+		{
+			name: "context only",
+			hunk: hunk(1, 1, " a", " b"),
+			want: []Hunk{hunk(1, 1, " a", " b")},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got := tt.hunk.Split()
+			if !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("Split =\n%s\nwant\n%s", hunkLines(got), hunkLines(tt.want))
+			}
+		})
+	}
+}
+
+func hunkLines(hunks []Hunk) string {
+	var b strings.Builder
+	for _, h := range hunks {
+		b.WriteString(h.Header() + "\n" + strings.Join(h.Lines, "\n") + "\n")
+	}
+	return b.String()
+}
@@ -0,0 +1,135 @@
+package gitops
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+)
+
+// Operation is a multi-step git command left partway: stopped on a
+// conflict, or waiting for the user, as with bisect.
+type Operation struct {
+	// Kind is "rebase", "merge", "cherry-pick", "revert", or "bisect".
+	Kind string
+	// Head is the commit being merged, picked, or reverted, or the one a
+	// rebase stopped at.
+	Head string
+	// Rebase is set for a rebase, with its progress.
+	Rebase *RebaseProgress
+	// Conflicts are the paths with unresolved merge conflicts.
+	Conflicts []string
+}
+
+// InProgress reports the operation left in progress, found the way git
+// status finds it, or nil when there is none.
+func (r *Repo) InProgress() (*Operation, error) {
+	rebase, err := r.RebaseState()
+	if err != nil {
+		return nil, err
+	}
+	if rebase != nil {
+		return &Operation{Kind: "rebase", Head: rebase.Stopped, Rebase: rebase, Conflicts: rebase.Conflicts}, nil
+	}
+	for _, state := range []struct{ file, kind string }{
+		{"MERGE_HEAD", "merge"},
+		{"CHERRY_PICK_HEAD", "cherry-pick"},
+		{"REVERT_HEAD", "revert"},
+	} {
+		path, err := r.gitPath(state.file)
+		if err != nil {
+			return nil, err
+		}
+		data, err := os.ReadFile(path)
+		if err != nil {
+			continue
+		}
+		head, _, _ := strings.Cut(strings.TrimSpace(string(data)), "\n")
+		op := &Operation{Kind: state.kind, Head: head}
+		out, err := r.run(nil, "diff", "--name-only", "--diff-filter=U")
+		if err != nil {
+			return nil, err
+		}
+		if out != "" {
+			op.Conflicts = strings.Split(out, "\n")
+		}
+		return op, nil
+	}
+	path, err := r.gitPath("BISECT_LOG")
+	if err != nil {
+		return nil, err
+	}
+	if _, err := os.Stat(path); err == nil {
+		return &Operation{Kind: "bisect"}, nil
+	}
+	return nil, nil
+}
+
+// Continue carries op on once its conflicts are resolved and staged,
+// keeping the prepared message instead of opening an editor.
+func (r *Repo) Continue(op *Operation) (string, error) {
+	if err := r.Allow(OpCommit, ""); err != nil {
+		return "", err
+	}
+	switch op.Kind {
+	case "rebase":
+		return r.RebaseContinue()
+	case "bisect":
+		return "", fmt.Errorf("a bisect carries on with git bisect good or bad")
+	}
+	return r.journaled(op.Kind+" --continue", "soft", false, func() (string, error) {
+		return r.run([]string{"GIT_EDITOR=true"}, op.Kind, "--continue")
+	})
+}
+
+// Abort puts HEAD and the working tree back as they were before op
+// started; for a bisect, back on the branch it started from. It is
+// journaled like a hard reset, stashing uncommitted changes first so undo
+// brings them back, unless conflicts are left, which git cannot stash.
+func (r *Repo) Abort(op *Operation) (string, error) {
+	if err := r.Allow(OpRestore, ""); err != nil {
+		return "", err
+	}
+	return r.journaled(op.Kind+" --abort", "hard", len(op.Conflicts) == 0, func() (string, error) {
+		switch op.Kind {
+		case "rebase":
+			return r.RebaseAbort()
+		case "bisect":
+			return r.run(nil, "bisect", "reset")
+		}
+		return r.run(nil, op.Kind, "--abort")
+	})
+}
+
+// StaleLocks are index and HEAD locks old enough that the git holding them
+// most likely crashed. Any mutation waits on them and then gives up.
+func (r *Repo) StaleLocks() ([]*LockError, error) {
+	gitDir, err := r.run(nil, "rev-parse", "--absolute-git-dir")
+	if err != nil {
+		return nil, err
+	}
+	var stale []*LockError
+	for _, name := range []string{"index.lock", "HEAD.lock"} {
+		if lock := heldLock(filepath.Join(gitDir, name)); lock != nil && lock.Stale() {
+			stale = append(stale, lock)
+		}
+	}
+	return stale, nil
+}
+
+// RemoveLock deletes a lock file left behind by a crashed git. The lock is
+// looked at again first, as a git started since it was reported may have
+// taken a fresh one at the same path.
+func (r *Repo) RemoveLock(lock *LockError) error {
+	if err := r.Allow(OpRestore, ""); err != nil {
+		return err
+	}
+	current := heldLock(lock.Path)
+	if current == nil {
+		return nil
+	}
+	if !current.Stale() {
+		return current
+	}
+	return os.Remove(lock.Path)
+}
@@ -0,0 +1,332 @@
+package gitops
+
+import (
+	"errors"
+	"fmt"
+	"sort"
+	"strings"
+	"time"
+
+	"arbor/internal/journal"
+)
+
+// JournalRefPrefix keeps working-tree stashes taken before destructive
+// operations reachable until undo applies them or they leave the journal.
+const JournalRefPrefix = "refs/arbor/journal/"
+
+var ErrNothingToUndo = errors.New("nothing to undo")
+
+// Snapshot is where every local branch, remote-tracking branch and HEAD
+// pointed at one moment.
+type Snapshot struct {
+	branch string
+	head   string
+	refs   map[string]string
+}
+
+func (r *Repo) Snapshot() (*Snapshot, error) {
+	out, err := r.run(nil, "for-each-ref", "--format=%(refname) %(objectname) %(symref)", "refs/heads/", "refs/remotes/")
+	if err != nil {
+		return nil, err
+	}
+	s := &Snapshot{branch: r.CurrentBranch(), refs: make(map[string]string)}
+	for _, line := range strings.Split(out, "\n") {
+		// A symbolic ref such as origin/HEAD moves with its target, which
+		// is recorded, and undone, on its own.
+		fields := strings.Fields(line)
+		if len(fields) == 2 {
+			s.refs[fields[0]] = fields[1]
+		}
+	}
+	// An unborn HEAD has no commit; that is recorded as "".
+	s.head, _ = r.run(nil, "rev-parse", "--verify", "--quiet", "HEAD")
+	return s, nil
+}
+
+// Record journals the refs that moved since before as one operation. mode
+// and stash are as in journal.Entry. An operation that moved no refs is
+// still journaled when it stashed the working tree, which undo brings
+// back.
+func (r *Repo) Record(title, mode, stash string, before *Snapshot) error {
+	after, err := r.Snapshot()
+	if err != nil {
+		return err
+	}
+	var changes []journal.RefChange
+	for name, old := range before.refs {
+		if now := after.refs[name]; now != old {
+			changes = append(changes, journal.RefChange{Name: name, Old: old, New: now})
+		}
+	}
+	for name, now := range after.refs {
+		if _, ok := before.refs[name]; !ok {
+			changes = append(changes, journal.RefChange{Name: name, New: now})
+		}
+	}
+	if before.branch == "" && after.branch == "" && before.head != after.head {
+		changes = append(changes, journal.RefChange{Name: "HEAD", Old: before.head, New: after.head})
+	}
+	if len(changes) == 0 && stash == "" {
+		return nil
+	}
+	sort.Slice(changes, func(i, j int) bool { return changes[i].Name < changes[j].Name })
+	return r.addEntry(journal.Entry{Title: title, Time: time.Now(), Branch: before.branch, Refs: changes, Mode: mode, Stash: stash})
+}
+
+func (r *Repo) addEntry(e journal.Entry) error {
+	commonDir, err := r.CommonDir()
+	if err != nil {
+		return err
+	}
+	unlock, err := lockFile(journal.Path(commonDir))
+	if err != nil {
+		return err
+	}
+	defer unlock()
+	j, err := journal.Load(commonDir)
+	if err != nil {
+		return err
+	}
+	dropped := j.Add(e)
+	for _, e := range dropped {
+		if e.Stash != "" {
+			_, _ = r.run(nil, "update-ref", "-d", JournalRefPrefix+e.Stash)
+		}
+	}
+	return j.Save()
+}
+
+// journaled runs op as one journal entry, or just runs it when r is already
+// inside one. With saveWorktree, uncommitted changes are stashed first so
+// undo can bring them back.
+func (r *Repo) journaled(title, mode string, saveWorktree bool, op func() (string, error)) (string, error) {
+	if r.inJournal {
+		return op()
+	}
+	if err := r.waitForLocks(); err != nil {
+		return "", err
+	}
+	before, err := r.Snapshot()
+	if err != nil {
+		return "", err
+	}
+	stash := ""
+	if saveWorktree {
+		if stash, err = r.stashWorktree(); err != nil {
+			return "", err
+		}
+	}
+	out, opErr := op()
+	// A failed rebase can still have moved refs, so record either way.
+	if err := r.Record(title, mode, stash, before); err != nil && opErr == nil {
+		opErr = fmt.Errorf("undo journal: %w", err)
+	}
+	return out, opErr
+}
+
+// stashWorktree snapshots uncommitted changes without touching them,
+// returning "" when there are none.
+func (r *Repo) stashWorktree() (string, error) {
+	stash, err := r.run(nil, "stash", "create")
+	if err != nil || stash == "" {
+		return "", err
+	}
+	_, err = r.run(nil, "update-ref", JournalRefPrefix+stash, stash)
+	return stash, err
+}
+
+// QuickStash stashes the uncommitted changes to tracked files with git
+// stash push, under a name saying what they were set aside for, so a
+// checkout, reset or rebase can run on a clean working tree. The stash is
+// journaled as an operation of its own: undo, like ApplyStash, brings the
+// changes back. It returns the stash commit, or an error if there was
+// nothing to stash, when git stash push leaves refs/stash where it was.
+func (r *Repo) QuickStash(action string) (string, error) {
+	if err := r.Allow(OpCommit, ""); err != nil {
+		return "", err
+	}
+	if err := r.waitForLocks(); err != nil {
+		return "", err
+	}
+	before, _ := r.run(nil, "rev-parse", "--verify", "--quiet", "refs/stash")
+	message := fmt.Sprintf("arbor: before %s (%s)", action, time.Now().Format("2006-01-02 15:04"))
+	if _, err := r.run(nil, "stash", "push", "-m", message); err != nil {
+		return "", err
+	}
+	stash, _ := r.run(nil, "rev-parse", "--verify", "--quiet", "refs/stash")
+	if stash == "" || stash == before {
+		return "", errors.New("git stash: no local changes to save")
+	}
+	if _, err := r.run(nil, "update-ref", JournalRefPrefix+stash, stash); err != nil {
+		return "", err
+	}
+	e := journal.Entry{Title: "stash before " + action, Time: time.Now(), Branch: r.CurrentBranch(), Mode: "soft", Stash: stash}
+	return stash, r.addEntry(e)
+}
+
+// ApplyStash re-applies a stash QuickStash took onto whatever the
+// operation left checked out, drops it from the stash list, and marks its
+// journal entry undone, since there is nothing left for undo to bring back.
+func (r *Repo) ApplyStash(stash string) (string, error) {
+	if err := r.Allow(OpCommit, ""); err != nil {
+		return "", err
+	}
+	if err := r.waitForLocks(); err != nil {
+		return "", err
+	}
+	out, err := r.applyStash(stash, false)
+	if err != nil {
+		return out, err
+	}
+	commonDir, err := r.CommonDir()
+	if err != nil {
+		return out, err
+	}
+	unlock, err := lockFile(journal.Path(commonDir))
+	if err != nil {
+		return out, err
+	}
+	defer unlock()
+	j, err := journal.Load(commonDir)
+	if err != nil {
+		return out, err
+	}
+	for i := range j.Entries {
+		if j.Entries[i].Stash == stash {
+			j.Entries[i].Undone = true
+		}
+	}
+	return out, j.Save()
+}
+
+// applyStash applies a journaled stash, with its index when the working
+// tree is back where the stash was taken, then lets go of it: its journal
+// ref, and its place in the stash list if it has one.
+func (r *Repo) applyStash(stash string, index bool) (string, error) {
+	args := []string{"stash", "apply"}
+	if index {
+		args = append(args, "--index")
+	}
+	out, err := r.run(nil, append(args, stash)...)
+	if err != nil {
+		return out, err
+	}
+	_, _ = r.run(nil, "update-ref", "-d", JournalRefPrefix+stash)
+	list, _ := r.run(nil, "stash", "list", "--format=%H")
+	for i, hash := range strings.Split(list, "\n") {
+		if hash == stash {
+			_, _ = r.run(nil, "stash", "drop", fmt.Sprintf("stash@{%d}", i))
+			break
+		}
+	}
+	return out, nil
+}
+
+// Journaled records everything op does through the handle it is given as
+// a single operation, such as deleting several branches at once.
+func (r *Repo) Journaled(title string, op func(*Repo) (string, error)) (string, error) {
+	inner := *r
+	inner.inJournal = true
+	return r.journaled(title, "soft", false, func() (string, error) {
+		return op(&inner)
+	})
+}
+
+// LastOperation is the operation Undo would revert, or nil.
+func (r *Repo) LastOperation() (*journal.Entry, error) {
+	commonDir, err := r.CommonDir()
+	if err != nil {
+		return nil, err
+	}
+	j, err := journal.Load(commonDir)
+	if err != nil {
+		return nil, err
+	}
+	return j.Last(), nil
+}
+
+// Undo reverts the newest journaled operation not yet undone. It refuses
+// when any ref the operation moved has moved again since, and when a hard
+// reset back would throw away uncommitted changes.
+func (r *Repo) Undo() (*journal.Entry, error) {
+	// Entries that only saved a stash move no refs for the checks below;
+	// read-only mode still refuses to apply it or rewrite the journal.
+	if err := r.Allow(OpRewrite, ""); err != nil {
+		return nil, err
+	}
+	if err := r.waitForLocks(); err != nil {
+		return nil, err
+	}
+	commonDir, err := r.CommonDir()
+	if err != nil {
+		return nil, err
+	}
+	unlock, err := lockFile(journal.Path(commonDir))
+	if err != nil {
+		return nil, err
+	}
+	defer unlock()
+	j, err := journal.Load(commonDir)
+	if err != nil {
+		return nil, err
+	}
+	e := j.Last()
+	if e == nil {
+		return nil, ErrNothingToUndo
+	}
+	current := r.CurrentBranch()
+	checkedOut := func(c journal.RefChange) bool {
+		return c.Name == "refs/heads/"+current || c.Name == "HEAD" && current == ""
+	}
+	for _, c := range e.Refs {
+		if err := r.Allow(OpRewrite, strings.TrimPrefix(c.Name, "refs/heads/")); err != nil {
+			return nil, err
+		}
+		now, _ := r.run(nil, "rev-parse", "--verify", "--quiet", c.Name)
+		if now != c.New {
+			return nil, fmt.Errorf("%s has moved since %q; not undoing", shortRef(c.Name), e.Title)
+		}
+		if checkedOut(c) && c.Old == "" {
+			return nil, fmt.Errorf("%q created the checked-out branch %s; switch away to undo it", e.Title, shortRef(c.Name))
+		}
+		if checkedOut(c) && e.Mode == "hard" {
+			dirty, err := r.DirtyFiles()
+			if err != nil {
+				return nil, err
+			}
+			if len(dirty) > 0 {
+				return nil, fmt.Errorf("undoing %q resets the working tree; commit or stash the %d changed files first", e.Title, len(dirty))
+			}
+		}
+	}
+	for _, c := range e.Refs {
+		if checkedOut(c) {
+			_, err = r.run(nil, "reset", "--"+e.Mode, c.Old)
+		} else if c.Old == "" {
+			_, err = r.run(nil, "update-ref", "-d", c.Name, c.New)
+		} else {
+			_, err = r.run(nil, "update-ref", "-m", "arbor: undo "+e.Title, c.Name, c.Old, c.New)
+		}
+		if err != nil {
+			return nil, err
+		}
+	}
+	e.Undone = true
+	undone := *e
+	if err := j.Save(); err != nil {
+		return nil, err
+	}
+	if e.Stash != "" {
+		if _, err := r.applyStash(e.Stash, true); err != nil {
+			return nil, fmt.Errorf("refs restored, but the saved working tree did not apply; it is kept at %s: %w", JournalRefPrefix+e.Stash, err)
+		}
+	}
+	return &undone, nil
+}
+
+func shortRef(name string) string {
+	if rest, ok := strings.CutPrefix(name, "refs/remotes/"); ok {
+		return rest
+	}
+	return strings.TrimPrefix(name, "refs/heads/")
+}
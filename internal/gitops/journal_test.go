@@ -0,0 +1,92 @@
+package gitops
+
+import (
+	"errors"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"testing"
+)
+
+// gitRepo makes a repository with one commit of file f.
+func gitRepo(t *testing.T) string {
+	t.Helper()
+	dir := t.TempDir()
+	t.Setenv("GIT_CONFIG_GLOBAL", os.DevNull)
+	t.Setenv("GIT_CONFIG_NOSYSTEM", "1")
+	for _, args := range [][]string{
+		{"init", "-q"},
+		{"config", "user.name", "A"},
+		{"config", "user.email", "a@example.com"},
+	} {
+		if out, err := exec.Command("git", append([]string{"-C", dir}, args...)...).CombinedOutput(); err != nil {
+			t.Fatalf("git %v: %v\n%s", args, err, out)
+		}
+	}
+	if err := os.WriteFile(filepath.Join(dir, "f"), []byte("one\n"), 0o644); err != nil {
+		t.Fatal(err)
+	}
+	for _, args := range [][]string{{"add", "f"}, {"commit", "-q", "-m", "one"}} {
+		if out, err := exec.Command("git", append([]string{"-C", dir}, args...)...).CombinedOutput(); err != nil {
+			t.Fatalf("git %v: %v\n%s", args, err, out)
+		}
+	}
+	return dir
+}
+
+func TestUndoStashReadOnly(t *testing.T) {
+	dir := gitRepo(t)
+	if err := os.WriteFile(filepath.Join(dir, "f"), []byte("two\n"), 0o644); err != nil {
+		t.Fatal(err)
+	}
+	r := New(dir)
+	if _, err := r.QuickStash("reset"); err != nil {
+		t.Fatal(err)
+	}
+
+	r.ReadOnly = true
+	if _, err := r.Undo(); !errors.Is(err, ErrReadOnly) {
+		t.Fatalf("Undo read-only = %v, want ErrReadOnly", err)
+	}
+	last, err := r.LastOperation()
+	if err != nil || last == nil || last.Stash == "" || last.Undone {
+		t.Errorf("last operation = %+v, %v, want the stash entry left as it was", last, err)
+	}
+	if data, _ := os.ReadFile(filepath.Join(dir, "f")); string(data) != "one\n" {
+		t.Errorf("f = %q, want the stash left unapplied", data)
+	}
+
+	r.ReadOnly = false
+	if _, err := r.Undo(); err != nil {
+		t.Fatal(err)
+	}
+	if data, _ := os.ReadFile(filepath.Join(dir, "f")); string(data) != "two\n" {
+		t.Errorf("f = %q after undo, want the stashed change back", data)
+	}
+}
+
+func TestUndoCheckoutPullRequest(t *testing.T) {
+	dir := gitRepo(t)
+	r := New(dir)
+	home := r.CurrentBranch()
+	hash, err := r.run(nil, "rev-parse", "HEAD")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, err := r.CheckoutPullRequest("7", hash); err != nil {
+		t.Fatal(err)
+	}
+	last, err := r.LastOperation()
+	if err != nil || last == nil || len(last.Refs) != 1 || last.Refs[0].Name != "refs/heads/pr/7" {
+		t.Fatalf("last operation = %+v, %v, want the branch pr/7 created", last, err)
+	}
+	if _, err := r.run(nil, "switch", home); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := r.Undo(); err != nil {
+		t.Fatal(err)
+	}
+	if out, _ := r.run(nil, "rev-parse", "--verify", "--quiet", "refs/heads/pr/7"); out != "" {
+		t.Errorf("pr/7 = %s after undo, want it deleted", out)
+	}
+}
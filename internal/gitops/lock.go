@@ -0,0 +1,108 @@
+package gitops
+
+import (
+	"errors"
+	"fmt"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"regexp"
+	"time"
+)
+
+// lockWait is how long a mutation waits for another process to release
+// the index or HEAD before giving up with a LockError.
+const lockWait = 2 * time.Second
+
+// staleLock is the age past which a lock file is more likely left behind
+// by a crashed git than held by a running one.
+const staleLock = 10 * time.Minute
+
+var lockRe = regexp.MustCompile(`Unable to create '([^']+\.lock)': File exists`)
+
+// LockError reports a lock file held by another process: an editor or IDE
+// refreshing the index, or a git command running in another terminal. The
+// operation did not start (or stopped before changing anything) and can
+// simply be retried once the lock is gone.
+type LockError struct {
+	Path string
+	Age  time.Duration
+}
+
+func (e *LockError) Error() string {
+	return fmt.Sprintf("%s is locked by another git process", e.Path)
+}
+
+// Stale reports whether the lock has been held long enough that no git is
+// likely to be holding it any more.
+func (e *LockError) Stale() bool {
+	return e.Age >= staleLock
+}
+
+// lockError recognises git failing to take a lock in a command's stderr.
+func lockError(stderr string) *LockError {
+	match := lockRe.FindStringSubmatch(stderr)
+	if match == nil {
+		return nil
+	}
+	e := &LockError{Path: match[1]}
+	if info, err := os.Stat(e.Path); err == nil {
+		e.Age = time.Since(info.ModTime())
+	}
+	return e
+}
+
+// waitForLocks holds a mutation back while another process has the index
+// or HEAD locked, so arbor queues behind an IDE's index refresh instead of
+// failing halfway through a multi-step operation.
+func (r *Repo) waitForLocks() error {
+	gitDir, err := r.run(nil, "rev-parse", "--absolute-git-dir")
+	if err != nil {
+		return err
+	}
+	deadline := time.Now().Add(lockWait)
+	for {
+		held := heldLock(filepath.Join(gitDir, "index.lock"), filepath.Join(gitDir, "HEAD.lock"))
+		if held == nil {
+			return nil
+		}
+		if time.Now().After(deadline) {
+			return held
+		}
+		time.Sleep(100 * time.Millisecond)
+	}
+}
+
+func heldLock(paths ...string) *LockError {
+	for _, path := range paths {
+		if info, err := os.Stat(path); err == nil {
+			return &LockError{Path: path, Age: time.Since(info.ModTime())}
+		}
+	}
+	return nil
+}
+
+// lockFile takes path+".lock" the way git does, by creating it exclusively,
+// waiting up to lockWait for another arbor to let go. The returned func
+// releases it.
+func lockFile(path string) (func(), error) {
+	lock := path + ".lock"
+	if err := os.MkdirAll(filepath.Dir(lock), 0o755); err != nil {
+		return nil, err
+	}
+	deadline := time.Now().Add(lockWait)
+	for {
+		f, err := os.OpenFile(lock, os.O_CREATE|os.O_EXCL|os.O_WRONLY, 0o644)
+		if err == nil {
+			f.Close()
+			return func() { os.Remove(lock) }, nil
+		}
+		if !errors.Is(err, fs.ErrExist) {
+			return nil, err
+		}
+		if held := heldLock(lock); held != nil && time.Now().After(deadline) {
+			return nil, held
+		}
+		time.Sleep(100 * time.Millisecond)
+	}
+}
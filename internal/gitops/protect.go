@@ -0,0 +1,59 @@
+package gitops
+
+import (
+	"errors"
+	"fmt"
+	"path"
+)
+
+// Op is a class of mutation that read-only mode and branch protection can
+// refuse.
+type Op string
+
+const (
+	OpCommit  Op = "commit"
+	OpRewrite Op = "rewrite"
+	OpDelete  Op = "delete"
+	OpRestore Op = "restore"
+)
+
+var ErrReadOnly = errors.New("arbor is running read-only")
+
+// ProtectedError reports an operation refused on a protected branch.
+type ProtectedError struct {
+	Op      Op
+	Branch  string
+	Pattern string
+}
+
+func (e *ProtectedError) Error() string {
+	return fmt.Sprintf("%s is protected (%s): arbor will not %s it", e.Branch, e.Pattern, e.Op)
+}
+
+// Allow reports whether op may run against branch. Read-only mode refuses
+// everything; protected branches refuse rewrites and deletion but still take
+// new commits. An empty branch (detached HEAD) is never protected.
+func (r *Repo) Allow(op Op, branch string) error {
+	if r.ReadOnly {
+		return fmt.Errorf("%s: %w", op, ErrReadOnly)
+	}
+	if branch == "" || (op != OpRewrite && op != OpDelete) {
+		return nil
+	}
+	for _, pattern := range r.Protected {
+		if ok, _ := path.Match(pattern, branch); ok {
+			return &ProtectedError{Op: op, Branch: branch, Pattern: pattern}
+		}
+	}
+	return nil
+}
+
+// CurrentBranch is the short name of the checked-out branch, or "" when HEAD
+// is detached.
+func (r *Repo) CurrentBranch() string {
+	out, err := r.run(nil, "symbolic-ref", "--quiet", "--short", "HEAD")
+	if err != nil {
+		return ""
+	}
+	return out
+}
@@ -0,0 +1,162 @@
+package gitops
+
+import (
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+)
+
+// RebaseOnto replays the current branch's commits after upstream onto onto,
+// as in git rebase --onto onto upstream. When a commit does not apply, git
+// stops with the rebase in progress; see RebaseState.
+func (r *Repo) RebaseOnto(onto, upstream string) (string, error) {
+	if err := r.Allow(OpRewrite, r.CurrentBranch()); err != nil {
+		return "", err
+	}
+	return r.journaled("rebase --onto", "soft", false, func() (string, error) {
+		return r.run(nil, "rebase", "--onto", onto, upstream)
+	})
+}
+
+// Replayed lists the commits a rebase of HEAD past upstream would replay,
+// oldest first, as "<short hash> <subject>", and how many merge commits it
+// would drop (rebase flattens them).
+func (r *Repo) Replayed(upstream string) (commits []string, merges int, err error) {
+	out, err := r.run(nil, "log", "--reverse", "--no-merges", "--format=%h %s", upstream+"..HEAD")
+	if err != nil {
+		return nil, 0, err
+	}
+	if out != "" {
+		commits = strings.Split(out, "\n")
+	}
+	out, err = r.run(nil, "rev-list", "--count", "--merges", upstream+"..HEAD")
+	if err != nil {
+		return nil, 0, err
+	}
+	merges, err = strconv.Atoi(out)
+	return commits, merges, err
+}
+
+// MergeBase is the best common ancestor of a and b.
+func (r *Repo) MergeBase(a, b string) (string, error) {
+	return r.run(nil, "merge-base", a, b)
+}
+
+// RebaseProgress describes a rebase that stopped partway, whether started
+// by arbor or by hand.
+type RebaseProgress struct {
+	// Branch is the branch being rebased, "" for a detached HEAD.
+	Branch string
+	Onto   string
+	// Step counts the commits applied so far, including the one stopped
+	// at, out of Total.
+	Step, Total int
+	// Stopped is the commit that did not apply, when git recorded it.
+	Stopped string
+	// Conflicts are the paths with unresolved merge conflicts.
+	Conflicts []string
+}
+
+// RebaseState reports the rebase in progress, or nil when there is none.
+func (r *Repo) RebaseState() (*RebaseProgress, error) {
+	dir, err := r.gitPath("rebase-merge")
+	if err != nil {
+		return nil, err
+	}
+	step, total := "msgnum", "end"
+	if !isDir(dir) {
+		if dir, err = r.gitPath("rebase-apply"); err != nil {
+			return nil, err
+		}
+		if !isDir(dir) {
+			return nil, nil
+		}
+		step, total = "next", "last"
+	}
+	p := &RebaseProgress{
+		Branch:  strings.TrimPrefix(readState(dir, "head-name"), "refs/heads/"),
+		Onto:    readState(dir, "onto"),
+		Stopped: readState(dir, "stopped-sha"),
+	}
+	if p.Branch == "detached HEAD" {
+		p.Branch = ""
+	}
+	p.Step, _ = strconv.Atoi(readState(dir, step))
+	p.Total, _ = strconv.Atoi(readState(dir, total))
+	out, err := r.run(nil, "diff", "--name-only", "--diff-filter=U")
+	if err != nil {
+		return nil, err
+	}
+	if out != "" {
+		p.Conflicts = strings.Split(out, "\n")
+	}
+	return p, nil
+}
+
+// RebaseContinue commits the resolved (and staged) commit and carries on,
+// keeping its message instead of opening an editor.
+func (r *Repo) RebaseContinue() (string, error) {
+	if err := r.Allow(OpRewrite, r.rebasing()); err != nil {
+		return "", err
+	}
+	return r.journaled("rebase --continue", "soft", false, func() (string, error) {
+		return r.run([]string{"GIT_EDITOR=true"}, "rebase", "--continue")
+	})
+}
+
+// RebaseSkip drops the commit that did not apply and carries on.
+func (r *Repo) RebaseSkip() (string, error) {
+	if err := r.Allow(OpRewrite, r.rebasing()); err != nil {
+		return "", err
+	}
+	return r.journaled("rebase --skip", "soft", false, func() (string, error) {
+		return r.run([]string{"GIT_EDITOR=true"}, "rebase", "--skip")
+	})
+}
+
+// RebaseAbort puts the branch and working tree back as they were before
+// the rebase started.
+func (r *Repo) RebaseAbort() (string, error) {
+	if err := r.Allow(OpRewrite, r.rebasing()); err != nil {
+		return "", err
+	}
+	if err := r.waitForLocks(); err != nil {
+		return "", err
+	}
+	return r.run(nil, "rebase", "--abort")
+}
+
+// rebasing is the branch the rebase in progress rewrites; HEAD is detached
+// from it until the rebase ends.
+func (r *Repo) rebasing() string {
+	if state, err := r.RebaseState(); err == nil && state != nil {
+		return state.Branch
+	}
+	return r.CurrentBranch()
+}
+
+// gitPath is where git keeps name inside the git directory.
+func (r *Repo) gitPath(name string) (string, error) {
+	path, err := r.run(nil, "rev-parse", "--git-path", name)
+	if err != nil {
+		return "", err
+	}
+	if !filepath.IsAbs(path) {
+		path = filepath.Join(r.Dir, path)
+	}
+	return path, nil
+}
+
+func isDir(path string) bool {
+	info, err := os.Stat(path)
+	return err == nil && info.IsDir()
+}
+
+func readState(dir, name string) string {
+	data, err := os.ReadFile(filepath.Join(dir, name))
+	if err != nil {
+		return ""
+	}
+	return strings.TrimSpace(string(data))
+}
@@ -0,0 +1,315 @@
+package gitops
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"strconv"
+	"strings"
+)
+
+// askpassEnv marks a process started by git or ssh as askpass, which arbor
+// answers from the file askpassAnswers names instead of starting up
+// normally. Answers never go in the environment itself, which every hook,
+// credential helper and ssh child inherits and /proc exposes.
+const (
+	askpassEnv     = "ARBOR_ASKPASS"
+	askpassAnswers = "ARBOR_ASKPASS_ANSWERS"
+	askpassLog     = "ARBOR_ASKPASS_LOG"
+)
+
+// RemoteAuth is per-remote configuration for fetch and push. Without it,
+// git's own setup applies: the ssh agent and keys in ~/.ssh, and credential
+// helpers for https.
+type RemoteAuth struct {
+	// SSHKey is a private key to use instead of the agent's.
+	SSHKey string
+	// Username answers https username prompts.
+	Username string
+	// TokenEnv names an environment variable holding an https token, which
+	// answers password prompts.
+	TokenEnv string
+}
+
+// Answers maps prompts git or ssh asked, such as "Enter passphrase for key
+// '/home/me/.ssh/id_ed25519': ", to what the user typed.
+type Answers map[string]string
+
+// AuthError is a fetch or push that stopped at a prompt nobody had
+// answered. Retry with the answer added under Prompt.
+type AuthError struct {
+	Remote string
+	Prompt string
+}
+
+func (e *AuthError) Error() string {
+	return fmt.Sprintf("%s: %s", e.Remote, strings.TrimSpace(e.Prompt))
+}
+
+// Secret reports whether the prompt asks for something not to be echoed.
+func (e *AuthError) Secret() bool {
+	p := strings.ToLower(e.Prompt)
+	return strings.Contains(p, "pass") || strings.Contains(p, "token") || strings.Contains(p, "pin")
+}
+
+func (r *Repo) Remotes() ([]string, error) {
+	out, err := r.run(nil, "remote")
+	if err != nil || out == "" {
+		return nil, err
+	}
+	return strings.Split(out, "\n"), nil
+}
+
+// Upstream is where branch pushes to: its configured remote and branch, or
+// "" when it has none.
+func (r *Repo) Upstream(branch string) (remote, merge string) {
+	remote, _ = r.run(nil, "config", "--get", "branch."+branch+".remote")
+	merge, _ = r.run(nil, "config", "--get", "branch."+branch+".merge")
+	return remote, strings.TrimPrefix(merge, "refs/heads/")
+}
+
+// Fetch fetches every remote in turn, pruning deleted branches. It stops at
+// the first prompt it cannot answer with an *AuthError.
+func (r *Repo) Fetch(answers Answers) (string, error) {
+	// --prune deletes remote-tracking refs.
+	if err := r.Allow(OpDelete, ""); err != nil {
+		return "", err
+	}
+	remotes, err := r.Remotes()
+	if err != nil {
+		return "", err
+	}
+	if len(remotes) == 0 {
+		return "", errors.New("no remotes configured")
+	}
+	var out []string
+	for _, remote := range remotes {
+		if _, err := r.runRemote(remote, answers, "fetch", "--prune", remote); err != nil {
+			return strings.Join(out, "\n"), err
+		}
+		out = append(out, "Fetched "+remote+".")
+	}
+	return strings.Join(out, "\n"), nil
+}
+
+// StaleRef is a remote-tracking ref whose branch is gone from the remote.
+type StaleRef struct {
+	// Name is the full ref name, as refs/remotes/origin/topic.
+	Name string
+	Hash string
+	// Subject is the first line of the commit it points at.
+	Subject string
+}
+
+// Short is the ref as git log shows it, as origin/topic.
+func (s StaleRef) Short() string {
+	return strings.TrimPrefix(s.Name, "refs/remotes/")
+}
+
+// StaleRemoteRefs asks every remote which of its remote-tracking refs
+// point at branches it no longer has, as git remote prune --dry-run does,
+// without deleting them. It stops at the first prompt it cannot answer
+// with an *AuthError.
+func (r *Repo) StaleRemoteRefs(answers Answers) ([]StaleRef, error) {
+	remotes, err := r.Remotes()
+	if err != nil {
+		return nil, err
+	}
+	if len(remotes) == 0 {
+		return nil, errors.New("no remotes configured")
+	}
+	var stale []StaleRef
+	for _, remote := range remotes {
+		out, err := r.runRemote(remote, answers, "remote", "prune", "--dry-run", remote)
+		if err != nil {
+			return nil, err
+		}
+		for _, line := range strings.Split(out, "\n") {
+			_, short, ok := strings.Cut(line, "[would prune] ")
+			if !ok {
+				continue
+			}
+			name := "refs/remotes/" + strings.TrimSpace(short)
+			tip, err := r.run(nil, "for-each-ref", "--format=%(objectname) %(contents:subject)", name)
+			if err != nil || tip == "" {
+				continue
+			}
+			hash, subject, _ := strings.Cut(tip, " ")
+			stale = append(stale, StaleRef{Name: name, Hash: hash, Subject: subject})
+		}
+	}
+	return stale, nil
+}
+
+// OnlyReachableFrom counts the commits no ref but refs reaches: those that
+// leave an --all view once refs are deleted.
+func (r *Repo) OnlyReachableFrom(refs []StaleRef) (int, error) {
+	args := []string{"rev-list", "--count"}
+	for _, ref := range refs {
+		args = append(args, ref.Hash)
+	}
+	args = append(args, "--not")
+	for _, ref := range refs {
+		args = append(args, "--exclude="+ref.Name)
+	}
+	out, err := r.run(nil, append(args, "--all")...)
+	if err != nil {
+		return 0, err
+	}
+	return strconv.Atoi(out)
+}
+
+// DeleteRemoteRefs deletes refs, each only if it still points where it
+// did, so a fetch in between is not undone. A later fetch recreates any
+// whose branch comes back, and undo restores them until then.
+func (r *Repo) DeleteRemoteRefs(refs []StaleRef) (string, error) {
+	for _, ref := range refs {
+		if err := r.Allow(OpDelete, ref.Short()); err != nil {
+			return "", err
+		}
+	}
+	var input strings.Builder
+	for _, ref := range refs {
+		fmt.Fprintf(&input, "delete %s %s\n", ref.Name, ref.Hash)
+	}
+	_, err := r.journaled("prune remote branches", "soft", false, func() (string, error) {
+		return r.runInput(nil, input.String(), "update-ref", "--stdin")
+	})
+	if err != nil {
+		return "", err
+	}
+	return fmt.Sprintf("Pruned %d remote-tracking refs.", len(refs)), nil
+}
+
+// Push pushes branch to remote's merge branch, setting it as the upstream
+// when the branch has none. It never forces.
+func (r *Repo) Push(branch, remote, merge string, answers Answers) (string, error) {
+	if err := r.Allow(OpCommit, branch); err != nil {
+		return "", err
+	}
+	args := []string{"push", remote, "refs/heads/" + branch + ":refs/heads/" + merge}
+	if up, _ := r.Upstream(branch); up == "" {
+		args = []string{"push", "--set-upstream", remote, branch}
+	}
+	if _, err := r.runRemote(remote, answers, args...); err != nil {
+		return "", err
+	}
+	return fmt.Sprintf("Pushed %s to %s/%s.", branch, remote, merge), nil
+}
+
+// runRemote runs a git command that talks to remote without ever prompting
+// on the terminal: git and ssh ask arbor itself (see RunAskpass), which
+// answers from answers and the remote's RemoteAuth, and records any prompt
+// it cannot answer so it can be returned as an *AuthError.
+func (r *Repo) runRemote(remote string, answers Answers, args ...string) (string, error) {
+	exe, err := os.Executable()
+	if err != nil {
+		return "", err
+	}
+	log, err := os.CreateTemp("", "arbor-askpass-*")
+	if err != nil {
+		return "", err
+	}
+	log.Close()
+	defer os.Remove(log.Name())
+
+	auth := r.RemoteAuth[remote]
+	all := Answers{}
+	for prompt, answer := range answers {
+		all[prompt] = answer
+	}
+	if auth.Username != "" {
+		all["username"] = auth.Username
+	}
+	if token := os.Getenv(auth.TokenEnv); auth.TokenEnv != "" && token != "" {
+		all["password"] = token
+	}
+	encoded, err := json.Marshal(all)
+	if err != nil {
+		return "", err
+	}
+	// CreateTemp makes the file readable by the user alone.
+	file, err := os.CreateTemp("", "arbor-answers-*")
+	if err != nil {
+		return "", err
+	}
+	defer os.Remove(file.Name())
+	_, err = file.Write(encoded)
+	if closeErr := file.Close(); err == nil {
+		err = closeErr
+	}
+	if err != nil {
+		return "", err
+	}
+	env := []string{
+		"GIT_TERMINAL_PROMPT=0",
+		"GIT_ASKPASS=" + exe,
+		"SSH_ASKPASS=" + exe,
+		"SSH_ASKPASS_REQUIRE=force",
+		askpassEnv + "=1",
+		askpassAnswers + "=" + file.Name(),
+		askpassLog + "=" + log.Name(),
+	}
+	if auth.SSHKey != "" {
+		env = append(env, "GIT_SSH_COMMAND=ssh -o IdentitiesOnly=yes -i "+shellQuote(expandHome(auth.SSHKey)))
+	}
+	out, err := r.run(env, args...)
+	if err != nil {
+		if prompt, _ := os.ReadFile(log.Name()); len(prompt) > 0 {
+			return out, &AuthError{Remote: remote, Prompt: string(prompt)}
+		}
+	}
+	return out, err
+}
+
+// IsAskpass reports whether this process was started by git or ssh to ask
+// for a credential.
+func IsAskpass() bool {
+	return os.Getenv(askpassEnv) == "1"
+}
+
+// RunAskpass answers one prompt, passed as the only argument, and returns
+// the exit code. Username and password prompts from git fall back to the
+// remote's configured username and token. An unanswered prompt is written
+// to the log for runRemote and fails, so git gives up instead of waiting.
+func RunAskpass(args []string, stdout io.Writer) int {
+	prompt := strings.Join(args, " ")
+	var answers Answers
+	if path := os.Getenv(askpassAnswers); path != "" {
+		if data, err := os.ReadFile(path); err == nil {
+			_ = json.Unmarshal(data, &answers)
+		}
+	}
+	answer, ok := answers[prompt]
+	if !ok {
+		switch lower := strings.ToLower(prompt); {
+		case strings.HasPrefix(lower, "username for"):
+			answer, ok = answers["username"]
+		case strings.HasPrefix(lower, "password for"):
+			answer, ok = answers["password"]
+		}
+	}
+	if ok {
+		fmt.Fprintln(stdout, answer)
+		return 0
+	}
+	if path := os.Getenv(askpassLog); path != "" {
+		_ = os.WriteFile(path, []byte(prompt), 0o600)
+	}
+	return 1
+}
+
+func expandHome(path string) string {
+	if rest, ok := strings.CutPrefix(path, "~/"); ok {
+		if home, err := os.UserHomeDir(); err == nil {
+			return home + "/" + rest
+		}
+	}
+	return path
+}
+
+func shellQuote(s string) string {
+	return "'" + strings.ReplaceAll(s, "'", `'\''`) + "'"
+}
@@ -0,0 +1,53 @@
+package gitops
+
+import (
+	"slices"
+	"strings"
+)
+
+// Signature is git's verdict on a commit's signature (%G?: G good, U good
+// from a key of unknown trust, N none, B bad, E unverifiable, ...) and the
+// signing key's ID and fingerprints, as far as git reports them.
+type Signature struct {
+	Status string
+	Keys   []string
+}
+
+func (s Signature) Signed() bool {
+	return s.Status != "N"
+}
+
+// Good is a good signature, however far the key is trusted; a policy
+// listing allowed keys decides that.
+func (s Signature) Good() bool {
+	return s.Status == "G" || s.Status == "U"
+}
+
+// Signatures verifies the signatures of commits, by full hash, with gpg
+// or ssh-keygen as git is configured to (gpg.format,
+// gpg.ssh.allowedSignersFile).
+func (r *Repo) Signatures(hashes []string) (map[string]Signature, error) {
+	const batch = 500
+	sigs := make(map[string]Signature, len(hashes))
+	for start := 0; start < len(hashes); start += batch {
+		args := []string{"log", "--no-walk=unsorted", "--format=%H%x1f%G?%x1f%GK%x1f%GF%x1f%GP"}
+		out, err := r.run(nil, append(args, hashes[start:min(start+batch, len(hashes))]...)...)
+		if err != nil {
+			return nil, err
+		}
+		for _, line := range strings.Split(out, "\n") {
+			fields := strings.Split(line, "\x1f")
+			if len(fields) != 5 {
+				continue
+			}
+			sig := Signature{Status: fields[1]}
+			for _, key := range fields[2:] {
+				if key != "" && !slices.Contains(sig.Keys, key) {
+					sig.Keys = append(sig.Keys, key)
+				}
+			}
+			sigs[fields[0]] = sig
+		}
+	}
+	return sigs, nil
+}
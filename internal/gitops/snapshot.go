@@ -0,0 +1,123 @@
+package gitops
+
+import (
+	"fmt"
+	"strings"
+)
+
+// SnapshotRefPrefix holds saved ref positions, one namespace per snapshot:
+// refs/arbor/snapshots/<name>/heads/main for refs/heads/main, and
+// refs/arbor/snapshots/<name>/HEAD for the commit HEAD was on. Being refs,
+// they also keep commits a later rebase drops from being pruned.
+const SnapshotRefPrefix = "refs/arbor/snapshots/"
+
+// SaveSnapshot records where HEAD and every branch, remote-tracking branch,
+// and tag point as snapshot name, replacing an earlier one of that name,
+// and returns how many refs it saved. It only writes under refs/arbor/,
+// but read-only mode still refuses it.
+func (r *Repo) SaveSnapshot(name string) (int, error) {
+	if err := r.Allow(OpCommit, ""); err != nil {
+		return 0, err
+	}
+	prefix, err := r.snapshotPrefix(name)
+	if err != nil {
+		return 0, err
+	}
+	out, err := r.run(nil, "for-each-ref", "--format=%(refname) %(objectname)", "refs/heads/", "refs/remotes/", "refs/tags/")
+	if err != nil {
+		return 0, err
+	}
+	saved := make(map[string]string)
+	for _, line := range strings.Split(out, "\n") {
+		ref, hash, ok := strings.Cut(line, " ")
+		if !ok || strings.HasSuffix(ref, "/HEAD") {
+			continue
+		}
+		saved[prefix+strings.TrimPrefix(ref, "refs/")] = hash
+	}
+	if head, _ := r.run(nil, "rev-parse", "--verify", "--quiet", "HEAD"); head != "" {
+		saved[prefix+"HEAD"] = head
+	}
+	if len(saved) == 0 {
+		return 0, fmt.Errorf("nothing to snapshot: the repository has no commits")
+	}
+
+	var input strings.Builder
+	old, err := r.snapshotRefs(prefix)
+	if err != nil {
+		return 0, err
+	}
+	for _, ref := range old {
+		if _, ok := saved[ref]; !ok {
+			fmt.Fprintf(&input, "delete %s\n", ref)
+		}
+	}
+	for ref, hash := range saved {
+		fmt.Fprintf(&input, "update %s %s\n", ref, hash)
+	}
+	if _, err := r.runInput(nil, input.String(), "update-ref", "-m", "arbor: snapshot "+name, "--stdin"); err != nil {
+		return 0, err
+	}
+	return len(saved), nil
+}
+
+// Snapshots are the names of the saved snapshots, sorted.
+func (r *Repo) Snapshots() ([]string, error) {
+	refs, err := r.snapshotRefs(SnapshotRefPrefix)
+	if err != nil {
+		return nil, err
+	}
+	var names []string
+	for _, ref := range refs {
+		name, _, _ := strings.Cut(strings.TrimPrefix(ref, SnapshotRefPrefix), "/")
+		if len(names) == 0 || names[len(names)-1] != name {
+			names = append(names, name)
+		}
+	}
+	return names, nil
+}
+
+// DropSnapshot deletes snapshot name, letting its commits be pruned.
+func (r *Repo) DropSnapshot(name string) error {
+	if err := r.Allow(OpDelete, ""); err != nil {
+		return err
+	}
+	prefix, err := r.snapshotPrefix(name)
+	if err != nil {
+		return err
+	}
+	refs, err := r.snapshotRefs(prefix)
+	if err != nil {
+		return err
+	}
+	if len(refs) == 0 {
+		return fmt.Errorf("no snapshot named %q", name)
+	}
+	var input strings.Builder
+	for _, ref := range refs {
+		fmt.Fprintf(&input, "delete %s\n", ref)
+	}
+	_, err = r.runInput(nil, input.String(), "update-ref", "--stdin")
+	return err
+}
+
+// snapshotPrefix is the namespace of snapshot name, which has to be a
+// single valid ref component.
+func (r *Repo) snapshotPrefix(name string) (string, error) {
+	prefix := SnapshotRefPrefix + name + "/"
+	if name == "" || strings.Contains(name, "/") {
+		return "", fmt.Errorf("invalid snapshot name %q: use a single word such as pre-rebase", name)
+	}
+	if _, err := r.run(nil, "check-ref-format", prefix+"HEAD"); err != nil {
+		return "", fmt.Errorf("invalid snapshot name %q", name)
+	}
+	return prefix, nil
+}
+
+func (r *Repo) snapshotRefs(prefix string) ([]string, error) {
+	out, err := r.run(nil, "for-each-ref", "--format=%(refname)", prefix)
+	if err != nil || out == "" {
+		return nil, err
+	}
+	return strings.Split(out, "\n"), nil
+}
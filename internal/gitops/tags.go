@@ -0,0 +1,65 @@
+package gitops
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"os/exec"
+	"strings"
+)
+
+// VerifyTag checks the signature on a tag object with git verify-tag and
+// sums up the result, e.g. "good signature from Jane <jane@example.com>".
+// A signature that does not verify is a status, not an error.
+func (r *Repo) VerifyTag(object string) (string, error) {
+	cmd := exec.Command("git", "verify-tag", "--raw", object)
+	cmd.Dir = r.Dir
+	var stderr bytes.Buffer
+	cmd.Stderr = &stderr
+	err := cmd.Run()
+	var exit *exec.ExitError
+	if err != nil && !errors.As(err, &exit) {
+		return "", fmt.Errorf("git verify-tag: %w", err)
+	}
+	var last string
+	for _, line := range strings.Split(strings.TrimSpace(stderr.String()), "\n") {
+		line = strings.TrimSpace(line)
+		if line == "" {
+			continue
+		}
+		last = line
+		// GnuPG status lines: "[GNUPG:] GOODSIG <keyid> <user id>".
+		fields := strings.SplitN(strings.TrimPrefix(line, "[GNUPG:] "), " ", 3)
+		if !strings.HasPrefix(line, "[GNUPG:] ") || len(fields) < 2 {
+			continue
+		}
+		who := fields[1]
+		if len(fields) == 3 {
+			who = fields[2]
+		}
+		switch fields[0] {
+		case "GOODSIG":
+			if err != nil {
+				return "good signature from " + who + ", but not trusted", nil
+			}
+			return "good signature from " + who, nil
+		case "EXPKEYSIG":
+			return "signed by " + who + " with an expired key", nil
+		case "REVKEYSIG":
+			return "signed by " + who + " with a revoked key", nil
+		case "BADSIG":
+			return "BAD signature from " + who, nil
+		case "ERRSIG", "NO_PUBKEY":
+			return "signed by key " + fields[1] + ", which is not in the keyring", nil
+		}
+	}
+	switch {
+	case err == nil && last != "":
+		return last, nil
+	case err == nil:
+		return "good signature", nil
+	case last != "":
+		return "not verified: " + last, nil
+	}
+	return "not verified", nil
+}
@@ -0,0 +1,128 @@
+package journal
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"strings"
+	"time"
+)
+
+// maxEntries bounds the journal; older operations fall off the end.
+const maxEntries = 50
+
+// RefChange is one ref an operation moved. An empty Old or New means the
+// ref did not exist before or after.
+type RefChange struct {
+	Name string `json:"name"`
+	Old  string `json:"old"`
+	New  string `json:"new"`
+}
+
+// Entry records one mutating operation arbor performed. Mode is how undo
+// resets the checked-out branch ("soft", "mixed", or "hard"); Stash, when
+// set, is a `git stash create` commit of the working tree taken before an
+// operation that discarded it.
+type Entry struct {
+	ID     int         `json:"id"`
+	Title  string      `json:"title"`
+	Time   time.Time   `json:"time"`
+	Branch string      `json:"branch"`
+	Refs   []RefChange `json:"refs"`
+	Mode   string      `json:"mode"`
+	Stash  string      `json:"stash,omitempty"`
+	Undone bool        `json:"undone,omitempty"`
+}
+
+// Journal is the operation log under the common git directory, shared by
+// every worktree, oldest entry first.
+type Journal struct {
+	Entries []Entry `json:"entries"`
+
+	path string
+}
+
+func Path(commonDir string) string {
+	return filepath.Join(commonDir, "arbor", "journal.json")
+}
+
+func Load(commonDir string) (*Journal, error) {
+	j := &Journal{path: Path(commonDir)}
+	data, err := os.ReadFile(j.path)
+	if errors.Is(err, fs.ErrNotExist) {
+		return j, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	if err := json.Unmarshal(data, j); err != nil {
+		return nil, err
+	}
+	return j, nil
+}
+
+// Add appends e with the next id. It returns the entries that fell off the
+// end, whose stashes are no longer needed.
+func (j *Journal) Add(e Entry) []Entry {
+	e.ID = 1
+	if n := len(j.Entries); n > 0 {
+		e.ID = j.Entries[n-1].ID + 1
+	}
+	j.Entries = append(j.Entries, e)
+	if over := len(j.Entries) - maxEntries; over > 0 {
+		dropped := append([]Entry(nil), j.Entries[:over]...)
+		j.Entries = j.Entries[over:]
+		return dropped
+	}
+	return nil
+}
+
+// Last is the newest operation not yet undone, or nil.
+func (j *Journal) Last() *Entry {
+	for i := len(j.Entries) - 1; i >= 0; i-- {
+		if !j.Entries[i].Undone {
+			return &j.Entries[i]
+		}
+	}
+	return nil
+}
+
+func (j *Journal) Save() error {
+	if err := os.MkdirAll(filepath.Dir(j.path), 0o755); err != nil {
+		return err
+	}
+	data, err := json.MarshalIndent(j, "", "  ")
+	if err != nil {
+		return err
+	}
+	tmp := j.path + ".tmp"
+	if err := os.WriteFile(tmp, append(data, '\n'), 0o644); err != nil {
+		return err
+	}
+	return os.Rename(tmp, j.path)
+}
+
+// String reads "main 1a2b3c4 → 5d6e7f8", with "(none)" for a side where
+// the ref did not exist.
+func (c RefChange) String() string {
+	name, ok := strings.CutPrefix(c.Name, "refs/remotes/")
+	if !ok {
+		name = strings.TrimPrefix(c.Name, "refs/heads/")
+	}
+	return fmt.Sprintf("%s %s → %s", name, short(c.Old), short(c.New))
+}
+
+// Reversed is the move that undoes c.
+func (c RefChange) Reversed() RefChange {
+	return RefChange{Name: c.Name, Old: c.New, New: c.Old}
+}
+
+func short(hash string) string {
+	if hash == "" {
+		return "(none)"
+	}
+	return hash[:min(7, len(hash))]
+}
@@ -0,0 +1,7 @@
+package platform
+
+import "os/exec"
+
+// KillGroup leaves cmd as it is: killing the process is all cancelling
+// its context can do here.
+func KillGroup(cmd *exec.Cmd) {}
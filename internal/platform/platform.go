@@ -0,0 +1,80 @@
+package platform
+
+import (
+	"encoding/base64"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"os/exec"
+	"strings"
+)
+
+var ErrNoClipboard = errors.New("no clipboard available")
+
+// Swapped out by tests.
+var (
+	lookPath = exec.LookPath
+	getenv   = os.Getenv
+	run      = func(name string, args []string, stdin string) error {
+		cmd := exec.Command(name, args...)
+		if stdin != "" {
+			cmd.Stdin = strings.NewReader(stdin)
+		}
+		return cmd.Run()
+	}
+	start = func(name string, args []string) error {
+		return exec.Command(name, args...).Start()
+	}
+)
+
+// OSC52 returns the escape sequence asking the terminal to set its
+// clipboard. Inside tmux it is wrapped for passthrough.
+func OSC52(text string) string {
+	seq := "\x1b]52;c;" + base64.StdEncoding.EncodeToString([]byte(text)) + "\a"
+	if getenv("TMUX") != "" {
+		return "\x1bPtmux;\x1b" + seq + "\x1b\\"
+	}
+	return seq
+}
+
+// Copy puts text on the clipboard. It always emits OSC52 to term when one is
+// given, which also works over SSH, and additionally feeds the first native
+// clipboard tool found. ErrNoClipboard means neither route was possible.
+func Copy(term io.Writer, text string) error {
+	copied := false
+	if term != nil {
+		if _, err := io.WriteString(term, OSC52(text)); err == nil {
+			copied = true
+		}
+	}
+	var nativeErr error
+	for _, argv := range clipboardCommands() {
+		if _, err := lookPath(argv[0]); err != nil {
+			continue
+		}
+		if nativeErr = run(argv[0], argv[1:], text); nativeErr == nil {
+			return nil
+		}
+	}
+	if copied {
+		return nil
+	}
+	if nativeErr != nil {
+		return fmt.Errorf("copy to clipboard: %w", nativeErr)
+	}
+	return ErrNoClipboard
+}
+
+// Open hands a URL or file path to the desktop's default handler without
+// waiting for it.
+func Open(target string) error {
+	argv := openCommand(target)
+	if _, err := lookPath(argv[0]); err != nil {
+		return fmt.Errorf("open %s: %w", target, err)
+	}
+	if err := start(argv[0], argv[1:]); err != nil {
+		return fmt.Errorf("open %s: %w", target, err)
+	}
+	return nil
+}
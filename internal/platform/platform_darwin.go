@@ -0,0 +1,9 @@
+package platform
+
+func clipboardCommands() [][]string {
+	return [][]string{{"pbcopy"}}
+}
+
+func openCommand(target string) []string {
+	return []string{"open", target}
+}
@@ -0,0 +1,134 @@
+package platform
+
+import (
+	"bytes"
+	"encoding/base64"
+	"errors"
+	"os/exec"
+	"strings"
+	"testing"
+)
+
+type call struct {
+	name  string
+	args  []string
+	stdin string
+}
+
+// stub replaces the process hooks for one test. Only tools listed in
+// installed are found on PATH; failing ones exit with an error.
+func stub(t *testing.T, env map[string]string, installed []string, failing ...string) *[]call {
+	t.Helper()
+	origLook, origEnv, origRun, origStart := lookPath, getenv, run, start
+	t.Cleanup(func() { lookPath, getenv, run, start = origLook, origEnv, origRun, origStart })
+
+	var calls []call
+	lookPath = func(name string) (string, error) {
+		for _, tool := range installed {
+			if tool == name {
+				return "/usr/bin/" + name, nil
+			}
+		}
+		return "", exec.ErrNotFound
+	}
+	getenv = func(key string) string { return env[key] }
+	fail := func(name string) error {
+		for _, tool := range failing {
+			if tool == name {
+				return errors.New("exit status 1")
+			}
+		}
+		return nil
+	}
+	run = func(name string, args []string, stdin string) error {
+		calls = append(calls, call{name, args, stdin})
+		return fail(name)
+	}
+	start = func(name string, args []string) error {
+		calls = append(calls, call{name: name, args: args})
+		return fail(name)
+	}
+	return &calls
+}
+
+func TestOSC52(t *testing.T) {
+	stub(t, nil, nil)
+	want := "\x1b]52;c;" + base64.StdEncoding.EncodeToString([]byte("abc123")) + "\a"
+	if got := OSC52("abc123"); got != want {
+		t.Fatalf("OSC52 = %q, want %q", got, want)
+	}
+}
+
+func TestOSC52Tmux(t *testing.T) {
+	stub(t, map[string]string{"TMUX": "/tmp/tmux-0/default,1,0"}, nil)
+	got := OSC52("x")
+	if !strings.HasPrefix(got, "\x1bPtmux;\x1b\x1b]52;c;") || !strings.HasSuffix(got, "\a\x1b\\") {
+		t.Fatalf("OSC52 in tmux = %q, want DCS passthrough", got)
+	}
+}
+
+func TestCopyWritesOSC52AndNative(t *testing.T) {
+	native := clipboardCommands()[0][0]
+	calls := stub(t, nil, []string{native})
+	var term bytes.Buffer
+	if err := Copy(&term, "deadbeef"); err != nil {
+		t.Fatalf("Copy: %v", err)
+	}
+	if term.String() != OSC52("deadbeef") {
+		t.Errorf("terminal got %q", term.String())
+	}
+	if len(*calls) != 1 || (*calls)[0].name != native || (*calls)[0].stdin != "deadbeef" {
+		t.Errorf("native calls = %+v, want one %s with the text on stdin", *calls, native)
+	}
+}
+
+func TestCopyFallsBackToNextTool(t *testing.T) {
+	commands := clipboardCommands()
+	if len(commands) < 2 {
+		t.Skip("only one native clipboard tool on this platform")
+	}
+	first, second := commands[0][0], commands[1][0]
+	calls := stub(t, nil, []string{first, second}, first)
+	if err := Copy(nil, "x"); err != nil {
+		t.Fatalf("Copy: %v", err)
+	}
+	if len(*calls) != 2 || (*calls)[1].name != second {
+		t.Fatalf("calls = %+v, want %s after %s failed", *calls, second, first)
+	}
+}
+
+func TestCopyWithoutAnyRoute(t *testing.T) {
+	stub(t, nil, nil)
+	if err := Copy(nil, "x"); !errors.Is(err, ErrNoClipboard) {
+		t.Fatalf("Copy = %v, want ErrNoClipboard", err)
+	}
+}
+
+func TestCopyOSC52OnlyStillSucceeds(t *testing.T) {
+	stub(t, nil, nil)
+	var term bytes.Buffer
+	if err := Copy(&term, "x"); err != nil {
+		t.Fatalf("Copy: %v", err)
+	}
+}
+
+func TestOpen(t *testing.T) {
+	argv := openCommand("https://example.com/a?b=1&c=2")
+	calls := stub(t, nil, []string{argv[0]})
+	if err := Open("https://example.com/a?b=1&c=2"); err != nil {
+		t.Fatalf("Open: %v", err)
+	}
+	if len(*calls) != 1 || (*calls)[0].name != argv[0] {
+		t.Fatalf("calls = %+v, want %v", *calls, argv)
+	}
+	if args := (*calls)[0].args; args[len(args)-1] != "https://example.com/a?b=1&c=2" {
+		t.Errorf("target not passed verbatim: %v", args)
+	}
+}
+
+func TestOpenMissingTool(t *testing.T) {
+	stub(t, nil, nil)
+	if err := Open("README.md"); err == nil {
+		t.Fatal("Open succeeded without an opener on PATH")
+	}
+}
@@ -0,0 +1,20 @@
+//go:build !darwin && !windows
+
+package platform
+
+// clipboardCommands prefers the Wayland tool when a Wayland session is
+// running, then the X11 ones.
+func clipboardCommands() [][]string {
+	x11 := [][]string{
+		{"xclip", "-selection", "clipboard"},
+		{"xsel", "--clipboard", "--input"},
+	}
+	if getenv("WAYLAND_DISPLAY") != "" {
+		return append([][]string{{"wl-copy"}}, x11...)
+	}
+	return x11
+}
+
+func openCommand(target string) []string {
+	return []string{"xdg-open", target}
+}
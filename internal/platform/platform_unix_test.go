@@ -0,0 +1,25 @@
+//go:build !darwin && !windows
+
+package platform
+
+import "testing"
+
+func TestClipboardPrefersWayland(t *testing.T) {
+	stub(t, map[string]string{"WAYLAND_DISPLAY": "wayland-0"}, nil)
+	if got := clipboardCommands()[0][0]; got != "wl-copy" {
+		t.Fatalf("first clipboard tool = %s, want wl-copy", got)
+	}
+}
+
+func TestClipboardX11(t *testing.T) {
+	stub(t, nil, nil)
+	if got := clipboardCommands()[0][0]; got != "xclip" {
+		t.Fatalf("first clipboard tool = %s, want xclip", got)
+	}
+}
+
+func TestOpenUsesXdgOpen(t *testing.T) {
+	if got := openCommand("x")[0]; got != "xdg-open" {
+		t.Fatalf("opener = %s, want xdg-open", got)
+	}
+}
@@ -0,0 +1,11 @@
+package platform
+
+func clipboardCommands() [][]string {
+	return [][]string{{"clip"}}
+}
+
+// openCommand goes through rundll32 rather than `cmd /c start`, which would
+// treat & and ^ in URLs as shell syntax.
+func openCommand(target string) []string {
+	return []string{"rundll32", "url.dll,FileProtocolHandler", target}
+}
@@ -0,0 +1,139 @@
+package review
+
+import (
+	"encoding/json"
+	"errors"
+	"io/fs"
+	"net/url"
+	"os"
+	"path/filepath"
+
+	"arbor/internal/gitgraph"
+
+	git "github.com/go-git/go-git/v5"
+	"github.com/go-git/go-git/v5/plumbing"
+	"github.com/go-git/go-git/v5/plumbing/object"
+)
+
+// State is the persisted `arbor review` checklist. Commits are keyed by
+// patch id, so a rebase onto a newer base keeps them checked while an
+// edited commit does not.
+type State struct {
+	Base     string            `json:"base"`
+	Head     string            `json:"head"`
+	Reviewed map[string]string `json:"reviewed"`
+
+	path string
+}
+
+// Path is where the checklist for reviewing head against base lives: in
+// the common git directory, so every worktree sees the same one.
+func Path(commonDir, base, head string) string {
+	return filepath.Join(commonDir, "arbor", "review", url.PathEscape(head)+".."+url.PathEscape(base)+".json")
+}
+
+func Load(commonDir, base, head string) (*State, error) {
+	s := &State{Base: base, Head: head, Reviewed: make(map[string]string), path: Path(commonDir, base, head)}
+	data, err := os.ReadFile(s.path)
+	if errors.Is(err, fs.ErrNotExist) {
+		return s, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	if err := json.Unmarshal(data, s); err != nil {
+		return nil, err
+	}
+	if s.Reviewed == nil {
+		s.Reviewed = make(map[string]string)
+	}
+	return s, nil
+}
+
+func (s *State) IsReviewed(patchID string) bool {
+	_, ok := s.Reviewed[patchID]
+	return ok
+}
+
+// Toggle flips a commit's checkmark and returns the new value.
+func (s *State) Toggle(patchID string, hash plumbing.Hash) bool {
+	if s.IsReviewed(patchID) {
+		delete(s.Reviewed, patchID)
+		return false
+	}
+	s.Reviewed[patchID] = hash.String()
+	return true
+}
+
+func (s *State) Save() error {
+	if err := os.MkdirAll(filepath.Dir(s.path), 0o755); err != nil {
+		return err
+	}
+	data, err := json.MarshalIndent(s, "", "  ")
+	if err != nil {
+		return err
+	}
+	tmp := s.path + ".tmp"
+	if err := os.WriteFile(tmp, append(data, '\n'), 0o644); err != nil {
+		return err
+	}
+	return os.Rename(tmp, s.path)
+}
+
+// Reset forgets every checkmark.
+func (s *State) Reset() error {
+	s.Reviewed = make(map[string]string)
+	if err := os.Remove(s.path); err != nil && !errors.Is(err, fs.ErrNotExist) {
+		return err
+	}
+	return nil
+}
+
+// Session is one review: the commits to go through, newest first, with
+// their patch ids, and the tip and merge base for the combined diff.
+type Session struct {
+	State     *State
+	Commits   []*object.Commit
+	PatchIDs  map[plumbing.Hash]string
+	MergeBase *object.Commit
+	Tip       *object.Commit
+}
+
+func Open(repo *git.Repository, commonDir, base, head string, baseHash, headHash plumbing.Hash) (*Session, error) {
+	mergeBase, commits, err := gitgraph.ReviewRange(repo, gitgraph.NewGenerations(repo), baseHash, headHash)
+	if err != nil {
+		return nil, err
+	}
+	tip, err := repo.CommitObject(headHash)
+	if err != nil {
+		return nil, err
+	}
+	state, err := Load(commonDir, base, head)
+	if err != nil {
+		return nil, err
+	}
+	s := &Session{State: state, Commits: commits, PatchIDs: make(map[plumbing.Hash]string, len(commits)), MergeBase: mergeBase, Tip: tip}
+	for _, commit := range commits {
+		id, err := gitgraph.PatchID(commit)
+		if err != nil {
+			return nil, err
+		}
+		s.PatchIDs[commit.Hash] = id
+	}
+	return s, nil
+}
+
+func (s *Session) IsReviewed(hash plumbing.Hash) bool {
+	return s.State.IsReviewed(s.PatchIDs[hash])
+}
+
+// Progress counts reviewed commits out of all of them.
+func (s *Session) Progress() (int, int) {
+	done := 0
+	for _, commit := range s.Commits {
+		if s.IsReviewed(commit.Hash) {
+			done++
+		}
+	}
+	return done, len(s.Commits)
+}
@@ -0,0 +1,103 @@
+package server
+
+import (
+	"bufio"
+	"encoding/json"
+	"fmt"
+	"io"
+)
+
+// request is one line of the headless protocol, e.g.
+//
+//	{"id": 1, "method": "commits", "params": {"page": 0}}
+//
+// Every request gets exactly one response line carrying its id, with
+// either result or error set. Methods and params mirror the HTTP API:
+//
+//	commits  {"page": N, "size": 100} or {"offset": N, "limit": 100}
+//	commit   {"rev": "HEAD~2"}
+//	diff     {"rev": "abc1234"}
+//	blame    {"rev": "HEAD", "path": "main.go"}
+//	refs     {}
+type request struct {
+	ID     json.RawMessage `json:"id"`
+	Method string          `json:"method"`
+	Params struct {
+		Page   *int   `json:"page"`
+		Size   int    `json:"size"`
+		Offset int    `json:"offset"`
+		Limit  int    `json:"limit"`
+		Rev    string `json:"rev"`
+		Path   string `json:"path"`
+	} `json:"params"`
+}
+
+type response struct {
+	ID     json.RawMessage `json:"id"`
+	Result any             `json:"result,omitempty"`
+	Error  string          `json:"error,omitempty"`
+}
+
+// ServeLines answers JSON requests read one per line from r, writing one
+// JSON response per line to w, until r ends. It is what editor plugins talk
+// to through arbor --headless.
+func (s *Server) ServeLines(r io.Reader, w io.Writer) error {
+	scanner := bufio.NewScanner(r)
+	scanner.Buffer(make([]byte, 64*1024), 1024*1024)
+	enc := json.NewEncoder(w)
+	for scanner.Scan() {
+		line := scanner.Bytes()
+		if len(line) == 0 {
+			continue
+		}
+		var req request
+		var resp response
+		if err := json.Unmarshal(line, &req); err != nil {
+			resp.Error = fmt.Sprintf("malformed request: %v", err)
+		} else {
+			resp.ID = req.ID
+			result, err := s.call(req)
+			if err != nil {
+				resp.Error = err.Error()
+			} else {
+				resp.Result = result
+			}
+		}
+		if err := enc.Encode(resp); err != nil {
+			return err
+		}
+	}
+	return scanner.Err()
+}
+
+func (s *Server) call(req request) (any, error) {
+	p := req.Params
+	rev := p.Rev
+	if rev == "" {
+		rev = "HEAD"
+	}
+	switch req.Method {
+	case "commits":
+		offset, limit := p.Offset, p.Limit
+		if p.Page != nil {
+			limit = min(p.Size, maxPageSize)
+			if limit == 0 {
+				limit = defaultPageSize
+			}
+			offset = *p.Page * limit
+		}
+		if limit == 0 {
+			limit = defaultPageSize
+		}
+		return s.Commits(offset, limit)
+	case "commit":
+		return s.Commit(rev)
+	case "diff":
+		return s.Diff(rev)
+	case "blame":
+		return s.Blame(rev, p.Path)
+	case "refs":
+		return s.Refs()
+	}
+	return nil, fmt.Errorf("unknown method %q (use commits, commit, diff, blame, or refs)", req.Method)
+}
@@ -0,0 +1,438 @@
+package server
+
+import (
+	"crypto/sha1"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"arbor/internal/gitgraph"
+
+	git "github.com/go-git/go-git/v5"
+	"github.com/go-git/go-git/v5/plumbing"
+	"github.com/go-git/go-git/v5/plumbing/object"
+)
+
+const (
+	defaultPageSize = 100
+	maxPageSize     = 1000
+)
+
+type Server struct {
+	repo *git.Repository
+
+	// mu guards provider and refState, and serializes every read of repo:
+	// go-git's storage is not safe for concurrent use, and handlers run
+	// concurrently with each other and with a reload.
+	mu       sync.Mutex
+	provider *gitgraph.CommitProvider
+	// refState is the refs' state the provider was loaded at; see
+	// gitgraph.RefState.
+	refState string
+}
+
+func New(repo *git.Repository, provider *gitgraph.CommitProvider) *Server {
+	return &Server{repo: repo, provider: provider, refState: gitgraph.RefState(repo)}
+}
+
+// current is the provider, reloaded first when refs moved since it was
+// loaded, so commits made or fetched while serving show up. The caller
+// holds s.mu.
+func (s *Server) current() (*gitgraph.CommitProvider, error) {
+	state := gitgraph.RefState(s.repo)
+	if state == s.refState {
+		return s.provider, nil
+	}
+	next, err := s.provider.Reload()
+	if err != nil {
+		return nil, err
+	}
+	s.provider, s.refState = next, state
+	return next, nil
+}
+
+func (s *Server) Handler() http.Handler {
+	mux := http.NewServeMux()
+	mux.HandleFunc("GET /api/commits", s.handleCommits)
+	mux.HandleFunc("GET /api/commits/{hash}", s.handleCommit)
+	mux.HandleFunc("GET /api/commits/{hash}/diff", s.handleDiff)
+	mux.HandleFunc("GET /api/commits/{hash}/blame", s.handleBlame)
+	mux.HandleFunc("GET /api/refs", s.handleRefs)
+	return mux
+}
+
+type graphCell struct {
+	Ch    string `json:"ch"`
+	Color int    `json:"color"`
+}
+
+type commitJSON struct {
+	Hash      string      `json:"hash"`
+	ShortHash string      `json:"shortHash"`
+	Subject   string      `json:"subject"`
+	Author    string      `json:"author"`
+	When      time.Time   `json:"when"`
+	Parents   []string    `json:"parents"`
+	Graph     []graphCell `json:"graph,omitempty"`
+	Message   string      `json:"message,omitempty"`
+}
+
+type commitsPage struct {
+	Offset  int          `json:"offset"`
+	Limit   int          `json:"limit"`
+	Loaded  int          `json:"loaded"`
+	HasMore bool         `json:"hasMore"`
+	Commits []commitJSON `json:"commits"`
+}
+
+type fileJSON struct {
+	From      string `json:"from,omitempty"`
+	To        string `json:"to,omitempty"`
+	Binary    bool   `json:"binary"`
+	Additions int    `json:"additions"`
+	Deletions int    `json:"deletions"`
+}
+
+type diffJSON struct {
+	Hash  string     `json:"hash"`
+	Files []fileJSON `json:"files"`
+	Patch string     `json:"patch"`
+}
+
+type blameJSON struct {
+	Hash  string      `json:"hash"`
+	Path  string      `json:"path"`
+	Lines []blameLine `json:"lines"`
+}
+
+type blameLine struct {
+	Hash   string    `json:"hash"`
+	Author string    `json:"author"`
+	Email  string    `json:"email"`
+	When   time.Time `json:"when"`
+	Text   string    `json:"text"`
+}
+
+type refJSON struct {
+	Name   string `json:"name"`
+	Short  string `json:"short"`
+	Kind   string `json:"kind"`
+	Hash   string `json:"hash"`
+	Target string `json:"target"`
+}
+
+func (s *Server) handleCommits(w http.ResponseWriter, r *http.Request) {
+	offset, err := queryInt(r, "offset", 0)
+	if err != nil {
+		offset = -1
+	}
+	limit, err := queryInt(r, "limit", defaultPageSize)
+	if err != nil {
+		limit = -1
+	}
+	page, err := s.Commits(offset, limit)
+	if err != nil {
+		failJSON(w, err)
+		return
+	}
+	h := sha1.New()
+	for _, c := range page.Commits {
+		h.Write([]byte(c.Hash))
+	}
+	fmt.Fprintf(h, "%d:%d:%t", page.Offset, page.Limit, page.HasMore)
+	writeJSON(w, r, hex.EncodeToString(h.Sum(nil)), page)
+}
+
+func (s *Server) handleCommit(w http.ResponseWriter, r *http.Request) {
+	commit, err := s.Commit(r.PathValue("hash"))
+	if err != nil {
+		failJSON(w, err)
+		return
+	}
+	writeJSON(w, r, commit.Hash, commit)
+}
+
+func (s *Server) handleDiff(w http.ResponseWriter, r *http.Request) {
+	diff, err := s.Diff(r.PathValue("hash"))
+	if err != nil {
+		failJSON(w, err)
+		return
+	}
+	writeJSON(w, r, "diff-"+diff.Hash, diff)
+}
+
+func (s *Server) handleBlame(w http.ResponseWriter, r *http.Request) {
+	blame, err := s.Blame(r.PathValue("hash"), r.URL.Query().Get("path"))
+	if err != nil {
+		failJSON(w, err)
+		return
+	}
+	writeJSON(w, r, "blame-"+blame.Hash+":"+blame.Path, blame)
+}
+
+func (s *Server) handleRefs(w http.ResponseWriter, r *http.Request) {
+	refs, err := s.Refs()
+	if err != nil {
+		failJSON(w, err)
+		return
+	}
+	h := sha1.New()
+	for _, ref := range refs {
+		fmt.Fprintf(h, "%s %s\n", ref.Name, ref.Hash)
+	}
+	writeJSON(w, r, hex.EncodeToString(h.Sum(nil)), refs)
+}
+
+// Commits is a page of the walk, loading more of it as needed.
+func (s *Server) Commits(offset, limit int) (commitsPage, error) {
+	if offset < 0 {
+		return commitsPage{}, badRequest("offset must be a non-negative integer")
+	}
+	if limit <= 0 {
+		return commitsPage{}, badRequest("limit must be a positive integer")
+	}
+	limit = min(limit, maxPageSize)
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	provider, err := s.current()
+	if err != nil {
+		return commitsPage{}, err
+	}
+	if err := provider.Ensure(offset + limit); err != nil {
+		return commitsPage{}, err
+	}
+	end := min(offset+limit, len(provider.Commits))
+	page := commitsPage{Offset: offset, Limit: limit, Commits: []commitJSON{}}
+	for i := offset; i < end; i++ {
+		page.Commits = append(page.Commits, toCommitJSON(provider.Commits[i], false))
+	}
+	page.Loaded = len(provider.Commits)
+	page.HasMore = end < len(provider.Commits) || provider.HasMore()
+	return page, nil
+}
+
+// Commit is one commit with its full message.
+func (s *Server) Commit(rev string) (commitJSON, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	provider, err := s.current()
+	if err != nil {
+		return commitJSON{}, err
+	}
+	commit, err := lookup(s.repo, provider, rev)
+	if err != nil {
+		return commitJSON{}, err
+	}
+	info := &gitgraph.CommitInfo{
+		Hash:    commit.Hash,
+		Subject: gitgraph.FirstLine(commit.Message),
+		Author:  commit.Author.Name,
+		When:    commit.Committer.When,
+		Commit:  commit,
+	}
+	out := toCommitJSON(info, true)
+	out.ShortHash = provider.Abbrev().Short(commit.Hash)
+	return out, nil
+}
+
+// Diff is a commit's change against its first parent.
+func (s *Server) Diff(rev string) (diffJSON, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	provider, err := s.current()
+	if err != nil {
+		return diffJSON{}, err
+	}
+	commit, err := lookup(s.repo, provider, rev)
+	if err != nil {
+		return diffJSON{}, err
+	}
+	changes, err := gitgraph.CommitChanges(commit)
+	if err != nil {
+		return diffJSON{}, err
+	}
+	attrs := provider.Attributes()
+	patch, err := attrs.Patch(changes)
+	if err != nil {
+		return diffJSON{}, err
+	}
+	stats, err := attrs.NumStat(changes)
+	if err != nil {
+		return diffJSON{}, err
+	}
+	out := diffJSON{Hash: commit.Hash.String(), Files: []fileJSON{}, Patch: gitgraph.LoadDiffFormat(s.repo).Format(patch)}
+	for i, fp := range patch.FilePatches() {
+		from, to := fp.Files()
+		file := fileJSON{Binary: stats[i].Binary, Additions: stats[i].Added, Deletions: stats[i].Deleted}
+		if from != nil {
+			file.From = from.Path()
+		}
+		if to != nil {
+			file.To = to.Path()
+		}
+		out.Files = append(out.Files, file)
+	}
+	return out, nil
+}
+
+// Blame attributes each line of path, as of rev, to the commit that last
+// changed it.
+func (s *Server) Blame(rev, path string) (blameJSON, error) {
+	if path == "" {
+		return blameJSON{}, badRequest("path is required")
+	}
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	provider, err := s.current()
+	if err != nil {
+		return blameJSON{}, err
+	}
+	commit, err := lookup(s.repo, provider, rev)
+	if err != nil {
+		return blameJSON{}, err
+	}
+	if _, err := commit.File(path); err != nil {
+		return blameJSON{}, &apiError{status: http.StatusNotFound, msg: fmt.Sprintf("%s is not a file in %s", path, provider.Abbrev().Short(commit.Hash))}
+	}
+	result, err := git.Blame(commit, path)
+	if err != nil {
+		return blameJSON{}, err
+	}
+	out := blameJSON{Hash: commit.Hash.String(), Path: path, Lines: []blameLine{}}
+	for _, line := range result.Lines {
+		out.Lines = append(out.Lines, blameLine{
+			Hash:   line.Hash.String(),
+			Author: line.AuthorName,
+			Email:  line.Author,
+			When:   line.Date,
+			Text:   line.Text,
+		})
+	}
+	return out, nil
+}
+
+// Refs are HEAD, branches, remote branches, and tags.
+func (s *Server) Refs() ([]refJSON, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	refs, err := gitgraph.ListRefs(s.repo)
+	if err != nil {
+		return nil, err
+	}
+	out := make([]refJSON, 0, len(refs))
+	for _, ref := range refs {
+		out = append(out, refJSON{
+			Name:   ref.Name,
+			Short:  ref.Short,
+			Kind:   string(ref.Kind),
+			Hash:   ref.Hash.String(),
+			Target: ref.Target.String(),
+		})
+	}
+	return out, nil
+}
+
+// lookup resolves rev to a commit as the graph shows it, with replace refs,
+// grafts and the shallow boundary applied. The caller holds s.mu.
+func lookup(repo *git.Repository, provider *gitgraph.CommitProvider, rev string) (*object.Commit, error) {
+	hash, err := repo.ResolveRevision(plumbing.Revision(rev))
+	if err != nil {
+		return nil, &apiError{status: http.StatusNotFound, msg: fmt.Sprintf("unknown revision %q", rev)}
+	}
+	commit, err := provider.Generations().Commit(*hash)
+	if err != nil {
+		return nil, &apiError{status: http.StatusNotFound, msg: fmt.Sprintf("%s is not a commit", rev)}
+	}
+	return commit, nil
+}
+
+// apiError is a failure the caller caused, with the HTTP status that says
+// so; anything else is a 500.
+type apiError struct {
+	status int
+	msg    string
+}
+
+func (e *apiError) Error() string {
+	return e.msg
+}
+
+func badRequest(msg string) error {
+	return &apiError{status: http.StatusBadRequest, msg: msg}
+}
+
+func toCommitJSON(info *gitgraph.CommitInfo, withMessage bool) commitJSON {
+	out := commitJSON{
+		Hash:      info.Hash.String(),
+		ShortHash: info.ShortHash(),
+		Subject:   info.Subject,
+		Author:    info.Author,
+		When:      info.When,
+		Parents:   []string{},
+	}
+	for _, parent := range info.Commit.ParentHashes {
+		out.Parents = append(out.Parents, parent.String())
+	}
+	for _, cell := range info.Graph {
+		out.Graph = append(out.Graph, graphCell{Ch: cell.Ch, Color: cell.Color})
+	}
+	if withMessage {
+		out.Message = info.Message()
+	}
+	return out
+}
+
+func writeJSON(w http.ResponseWriter, r *http.Request, etag string, v any) {
+	if etag != "" {
+		quoted := `"` + etag + `"`
+		w.Header().Set("ETag", quoted)
+		if match := r.Header.Get("If-None-Match"); match != "" && etagMatches(match, quoted) {
+			w.WriteHeader(http.StatusNotModified)
+			return
+		}
+	}
+	w.Header().Set("Content-Type", "application/json")
+	enc := json.NewEncoder(w)
+	enc.SetIndent("", "  ")
+	_ = enc.Encode(v)
+}
+
+func etagMatches(header, etag string) bool {
+	for _, candidate := range strings.Split(header, ",") {
+		candidate = strings.TrimPrefix(strings.TrimSpace(candidate), "W/")
+		if candidate == etag || candidate == "*" {
+			return true
+		}
+	}
+	return false
+}
+
+func failJSON(w http.ResponseWriter, err error) {
+	var api *apiError
+	if errors.As(err, &api) {
+		httpError(w, api.status, api.msg)
+		return
+	}
+	httpError(w, http.StatusInternalServerError, err.Error())
+}
+
+func httpError(w http.ResponseWriter, status int, msg string) {
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(status)
+	_ = json.NewEncoder(w).Encode(map[string]string{"error": msg})
+}
+
+func queryInt(r *http.Request, key string, fallback int) (int, error) {
+	raw := r.URL.Query().Get(key)
+	if raw == "" {
+		return fallback, nil
+	}
+	return strconv.Atoi(raw)
+}
@@ -0,0 +1,166 @@
+package tui
+
+import (
+	"errors"
+	"fmt"
+	"strings"
+	"time"
+
+	"arbor/internal/debuglog"
+	"arbor/internal/gitgraph"
+	"arbor/internal/gitops"
+
+	tea "github.com/charmbracelet/bubbletea"
+	"github.com/go-git/go-git/v5/plumbing"
+)
+
+// gitOpMsg reports a finished git command. The graph is reloaded whether or
+// not it succeeded, since a failed rebase can still leave refs moved.
+type gitOpMsg struct {
+	title  string
+	output string
+	err    error
+	// retry runs the command again, offered when it failed on a lock
+	// another process held.
+	retry tea.Cmd
+	// stash is the stash taken before the command, offered back after it.
+	stash string
+}
+
+func runGitOp(title string, op func() (string, error)) tea.Cmd {
+	var cmd tea.Cmd
+	cmd = func() tea.Msg {
+		out, err := op()
+		return gitOpMsg{title: title, output: out, err: err, retry: cmd}
+	}
+	return cmd
+}
+
+func (m *model) applyGitOp(msg gitOpMsg) {
+	debuglog.Info("operation finished", "title", msg.title, "err", msg.err)
+	text := msg.output
+	if msg.err != nil {
+		text = msg.err.Error()
+	}
+	if text == "" {
+		text = "Done."
+	}
+	lines := strings.Split(text, "\n")
+	if err := m.reload(); err != nil {
+		lines = append(lines, "", fmt.Sprintf("Reload failed: %v", err))
+	}
+	m.checkInProgress()
+	var lock *gitops.LockError
+	if errors.As(msg.err, &lock) && msg.retry != nil {
+		m.openPrompt(msg.title, append(lines, "", lockAdvice(lock)),
+			popupAction{key: "enter", label: "retry", run: func() tea.Cmd { return msg.retry }})
+		return
+	}
+	if msg.stash != "" {
+		lines = append(lines, m.stashedLines(msg.title, msg.stash)...)
+	}
+	if msg.err != nil && m.showRebaseStatus(lines) {
+		return
+	}
+	if msg.stash != "" {
+		m.openPrompt(msg.title, lines, m.reapplyStash(msg.stash))
+		return
+	}
+	m.openPopup(msg.title, lines...)
+}
+
+// lockAdvice explains a lock another process holds: usually it is an IDE or
+// a git command that finishes on its own, but a lock left by a crashed git
+// stays until it is removed by hand.
+func lockAdvice(lock *gitops.LockError) string {
+	if lock.Stale() {
+		return fmt.Sprintf("The lock is %s old. If no git process is running, it was left behind by one that crashed; remove %s and retry.",
+			lock.Age.Round(time.Minute), lock.Path)
+	}
+	return "Another program (an IDE, an editor, or git in another terminal) is using the repository. Retry once it has finished."
+}
+
+// reload rebuilds the provider after arbor has changed the repository and
+// drops all state keyed to the old walk. The cursor stays on the commit it
+// was on, at the same screen row, when that commit is still in the graph.
+func (m *model) reload() error {
+	provider, err := m.provider.Reload()
+	if err != nil {
+		return err
+	}
+	m.useProvider(provider)
+	if m.watch {
+		// Moves arbor made itself are in this load; watch need not redo it.
+		m.noteRefs()
+	}
+	return nil
+}
+
+// cycleScope walks from HEAD alone, then local branches, then all branches,
+// keeping the selected commit when the new scope still reaches it.
+func (m *model) cycleScope() {
+	provider, err := m.provider.WithScope(m.provider.Scope().Next())
+	if err != nil {
+		m.openPopup("Scope", err.Error())
+		return
+	}
+	m.useProvider(provider)
+	m.status = "showing " + provider.Scope().String()
+}
+
+// useProvider swaps in a fresh walk, restoring the selection by hash at the
+// same screen row.
+func (m *model) useProvider(provider *gitgraph.CommitProvider) {
+	selected, row := m.selectedCommit(), m.cursor-m.offset
+	if m.provider != provider {
+		m.provider.Close()
+		provider.Prefetch(m.prefetch)
+	}
+	m.provider = provider
+	m.headName = gitgraph.HeadLabel(provider.Repo())
+	m.loadDeploys()
+	m.loadTags()
+	m.loadHeadVisits()
+	m.loadPullHeads()
+	m.mark = nil
+	m.contains = make(map[plumbing.Hash][]gitgraph.Ref)
+	m.markdownCache = make(map[markdownKey][]string)
+	m.containsPending = make(map[plumbing.Hash]bool)
+	m.filtered = nil
+	m.filterScanned = 0
+	m.cursor = 0
+	m.offset = 0
+	_ = m.provider.Ensure(0)
+	m.refreshFilter()
+	if selected != nil && m.selectHash(selected.Hash) {
+		m.offset = max(0, m.cursor-row)
+	}
+	m.ensureVisible()
+	m.normalizePosition()
+}
+
+// worktreeAvailable reports whether mutating actions can run, opening an
+// explanatory popup when they cannot.
+func (m *model) worktreeAvailable(title string) bool {
+	if m.git == nil {
+		m.openPopup(title, "This action needs a working tree; the repository is bare.")
+		return false
+	}
+	return true
+}
+
+// allowed checks read-only mode and branch protection up front, so a
+// blocked action explains itself before asking for any input.
+func (m *model) allowed(title string, op gitops.Op, branch string) bool {
+	err := m.git.Allow(op, branch)
+	switch {
+	case err == nil:
+		return true
+	case errors.Is(err, gitops.ErrReadOnly):
+		m.openPopup(title, "arbor is running read-only, so this action is disabled.",
+			"Restart without --read-only (or set read_only = false) to use it.")
+	default:
+		m.openPopup(title, err.Error(), "Edit protect.branches in the config to change this.")
+	}
+	return false
+}
@@ -0,0 +1,49 @@
+package tui
+
+import (
+	"fmt"
+	"strings"
+
+	"arbor/internal/doctor"
+
+	tea "github.com/charmbracelet/bubbletea"
+)
+
+type advisoryMsg struct {
+	issues []doctor.Issue
+}
+
+func (m *model) checkRepo() tea.Cmd {
+	if !m.advisories {
+		return nil
+	}
+	repo := m.provider.Repo()
+	return func() tea.Msg {
+		issues, err := doctor.Check(repo)
+		if err != nil {
+			return nil
+		}
+		return advisoryMsg{issues: issues}
+	}
+}
+
+func (m *model) bannerView(width int) string {
+	if width <= 0 {
+		return ""
+	}
+	if text := m.inProgressBanner(); text != "" {
+		return inProgressBannerStyle.Width(width).Render(truncateText(text, max(0, width-2)))
+	}
+	if len(m.advisory) == 0 {
+		return ""
+	}
+	summaries := make([]string, 0, len(m.advisory))
+	for _, issue := range m.advisory {
+		summaries = append(summaries, issue.Summary)
+	}
+	text := fmt.Sprintf("⚠ %s — run `arbor doctor` for details", strings.Join(summaries, ", "))
+	if key := m.keys.first(actionDismiss); key != "" {
+		text += fmt.Sprintf(" | %s dismiss", key)
+	}
+	return bannerStyle.Width(width).Render(truncateText(text, max(0, width-2)))
+}
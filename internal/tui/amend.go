@@ -0,0 +1,101 @@
+package tui
+
+import (
+	"fmt"
+	"strings"
+
+	"arbor/internal/gitops"
+
+	tea "github.com/charmbracelet/bubbletea"
+)
+
+// startAmend folds the staged changes into HEAD, showing HEAD's diff stat
+// before and after, and offers to reword it. A HEAD that is already on a
+// remote branch is refused unless protect.amend_published is set.
+func (m *model) startAmend() {
+	const title = "Amend"
+	if !m.worktreeAvailable(title) || !m.allowed(title, gitops.OpRewrite, m.git.CurrentBranch()) {
+		return
+	}
+	if !m.amendPublished {
+		remotes, err := m.git.PublishedOn("HEAD")
+		if err != nil {
+			m.openPopup(title, err.Error())
+			return
+		}
+		if len(remotes) > 0 {
+			m.openPopup(title,
+				fmt.Sprintf("HEAD is already on %s; amending it would rewrite published history.", strings.Join(remotes, ", ")),
+				"Make a new commit (or a fixup) instead, or set protect.amend_published = true.")
+			return
+		}
+	}
+	staged, err := m.git.HasStagedChanges()
+	if err != nil {
+		m.openPopup(title, err.Error())
+		return
+	}
+	message, err := m.git.HeadMessage()
+	if err != nil {
+		m.openPopup(title, err.Error())
+		return
+	}
+	git := m.committer()
+	amend := func(message string) tea.Cmd {
+		return runGitOp(title, func() (string, error) {
+			out, err := git.Amend(message)
+			if err == nil {
+				out = "Amended HEAD."
+			}
+			return out, err
+		})
+	}
+	reword := popupAction{key: "e", label: "edit message", run: func() tea.Cmd {
+		return m.rewordHead(message, amend)
+	}}
+	subject, _, _ := strings.Cut(message, "\n")
+	if !staged {
+		m.openPrompt(title, []string{
+			"Nothing is staged, so only the message of HEAD can change:",
+			subject,
+		}, reword)
+		return
+	}
+	before, after, err := m.git.AmendStat()
+	if err != nil {
+		m.openPopup(title, err.Error())
+		return
+	}
+	lines := []string{"Amend HEAD " + subject, "Committer: " + m.identityLabel(), "", "Before:"}
+	lines = append(lines, statLines(before)...)
+	lines = append(lines, "", "After:")
+	lines = append(lines, statLines(after)...)
+	m.openPrompt(title, lines,
+		popupAction{key: "enter", label: "amend", run: func() tea.Cmd { return amend("") }},
+		reword)
+}
+
+// rewordHead asks for HEAD's new message. The bottom bar edits the subject
+// and keeps the body; the editor gets the whole message.
+func (m *model) rewordHead(message string, amend func(string) tea.Cmd) tea.Cmd {
+	subject, body, _ := strings.Cut(strings.TrimSpace(message), "\n")
+	if m.useEditor {
+		return m.openCommitInput("Amend message", message, amend)
+	}
+	return m.openCommitInput("Amend subject", subject, func(value string) tea.Cmd {
+		if strings.TrimSpace(value) == "" {
+			return nil
+		}
+		if body = strings.TrimSpace(body); body != "" {
+			value += "\n\n" + body
+		}
+		return amend(value)
+	})
+}
+
+func statLines(stat string) []string {
+	if stat == "" {
+		return []string{"(no file changes)"}
+	}
+	return strings.Split(stat, "\n")
+}
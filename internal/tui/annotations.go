@@ -0,0 +1,63 @@
+package tui
+
+import (
+	"arbor/internal/annotate"
+
+	tea "github.com/charmbracelet/bubbletea"
+	"github.com/go-git/go-git/v5/plumbing"
+)
+
+// maxAnnotationWidth keeps a chatty provider from pushing the rest of the
+// row off screen.
+const maxAnnotationWidth = 24
+
+type annotationMsg struct {
+	asked  []plumbing.Hash
+	labels map[string]string
+	err    error
+}
+
+// annotateVisible asks the annotation command about the rows on screen it
+// has not labelled yet, in one batch, off the UI goroutine.
+func (m *model) annotateVisible() tea.Cmd {
+	if m.annotateCommand == "" || m.annotateErr != nil {
+		return nil
+	}
+	var asked []plumbing.Hash
+	var hashes []string
+	for _, commit := range m.visibleCommits() {
+		if _, done := m.annotations[commit.Hash]; done || m.annotatePending[commit.Hash] {
+			continue
+		}
+		m.annotatePending[commit.Hash] = true
+		asked = append(asked, commit.Hash)
+		hashes = append(hashes, commit.Hash.String())
+	}
+	if len(asked) == 0 {
+		return nil
+	}
+	dir, command := m.repoPath, m.annotateCommand
+	return func() tea.Msg {
+		labels, err := annotate.Run(dir, command, hashes)
+		return annotationMsg{asked: asked, labels: labels, err: err}
+	}
+}
+
+// applyAnnotations records a label, possibly empty, for every hash asked
+// about. A failing command is reported once and not run again.
+func (m *model) applyAnnotations(msg annotationMsg) {
+	for _, hash := range msg.asked {
+		m.annotations[hash] = msg.labels[hash.String()]
+		delete(m.annotatePending, hash)
+	}
+	if msg.err != nil && m.annotateErr == nil {
+		m.annotateErr = msg.err
+		m.status = msg.err.Error()
+	}
+}
+
+// loadVisible starts the background work for the rows on screen and the
+// selected commit.
+func (m *model) loadVisible() tea.Cmd {
+	return tea.Batch(m.auditVisible(), m.policyVisible(), m.significanceVisible(), m.diffStatVisible(), m.annotateVisible(), m.containsSelected(), m.describeSelected(), m.computeReleases())
+}
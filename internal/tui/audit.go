@@ -0,0 +1,77 @@
+package tui
+
+import (
+	"arbor/internal/gitgraph"
+
+	tea "github.com/charmbracelet/bubbletea"
+	"github.com/go-git/go-git/v5/plumbing"
+)
+
+type auditMsg struct {
+	results map[plumbing.Hash][]gitgraph.Finding
+}
+
+// auditVisible audits the rows on screen that have not been checked yet.
+// The work runs as a command so scrolling never waits on diffing.
+func (m *model) auditVisible() tea.Cmd {
+	if !m.auditBadges {
+		return nil
+	}
+	var pending []*gitgraph.CommitInfo
+	for _, commit := range m.visibleCommits() {
+		if _, done := m.audits[commit.Hash]; done || m.auditPending[commit.Hash] {
+			continue
+		}
+		m.auditPending[commit.Hash] = true
+		pending = append(pending, commit)
+	}
+	if len(pending) == 0 {
+		return nil
+	}
+	opts := m.auditOpts
+	return func() tea.Msg {
+		results := make(map[plumbing.Hash][]gitgraph.Finding, len(pending))
+		for _, commit := range pending {
+			findings, err := gitgraph.AuditCommit(commit.Commit, opts)
+			if err != nil {
+				findings = nil
+			}
+			results[commit.Hash] = findings
+		}
+		return auditMsg{results: results}
+	}
+}
+
+func (m *model) applyAudit(msg auditMsg) {
+	for hash, findings := range msg.results {
+		m.audits[hash] = findings
+		delete(m.auditPending, hash)
+	}
+}
+
+func (m *model) visibleCommits() []*gitgraph.CommitInfo {
+	listLen := m.listLength()
+	start := min(m.offset, max(0, listLen-1))
+	end := min(start+m.pageRows(start), listLen)
+	commits := make([]*gitgraph.CommitInfo, 0, max(0, end-start))
+	for i := start; i < end; i++ {
+		if commit := m.commitAt(i); commit != nil {
+			commits = append(commits, commit)
+		}
+	}
+	return commits
+}
+
+func (m *model) commitAt(listIndex int) *gitgraph.CommitInfo {
+	index := listIndex
+	if m.filtering() {
+		if listIndex >= len(m.filtered) {
+			return nil
+		}
+		index = m.filtered[listIndex]
+	}
+	if index < 0 || index >= len(m.provider.Commits) {
+		return nil
+	}
+	return m.provider.Commits[index]
+}
@@ -0,0 +1,27 @@
+package tui
+
+import (
+	"hash/fnv"
+	"strings"
+
+	"arbor/internal/gitgraph"
+
+	"github.com/charmbracelet/lipgloss"
+)
+
+// colorForAuthor maps an author to a stable palette slot so the same person
+// gets the same color across rows, sessions, and repositories.
+func colorForAuthor(commit *gitgraph.CommitInfo) lipgloss.TerminalColor {
+	h := fnv.New32a()
+	h.Write([]byte(authorIdentity(commit)))
+	return branchColors[h.Sum32()%uint32(len(branchColors))]
+}
+
+func authorIdentity(commit *gitgraph.CommitInfo) string {
+	if commit.Commit != nil {
+		if email := strings.ToLower(strings.TrimSpace(commit.Commit.Author.Email)); email != "" {
+			return email
+		}
+	}
+	return strings.ToLower(strings.TrimSpace(commit.Author))
+}
@@ -0,0 +1,252 @@
+package tui
+
+import (
+	"fmt"
+	"math"
+	"slices"
+	"sort"
+	"strings"
+
+	"arbor/internal/gitgraph"
+
+	tea "github.com/charmbracelet/bubbletea"
+	"github.com/go-git/go-git/v5/plumbing/format/diff"
+)
+
+// diffPreviewLines is how much of one file's diff a diff pager shows, and
+// how much more each press of the more key adds.
+const diffPreviewLines = 400
+
+// diffView is a diff shown file by file in the pager. Files are diffed as
+// they scroll into view, and each shows its first diffPreviewLines lines;
+// large files are not diffed at all until asked, so a commit that touches
+// thousands of files, regenerates a lock file or vendors a dependency
+// opens at once.
+type diffView struct {
+	files []*diffFile
+	// markers maps the pager line of each truncated file's "more" note to
+	// the file.
+	markers map[int]*diffFile
+	// fileStarts and hunkStarts are the pager lines each file and each
+	// hunk header ("@@") start at, for jumps and the outline.
+	fileStarts []int
+	hunkStarts []int
+	// at is the line the last jump went to, which the outline follows
+	// while it is in view: near the end a jump cannot scroll it to the top.
+	at int
+	// format is the repository's patch layout, as git diff writes it, and
+	// attrs picks each file's textconv driver.
+	format gitgraph.DiffFormat
+	attrs  *gitgraph.Attributes
+}
+
+type diffFile struct {
+	diff *gitgraph.FileDiff
+	// lines is the file's patch, nil until diffed.
+	lines []string
+	shown int
+	err   error
+	// converting is set while its textconv driver runs in the background.
+	converting bool
+}
+
+// diffConvertedMsg is a file's patch through its textconv driver.
+type diffConvertedMsg struct {
+	file  *diffFile
+	lines []string
+	err   error
+}
+
+func newDiffView(format gitgraph.DiffFormat, attrs *gitgraph.Attributes, diffs []*gitgraph.FileDiff) *diffView {
+	d := &diffView{format: format, attrs: attrs}
+	for _, diff := range diffs {
+		d.files = append(d.files, &diffFile{diff: diff, shown: diffPreviewLines})
+	}
+	return d
+}
+
+// fill diffs the files that are not large and show in the rows starting
+// at offset, until none of those is left undiffed, and returns the pager
+// lines with the command converting those with a textconv driver, which
+// can take long.
+func (d *diffView) fill(offset, rows int) ([]string, tea.Cmd) {
+	var cmds []tea.Cmd
+	for {
+		lines := d.render()
+		var due []*diffFile
+		for i, start := range d.fileStarts {
+			if start >= offset+rows {
+				break
+			}
+			end := len(lines)
+			if i+1 < len(d.fileStarts) {
+				end = d.fileStarts[i+1]
+			}
+			if file := d.files[i]; end > offset && file.due() {
+				due = append(due, file)
+			}
+		}
+		if len(due) == 0 {
+			return lines, tea.Batch(cmds...)
+		}
+		for _, file := range due {
+			cmds = append(cmds, d.load(file))
+		}
+	}
+}
+
+// due reports whether the file is diffed once in view: it is neither
+// large nor diffed, or being converted, already.
+func (f *diffFile) due() bool {
+	return f.lines == nil && f.err == nil && !f.converting && !f.diff.Large()
+}
+
+// load diffs file, or starts converting it when it has a textconv driver.
+func (d *diffView) load(file *diffFile) tea.Cmd {
+	command := d.attrs.Textconv(file.diff.Path)
+	if command == "" {
+		patch, err := file.diff.Patch(d.attrs)
+		file.lines, file.err = d.patchLines(patch, err)
+		return nil
+	}
+	file.converting = true
+	return func() tea.Msg {
+		patch, err := file.diff.ConvertedPatch(d.attrs, command)
+		lines, err := d.patchLines(patch, err)
+		return diffConvertedMsg{file: file, lines: lines, err: err}
+	}
+}
+
+func (d *diffView) patchLines(patch diff.Patch, err error) ([]string, error) {
+	if err != nil {
+		return nil, err
+	}
+	lines := []string{}
+	if text := strings.TrimSuffix(d.format.Format(patch), "\n"); text != "" {
+		lines = strings.Split(sanitizeText(text), "\n")
+	}
+	return lines, nil
+}
+
+// applyConverted fills in a converted file, reporting whether it is one
+// of this view's.
+func (d *diffView) applyConverted(msg diffConvertedMsg) bool {
+	if !slices.Contains(d.files, msg.file) {
+		return false
+	}
+	msg.file.converting = false
+	msg.file.lines, msg.file.err = msg.lines, msg.err
+	return true
+}
+
+// render lays the files out as pager lines, noting the markers.
+func (d *diffView) render() []string {
+	d.markers = make(map[int]*diffFile)
+	d.fileStarts, d.hunkStarts = d.fileStarts[:0], d.hunkStarts[:0]
+	var lines []string
+	for _, file := range d.files {
+		d.fileStarts = append(d.fileStarts, len(lines))
+		switch {
+		case file.err != nil:
+			lines = append(lines, "diff "+sanitizeLine(file.diff.Path), file.err.Error())
+		case file.converting:
+			lines = append(lines, "diff "+sanitizeLine(file.diff.Path), "… converting with its textconv driver")
+		case file.due():
+			lines = append(lines, "diff "+sanitizeLine(file.diff.Path), "…")
+		case file.lines == nil:
+			lines = append(lines, "diff "+sanitizeLine(file.diff.Path))
+			d.markers[len(lines)] = file
+			lines = append(lines, fmt.Sprintf("… %s of changes not diffed yet | a diffs the whole file", gitgraph.FormatSize(file.diff.Size)))
+		default:
+			shown := min(file.shown, len(file.lines))
+			for i, line := range file.lines[:shown] {
+				if strings.HasPrefix(line, "@@") {
+					d.hunkStarts = append(d.hunkStarts, len(lines)+i)
+				}
+			}
+			lines = append(lines, file.lines[:shown]...)
+			if rest := len(file.lines) - shown; rest > 0 {
+				d.markers[len(lines)] = file
+				lines = append(lines, fmt.Sprintf("… %d more lines | + shows %d more, a shows them all", rest, min(rest, diffPreviewLines)))
+			}
+		}
+	}
+	if len(lines) == 0 {
+		lines = []string{noFileChanges}
+	}
+	return lines
+}
+
+// expand grows the first truncated file whose note is in rows starting at
+// offset: by diffPreviewLines, or with all, to the whole file, diffing it
+// first if need be. It reports whether there was one, with the command
+// converting it if it has a textconv driver.
+func (d *diffView) expand(offset, rows int, all bool) (bool, tea.Cmd) {
+	for line := offset; line < offset+rows; line++ {
+		file, ok := d.markers[line]
+		if !ok {
+			continue
+		}
+		if file.lines == nil {
+			if !all {
+				return true, nil
+			}
+			if cmd := d.load(file); cmd != nil {
+				file.shown = math.MaxInt
+				return true, cmd
+			}
+		}
+		if all {
+			file.shown = len(file.lines)
+		} else {
+			file.shown += diffPreviewLines
+		}
+		return true, nil
+	}
+	return false, nil
+}
+
+// jump finds the next file (or hunk) start after line, or, going back, the
+// last one before it.
+func jump(starts []int, line int, forward bool) (int, bool) {
+	if forward {
+		i := sort.SearchInts(starts, line+1)
+		if i == len(starts) {
+			return 0, false
+		}
+		return starts[i], true
+	}
+	i := sort.SearchInts(starts, line) - 1
+	if i < 0 {
+		return 0, false
+	}
+	return starts[i], true
+}
+
+// current is the line the outline describes and jumps start from: the
+// last jump's target while it is on screen, otherwise the top line.
+func (d *diffView) current(offset, rows int) int {
+	if d.at > offset && d.at < offset+rows {
+		return d.at
+	}
+	return offset
+}
+
+// outline says where line is: "file 2/5 cmd/root.go  hunk 3/7", counting
+// hunks within the file.
+func (d *diffView) outline(line int) string {
+	if len(d.fileStarts) == 0 {
+		return ""
+	}
+	file := max(0, sort.SearchInts(d.fileStarts, line+1)-1)
+	text := fmt.Sprintf("file %d/%d %s", file+1, len(d.files), sanitizeLine(d.files[file].diff.Path))
+	first, last := sort.SearchInts(d.hunkStarts, d.fileStarts[file]), len(d.hunkStarts)
+	if file+1 < len(d.fileStarts) {
+		last = sort.SearchInts(d.hunkStarts, d.fileStarts[file+1])
+	}
+	if hunks := last - first; hunks > 0 {
+		current := max(1, sort.SearchInts(d.hunkStarts[first:last], line+1))
+		text += fmt.Sprintf("  hunk %d/%d", current, hunks)
+	}
+	return text
+}
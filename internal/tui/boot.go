@@ -0,0 +1,159 @@
+package tui
+
+import (
+	"fmt"
+	"strings"
+	"time"
+
+	tea "github.com/charmbracelet/bubbletea"
+	"github.com/charmbracelet/lipgloss"
+)
+
+// bootDelay is how long startup runs before its progress is shown, so a
+// repository that opens quickly goes straight to the graph.
+const bootDelay = 200 * time.Millisecond
+
+// BootStep is one stage of startup, such as opening the repository.
+type BootStep struct {
+	Label string
+	Run   func() error
+}
+
+// Boot runs startup steps in order, off the UI, with a screen showing how
+// far they have got, then hands the terminal to the model next returns
+// from what they loaded. On a large repository the steps take seconds,
+// which would otherwise pass on a blank screen; q, esc, or ctrl+c cancel
+// them, once the step running returns, so nothing is left loading after
+// Boot has quit. Steps should only load: next runs on the UI, where it
+// can set up the theme and styles View reads.
+type Boot struct {
+	steps []BootStep
+	next  func() tea.Model
+	// took is how long each finished step ran.
+	took    []time.Duration
+	begun   time.Time
+	stepAt  time.Time
+	size    *tea.WindowSizeMsg
+	model   tea.Model
+	err     error
+	aborted bool
+}
+
+type bootStepMsg struct{ err error }
+
+type bootTickMsg struct{}
+
+// NewBootModel runs steps, then shows the model next returns.
+func NewBootModel(steps []BootStep, next func() tea.Model) *Boot {
+	return &Boot{steps: steps, next: next}
+}
+
+// Err is the error that stopped startup, if one did.
+func (b *Boot) Err() error {
+	return b.err
+}
+
+func (b *Boot) Init() tea.Cmd {
+	b.begun, b.stepAt = time.Now(), time.Now()
+	return tea.Batch(b.runStep(), bootTick())
+}
+
+func (b *Boot) runStep() tea.Cmd {
+	if len(b.took) == len(b.steps) {
+		return func() tea.Msg { return bootStepMsg{} }
+	}
+	step := b.steps[len(b.took)]
+	return func() tea.Msg { return bootStepMsg{err: step.Run()} }
+}
+
+func bootTick() tea.Cmd {
+	return tea.Tick(100*time.Millisecond, func(time.Time) tea.Msg { return bootTickMsg{} })
+}
+
+func (b *Boot) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
+	if b.model != nil {
+		var cmd tea.Cmd
+		b.model, cmd = b.model.Update(msg)
+		return b, cmd
+	}
+	switch msg := msg.(type) {
+	case tea.WindowSizeMsg:
+		b.size = &msg
+	case tea.KeyMsg:
+		switch msg.String() {
+		case "ctrl+c", "q", "esc":
+			b.aborted = true
+		}
+	case bootTickMsg:
+		return b, bootTick()
+	case bootStepMsg:
+		if b.aborted {
+			return b, tea.Quit
+		}
+		if msg.err != nil {
+			b.err = msg.err
+			return b, tea.Quit
+		}
+		if len(b.took) < len(b.steps) {
+			now := time.Now()
+			b.took = append(b.took, now.Sub(b.stepAt))
+			b.stepAt = now
+		}
+		if len(b.took) < len(b.steps) {
+			return b, b.runStep()
+		}
+		b.model = b.next()
+		cmds := []tea.Cmd{b.model.Init()}
+		if b.size != nil {
+			var cmd tea.Cmd
+			b.model, cmd = b.model.Update(*b.size)
+			cmds = append(cmds, cmd)
+		}
+		return b, tea.Batch(cmds...)
+	}
+	return b, nil
+}
+
+// Close closes the model startup handed over to, if it got that far.
+func (b *Boot) Close() {
+	if closer, ok := b.model.(interface{ Close() }); ok {
+		closer.Close()
+	}
+}
+
+func (b *Boot) View() string {
+	if b.model != nil {
+		return b.model.View()
+	}
+	if b.size == nil || time.Since(b.begun) < bootDelay {
+		return ""
+	}
+	lines := []string{popupTitleStyle.Render("Starting arbor"), ""}
+	width := 0
+	for _, step := range b.steps {
+		width = max(width, len(step.Label))
+	}
+	for i, step := range b.steps {
+		label := fmt.Sprintf("%-*s", width, step.Label)
+		switch {
+		case i < len(b.took):
+			lines = append(lines, fmt.Sprintf("%s %s  %s", markStyle.Render("✓"), label, seconds(b.took[i])))
+		case i == len(b.took):
+			lines = append(lines, fmt.Sprintf("%s %s  %s", listCursorStyle.Render("▸"), label, seconds(time.Since(b.stepAt))))
+		default:
+			lines = append(lines, "  "+sidebarHintStyle.Render(label))
+		}
+	}
+	hint := "q cancel"
+	if b.aborted {
+		hint = "cancelling once this step returns…"
+	}
+	lines = append(lines, "", popupHintStyle.Render(hint))
+	box := popupStyle.Render(strings.Join(lines, "\n"))
+	return lipgloss.Place(b.size.Width, b.size.Height, lipgloss.Center, lipgloss.Center, box,
+		lipgloss.WithWhitespaceBackground(palette.bg))
+}
+
+func seconds(d time.Duration) string {
+	return fmt.Sprintf("%.1fs", d.Seconds())
+}
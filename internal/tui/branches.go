@@ -0,0 +1,157 @@
+package tui
+
+import (
+	"fmt"
+	"strings"
+
+	"arbor/internal/gitgraph"
+
+	tea "github.com/charmbracelet/bubbletea"
+	"github.com/charmbracelet/lipgloss"
+)
+
+const branchesTitle = "Branches"
+
+type branchesMsg struct {
+	statuses []gitgraph.BranchStatus
+	remotes  []gitgraph.RemoteTip
+	err      error
+}
+
+type branchTable struct {
+	sortKey int
+	cursor  int
+}
+
+// computeBranches refreshes the ahead/behind matrix in the background; it
+// runs at startup and after every action that can move a branch.
+func (m *model) computeBranches() tea.Cmd {
+	if m.branchesPending {
+		return nil
+	}
+	m.branchesPending = true
+	repo, gens, head := m.provider.Repo(), m.provider.Generations(), m.headName
+	return func() tea.Msg {
+		statuses, err := gitgraph.BranchStatuses(repo, gens)
+		msg := branchesMsg{statuses: statuses, err: err}
+		if err == nil {
+			msg.remotes, msg.err = gitgraph.RemoteTips(repo, gens, head)
+		}
+		return msg
+	}
+}
+
+func (m *model) applyBranches(msg branchesMsg) {
+	m.branchesPending = false
+	m.branchStatus, m.branchErr = msg.statuses, msg.err
+	m.remoteTips = msg.remotes
+	if m.branches != nil {
+		m.sortBranches()
+	}
+}
+
+func (m *model) openBranches() {
+	m.branches = &branchTable{}
+	m.sortBranches()
+}
+
+func (m *model) sortBranches() {
+	gitgraph.SortBranchStatuses(m.branchStatus, gitgraph.BranchSortKeys[m.branches.sortKey])
+	m.branches.cursor = clamp(m.branches.cursor, 0, max(0, len(m.branchStatus)-1))
+}
+
+func (m *model) handleBranchesKey(msg tea.KeyMsg) (tea.Model, tea.Cmd) {
+	t := m.branches
+	switch msg.String() {
+	case "ctrl+c":
+		return m, tea.Quit
+	case "esc", "q":
+		m.branches = nil
+	case "up", "k":
+		t.cursor = max(0, t.cursor-1)
+	case "down", "j":
+		t.cursor = min(max(0, len(m.branchStatus)-1), t.cursor+1)
+	case "s":
+		t.sortKey = (t.sortKey + 1) % len(gitgraph.BranchSortKeys)
+		m.sortBranches()
+	case "enter":
+		if t.cursor >= len(m.branchStatus) {
+			return m, nil
+		}
+		status := m.branchStatus[t.cursor]
+		m.branches = nil
+		if !m.selectHash(status.Tip.Hash) {
+			m.openPopup(branchesTitle, fmt.Sprintf("%s is not part of the current graph; try --all or a larger --limit.", status.Branch))
+		}
+		return m, m.loadVisible()
+	}
+	return m, nil
+}
+
+func (m *model) renderBranches(width, height int) string {
+	t := m.branches
+	boxWidth := min(max(30, width*4/5), max(1, width-4))
+	sortKey := gitgraph.BranchSortKeys[t.sortKey]
+	lines := []string{popupTitleStyle.Render(fmt.Sprintf("%s (sorted by %s)", branchesTitle, sortKey)), ""}
+
+	switch {
+	case m.branchErr != nil:
+		lines = append(lines, m.branchErr.Error())
+	case m.branchStatus == nil && m.branchesPending:
+		lines = append(lines, "Computing ahead/behind…")
+	case len(m.branchStatus) == 0:
+		lines = append(lines, "No local branches.")
+	default:
+		nameWidth := len("BRANCH")
+		upWidth := len("UPSTREAM")
+		for _, s := range m.branchStatus {
+			nameWidth = max(nameWidth, len(s.Branch))
+			upWidth = max(upWidth, len(m.upstreamLabel(s)))
+		}
+		nameWidth = min(nameWidth, max(8, boxWidth/3))
+		upWidth = min(upWidth, max(8, boxWidth/4))
+		base := "main"
+		if s := m.branchStatus[0]; s.Base != "" {
+			base = s.Base
+		}
+		row := func(name, upstream, upAhead, upBehind, baseAhead, baseBehind string) string {
+			return truncateText(fmt.Sprintf("%-*s  %-*s %4s %4s  %6s %6s",
+				nameWidth, truncateText(name, nameWidth), upWidth, truncateText(upstream, upWidth),
+				upAhead, upBehind, baseAhead, baseBehind), boxWidth-4)
+		}
+		lines = append(lines, popupHintStyle.Render(row("BRANCH", "UPSTREAM", "↑", "↓", base+" ↑", base+" ↓")))
+
+		rows := max(1, height-7)
+		start := clamp(t.cursor-rows/2, 0, max(0, len(m.branchStatus)-rows))
+		end := min(start+rows, len(m.branchStatus))
+		for i := start; i < end; i++ {
+			s := m.branchStatus[i]
+			upAhead, upBehind, baseAhead, baseBehind := "", "", "", ""
+			if s.Upstream != "" && !s.UpstreamGone {
+				upAhead, upBehind = fmt.Sprint(s.UpstreamAhead), fmt.Sprint(s.UpstreamBehind)
+			}
+			if s.Base != "" {
+				baseAhead, baseBehind = fmt.Sprint(s.BaseAhead), fmt.Sprint(s.BaseBehind)
+			}
+			line := row(s.Branch, m.upstreamLabel(s), upAhead, upBehind, baseAhead, baseBehind)
+			if i == t.cursor {
+				line = listCursorStyle.Width(boxWidth - 2).Render(line)
+			}
+			lines = append(lines, line)
+		}
+	}
+	lines = append(lines, "", popupHintStyle.Render("s sort | enter jump to tip | esc close"))
+	box := popupStyle.Width(boxWidth).Render(strings.Join(lines, "\n"))
+	return lipgloss.Place(width, height, lipgloss.Center, lipgloss.Center, box,
+		lipgloss.WithWhitespaceBackground(palette.bg))
+}
+
+func (m *model) upstreamLabel(s gitgraph.BranchStatus) string {
+	switch {
+	case s.UpstreamGone:
+		return m.remoteLabel(s.Upstream) + " (gone)"
+	case s.Upstream == "":
+		return "-"
+	}
+	return m.remoteLabel(s.Upstream)
+}
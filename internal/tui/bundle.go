@@ -0,0 +1,89 @@
+package tui
+
+import (
+	"fmt"
+	"path/filepath"
+	"strings"
+
+	"arbor/internal/gitgraph"
+
+	tea "github.com/charmbracelet/bubbletea"
+)
+
+// startBundle writes the branch or tag at the selected commit to a bundle
+// file: its whole history, or only the commits since the marked commit
+// when one is marked.
+func (m *model) startBundle() tea.Cmd {
+	const title = "Bundle"
+	commit := m.selectedCommit()
+	if commit == nil || !m.worktreeAvailable(title) {
+		return nil
+	}
+	ref, ok := m.bundleRef(commit)
+	if !ok {
+		m.openPopup(title, fmt.Sprintf("%s %s has no branch or tag on it.", commit.ShortHash(), commit.Subject),
+			"A bundle carries refs, so select a commit one points at.")
+		return nil
+	}
+	revs, what := []string{ref.Name}, ref.Short
+	if m.mark != nil && m.mark.Hash != commit.Hash {
+		revs = []string{"^" + m.mark.Hash.String(), ref.Name}
+		what = fmt.Sprintf("%s since %s", ref.Short, m.mark.ShortHash())
+	}
+	name := strings.ReplaceAll(ref.Short, "/", "-") + ".bundle"
+	return m.openInput("Bundle "+what+" to", name, func(file string) tea.Cmd {
+		file = strings.TrimSpace(file)
+		if file == "" {
+			return nil
+		}
+		if !filepath.IsAbs(file) {
+			file = filepath.Join(m.git.Dir, file)
+		}
+		return runGitOp(title, func() (string, error) {
+			count, err := m.git.CreateBundle(file, revs...)
+			if err != nil {
+				return "", err
+			}
+			fetch := fmt.Sprintf("git fetch %s %s:%s", file, ref.Short, ref.Short)
+			if ref.Kind == gitgraph.RefTag {
+				fetch = fmt.Sprintf("git fetch %s tag %s", file, ref.Short)
+			}
+			return fmt.Sprintf("Wrote %d commits of %s to %s.\n\nIn the other clone, check it with git bundle verify, then:\n  %s",
+				count, what, file, fetch), nil
+		})
+	})
+}
+
+// bundleRef picks the ref at commit to bundle: the current branch, else
+// another local branch, a tag, or a remote-tracking branch, in that order.
+func (m *model) bundleRef(commit *gitgraph.CommitInfo) (gitgraph.Ref, bool) {
+	refs, err := gitgraph.ListRefs(m.provider.Repo())
+	if err != nil {
+		return gitgraph.Ref{}, false
+	}
+	current := m.git.CurrentBranch()
+	rank := func(ref gitgraph.Ref) int {
+		switch {
+		case ref.Kind == gitgraph.RefBranch && ref.Short == current:
+			return 0
+		case ref.Kind == gitgraph.RefBranch:
+			return 1
+		case ref.Kind == gitgraph.RefTag:
+			return 2
+		case ref.Kind == gitgraph.RefRemote:
+			return 3
+		}
+		return -1
+	}
+	var best gitgraph.Ref
+	found := false
+	for _, ref := range refs {
+		if ref.Target != commit.Hash || rank(ref) < 0 {
+			continue
+		}
+		if !found || rank(ref) < rank(best) {
+			best, found = ref, true
+		}
+	}
+	return best, found
+}
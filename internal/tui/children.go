@@ -0,0 +1,33 @@
+package tui
+
+import (
+	"fmt"
+
+	"arbor/internal/gitgraph"
+)
+
+// maxChildrenShown caps the children listed in the sidebar.
+const maxChildrenShown = 5
+
+// childLines lists the commits built directly on commit when history
+// splits there; a single child is just the row above.
+func (m *model) childLines(commit *gitgraph.CommitInfo, width int) []string {
+	children := m.provider.Children(commit.Hash)
+	if len(children) < 2 {
+		return nil
+	}
+	lines := []string{"", sidebarSubtitleStyle.Render(fmt.Sprintf("Branch point: %d children", len(children)))}
+	repo := m.provider.Repo()
+	for i, hash := range children {
+		if i == maxChildrenShown {
+			lines = append(lines, fmt.Sprintf("+%d more", len(children)-i))
+			break
+		}
+		line := "- " + m.short(hash.String())
+		if child, err := repo.CommitObject(hash); err == nil {
+			line += " " + sanitizeLine(gitgraph.FirstLine(child.Message))
+		}
+		lines = append(lines, truncateText(line, width-2))
+	}
+	return lines
+}
@@ -0,0 +1,63 @@
+package tui
+
+import (
+	"fmt"
+	"os"
+
+	"arbor/internal/gitgraph"
+	"arbor/internal/platform"
+
+	tea "github.com/charmbracelet/bubbletea"
+)
+
+// copiedMsg reports a copy made by copyText, with the status to show once
+// it worked.
+type copiedMsg struct {
+	status string
+	err    error
+}
+
+// copyText puts text on the clipboard from a command rather than from
+// Update, so the OSC52 sequence is not written while a frame is drawn.
+func copyText(text, status string) tea.Cmd {
+	return func() tea.Msg {
+		return copiedMsg{status: status, err: platform.Copy(os.Stdout, text)}
+	}
+}
+
+func (m *model) applyCopied(msg copiedMsg) {
+	if msg.err != nil {
+		m.openPopup("Copy", msg.err.Error())
+		return
+	}
+	m.status = msg.status
+}
+
+// short abbreviates a hash as the list does (see gitgraph.Abbrev).
+func (m *model) short(hash string) string {
+	return m.provider.Abbrev().ShortString(hash)
+}
+
+func (m *model) copyHash() tea.Cmd {
+	commit := m.selectedCommit()
+	if commit == nil {
+		return nil
+	}
+	return copyText(commit.Hash.String(), fmt.Sprintf("copied %s", commit.ShortHash()))
+}
+
+func (m *model) openCommit() {
+	commit := m.selectedCommit()
+	if commit == nil {
+		return
+	}
+	url, err := gitgraph.CommitURL(m.provider.Repo(), commit.Hash)
+	if err == nil {
+		err = platform.Open(url)
+	}
+	if err != nil {
+		m.openPopup("Open", err.Error())
+		return
+	}
+	m.status = "opened " + url
+}
@@ -0,0 +1,65 @@
+package tui
+
+import (
+	"fmt"
+
+	"arbor/internal/gitgraph"
+
+	tea "github.com/charmbracelet/bubbletea"
+	"github.com/go-git/go-git/v5/plumbing"
+)
+
+// maxContainsShown caps the sidebar list; the rest are summarized.
+const maxContainsShown = 8
+
+type containsMsg struct {
+	hash plumbing.Hash
+	refs []gitgraph.Ref
+	err  error
+}
+
+// containsSelected looks up the refs containing the selected commit in the
+// background. Results are cached per commit until the next reload.
+func (m *model) containsSelected() tea.Cmd {
+	commit := m.selectedCommit()
+	if commit == nil {
+		return nil
+	}
+	if _, done := m.contains[commit.Hash]; done || m.containsPending[commit.Hash] {
+		return nil
+	}
+	m.containsPending[commit.Hash] = true
+	repo, gens, hash := m.provider.Repo(), m.provider.Generations(), commit.Hash
+	return func() tea.Msg {
+		refs, err := gitgraph.ContainingRefs(repo, gens, hash)
+		return containsMsg{hash: hash, refs: refs, err: err}
+	}
+}
+
+func (m *model) applyContains(msg containsMsg) {
+	delete(m.containsPending, msg.hash)
+	if msg.err != nil {
+		m.status = "contained in: " + msg.err.Error()
+		msg.refs = nil
+	}
+	m.contains[msg.hash] = msg.refs
+}
+
+func (m *model) containsLines(hash plumbing.Hash) []string {
+	refs, done := m.contains[hash]
+	if !done {
+		return []string{"…"}
+	}
+	if len(refs) == 0 {
+		return []string{"(no branch or tag)"}
+	}
+	var lines []string
+	for i, ref := range refs {
+		if i == maxContainsShown {
+			lines = append(lines, fmt.Sprintf("+%d more", len(refs)-i))
+			break
+		}
+		lines = append(lines, "- "+m.refLabel(ref))
+	}
+	return lines
+}
@@ -0,0 +1,232 @@
+package tui
+
+import (
+	"errors"
+	"fmt"
+	"path/filepath"
+	"strings"
+
+	"arbor/internal/config"
+	"arbor/internal/gitgraph"
+	"arbor/internal/gitops"
+
+	tea "github.com/charmbracelet/bubbletea"
+	"github.com/charmbracelet/lipgloss"
+	git "github.com/go-git/go-git/v5"
+	"github.com/go-git/go-git/v5/plumbing"
+	"github.com/go-git/go-git/v5/plumbing/object"
+)
+
+// repoSummary is a dashboard row: where the repository's HEAD is, how many
+// tracked files have uncommitted changes, how far the branch is from its
+// upstream ("" when it has none, gone when it was deleted), and its last
+// commit.
+type repoSummary struct {
+	branch   string
+	changed  int
+	upstream string
+	gone     bool
+	ahead    int
+	behind   int
+	last     *object.Commit
+	short    string
+	err      error
+}
+
+type dashboardMsg struct {
+	index   int
+	summary *repoSummary
+}
+
+// Dashboard summarizes the repositories of a workspace, one row each, and
+// quits with the one picked, for the caller to open in arbor and come back
+// from.
+type Dashboard struct {
+	title   string
+	repos   []string
+	rows    []*repoSummary
+	cursor  int
+	picked  string
+	pending int
+	width   int
+	height  int
+}
+
+// NewDashboardModel lists repos, absolute paths, with the cursor on
+// selected if it is one of them.
+func NewDashboardModel(cfg *config.Config, name string, repos []string, selected string) *Dashboard {
+	setTheme(cfg.Theme)
+	d := &Dashboard{title: "Workspace " + name, repos: repos, rows: make([]*repoSummary, len(repos))}
+	for i, repo := range repos {
+		if repo == selected {
+			d.cursor = i
+		}
+	}
+	return d
+}
+
+// Picked is the repository chosen with enter, "" when the dashboard was
+// quit.
+func (d *Dashboard) Picked() string {
+	return d.picked
+}
+
+func (d *Dashboard) Init() tea.Cmd {
+	return d.refresh()
+}
+
+// refresh summarizes every repository again, each in the background.
+func (d *Dashboard) refresh() tea.Cmd {
+	cmds := make([]tea.Cmd, len(d.repos))
+	for i, path := range d.repos {
+		cmds[i] = func() tea.Msg { return dashboardMsg{index: i, summary: summarize(path)} }
+	}
+	d.pending = len(d.repos)
+	return tea.Batch(cmds...)
+}
+
+func summarize(path string) *repoSummary {
+	s := &repoSummary{}
+	repo, err := git.PlainOpenWithOptions(path, &git.PlainOpenOptions{EnableDotGitCommonDir: true})
+	if err != nil {
+		s.err = err
+		return s
+	}
+	s.branch = gitgraph.HeadLabel(repo)
+	head, err := repo.Head()
+	if err != nil {
+		s.err = errors.New("no commits yet")
+		return s
+	}
+	if s.last, err = repo.CommitObject(head.Hash()); err != nil {
+		s.err = err
+		return s
+	}
+	ops := gitops.New(path)
+	if s.short, err = ops.ShortHash(head.Hash().String()); err != nil {
+		s.err = err
+		return s
+	}
+	if head.Name().IsBranch() {
+		if upstream, ok := gitgraph.UpstreamRef(repo, head.Name().Short()); ok {
+			s.upstream = plumbing.ReferenceName(upstream).Short()
+			if ref, err := repo.Reference(plumbing.ReferenceName(upstream), true); err != nil {
+				s.gone = true
+			} else {
+				s.ahead, s.behind, _ = gitgraph.AheadBehind(gitgraph.NewGenerations(repo), head.Hash(), ref.Hash())
+			}
+		}
+	}
+	if _, err := repo.Worktree(); err == nil {
+		dirty, err := ops.DirtyFiles()
+		if err != nil {
+			s.err = err
+			return s
+		}
+		s.changed = len(dirty)
+	}
+	return s
+}
+
+func (d *Dashboard) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
+	switch msg := msg.(type) {
+	case tea.WindowSizeMsg:
+		d.width, d.height = msg.Width, msg.Height
+	case dashboardMsg:
+		d.rows[msg.index] = msg.summary
+		d.pending--
+	case tea.KeyMsg:
+		switch msg.String() {
+		case "ctrl+c", "q", "esc":
+			return d, tea.Quit
+		case "up", "k":
+			d.cursor = max(0, d.cursor-1)
+		case "down", "j":
+			d.cursor = min(max(0, len(d.repos)-1), d.cursor+1)
+		case "r":
+			if d.pending == 0 {
+				return d, d.refresh()
+			}
+		case "enter":
+			if d.cursor < len(d.rows) {
+				if row := d.rows[d.cursor]; row != nil && row.err == nil {
+					d.picked = d.repos[d.cursor]
+					return d, tea.Quit
+				}
+			}
+		}
+	}
+	return d, nil
+}
+
+func (d *Dashboard) View() string {
+	if d.width == 0 {
+		return ""
+	}
+	boxWidth := max(1, d.width-4)
+	lines := []string{popupTitleStyle.Render(d.title), ""}
+	if len(d.repos) == 0 {
+		lines = append(lines, "No repositories match the workspace's repos.")
+	} else {
+		nameWidth, branchWidth, upWidth := len("REPOSITORY"), len("BRANCH"), len("UPSTREAM")
+		for i, path := range d.repos {
+			nameWidth = max(nameWidth, len(filepath.Base(path)))
+			if row := d.rows[i]; row != nil {
+				branchWidth = max(branchWidth, len(row.branch))
+				upWidth = max(upWidth, len(row.upstream))
+				if row.gone {
+					upWidth = max(upWidth, len(row.upstream+" (gone)"))
+				}
+			}
+		}
+		nameWidth = min(nameWidth, max(8, boxWidth/5))
+		branchWidth = min(branchWidth, max(8, boxWidth/6))
+		upWidth = min(upWidth, max(8, boxWidth/6))
+		row := func(name, branch, changes, upstream, ahead, behind, last string) string {
+			return truncateText(fmt.Sprintf("%-*s  %-*s  %7s  %-*s %4s %4s  %s",
+				nameWidth, truncateText(name, nameWidth), branchWidth, truncateText(branch, branchWidth),
+				changes, upWidth, truncateText(upstream, upWidth), ahead, behind, last), boxWidth-4)
+		}
+		lines = append(lines, popupHintStyle.Render(row("REPOSITORY", "BRANCH", "CHANGES", "UPSTREAM", "↑", "↓", "LAST COMMIT")))
+
+		rows := max(1, d.height-7)
+		start := clamp(d.cursor-rows/2, 0, max(0, len(d.repos)-rows))
+		end := min(start+rows, len(d.repos))
+		for i := start; i < end; i++ {
+			name := filepath.Base(d.repos[i])
+			var line string
+			switch s := d.rows[i]; {
+			case s == nil:
+				line = row(name, "", "", "", "", "", "loading…")
+			case s.err != nil:
+				line = row(name, s.branch, "", "", "", "", s.err.Error())
+			default:
+				changes := "clean"
+				if s.changed > 0 {
+					changes = fmt.Sprint(s.changed)
+				}
+				upstream, ahead, behind := "-", "", ""
+				switch {
+				case s.gone:
+					upstream = s.upstream + " (gone)"
+				case s.upstream != "":
+					upstream, ahead, behind = s.upstream, fmt.Sprint(s.ahead), fmt.Sprint(s.behind)
+				}
+				last := fmt.Sprintf("%s %s %s", s.short, s.last.Committer.When.Format("2006-01-02"), sanitizeLine(gitgraph.FirstLine(s.last.Message)))
+				line = row(name, s.branch, changes, upstream, ahead, behind, last)
+			}
+			if i == d.cursor {
+				line = listCursorStyle.Width(boxWidth - 2).Render(line)
+			}
+			lines = append(lines, line)
+		}
+	}
+	hint := "enter open in arbor | r refresh | q quit"
+	if d.cursor < len(d.repos) {
+		hint = d.repos[d.cursor] + " | " + hint
+	}
+	lines = append(lines, "", popupHintStyle.Render(truncateText(hint, boxWidth-4)))
+	box := popupStyle.Width(boxWidth).Render(strings.Join(lines, "\n"))
+	return lipgloss.Place(d.width, d.height, lipgloss.Center, lipgloss.Center, box,
+		lipgloss.WithWhitespaceBackground(palette.bg))
+}
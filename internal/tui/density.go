@@ -0,0 +1,184 @@
+package tui
+
+import (
+	"slices"
+	"strings"
+
+	"arbor/internal/config"
+	"arbor/internal/gitgraph"
+
+	"github.com/charmbracelet/lipgloss"
+)
+
+const (
+	densityCompact     = "compact"
+	densityComfortable = "comfortable"
+	densitySpaced      = "spaced"
+)
+
+func (m *model) toggleDensity() {
+	i := slices.Index(config.DensityNames, m.density)
+	m.density = config.DensityNames[(i+1)%len(config.DensityNames)]
+	m.status = m.density + " rows"
+}
+
+// rowLines is how many screen lines a commit takes: one when compact; in
+// comfortable mode a second for author and date and a third for the first
+// body line, if there is one; two when spaced.
+func (m *model) rowLines(commit *gitgraph.CommitInfo) int {
+	if commit == nil || m.density == densityCompact {
+		return 1
+	}
+	if m.density == densitySpaced {
+		return 2
+	}
+	if bodyPreview(commit) != "" {
+		return 3
+	}
+	return 2
+}
+
+// rowPadding is the blank margin before each row line.
+func (m *model) rowPadding(bg lipgloss.TerminalColor) string {
+	if m.padding == 0 {
+		return ""
+	}
+	return rowSpacerStyle.Background(bg).Render(strings.Repeat(" ", m.padding))
+}
+
+// linesAt is rowLines for the row at listIndex, plus its release
+// separator, if any.
+func (m *model) linesAt(listIndex int) int {
+	lines := m.rowLines(m.commitAt(listIndex))
+	if _, ok := m.releaseAt(listIndex); ok {
+		lines++
+	}
+	return lines
+}
+
+// pageRows is how many rows fit on screen starting at offset, at least one.
+func (m *model) pageRows(offset int) int {
+	viewport := m.viewportHeight()
+	if m.density == densityCompact && m.releases == nil {
+		return viewport
+	}
+	rows, used := 0, 0
+	for i := offset; i < m.listLength(); i++ {
+		h := m.linesAt(i)
+		if used+h > viewport && rows > 0 {
+			break
+		}
+		used += h
+		rows++
+	}
+	return max(1, rows)
+}
+
+// firstOffsetShowing is the smallest offset that still shows index in full
+// at the bottom of the screen.
+func (m *model) firstOffsetShowing(index int) int {
+	viewport := m.viewportHeight()
+	off, used := index, m.linesAt(index)
+	for off > 0 && used+m.linesAt(off-1) <= viewport {
+		off--
+		used += m.linesAt(off)
+	}
+	return off
+}
+
+// maxOffset keeps the screen full when scrolled to the end of the list.
+func (m *model) maxOffset() int {
+	listLen := m.listLength()
+	if listLen == 0 {
+		return 0
+	}
+	return m.firstOffsetShowing(listLen - 1)
+}
+
+// centerOn scrolls so index sits about mid-screen.
+func (m *model) centerOn(index int) {
+	above := m.viewportHeight() / 2
+	off := index
+	for off > 0 && above-m.linesAt(off-1) >= 0 {
+		above -= m.linesAt(off - 1)
+		off--
+	}
+	m.offset = off
+}
+
+// scrollToCursor moves the offset as little as possible to show the cursor.
+func (m *model) scrollToCursor() {
+	if m.cursor < m.offset {
+		m.offset = m.cursor
+	}
+	if first := m.firstOffsetShowing(m.cursor); m.offset < first {
+		m.offset = first
+	}
+}
+
+// bodyPreview is the first non-empty line of the message after the subject.
+func bodyPreview(commit *gitgraph.CommitInfo) string {
+	if commit.Commit == nil {
+		return ""
+	}
+	_, body, _ := strings.Cut(commit.Message(), "\n")
+	for _, line := range strings.Split(body, "\n") {
+		if line = strings.TrimSpace(line); line != "" {
+			return sanitizeLine(line)
+		}
+	}
+	return ""
+}
+
+// renderDetailLines draws the extra comfortable-mode lines under a row, or
+// the blank spaced-mode one, with the graph's lanes carried down beside them.
+func (m *model) renderDetailLines(commit, next *gitgraph.CommitInfo, selected bool, width int, alt bool) []string {
+	bg := palette.bg
+	color := palette.textMuted
+	if alt {
+		bg = palette.bgAlt
+	}
+	if selected {
+		bg = palette.highlightBg
+		color = palette.highlightText
+	}
+	prefix := m.rowPadding(bg)
+	lanes := continueGraph(commit, next)
+	if m.graphInline() {
+		prefix += renderGraph(lanes, bg) + rowSpacerStyle.Background(bg).Render(" ")
+	}
+	details := []string{""}
+	if m.density == densityComfortable {
+		details = []string{sanitizeLine(commit.Author) + " · " + commit.When.Format("2006-01-02 15:04")}
+		if body := bodyPreview(commit); body != "" {
+			details = append(details, body)
+		}
+	}
+	lines := make([]string, 0, len(details))
+	for i, text := range details {
+		style := authorStyle.Foreground(color).Background(bg)
+		if i > 0 {
+			style = style.Italic(true)
+		}
+		lines = append(lines, m.fitRow(prefix+style.Render(text), lanes, width, bg))
+	}
+	return lines
+}
+
+// continueGraph turns a row's graph cells into the lanes that carry on
+// below it: the commit's own lane if it has parents, new merge lanes, and
+// pass-through lanes, as far as the next row still has them.
+func continueGraph(commit, next *gitgraph.CommitInfo) []gitgraph.GraphCell {
+	cells := make([]gitgraph.GraphCell, len(commit.Graph))
+	for i, cell := range commit.Graph {
+		cells[i] = gitgraph.GraphCell{Ch: " ", Color: cell.Color}
+		live := cell.Ch == "\\" || cell.Ch == "|" || cell.Ch == "*" && commit.Commit != nil && commit.Commit.NumParents() > 0
+		if live && next != nil {
+			live = i < len(next.Graph) && (next.Graph[i].Ch == "|" || next.Graph[i].Ch == "*")
+		}
+		if live {
+			cells[i].Ch = "|"
+		}
+	}
+	return cells
+}
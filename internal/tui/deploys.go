@@ -0,0 +1,36 @@
+package tui
+
+import (
+	"strings"
+
+	"arbor/internal/gitgraph"
+
+	"github.com/charmbracelet/lipgloss"
+)
+
+// loadDeploys reads the deployment markers; it is cheap enough to redo on
+// every reload rather than track refs/notes changes.
+func (m *model) loadDeploys() {
+	if m.deployRefPrefix == "" && m.deployNotesRef == "" {
+		return
+	}
+	deploys, err := gitgraph.DeployMarkers(m.provider.Repo(), m.deployRefPrefix, m.deployNotesRef)
+	if err != nil {
+		m.status = "deploy markers: " + err.Error()
+		return
+	}
+	m.deploys = deploys
+}
+
+func (m *model) deployBadges(commit *gitgraph.CommitInfo, bg lipgloss.TerminalColor) string {
+	envs := m.deploys[commit.Hash]
+	if len(envs) == 0 {
+		return ""
+	}
+	space := rowSpacerStyle.Background(bg).Render(" ")
+	var badges []string
+	for _, env := range envs {
+		badges = append(badges, deployStyle.Render(env))
+	}
+	return strings.Join(badges, space) + space
+}
@@ -0,0 +1,47 @@
+package tui
+
+import (
+	"arbor/internal/gitgraph"
+
+	tea "github.com/charmbracelet/bubbletea"
+	"github.com/go-git/go-git/v5/plumbing"
+)
+
+type describeMsg struct {
+	hash plumbing.Hash
+	text string
+}
+
+// loadTags rebuilds the tag index; like deploy markers it is redone on
+// every reload.
+func (m *model) loadTags() {
+	ix, err := gitgraph.NewTagIndex(m.provider.Repo(), m.provider.Abbrev(), m.provider.Generations())
+	if err != nil {
+		m.status = "tags: " + err.Error()
+		return
+	}
+	m.tagIndex = ix
+	m.describes = make(map[plumbing.Hash]string)
+	m.releases, m.releasesPending = nil, false
+}
+
+// describeSelected computes `git describe --tags` for the selected commit
+// in the background, once per commit.
+func (m *model) describeSelected() tea.Cmd {
+	commit := m.selectedCommit()
+	if commit == nil || m.tagIndex == nil {
+		return nil
+	}
+	if _, done := m.describes[commit.Hash]; done {
+		return nil
+	}
+	m.describes[commit.Hash] = ""
+	ix, hash := m.tagIndex, commit.Hash
+	return func() tea.Msg {
+		desc, ok, err := ix.Describe(hash)
+		if err != nil || !ok {
+			return describeMsg{hash: hash}
+		}
+		return describeMsg{hash: hash, text: desc.String()}
+	}
+}
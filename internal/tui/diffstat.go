@@ -0,0 +1,85 @@
+package tui
+
+import (
+	"slices"
+	"strings"
+
+	"arbor/internal/gitgraph"
+
+	tea "github.com/charmbracelet/bubbletea"
+	"github.com/charmbracelet/lipgloss"
+	"github.com/go-git/go-git/v5/plumbing"
+)
+
+// diffStatWidth is the bar in the "diffstat" column, in cells.
+const diffStatWidth = 5
+
+type diffStatMsg struct {
+	results map[plumbing.Hash]gitgraph.DiffStat
+}
+
+// diffStatVisible diffs the rows on screen for the "diffstat" column off
+// the UI goroutine; rows show an empty slot until theirs arrives.
+func (m *model) diffStatVisible() tea.Cmd {
+	if !slices.Contains(m.columns, "diffstat") {
+		return nil
+	}
+	var pending []*gitgraph.CommitInfo
+	for _, commit := range m.visibleCommits() {
+		if _, done := m.diffStats[commit.Hash]; done || m.diffStatPending[commit.Hash] {
+			continue
+		}
+		m.diffStatPending[commit.Hash] = true
+		pending = append(pending, commit)
+	}
+	if len(pending) == 0 {
+		return nil
+	}
+	provider := m.provider
+	return func() tea.Msg {
+		attrs := provider.Attributes()
+		results := make(map[plumbing.Hash]gitgraph.DiffStat, len(pending))
+		for _, commit := range pending {
+			var stat gitgraph.DiffStat
+			if commit.Commit != nil && commit.Commit.NumParents() <= 1 {
+				stat, _ = gitgraph.CommitDiffStat(attrs, commit.Commit)
+			}
+			results[commit.Hash] = stat
+		}
+		return diffStatMsg{results: results}
+	}
+}
+
+func (m *model) applyDiffStats(msg diffStatMsg) {
+	for hash, stat := range msg.results {
+		m.diffStats[hash] = stat
+		delete(m.diffStatPending, hash)
+	}
+}
+
+// diffStatCell fills one cell per order of magnitude of lines changed
+// (1, 10, 50, 200, 1000), split between additions and deletions in
+// proportion. Merges and commits still being diffed get an empty slot.
+func (m *model) diffStatCell(commit *gitgraph.CommitInfo, bg lipgloss.TerminalColor) string {
+	stat, done := m.diffStats[commit.Hash]
+	total := stat.Added + stat.Deleted
+	if !done || total == 0 {
+		return rowSpacerStyle.Background(bg).Render(strings.Repeat(" ", diffStatWidth))
+	}
+	filled := 0
+	for _, step := range []int{1, 10, 50, 200, 1000} {
+		if total >= step {
+			filled++
+		}
+	}
+	added := (filled*stat.Added + total/2) / total
+	if stat.Added > 0 && added == 0 {
+		added = 1
+	}
+	if stat.Deleted > 0 && added == filled {
+		added = filled - 1
+	}
+	return diffAddStyle.Background(bg).Render(strings.Repeat("▮", added)) +
+		diffDelStyle.Background(bg).Render(strings.Repeat("▮", filled-added)) +
+		authorStyle.Foreground(palette.textDim).Background(bg).Render(strings.Repeat("▯", diffStatWidth-filled))
+}
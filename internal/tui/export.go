@@ -0,0 +1,120 @@
+package tui
+
+import (
+	"bytes"
+	"encoding/csv"
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+	"time"
+
+	"arbor/internal/gitgraph"
+
+	tea "github.com/charmbracelet/bubbletea"
+)
+
+type exportedCommit struct {
+	Hash    string `json:"hash"`
+	Subject string `json:"subject"`
+	Author  string `json:"author"`
+	Date    string `json:"date"`
+}
+
+const exportTitle = "Export"
+
+// startExport writes the commits the active filters match, through all of
+// history, to a CSV or JSON file picked by its extension, or copies them
+// as CSV when the name is left empty. The rest of history is scanned
+// first, in steps, as the filter scan does.
+func (m *model) startExport() tea.Cmd {
+	if !m.filtering() {
+		m.status = fmt.Sprintf("filter first (%s), then export the matches", m.keys.first(actionSearch))
+		return nil
+	}
+	if !m.provider.HasMore() {
+		return m.promptExport()
+	}
+	m.exporting = true
+	m.filterStopped = false
+	return m.scanFilter()
+}
+
+// promptExport asks where to export the matches once all of history is
+// scanned.
+func (m *model) promptExport() tea.Cmd {
+	m.refreshFilter()
+	commits := make([]exportedCommit, 0, len(m.filtered))
+	for _, index := range m.filtered {
+		commits = append(commits, exportRow(m.provider.Commits[index]))
+	}
+	if len(commits) == 0 {
+		m.status = "no matching commits to export"
+		return nil
+	}
+	label := fmt.Sprintf("Export %d commits to (.csv or .json; empty copies CSV)", len(commits))
+	return m.openInput(label, "commits.csv", func(file string) tea.Cmd {
+		file = strings.TrimSpace(file)
+		if file == "" {
+			data, err := exportCSV(commits)
+			if err != nil {
+				m.openPopup(exportTitle, err.Error())
+				return nil
+			}
+			return copyText(string(data), fmt.Sprintf("copied %d commits as CSV", len(commits)))
+		}
+		if !filepath.IsAbs(file) {
+			file = filepath.Join(m.repoPath, file)
+		}
+		if _, err := os.Stat(file); err == nil {
+			m.openPrompt(exportTitle, []string{file + " already exists."},
+				popupAction{key: "y", label: "overwrite", run: func() tea.Cmd {
+					m.writeExport(file, commits)
+					return nil
+				}})
+			return nil
+		}
+		m.writeExport(file, commits)
+		return nil
+	})
+}
+
+func (m *model) writeExport(file string, commits []exportedCommit) {
+	var data []byte
+	var err error
+	if strings.EqualFold(filepath.Ext(file), ".json") {
+		data, err = json.MarshalIndent(commits, "", "  ")
+		data = append(data, '\n')
+	} else {
+		data, err = exportCSV(commits)
+	}
+	if err == nil {
+		err = os.WriteFile(file, data, 0o644)
+	}
+	if err != nil {
+		m.openPopup(exportTitle, err.Error())
+		return
+	}
+	m.status = fmt.Sprintf("wrote %d commits to %s", len(commits), file)
+}
+
+func exportRow(commit *gitgraph.CommitInfo) exportedCommit {
+	return exportedCommit{
+		Hash:    commit.Hash.String(),
+		Subject: commit.Subject,
+		Author:  commit.Author,
+		Date:    commit.When.Format(time.RFC3339),
+	}
+}
+
+func exportCSV(commits []exportedCommit) ([]byte, error) {
+	var buf bytes.Buffer
+	w := csv.NewWriter(&buf)
+	w.Write([]string{"hash", "subject", "author", "date"})
+	for _, c := range commits {
+		w.Write([]string{c.Hash, c.Subject, c.Author, c.Date})
+	}
+	w.Flush()
+	return buf.Bytes(), w.Error()
+}
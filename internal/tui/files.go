@@ -0,0 +1,160 @@
+package tui
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+
+	"arbor/internal/gitgraph"
+	"arbor/internal/platform"
+
+	tea "github.com/charmbracelet/bubbletea"
+	"github.com/charmbracelet/x/ansi"
+	"github.com/go-git/go-git/v5/plumbing"
+)
+
+const (
+	noFileChanges    = "(no file changes)"
+	filesUnavailable = "(unable to load files)"
+)
+
+// pickableFiles is the selected commit's changed files when they have the
+// focus, so one can be picked, or nil.
+func (m *model) pickableFiles(commit *gitgraph.CommitInfo) []string {
+	if !m.filesFocus || !m.showFiles || commit == nil || commit.Commit == nil {
+		return nil
+	}
+	files := m.changedFiles(commit)
+	if len(files) == 1 && (files[0] == noFileChanges || files[0] == filesUnavailable) {
+		return nil
+	}
+	return files
+}
+
+// fileIndex is the changed file picked in the focused sidebar; a new
+// selection starts at the first.
+func (m *model) fileIndex(hash plumbing.Hash, files int) int {
+	if hash != m.fileHash {
+		return 0
+	}
+	return clamp(m.file, 0, max(0, files-1))
+}
+
+// fileLines lists the changed files, one line each so the picked one can
+// be scrolled to; long paths keep their end, where the file name is.
+func (m *model) fileLines(commit *gitgraph.CommitInfo, width int) []string {
+	title := sidebarSubtitleStyle.Render("Changed files")
+	picking := m.pickableFiles(commit) != nil
+	if picking {
+		title += sidebarHintStyle.Render(" d diff, o open, y copy, f history")
+	}
+	lines := []string{"", title}
+	files := m.changedFiles(commit)
+	picked := m.fileIndex(commit.Hash, len(files))
+	for i, file := range files {
+		line := "- " + sanitizeLine(file)
+		if over := ansi.StringWidth(line) - max(1, width-2); over > 0 {
+			line = ansi.TruncateLeft(line, over+1, "…")
+		}
+		if picking && i == picked {
+			line = matchStyle.Render(line)
+		}
+		lines = append(lines, line)
+	}
+	return lines
+}
+
+// handleFileKey moves between the changed files in the focused sidebar and
+// acts on the picked one; it reports false for keys it does not use.
+func (m *model) handleFileKey(key string) (bool, tea.Cmd) {
+	commit := m.selectedCommit()
+	files := m.pickableFiles(commit)
+	if files == nil {
+		return false, nil
+	}
+	picked := m.fileIndex(commit.Hash, len(files))
+	file := files[picked]
+	switch key {
+	case "d":
+		return true, m.openFileDiff(commit, file)
+	case "o":
+		m.openFile(file)
+		return true, nil
+	case "y":
+		return true, copyText(file, "copied "+file)
+	case "f":
+		m.setFolder(file)
+		return true, nil
+	}
+	switch m.keys.action(key) {
+	case actionUp:
+		picked--
+	case actionDown:
+		picked++
+	default:
+		return false, nil
+	}
+	m.file, m.fileHash = clamp(picked, 0, len(files)-1), commit.Hash
+	m.scrollToFile()
+	return true, nil
+}
+
+// scrollToFile scrolls the sidebar just enough to show the picked file.
+// The files are its last lines, one each.
+func (m *model) scrollToFile() {
+	commit := m.selectedCommit()
+	files := len(m.pickableFiles(commit))
+	if files == 0 {
+		return
+	}
+	lines := len(m.sidebarContent(m.sidebarWidth()))
+	rows := m.sidebarRows()
+	if lines <= rows {
+		return
+	}
+	line := lines - files + m.fileIndex(commit.Hash, files)
+	scroll := m.scrollOffset()
+	// The ▲ and ▼ indicators each take a row.
+	if line < scroll+1 {
+		scroll = line - 1
+	}
+	if line > scroll+rows-2 {
+		scroll = line - rows + 2
+	}
+	m.sidebarScroll = clamp(scroll, 0, lines-rows+1)
+	m.sidebarHash = commit.Hash
+}
+
+func (m *model) openFileDiff(commit *gitgraph.CommitInfo, file string) tea.Cmd {
+	title := fmt.Sprintf("Diff %s %s", commit.ShortHash(), file)
+	changes, err := gitgraph.CommitChanges(commit.Commit)
+	if err != nil {
+		m.openPopup(title, err.Error())
+		return nil
+	}
+	var diffs []*gitgraph.FileDiff
+	for _, diff := range gitgraph.FileDiffs(m.provider.Repo().Storer, changes) {
+		if diff.Path == file {
+			diffs = append(diffs, diff)
+		}
+	}
+	return m.openDiffPager(title, diffs)
+}
+
+// openFile opens the working tree's copy of file with the system opener.
+func (m *model) openFile(file string) {
+	const title = "Open file"
+	if !m.worktreeAvailable(title) {
+		return
+	}
+	full := filepath.Join(m.repoPath, filepath.FromSlash(file))
+	if _, err := os.Stat(full); err != nil {
+		m.openPopup(title, file+" is not in the working tree; it may have been deleted or renamed since.")
+		return
+	}
+	if err := platform.Open(full); err != nil {
+		m.openPopup(title, err.Error())
+		return
+	}
+	m.status = "opened " + file
+}
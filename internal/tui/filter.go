@@ -0,0 +1,80 @@
+package tui
+
+import (
+	"strconv"
+	"time"
+
+	tea "github.com/charmbracelet/bubbletea"
+)
+
+// filterScanStep bounds how long one scan step holds the event loop, so keys
+// typed while a filter walks deep history are handled between steps.
+const filterScanStep = 20 * time.Millisecond
+
+type filterScanMsg struct {
+	gen int
+}
+
+// scanFilter schedules the next scan step while the filtered list has fewer
+// matches than the viewport needs and more history is left to load.
+func (m *model) scanFilter() tea.Cmd {
+	if m.filterScanning || m.filterStopped || !m.filterNeedsMore() {
+		return nil
+	}
+	m.filterScanning = true
+	gen := m.filterGen
+	return func() tea.Msg {
+		return filterScanMsg{gen: gen}
+	}
+}
+
+func (m *model) filterNeedsMore() bool {
+	const buffer = 5
+	return m.filtering() && m.provider.HasMore() &&
+		(m.exporting || len(m.filtered) <= m.offset+m.pageRows(m.offset)+buffer)
+}
+
+func (m *model) stepFilterScan(msg filterScanMsg) tea.Cmd {
+	if msg.gen != m.filterGen {
+		return nil
+	}
+	m.filterScanning = false
+	deadline := time.Now().Add(filterScanStep)
+	for m.filterNeedsMore() && time.Now().Before(deadline) {
+		if err := m.provider.Ensure(len(m.provider.Commits)); err != nil {
+			m.filterStopped = true
+			m.status = err.Error()
+			break
+		}
+		m.refreshFilter()
+	}
+	m.normalizePosition()
+	var export tea.Cmd
+	if m.exporting && !m.filterNeedsMore() {
+		m.exporting = false
+		if !m.filterStopped {
+			export = m.promptExport()
+		}
+	}
+	return tea.Batch(m.loadVisible(), m.scanFilter(), export)
+}
+
+// stopFilterScan keeps the matches found so far; a new search starts over.
+func (m *model) stopFilterScan() {
+	m.filterGen++
+	m.filterScanning = false
+	m.filterStopped = true
+	if m.exporting {
+		m.exporting = false
+		m.status = "export stopped"
+	}
+}
+
+// formatCount groups digits in thousands: 48210 -> "48,210".
+func formatCount(n int) string {
+	s := strconv.Itoa(n)
+	for i := len(s) - 3; i > 0 && s[i-1] != '-'; i -= 3 {
+		s = s[:i] + "," + s[i:]
+	}
+	return s
+}
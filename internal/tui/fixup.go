@@ -0,0 +1,114 @@
+package tui
+
+import (
+	"fmt"
+
+	"arbor/internal/gitops"
+
+	tea "github.com/charmbracelet/bubbletea"
+)
+
+const rebaseAdvice = "Resolve and run `git rebase --continue`, or `git rebase --abort`."
+
+func (m *model) startFixup() {
+	const title = "Fixup"
+	commit := m.selectedCommit()
+	if commit == nil || !m.worktreeAvailable(title) || !m.allowed(title, gitops.OpCommit, "") {
+		return
+	}
+	staged, err := m.git.HasStagedChanges()
+	if err != nil {
+		m.openPopup(title, err.Error())
+		return
+	}
+	if !staged {
+		m.openPopup(title, "Nothing is staged. Stage the changes for the fixup with `git add` first.")
+		return
+	}
+	git := m.committer()
+	target := commit.Hash.String()
+	hasParent := commit.Commit.NumParents() > 0
+	useEditor := m.useEditor
+	lines := []string{
+		fmt.Sprintf("Commit the staged changes as fixup! %s %s", commit.ShortHash(), commit.Subject),
+		"Author: " + m.identityLabel(),
+		"",
+	}
+	commitOnly := popupAction{key: "enter", label: "commit", run: func() tea.Cmd {
+		return runGitOp(title, func() (string, error) {
+			return git.CommitFixup(target)
+		})
+	}}
+	if err := git.Allow(gitops.OpRewrite, git.CurrentBranch()); err != nil {
+		m.openPrompt(title, append(lines, "Autosquash is unavailable: "+err.Error()), commitOnly)
+		return
+	}
+	m.openPrompt(title,
+		append(lines, "Autosquash rebases from the target's parent and folds the fixup in right away."),
+		commitOnly,
+		popupAction{key: "r", label: "commit + autosquash", run: func() tea.Cmd {
+			if useEditor {
+				return fixupInEditor(title, git, target, hasParent)
+			}
+			return runGitOp(title, func() (string, error) {
+				out, err := git.CommitFixup(target)
+				if err != nil {
+					return out, err
+				}
+				rebased, err := git.AutosquashRebase(target, hasParent)
+				if err != nil {
+					return rebased, fmt.Errorf("%w\n%s", err, rebaseAdvice)
+				}
+				if rebased == "" {
+					rebased = "Autosquash rebase complete."
+				}
+				return out + "\n" + rebased, nil
+			})
+		}},
+	)
+}
+
+// fixupCommittedMsg reports the fixup commit made before the autosquash
+// todo list opens.
+type fixupCommittedMsg struct {
+	title     string
+	git       *gitops.Repo
+	target    string
+	hasParent bool
+	output    string
+	err       error
+}
+
+// fixupInEditor commits the fixup in the background; applyFixupCommitted
+// then hands the terminal to git so the autosquash todo list opens in the
+// user's sequence editor.
+func fixupInEditor(title string, git *gitops.Repo, target string, hasParent bool) tea.Cmd {
+	return func() tea.Msg {
+		out, err := git.CommitFixup(target)
+		return fixupCommittedMsg{title: title, git: git, target: target, hasParent: hasParent, output: out, err: err}
+	}
+}
+
+func (m *model) applyFixupCommitted(msg fixupCommittedMsg) tea.Cmd {
+	title, git, out := msg.title, msg.git, msg.output
+	if msg.err != nil {
+		return func() tea.Msg { return gitOpMsg{title: title, output: out, err: msg.err} }
+	}
+	cmd, err := git.AutosquashRebaseCommand(msg.target, msg.hasParent)
+	if err != nil {
+		return func() tea.Msg { return gitOpMsg{title: title, output: out, err: err} }
+	}
+	before, err := git.Snapshot()
+	if err != nil {
+		return func() tea.Msg { return gitOpMsg{title: title, output: out, err: err} }
+	}
+	return tea.ExecProcess(cmd, func(err error) tea.Msg {
+		if err != nil {
+			err = fmt.Errorf("git rebase: %w\n%s", err, rebaseAdvice)
+		}
+		if recordErr := git.Record("autosquash", "soft", "", before); recordErr != nil && err == nil {
+			err = fmt.Errorf("undo journal: %w", recordErr)
+		}
+		return gitOpMsg{title: title, output: out + "\nAutosquash rebase complete.", err: err}
+	})
+}
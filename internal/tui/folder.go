@@ -0,0 +1,131 @@
+package tui
+
+import (
+	"fmt"
+	"path"
+	"slices"
+	"strconv"
+	"strings"
+
+	"arbor/internal/gitgraph"
+
+	tea "github.com/charmbracelet/bubbletea"
+)
+
+// pickFolder narrows history to commits touching the directory of one of
+// the selected commit's changed files, asking which when there are several.
+// With a folder already applied it clears it instead.
+func (m *model) pickFolder() {
+	const title = "Folder history"
+	if m.folder != "" {
+		m.setFolder("")
+		m.status = "folder filter cleared"
+		return
+	}
+	commit := m.selectedCommit()
+	if commit == nil || commit.Commit == nil {
+		return
+	}
+	var dirs []string
+	for _, file := range m.changedFiles(commit) {
+		if dir := path.Dir(file); dir != "." && !slices.Contains(dirs, dir+"/") {
+			dirs = append(dirs, dir+"/")
+		}
+	}
+	switch {
+	case len(dirs) == 0:
+		m.openPopup(title, "Every file this commit changes is at the top of the tree.")
+		return
+	case len(dirs) == 1:
+		m.setFolder(dirs[0])
+		return
+	}
+	slices.Sort(dirs)
+	const choices = 9
+	lines := []string{"Show the history of which folder?", ""}
+	actions := make([]popupAction, 0, choices)
+	for i, dir := range dirs {
+		if i == choices {
+			lines = append(lines, fmt.Sprintf("… %d more", len(dirs)-choices))
+			break
+		}
+		key := strconv.Itoa(i + 1)
+		lines = append(lines, key+"  "+dir)
+		actions = append(actions, popupAction{key: key, label: dir, run: func() tea.Cmd {
+			m.setFolder(dir)
+			return tea.Batch(m.loadVisible(), m.scanFilter())
+		}})
+	}
+	m.openPrompt(title, lines, actions...)
+	m.popup.hint = fmt.Sprintf("1-%d pick | esc close", len(actions))
+}
+
+// setFolder layers a path prefix under the search filter, keeping the
+// selected commit in view when it still matches.
+func (m *model) setFolder(dir string) {
+	selected := m.selectedCommit()
+	m.folder = dir
+	m.applyFilter(m.filter)
+	if selected != nil && m.passesFilters(selected) {
+		m.selectHash(selected.Hash)
+	}
+	m.ensureVisible()
+	m.normalizePosition()
+}
+
+// clearFilters drops the search filter, the folder, the trailer, and the
+// mine filter.
+func (m *model) clearFilters() {
+	m.folder = ""
+	m.trailer = nil
+	m.mine = nil
+	m.applyFilter("")
+}
+
+func (m *model) filtering() bool {
+	return m.filter != "" || m.folder != "" || m.trailer != nil || m.mine != nil
+}
+
+func (m *model) touchesFolder(commit *gitgraph.CommitInfo) bool {
+	if m.folder == "" {
+		return true
+	}
+	if commit.Commit == nil {
+		return false
+	}
+	touched, err := gitgraph.TouchesPath(commit.Commit, m.folder)
+	return err == nil && touched
+}
+
+// folderBreadcrumb renders "internal/tui/" as "internal › tui".
+func folderBreadcrumb(dir string) string {
+	return strings.Join(strings.Split(strings.TrimSuffix(dir, "/"), "/"), " › ")
+}
+
+// filterLabel describes the active filters for the footer.
+func (m *model) filterLabel() string {
+	var parts []string
+	if m.filter != "" {
+		label := "filter"
+		if m.filterFuzzy {
+			label = "fuzzy"
+		}
+		if m.ranked() {
+			label += " by relevance"
+		}
+		parts = append(parts, fmt.Sprintf("%s %q", label, m.filter))
+	}
+	switch {
+	case strings.HasSuffix(m.folder, "/"):
+		parts = append(parts, "folder "+m.folder)
+	case m.folder != "":
+		parts = append(parts, "file "+m.folder)
+	}
+	if m.trailer != nil {
+		parts = append(parts, "trailer "+m.trailer.String())
+	}
+	if m.mine != nil {
+		parts = append(parts, "mine")
+	}
+	return strings.Join(parts, ", ")
+}
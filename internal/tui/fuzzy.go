@@ -0,0 +1,86 @@
+package tui
+
+import (
+	"strings"
+	"unicode"
+
+	"github.com/charmbracelet/lipgloss"
+)
+
+// fuzzyMatch finds every character of query in text in order, ignoring
+// case and the spaces in query, the way fzf does. Of the places the match
+// can start it keeps the best scoring: each character counts, runs of
+// consecutive characters and characters starting a word count extra, and
+// gaps between characters cost a little. positions are the rune indexes
+// matched in text.
+func fuzzyMatch(query, text string) (score int, positions []int, ok bool) {
+	q := []rune(strings.ToLower(strings.ReplaceAll(query, " ", "")))
+	if len(q) == 0 {
+		return 0, nil, true
+	}
+	target := []rune(strings.ToLower(text))
+	for start, r := range target {
+		if r != q[0] {
+			continue
+		}
+		s, p, matched := fuzzyFrom(q, target, start)
+		if matched && (!ok || s > score) {
+			score, positions, ok = s, p, true
+		}
+	}
+	return score, positions, ok
+}
+
+// fuzzyFrom matches q greedily in target, its first character at start.
+func fuzzyFrom(q, target []rune, start int) (int, []int, bool) {
+	score, i := 0, start
+	positions := make([]int, 0, len(q))
+	for _, r := range q {
+		for i < len(target) && target[i] != r {
+			i++
+		}
+		if i == len(target) {
+			return 0, nil, false
+		}
+		score++
+		if n := len(positions); n > 0 {
+			if gap := i - positions[n-1] - 1; gap == 0 {
+				score += 2
+			} else {
+				score -= min(gap, 3)
+			}
+		}
+		if i == 0 || !unicode.IsLetter(target[i-1]) {
+			score += 3
+		}
+		positions = append(positions, i)
+		i++
+	}
+	return score, positions, true
+}
+
+func fuzzyScore(query, text string) (int, bool) {
+	score, _, ok := fuzzyMatch(query, text)
+	return score, ok
+}
+
+// highlightFuzzy renders text with style, marking the characters a fuzzy
+// query matched.
+func highlightFuzzy(text, query string, style lipgloss.Style) string {
+	_, positions, ok := fuzzyMatch(query, text)
+	runes := []rune(text)
+	// Lowering can change the number of runes; skip those rather than
+	// mark the wrong characters.
+	if !ok || len(positions) == 0 || len([]rune(strings.ToLower(text))) != len(runes) {
+		return style.Render(text)
+	}
+	var b strings.Builder
+	from := 0
+	for _, pos := range positions {
+		b.WriteString(style.Render(string(runes[from:pos])))
+		b.WriteString(matchStyle.Render(string(runes[pos])))
+		from = pos + 1
+	}
+	b.WriteString(style.Render(string(runes[from:])))
+	return b.String()
+}
@@ -0,0 +1,135 @@
+package tui
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+
+	"arbor/internal/gitgraph"
+
+	tea "github.com/charmbracelet/bubbletea"
+)
+
+// maxCompletions caps the candidates a prompt lists after tab.
+const maxCompletions = 8
+
+// prefixIndex answers which names start with a prefix: a trie flattened
+// into a sorted list, where the names under a prefix are one run found by
+// binary search. That keeps an index of every loaded hash small.
+type prefixIndex []string
+
+func newPrefixIndex(names []string) prefixIndex {
+	sort.Strings(names)
+	return prefixIndex(compactSorted(names))
+}
+
+func compactSorted(names []string) []string {
+	out := names[:0]
+	for i, name := range names {
+		if i == 0 || name != names[i-1] {
+			out = append(out, name)
+		}
+	}
+	return out
+}
+
+// complete lists up to limit names starting with prefix, and the longest
+// prefix all of them share.
+func (ix prefixIndex) complete(prefix string, limit int) (matches []string, common string, total int) {
+	i := sort.SearchStrings(ix, prefix)
+	for j := i; j < len(ix) && strings.HasPrefix(ix[j], prefix); j++ {
+		if total == 0 {
+			common = ix[j]
+		} else {
+			common = commonPrefix(common, ix[j])
+		}
+		if total < limit {
+			matches = append(matches, ix[j])
+		}
+		total++
+	}
+	return matches, common, total
+}
+
+func commonPrefix(a, b string) string {
+	n := 0
+	for n < len(a) && n < len(b) && a[n] == b[n] {
+		n++
+	}
+	return a[:n]
+}
+
+// completion is what tab offers for a prompt's value: the longest
+// prefix every candidate shares, and up to maxCompletions of them.
+type completion struct {
+	common     string
+	candidates []string
+	total      int
+}
+
+// refCompletions completes branch, tag, and remote names, and the hashes
+// of loaded commits once the value picks out one of them.
+func (m *model) refCompletions() func(string) completion {
+	var names []string
+	if refs, err := gitgraph.ListRefs(m.provider.Repo()); err == nil {
+		for _, ref := range refs {
+			if ref.Kind != gitgraph.RefPull {
+				names = append(names, ref.Short)
+			}
+		}
+	}
+	refs := newPrefixIndex(names)
+	hashes := make([]string, len(m.provider.Commits))
+	for i, commit := range m.provider.Commits {
+		hashes[i] = commit.Hash.String()
+	}
+	commits := newPrefixIndex(hashes)
+	return func(value string) completion {
+		var c completion
+		c.candidates, c.common, c.total = refs.complete(value, maxCompletions)
+		if value == "" {
+			return c
+		}
+		if found, _, n := commits.complete(value, 1); n == 1 {
+			// The list's abbreviation, unless value is already as long.
+			hash := m.short(found[0])
+			if len(hash) <= len(value) {
+				hash = found[0]
+			}
+			if c.total == 0 {
+				c.common = hash
+			} else {
+				c.common = commonPrefix(c.common, hash)
+			}
+			c.candidates, c.total = append(c.candidates, hash), c.total+1
+		}
+		return c
+	}
+}
+
+// startGoto asks for a branch, tag, remote branch, or hash, with tab
+// completion, and selects that commit.
+func (m *model) startGoto() tea.Cmd {
+	m.input = &textInput{label: "Go to", complete: m.refCompletions(), submit: func(rev string) tea.Cmd {
+		rev = strings.TrimSpace(rev)
+		if rev == "" {
+			return nil
+		}
+		commit, err := gitgraph.ResolveCommit(m.provider.Repo(), rev)
+		if err != nil {
+			m.status = err.Error()
+			return nil
+		}
+		found := m.selectHash(commit.Hash)
+		if !found && m.filtering() {
+			m.clearFilters()
+			found = m.selectHash(commit.Hash)
+		}
+		if !found {
+			m.status = fmt.Sprintf("%s is not in the graph; %s shows all branches", rev, m.keys.first(actionScope))
+			return nil
+		}
+		return m.loadVisible()
+	}}
+	return nil
+}
@@ -0,0 +1,114 @@
+package tui
+
+import (
+	"slices"
+	"strings"
+
+	"arbor/internal/config"
+	"arbor/internal/gitgraph"
+
+	"github.com/charmbracelet/lipgloss"
+	"github.com/charmbracelet/x/ansi"
+)
+
+const (
+	graphLeft   = "left"
+	graphRight  = "right"
+	graphHidden = "hidden"
+)
+
+// cycleGraphPosition moves the graph from its place among the columns to
+// the right edge, then hides it, leaving a plain log with the most room
+// for subjects.
+func (m *model) cycleGraphPosition() {
+	if !slices.Contains(m.columns, "graph") {
+		m.status = "the graph is not in columns"
+		return
+	}
+	i := slices.Index(config.GraphPositions, m.graphPosition)
+	m.graphPosition = config.GraphPositions[(i+1)%len(config.GraphPositions)]
+	m.status = "graph " + m.graphPosition
+}
+
+// graphPlace is where the graph is drawn: hidden while matches are
+// sorted by relevance, as its lanes do not join up out of history order,
+// unless search_graph keeps it.
+func (m *model) graphPlace() string {
+	if m.ranked() && !m.rankedGraph {
+		return graphHidden
+	}
+	return m.graphPosition
+}
+
+// graphInline reports whether the graph is drawn in its place among the
+// columns.
+func (m *model) graphInline() bool {
+	return m.graphPlace() == graphLeft && slices.Contains(m.columns, "graph")
+}
+
+// rowColumns are the columns drawn left to right.
+func (m *model) rowColumns() []string {
+	if m.graphInline() {
+		return m.columns
+	}
+	return slices.DeleteFunc(slices.Clone(m.columns), func(column string) bool { return column == "graph" })
+}
+
+// rightGraphWidth is the width kept at the right edge for the graph: the
+// widest graph among the rows from start, so lanes line up from row to
+// row, and at most half the list.
+func (m *model) rightGraphWidth(start, rows, width int) int {
+	if m.graphPlace() != graphRight || !slices.Contains(m.columns, "graph") {
+		return 0
+	}
+	widest := 0
+	for i := start; i < start+rows; i++ {
+		commit := m.commitAt(i)
+		if commit == nil {
+			break
+		}
+		widest = max(widest, len(commit.Graph))
+	}
+	return min(widest, width/2)
+}
+
+// fitRow fits a row line to width, with cells, its graph, at the right
+// edge when the graph is on the right, a column clear of the sidebar.
+func (m *model) fitRow(text string, cells []gitgraph.GraphCell, width int, bg lipgloss.TerminalColor) string {
+	if m.graphWidth == 0 {
+		return fitLine(text, width, bg)
+	}
+	graph := ansi.Truncate(renderGraph(cells, bg), m.graphWidth, "")
+	if pad := m.graphWidth - len(cells); pad > 0 {
+		graph += rowSpacerStyle.Background(bg).Render(strings.Repeat(" ", pad))
+	}
+	space := rowSpacerStyle.Background(bg).Render(" ")
+	return fitLine(text, width-m.graphWidth-2, bg) + space + graph + space
+}
+
+// shrinkMin is the narrowest a shrinking column gets, ellipsis included.
+const shrinkMin = 6
+
+// shrinkColumns takes over cells out of the texts of the shrink columns,
+// first to last, each down to shrinkMin, so a tight row gives up its
+// author before its subject and keeps hash and graph whole. It reports
+// whether it narrowed any; what is still too wide is clipped at the right
+// edge.
+func (m *model) shrinkColumns(texts map[string]string, over int) bool {
+	shrunk := false
+	for _, column := range m.shrink {
+		text, ok := texts[column]
+		if !ok || over <= 0 {
+			continue
+		}
+		width := ansi.StringWidth(text)
+		cut := min(over, width-shrinkMin)
+		if cut <= 0 {
+			continue
+		}
+		texts[column] = truncateText(text, width-cut)
+		over -= cut
+		shrunk = true
+	}
+	return shrunk
+}
@@ -0,0 +1,186 @@
+package tui
+
+import (
+	"fmt"
+	"strings"
+
+	"arbor/internal/gitgraph"
+
+	tea "github.com/charmbracelet/bubbletea"
+	"github.com/charmbracelet/lipgloss"
+)
+
+const heatmapTitle = "Changes by path"
+
+// heatBarWidth is the longest bar, drawn for a path every loaded commit
+// changed.
+const heatBarWidth = 20
+
+type heatmapMsg struct {
+	view *heatmap
+	root *gitgraph.HeatNode
+	err  error
+}
+
+// heatmap is the loaded range aggregated into a tree of paths, directories
+// expanded on demand.
+type heatmap struct {
+	commits  int
+	root     *gitgraph.HeatNode
+	err      error
+	expanded map[string]bool
+	cursor   int
+}
+
+type heatRow struct {
+	node  *gitgraph.HeatNode
+	depth int
+}
+
+// openHeatmap counts changes per path over the commits loaded so far, in
+// the background since every commit needs a tree diff.
+func (m *model) openHeatmap() tea.Cmd {
+	commits := append([]*gitgraph.CommitInfo(nil), m.provider.Commits...)
+	view := &heatmap{commits: len(commits), expanded: make(map[string]bool)}
+	m.heatmap = view
+	return func() tea.Msg {
+		root, err := gitgraph.BuildHeatmap(commits)
+		return heatmapMsg{view: view, root: root, err: err}
+	}
+}
+
+func (m *model) applyHeatmap(msg heatmapMsg) {
+	msg.view.root, msg.view.err = msg.root, msg.err
+}
+
+// rows flattens the tree as drawn: top-level paths, and the children of
+// every expanded directory under its parent.
+func (h *heatmap) rows() []heatRow {
+	var rows []heatRow
+	var add func(node *gitgraph.HeatNode, depth int)
+	add = func(node *gitgraph.HeatNode, depth int) {
+		for _, child := range node.Children {
+			rows = append(rows, heatRow{node: child, depth: depth})
+			if h.expanded[child.Path] {
+				add(child, depth+1)
+			}
+		}
+	}
+	if h.root != nil {
+		add(h.root, 0)
+	}
+	return rows
+}
+
+func (m *model) handleHeatmapKey(msg tea.KeyMsg) (tea.Model, tea.Cmd) {
+	h := m.heatmap
+	rows := h.rows()
+	switch msg.String() {
+	case "ctrl+c":
+		return m, tea.Quit
+	case "esc", "q":
+		m.heatmap = nil
+		return m, nil
+	case "up", "k":
+		h.cursor = max(0, h.cursor-1)
+	case "down", "j":
+		h.cursor = min(max(0, len(rows)-1), h.cursor+1)
+	}
+	if h.cursor >= len(rows) {
+		return m, nil
+	}
+	row := rows[h.cursor]
+	switch msg.String() {
+	case "enter", "right", "l":
+		if row.node.Dir() {
+			h.expanded[row.node.Path] = msg.String() != "enter" || !h.expanded[row.node.Path]
+			return m, nil
+		}
+		if msg.String() == "enter" {
+			return m, m.showPathHistory(row.node.Path)
+		}
+	case "left", "h":
+		if row.node.Dir() && h.expanded[row.node.Path] {
+			h.expanded[row.node.Path] = false
+			return m, nil
+		}
+		for i := h.cursor - 1; i >= 0; i-- {
+			if rows[i].depth < row.depth {
+				h.cursor = i
+				break
+			}
+		}
+	case "f":
+		return m, m.showPathHistory(row.node.Path)
+	}
+	return m, nil
+}
+
+// showPathHistory leaves the heatmap for the history of one file or
+// directory, through the same filter the folder action applies.
+func (m *model) showPathHistory(p string) tea.Cmd {
+	m.heatmap = nil
+	m.setFolder(p)
+	return tea.Batch(m.loadVisible(), m.scanFilter())
+}
+
+func (m *model) renderHeatmap(width, height int) string {
+	h := m.heatmap
+	boxWidth := min(max(30, width*4/5), max(1, width-4))
+	lines := []string{popupTitleStyle.Render(fmt.Sprintf("%s (%d commits loaded)", heatmapTitle, h.commits)), ""}
+
+	rows := h.rows()
+	switch {
+	case h.err != nil:
+		lines = append(lines, h.err.Error())
+	case h.root == nil:
+		lines = append(lines, fmt.Sprintf("Counting changes in %d commits…", h.commits))
+	case len(rows) == 0:
+		lines = append(lines, "No file changes in the loaded commits.")
+	default:
+		barWidth := min(heatBarWidth, max(4, (boxWidth-4)/4))
+		nameWidth := max(8, boxWidth-4-barWidth-8)
+		visible := max(1, height-6)
+		h.cursor = clamp(h.cursor, 0, len(rows)-1)
+		start := clamp(h.cursor-visible/2, 0, max(0, len(rows)-visible))
+		end := min(start+visible, len(rows))
+		for i := start; i < end; i++ {
+			node := rows[i].node
+			marker := "  "
+			if node.Dir() && h.expanded[node.Path] {
+				marker = "▾ "
+			} else if node.Dir() {
+				marker = "▸ "
+			}
+			name := strings.Repeat("  ", rows[i].depth) + marker + node.Name
+			if node.Dir() {
+				name += "/"
+			}
+			label := fmt.Sprintf("%-*s %6d ", nameWidth, truncateText(name, nameWidth), node.Count)
+			bar := heatBar(node.Count, h.root.Count, barWidth)
+			if i == h.cursor {
+				lines = append(lines, listCursorStyle.Width(boxWidth-2).Render(label+bar))
+				continue
+			}
+			lines = append(lines, label+heatBarStyle.Render(bar))
+		}
+	}
+	lines = append(lines, "", popupHintStyle.Render("enter expand or history | h/l fold | f history | esc close"))
+	box := popupStyle.Width(boxWidth).Render(strings.Join(lines, "\n"))
+	return lipgloss.Place(width, height, lipgloss.Center, lipgloss.Center, box,
+		lipgloss.WithWhitespaceBackground(palette.bg))
+}
+
+// heatBar draws count out of total as a bar of up to width cells, in
+// eighths so small differences still show.
+func heatBar(count, total, width int) string {
+	if total == 0 || count == 0 {
+		return ""
+	}
+	eighths := max(1, count*width*8/total)
+	bar := strings.Repeat("█", eighths/8)
+	if rest := eighths % 8; rest > 0 {
+		bar += string([]rune("▏▎▍▌▋▊▉")[rest-1])
+	}
+	return bar
+}
@@ -0,0 +1,57 @@
+package tui
+
+import (
+	"arbor/internal/config"
+	"arbor/internal/gitops"
+)
+
+// identity returns the configured identity arbor commits as, or nil for
+// git's own user.name and user.email.
+func (m *model) identity() *gitops.Identity {
+	if m.identityIndex < 0 || m.identityIndex >= len(m.identities) {
+		return nil
+	}
+	id := m.identities[m.identityIndex]
+	return &gitops.Identity{Name: id.Name, Email: id.Email}
+}
+
+// committer is the repo handle commits should go through.
+func (m *model) committer() *gitops.Repo {
+	return m.git.As(m.identity())
+}
+
+// cycleIdentity steps through the configured identities, then back to git's
+// default.
+func (m *model) cycleIdentity() {
+	if len(m.identities) == 0 {
+		m.status = "no identities configured"
+		return
+	}
+	m.identityIndex++
+	if m.identityIndex >= len(m.identities) {
+		m.identityIndex = -1
+	}
+	m.status = "committing as " + m.identityLabel()
+}
+
+// identityLabel describes the active identity, e.g. "work: Jane <jane@corp>".
+func (m *model) identityLabel() string {
+	if id := m.identity(); id != nil {
+		return m.identities[m.identityIndex].Label + ": " + id.String()
+	}
+	if m.git != nil {
+		if id, err := m.git.DefaultIdentity(); err == nil {
+			return "git default: " + id.String()
+		}
+	}
+	return "git default"
+}
+
+func identityIndex(identities []config.Identity, label string) int {
+	for i, id := range identities {
+		if id.Label == label {
+			return i
+		}
+	}
+	return -1
+}
@@ -0,0 +1,141 @@
+package tui
+
+import (
+	"fmt"
+	"strings"
+	"time"
+
+	"arbor/internal/gitops"
+
+	tea "github.com/charmbracelet/bubbletea"
+)
+
+const inProgressTitle = "Operation in progress"
+
+// checkInProgress looks for a merge, rebase, cherry-pick, revert, or bisect
+// left partway, and for lock files a crashed git left behind, so the banner
+// can say so instead of showing the graph as if nothing were going on.
+func (m *model) checkInProgress() {
+	m.operation, m.staleLocks = nil, nil
+	if m.git == nil {
+		return
+	}
+	m.operation, _ = m.git.InProgress()
+	m.staleLocks, _ = m.git.StaleLocks()
+}
+
+// inProgressBanner is the banner text for the operation or stale locks
+// found, "" for none.
+func (m *model) inProgressBanner() string {
+	var parts []string
+	if op := m.operation; op != nil {
+		text := operationName(op) + " in progress"
+		switch n := len(op.Conflicts); {
+		case n == 1:
+			text += ", 1 file in conflict"
+		case n > 1:
+			text += fmt.Sprintf(", %d files in conflict", n)
+		}
+		parts = append(parts, text)
+	}
+	for _, lock := range m.staleLocks {
+		parts = append(parts, fmt.Sprintf("stale %s from %s ago", lock.Path, lock.Age.Round(time.Minute)))
+	}
+	if len(parts) == 0 {
+		return ""
+	}
+	text := "⚠ " + strings.Join(parts, "; ")
+	if key := m.keys.first(actionOperation); key != "" {
+		text += fmt.Sprintf(" | %s continue or abort", key)
+	}
+	return text
+}
+
+func operationName(op *gitops.Operation) string {
+	switch op.Kind {
+	case "cherry-pick":
+		return "Cherry-pick"
+	case "bisect":
+		return "Bisect"
+	}
+	return strings.ToUpper(op.Kind[:1]) + op.Kind[1:]
+}
+
+// showInProgress opens what the banner names, with its quick actions:
+// continue or abort the operation, and remove stale locks. It reports
+// whether there was anything to show.
+func (m *model) showInProgress() bool {
+	m.checkInProgress()
+	if m.operation == nil && len(m.staleLocks) == 0 {
+		return false
+	}
+	if m.operation != nil && m.operation.Kind == "rebase" && len(m.staleLocks) == 0 {
+		return m.showRebaseStatus(nil)
+	}
+	git := m.git
+	var lines []string
+	var actions []popupAction
+	if op := m.operation; op != nil {
+		lines = append(lines, m.describeOperation(op)...)
+		if op.Kind != "bisect" {
+			actions = append(actions, popupAction{key: "c", label: "continue", run: func() tea.Cmd {
+				return runGitOp(operationName(op), func() (string, error) { return git.Continue(op) })
+			}})
+		}
+		actions = append(actions, popupAction{key: "a", label: "abort", run: func() tea.Cmd {
+			return runGitOp(operationName(op), func() (string, error) { return git.Abort(op) })
+		}})
+	}
+	if len(m.staleLocks) > 0 {
+		if len(lines) > 0 {
+			lines = append(lines, "")
+		}
+		for _, lock := range m.staleLocks {
+			lines = append(lines, fmt.Sprintf("%s is %s old.", lock.Path, lock.Age.Round(time.Minute)))
+		}
+		lines = append(lines, "A git that crashed left it behind, unless one is still running; until it is removed, every git command that changes the repository fails.")
+		locks := m.staleLocks
+		actions = append(actions, popupAction{key: "r", label: "remove lock", run: func() tea.Cmd {
+			return runGitOp("Remove lock", func() (string, error) {
+				for _, lock := range locks {
+					if err := git.RemoveLock(lock); err != nil {
+						return "", err
+					}
+				}
+				return fmt.Sprintf("Removed %d lock files.", len(locks)), nil
+			})
+		}})
+	}
+	m.openPrompt(inProgressTitle, lines, actions...)
+	return true
+}
+
+func (m *model) describeOperation(op *gitops.Operation) []string {
+	branch := m.git.CurrentBranch()
+	if branch == "" {
+		branch = "detached HEAD"
+	}
+	var lines []string
+	switch op.Kind {
+	case "rebase":
+		lines = append(lines, fmt.Sprintf("Rebasing %s: commit %d of %d.", branch, op.Rebase.Step, op.Rebase.Total))
+	case "merge":
+		lines = append(lines, fmt.Sprintf("Merging %s into %s.", m.short(op.Head), branch))
+	case "cherry-pick":
+		lines = append(lines, fmt.Sprintf("Cherry-picking %s onto %s.", m.short(op.Head), branch))
+	case "revert":
+		lines = append(lines, fmt.Sprintf("Reverting %s on %s.", m.short(op.Head), branch))
+	case "bisect":
+		lines = append(lines, "Bisecting: mark the checked-out commit with git bisect good or git bisect bad until git names the first bad one. Abort returns to where the bisect started.")
+	}
+	if len(op.Conflicts) > 0 {
+		lines = append(lines, "", "Conflicts:")
+		for _, path := range op.Conflicts {
+			lines = append(lines, "  "+path)
+		}
+		lines = append(lines, "", "Resolve them in your editor and stage them with git add, then continue.")
+	} else if op.Kind != "bisect" {
+		lines = append(lines, "", "Nothing is left in conflict; continue to finish it.")
+	}
+	return lines
+}
@@ -0,0 +1,196 @@
+package tui
+
+import (
+	"fmt"
+	"os"
+	"strings"
+
+	"arbor/internal/gitops"
+
+	tea "github.com/charmbracelet/bubbletea"
+	"github.com/charmbracelet/lipgloss"
+)
+
+// textInput is the single-line prompt used by actions that need text, shown
+// in the bottom bar like search.
+type textInput struct {
+	label  string
+	value  string
+	submit func(value string) tea.Cmd
+	// commit prompts show the active identity and switch it with tab.
+	commit bool
+	author string
+	// secret prompts echo dots, for passphrases and tokens.
+	secret bool
+	// complete, when set, is what tab fills in; candidates are what it
+	// last offered, when more than one fit.
+	complete   func(value string) completion
+	candidates []string
+}
+
+type editorMsg struct {
+	label  string
+	text   string
+	err    error
+	submit func(value string) tea.Cmd
+}
+
+// openInput asks for text, either in the bottom bar or, with use_editor, in
+// the user's git editor while the program is suspended.
+func (m *model) openInput(label, value string, submit func(string) tea.Cmd) tea.Cmd {
+	if m.useEditor && m.git != nil {
+		return m.editInEditor(label, value, submit)
+	}
+	m.input = &textInput{label: label, value: value, submit: submit}
+	return nil
+}
+
+// openCommitInput is openInput for commit messages; submit should commit
+// through m.committer() so the identity picked in the prompt applies.
+func (m *model) openCommitInput(label, value string, submit func(string) tea.Cmd) tea.Cmd {
+	author := m.identityLabel()
+	if m.useEditor && m.git != nil {
+		return m.editInEditor(label+" as "+author, value, submit)
+	}
+	m.input = &textInput{label: label, value: value, submit: submit, commit: true, author: author}
+	return nil
+}
+
+func (m *model) editInEditor(label, value string, submit func(string) tea.Cmd) tea.Cmd {
+	editor, err := m.git.Editor()
+	if err != nil {
+		m.openPopup(label, err.Error())
+		return nil
+	}
+	f, err := os.CreateTemp("", "arbor-*.txt")
+	if err != nil {
+		m.openPopup(label, err.Error())
+		return nil
+	}
+	path := f.Name()
+	comment := m.git.CommentChar(value)
+	_, err = fmt.Fprintf(f, "%s\n\n%[2]s %[3]s. Lines starting with '%[2]s' are ignored;\n%[2]s an empty message cancels.\n", value, comment, label)
+	if closeErr := f.Close(); err == nil {
+		err = closeErr
+	}
+	if err != nil {
+		os.Remove(path)
+		m.openPopup(label, err.Error())
+		return nil
+	}
+	return tea.ExecProcess(m.git.EditorCommand(editor, path), func(err error) tea.Msg {
+		defer os.Remove(path)
+		if err != nil {
+			return editorMsg{label: label, err: fmt.Errorf("editor %s: %w", editor, err)}
+		}
+		data, err := os.ReadFile(path)
+		return editorMsg{label: label, text: gitops.CleanMessage(string(data), comment), err: err, submit: submit}
+	})
+}
+
+func (m *model) applyEditor(msg editorMsg) tea.Cmd {
+	if msg.err != nil {
+		m.openPopup(msg.label, msg.err.Error())
+		return nil
+	}
+	if msg.text == "" {
+		return nil
+	}
+	return msg.submit(msg.text)
+}
+
+func (m *model) handleInputKey(msg tea.KeyMsg) (tea.Model, tea.Cmd) {
+	in := m.input
+	switch msg.Type {
+	case tea.KeyCtrlC:
+		return m, tea.Quit
+	case tea.KeyEsc:
+		m.input = nil
+		return m, nil
+	case tea.KeyEnter:
+		m.input = nil
+		return m, in.submit(in.value)
+	case tea.KeyTab:
+		if in.commit {
+			m.cycleIdentity()
+			in.author = m.identityLabel()
+		}
+		if in.complete != nil {
+			in.completeValue()
+		}
+		return m, nil
+	}
+	in.candidates = nil
+	switch msg.Type {
+	case tea.KeyBackspace, tea.KeyDelete:
+		if runes := []rune(in.value); len(runes) > 0 {
+			in.value = string(runes[:len(runes)-1])
+		}
+		return m, nil
+	case tea.KeyCtrlU:
+		in.value = ""
+		return m, nil
+	case tea.KeySpace:
+		in.value += " "
+		return m, nil
+	}
+	if msg.Runes != nil {
+		in.value += string(msg.Runes)
+	}
+	return m, nil
+}
+
+// completeValue extends the value as far as every candidate agrees, and
+// lists them when more than one is left.
+func (in *textInput) completeValue() {
+	c := in.complete(in.value)
+	if len(c.common) > len(in.value) {
+		in.value = c.common
+	}
+	in.candidates = nil
+	if c.total > 1 {
+		in.candidates = c.candidates
+		if more := c.total - len(c.candidates); more > 0 {
+			in.candidates = append(in.candidates, fmt.Sprintf("+%d more", more))
+		}
+	}
+}
+
+func (m *model) inputView(width int) string {
+	if width <= 0 {
+		width = m.width
+	}
+	label := scrubberLabelStyle.Render(m.input.label + ":")
+	// Drop hints right to left until the value has room; a commit prompt
+	// keeps its identity the longest.
+	var hints []string
+	if m.input.commit {
+		hints = append(hints, "as "+m.input.author, "tab identity")
+	}
+	switch {
+	case len(m.input.candidates) > 0:
+		hints = append(hints, strings.Join(m.input.candidates, " "))
+	case m.input.complete != nil:
+		hints = append(hints, "tab complete")
+	}
+	hints = append(hints, "enter confirm", "esc cancel")
+	hint := ""
+	room := width - 2 - lipgloss.Width(label) - 2
+	for n := len(hints); n > 0; n-- {
+		hint = scrubberHintStyle.Render(strings.Join(hints[:n], "  "))
+		if r := room - lipgloss.Width(hint) - 2; r >= 10 {
+			room = r
+			break
+		}
+		hint = ""
+	}
+	value := m.input.value
+	if m.input.secret {
+		value = strings.Repeat("•", len([]rune(value)))
+	}
+	line := fmt.Sprintf("%s %s▏", label, truncateText(value, max(0, room)))
+	if hint != "" {
+		line += "  " + hint
+	}
+	return searchStyle.Width(width).Render(line)
+}
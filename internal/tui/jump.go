@@ -0,0 +1,191 @@
+package tui
+
+import (
+	"fmt"
+	"strings"
+	"time"
+
+	"arbor/internal/gitgraph"
+
+	tea "github.com/charmbracelet/bubbletea"
+	"github.com/charmbracelet/lipgloss"
+)
+
+// selectStart puts the cursor on the configured start ref. It waits for
+// the first layout, so the commit can be centered on screen.
+func (m *model) selectStart() {
+	if m.start == "" {
+		return
+	}
+	commit, err := gitgraph.ResolveCommit(m.provider.Repo(), m.start)
+	if err != nil {
+		m.status = fmt.Sprintf("start: %v", err)
+		return
+	}
+	if !m.selectHash(commit.Hash) {
+		m.status = fmt.Sprintf("start: %s is not in the graph; %s shows all branches", m.start, m.keys.first(actionScope))
+	}
+}
+
+// seek is a pending jump to the next or previous match. Like filter scans it
+// loads history in bounded steps, so a rare match does not freeze the UI.
+type seek struct {
+	forward bool
+	wrapped bool
+	from    int
+	pos     int
+}
+
+type seekMsg struct {
+	gen int
+}
+
+// applyJump starts a search that keeps every commit listed and moves the
+// cursor to the first match after it, instead of filtering.
+func (m *model) applyJump(query string) tea.Cmd {
+	if m.filtering() {
+		if commit := m.selectedCommit(); commit != nil {
+			m.clearFilters()
+			m.selectHash(commit.Hash)
+		} else {
+			m.clearFilters()
+		}
+	}
+	m.jump = strings.TrimSpace(query)
+	if m.jump == "" {
+		m.stopSeek()
+		return nil
+	}
+	return m.findMatch(true)
+}
+
+// findMatch searches from the selected commit, wrapping around the ends of
+// history like less and vim do.
+func (m *model) findMatch(forward bool) tea.Cmd {
+	if m.jump == "" {
+		m.status = "no search: " + m.keys.first(actionFind) + " to start one"
+		return nil
+	}
+	if m.filtering() {
+		return m.applyJump(m.jump)
+	}
+	m.seekGen++
+	from := m.cursor
+	pos := from + 1
+	if !forward {
+		pos = from - 1
+	}
+	m.seeking = &seek{forward: forward, from: from, pos: pos}
+	return m.stepSeek(seekMsg{gen: m.seekGen})
+}
+
+func (m *model) stepSeek(msg seekMsg) tea.Cmd {
+	s := m.seeking
+	if s == nil || msg.gen != m.seekGen {
+		return nil
+	}
+	query := strings.ToLower(m.jump)
+	deadline := time.Now().Add(filterScanStep)
+	for time.Now().Before(deadline) {
+		commits := m.provider.Commits
+		if s.forward {
+			for ; s.pos < len(commits) && !(s.wrapped && s.pos > s.from); s.pos++ {
+				if matchesQuery(commits[s.pos], query) {
+					return m.landSeek(s.pos)
+				}
+			}
+			if s.wrapped {
+				return m.missSeek()
+			}
+			if !m.provider.HasMore() {
+				s.wrapped, s.pos = true, 0
+				continue
+			}
+		} else {
+			for ; s.pos >= 0 && !(s.wrapped && s.pos < s.from); s.pos-- {
+				if matchesQuery(commits[s.pos], query) {
+					return m.landSeek(s.pos)
+				}
+			}
+			if s.wrapped {
+				return m.missSeek()
+			}
+			// Wrapping backwards starts from the oldest commit, so the
+			// whole history has to be loaded first.
+			if !m.provider.HasMore() {
+				s.wrapped, s.pos = true, len(commits)-1
+				continue
+			}
+		}
+		if err := m.provider.Ensure(len(m.provider.Commits)); err != nil {
+			m.seeking = nil
+			m.status = err.Error()
+			return nil
+		}
+	}
+	gen := m.seekGen
+	return func() tea.Msg {
+		return seekMsg{gen: gen}
+	}
+}
+
+func (m *model) landSeek(index int) tea.Cmd {
+	if m.seeking.wrapped {
+		if m.seeking.forward {
+			m.status = "search hit bottom, continuing at top"
+		} else {
+			m.status = "search hit top, continuing at bottom"
+		}
+	}
+	m.seeking = nil
+	m.cursor = index
+	if index < m.offset || index >= m.offset+m.pageRows(m.offset) {
+		m.centerOn(index)
+	}
+	m.ensureVisible()
+	m.normalizePosition()
+	return m.loadVisible()
+}
+
+func (m *model) missSeek() tea.Cmd {
+	m.seeking = nil
+	m.status = fmt.Sprintf("%q not found", m.jump)
+	return nil
+}
+
+func (m *model) stopSeek() {
+	m.seekGen++
+	m.seeking = nil
+}
+
+// matchesQuery is the search predicate shared by filtering and jumping;
+// query must already be lower case.
+func matchesQuery(commit *gitgraph.CommitInfo, query string) bool {
+	return strings.Contains(strings.ToLower(commit.Subject), query) ||
+		strings.Contains(strings.ToLower(commit.Author), query)
+}
+
+// highlightSearch marks what the ? search matches in text, or the letters
+// a fuzzy filter matched.
+func (m *model) highlightSearch(text string, style lipgloss.Style) string {
+	if m.jump == "" && m.filterFuzzy && m.filter != "" {
+		return highlightFuzzy(text, m.filter, style)
+	}
+	return highlightMatch(text, m.jump, style)
+}
+
+// highlightMatch renders text with style, marking the first case-insensitive
+// occurrence of query.
+func highlightMatch(text, query string, style lipgloss.Style) string {
+	lower := strings.ToLower(text)
+	i := strings.Index(lower, strings.ToLower(query))
+	// Lowering can change byte lengths outside ASCII; skip those rather
+	// than cut a rune in half.
+	if query == "" || i < 0 || len(lower) != len(text) {
+		return style.Render(text)
+	}
+	end := i + len(query)
+	return style.Render(text[:i]) +
+		matchStyle.Render(text[i:end]) +
+		style.Render(text[end:])
+}
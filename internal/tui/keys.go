@@ -0,0 +1,112 @@
+package tui
+
+import (
+	"fmt"
+	"strings"
+)
+
+const (
+	actionQuit         = "quit"
+	actionUp           = "up"
+	actionDown         = "down"
+	actionFiles        = "files"
+	actionSearch       = "search"
+	actionFind         = "find"
+	actionNextMatch    = "next_match"
+	actionPrevMatch    = "prev_match"
+	actionSidebar      = "sidebar"
+	actionFocus        = "focus"
+	actionDensity      = "density"
+	actionReview       = "review"
+	actionCombined     = "combined"
+	actionMark         = "mark"
+	actionAncestry     = "ancestry"
+	actionScrubber     = "scrubber"
+	actionDismiss      = "dismiss"
+	actionFixup        = "fixup"
+	actionSquash       = "squash"
+	actionFolder       = "folder"
+	actionVisits       = "visits"
+	actionTag          = "tag"
+	actionHeatmap      = "heatmap"
+	actionScope        = "scope"
+	actionPull         = "pr"
+	actionFetch        = "fetch"
+	actionPush         = "push"
+	actionAmend        = "amend"
+	actionRemotes      = "remotes"
+	actionPalette      = "palette"
+	actionLog          = "log"
+	actionRebase       = "rebase"
+	actionPruneRemotes = "prune_remotes"
+	actionMarkdown     = "markdown"
+	actionGraph        = "graph"
+	actionMine         = "mine"
+	actionLegend       = "legend"
+	actionOperation    = "operation"
+	actionStage        = "stage"
+	actionBundle       = "bundle"
+	actionFollow       = "follow"
+	actionExport       = "export"
+	actionGoto         = "goto"
+	actionSort         = "sort"
+	actionReset        = "reset"
+	actionUndo         = "undo"
+	actionPrune        = "prune"
+	actionMarker       = "marker"
+	actionCopy         = "copy"
+	actionOpen         = "open"
+	actionBranches     = "branches"
+	actionRangeDiff    = "rangediff"
+	actionIdentity     = "identity"
+)
+
+type keyMap struct {
+	actions  map[string]string
+	bindings map[string][]string
+}
+
+func newKeyMap(bindings map[string][]string) keyMap {
+	km := keyMap{actions: make(map[string]string), bindings: bindings}
+	for action, keys := range bindings {
+		for _, key := range keys {
+			km.actions[key] = action
+		}
+	}
+	return km
+}
+
+func (k keyMap) action(key string) string {
+	return k.actions[key]
+}
+
+func (k keyMap) first(action string) string {
+	keys := k.bindings[action]
+	if len(keys) == 0 {
+		return ""
+	}
+	return keys[0]
+}
+
+func (k keyMap) hints() string {
+	up, down := k.bindings[actionUp], k.bindings[actionDown]
+	var move []string
+	for i := 0; i < len(up) && i < len(down); i++ {
+		move = append(move, up[i]+"/"+down[i])
+	}
+	parts := []string{strings.Join(move, " ") + " move"}
+	for _, hint := range []struct{ action, label string }{
+		{actionFiles, "files"},
+		{actionSearch, "search"},
+		{actionSidebar, "sidebar"},
+		{actionMark, "mark"},
+		{actionAncestry, "ancestry"},
+		{actionScrubber, "time"},
+		{actionQuit, "quit"},
+	} {
+		if key := k.first(hint.action); key != "" {
+			parts = append(parts, fmt.Sprintf("%s %s", key, hint.label))
+		}
+	}
+	return strings.Join(parts, " | ")
+}
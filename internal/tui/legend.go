@@ -0,0 +1,189 @@
+package tui
+
+import (
+	"fmt"
+	"slices"
+	"strings"
+
+	"arbor/internal/gitgraph"
+)
+
+const legendTitle = "Graph legend"
+
+// openLegend explains what is on screen: the graph symbols in view,
+// which branch each lane in view carries and in what color, and the badges
+// shown next to subjects. The legend key closes it again, in
+// handlePopupKey.
+func (m *model) openLegend() {
+	m.openPopup(legendTitle, m.legendLines()...)
+}
+
+func (m *model) legendLines() []string {
+	start := min(m.offset, max(0, m.listLength()-1))
+	var rows []*gitgraph.CommitInfo
+	last := -1
+	for i := start; i < start+m.viewportHeight(); i++ {
+		commit := m.commitAt(i)
+		if commit == nil {
+			break
+		}
+		rows = append(rows, commit)
+		last = i
+		if m.filtering() {
+			last = m.filtered[i]
+		}
+	}
+	if len(rows) == 0 {
+		return []string{"No commits in view."}
+	}
+
+	var lines []string
+	if !slices.Contains(m.columns, "graph") || m.graphPlace() == graphHidden {
+		lines = append(lines, fmt.Sprintf("The graph is hidden; %s shows it.", m.keys.first(actionGraph)))
+	} else {
+		lines = append(lines, m.symbolLines(rows)...)
+		lines = append(lines, "", sidebarSubtitleStyle.Render("Lanes in view"))
+		lines = append(lines, m.laneLines(rows, last)...)
+	}
+	if badges := m.badgeLines(rows, start); len(badges) > 0 {
+		lines = append(lines, "", sidebarSubtitleStyle.Render("Badges"))
+		lines = append(lines, badges...)
+	}
+	return lines
+}
+
+// symbolLines explains the graph characters drawn in rows.
+func (m *model) symbolLines(rows []*gitgraph.CommitInfo) []string {
+	drawn := make(map[string]bool)
+	for _, commit := range rows {
+		for _, cell := range commit.Graph {
+			drawn[cell.Ch] = true
+		}
+	}
+	symbols := []struct{ ch, meaning string }{
+		{"*", "a commit, in the lane of the branch it is on"},
+		{"|", "a lane: that line of history passing the row"},
+		{"\\", "a merge's second parent, in a lane opened to the right"},
+	}
+	lines := []string{sidebarSubtitleStyle.Render("Symbols")}
+	for _, symbol := range symbols {
+		if drawn[symbol.ch] {
+			lines = append(lines, markStyle.Background(palette.panelBg).Render(symbol.ch)+" "+symbol.meaning)
+		}
+	}
+	return lines
+}
+
+// laneLines lists each lane drawn in rows with a swatch of its color and
+// the branch it carries. Lane colors go by column, so names come from
+// walking the graph from the top to the last row in view (an index into
+// the loaded commits): a lane is named for the refs at its newest commit,
+// or else for the commit it starts at, and keeps that name until it ends.
+func (m *model) laneLines(rows []*gitgraph.CommitInfo, last int) []string {
+	labels := make(map[string][]string)
+	if refs, err := gitgraph.ListRefs(m.provider.Repo()); err == nil {
+		for _, ref := range m.decorations.Refs(refs) {
+			if ref.Kind != gitgraph.RefTag {
+				key := ref.Target.String()
+				labels[key] = append(labels[key], m.refLabel(ref))
+			}
+		}
+	}
+	names := make(map[int]string)
+	var above []gitgraph.GraphCell
+	for i := 0; i <= last && i < len(m.provider.Commits); i++ {
+		commit := m.provider.Commits[i]
+		for lane, cell := range commit.Graph {
+			switch cell.Ch {
+			case "*":
+				if refs := labels[commit.Hash.String()]; len(refs) > 0 {
+					names[lane] = strings.Join(refs, ", ")
+				} else if lane >= len(above) || above[lane].Ch == " " {
+					names[lane] = "starts at " + commit.ShortHash() + " " + sanitizeLine(commit.Subject)
+				}
+			case "\\":
+				names[lane] = "merged in at " + commit.ShortHash() + " " + sanitizeLine(commit.Subject)
+			}
+		}
+		above = commit.Graph
+	}
+
+	drawn := make(map[int]bool)
+	for _, commit := range rows {
+		for lane, cell := range commit.Graph {
+			if cell.Ch != " " {
+				drawn[lane] = true
+			}
+		}
+	}
+	var lines []string
+	for lane := 0; lane < maxLanes(rows); lane++ {
+		if !drawn[lane] {
+			continue
+		}
+		swatch := branchStyles[lane%len(branchStyles)].Background(palette.panelBg).Render("██")
+		name := names[lane]
+		if name == "" {
+			name = "history above the loaded commits"
+		}
+		lines = append(lines, fmt.Sprintf("%s %d %s", swatch, lane+1, name))
+	}
+	if maxLanes(rows) > len(branchStyles) {
+		lines = append(lines, fmt.Sprintf("Colors repeat every %d lanes.", len(branchStyles)))
+	}
+	return lines
+}
+
+func maxLanes(rows []*gitgraph.CommitInfo) int {
+	n := 0
+	for _, commit := range rows {
+		n = max(n, len(commit.Graph))
+	}
+	return n
+}
+
+// badgeLines explains the badges shown before subjects in rows, which
+// start at list index start.
+func (m *model) badgeLines(rows []*gitgraph.CommitInfo, start int) []string {
+	bg := palette.panelBg
+	var lines []string
+	has := func(test func(*gitgraph.CommitInfo) bool) bool {
+		return slices.ContainsFunc(rows, test)
+	}
+	if m.review != nil {
+		lines = append(lines, markStyle.Background(bg).Render("✓")+" reviewed, "+emptyStyle.Background(bg).Render("○")+" still to review")
+	}
+	if m.mark != nil && has(func(c *gitgraph.CommitInfo) bool { return c.Hash == m.mark.Hash }) {
+		lines = append(lines, markStyle.Background(bg).Render("◆")+" the marked commit")
+	}
+	if has(func(c *gitgraph.CommitInfo) bool { return len(m.audits[c.Hash]) > 0 }) {
+		lines = append(lines, warningStyle.Background(bg).Render("⚠")+" adds a large file or a likely secret; the sidebar lists them")
+	}
+	if has(func(c *gitgraph.CommitInfo) bool { return len(m.policyProblems[c.Hash]) > 0 }) {
+		lines = append(lines, warningStyle.Background(bg).Render("✗")+" breaks the audit.policy rules on authors or signing keys")
+	}
+	if has(func(c *gitgraph.CommitInfo) bool { return len(m.lintProblems(c)) > 0 }) {
+		lines = append(lines, emptyStyle.Background(bg).Render("✎")+" the message breaks a [lint] rule")
+	}
+	if m.headHistory && has(func(c *gitgraph.CommitInfo) bool { return len(m.headVisits[c.Hash]) > 0 }) {
+		lines = append(lines, emptyStyle.Background(bg).Render("↺")+" HEAD was here, per the reflog")
+	}
+	var envs []string
+	for _, commit := range rows {
+		for _, env := range m.deploys[commit.Hash] {
+			if !slices.Contains(envs, env) {
+				envs = append(envs, env)
+			}
+		}
+	}
+	for _, env := range envs {
+		lines = append(lines, deployStyle.Render(env)+" deployed to "+env)
+	}
+	for i := range rows {
+		if release, ok := m.releaseAt(start + i); ok {
+			lines = append(lines, fmt.Sprintf("── %s ── the commits below first shipped in %s", sanitizeLine(release), sanitizeLine(release)))
+			break
+		}
+	}
+	return lines
+}
@@ -0,0 +1,276 @@
+package tui
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/charmbracelet/lipgloss"
+	"github.com/charmbracelet/x/ansi"
+	"github.com/go-git/go-git/v5/plumbing"
+	"github.com/russross/blackfriday/v2"
+)
+
+// markdownExtensions read commit messages the way people write them:
+// lists straight after a line of text, and "#123" at the start of a line
+// as an issue number rather than a heading.
+const markdownExtensions = blackfriday.NoIntraEmphasis | blackfriday.FencedCode |
+	blackfriday.Strikethrough | blackfriday.SpaceHeadings | blackfriday.NoEmptyLineBeforeBlock
+
+// markdownKey names a commit body rendered at a width.
+type markdownKey struct {
+	hash  plumbing.Hash
+	width int
+}
+
+// markdownBody is markdownLines for hash's message, rendered once per
+// width.
+func (m *model) markdownBody(hash plumbing.Hash, message string, width int) []string {
+	key := markdownKey{hash, width}
+	if lines, ok := m.markdownCache[key]; ok {
+		return lines
+	}
+	lines := markdownLines(message, width)
+	m.markdownCache[key] = lines
+	return lines
+}
+
+// markdownLines renders a commit message for the sidebar: paragraphs
+// reflow to width, lists and quotes keep their markers, code blocks keep
+// their lines, and emphasis and code spans are styled. Text is sanitized
+// before parsing, so nothing in it reaches the terminal as a control
+// sequence.
+func markdownLines(message string, width int) []string {
+	root := blackfriday.New(blackfriday.WithExtensions(markdownExtensions)).Parse([]byte(sanitizeText(message)))
+	r := markdownRenderer{width: max(width, 1)}
+	r.blocks(root, "", "")
+	for len(r.lines) > 0 && r.lines[len(r.lines)-1] == "" {
+		r.lines = r.lines[:len(r.lines)-1]
+	}
+	return r.lines
+}
+
+// rawMessageLines is the message as written, each line wrapped on its own.
+func rawMessageLines(message string, width int) []string {
+	var lines []string
+	for _, line := range strings.Split(sanitizeText(message), "\n") {
+		lines = append(lines, wrapText(line, width)...)
+	}
+	return lines
+}
+
+type markdownRenderer struct {
+	width int
+	lines []string
+}
+
+// blocks renders node's children. first prefixes a block's first line and
+// rest every line after, which is how list markers and quote bars nest.
+func (r *markdownRenderer) blocks(node *blackfriday.Node, first, rest string) {
+	number := 0
+	for child := node.FirstChild; child != nil; child = child.Next {
+		prefix := first
+		if child != node.FirstChild {
+			prefix = rest
+		}
+		switch child.Type {
+		case blackfriday.Paragraph:
+			r.wrap(r.words(child, inlineStyle{}), prefix, rest)
+			if !tightItem(child) {
+				r.blank()
+			}
+		case blackfriday.Heading:
+			r.wrap(r.words(child, inlineStyle{bold: true, heading: true}), prefix, rest)
+			r.blank()
+		case blackfriday.List:
+			r.blocks(child, prefix, rest)
+			if child.Parent.Type != blackfriday.Item {
+				r.blank()
+			}
+		case blackfriday.Item:
+			number++
+			marker := "• "
+			if child.ListFlags&blackfriday.ListTypeOrdered != 0 {
+				marker = fmt.Sprintf("%d. ", number)
+			}
+			indent := strings.Repeat(" ", ansi.StringWidth(marker))
+			r.blocks(child, prefix+marker, rest+indent)
+		case blackfriday.BlockQuote:
+			bar := markdownQuoteStyle.Render("│") + " "
+			r.blocks(child, prefix+bar, rest+bar)
+		case blackfriday.CodeBlock:
+			code := strings.TrimRight(string(child.Literal), "\n")
+			for i, line := range strings.Split(code, "\n") {
+				p := rest
+				if i == 0 {
+					p = prefix
+				}
+				line = strings.ReplaceAll(line, "\t", "    ")
+				line = truncateText(line, r.width-ansi.StringWidth(p))
+				r.lines = append(r.lines, p+markdownCodeStyle.Render(line))
+			}
+			r.blank()
+		case blackfriday.HorizontalRule:
+			r.lines = append(r.lines, prefix+markdownQuoteStyle.Render(strings.Repeat("─", max(r.width-ansi.StringWidth(prefix), 1))))
+			r.blank()
+		default:
+			// HTML blocks and anything else unrendered read best as written.
+			for _, line := range strings.Split(strings.TrimRight(string(child.Literal), "\n"), "\n") {
+				r.wrap(strings.Fields(line), prefix, rest)
+				prefix = rest
+			}
+			r.blank()
+		}
+	}
+}
+
+// tightItem reports whether paragraph is a line of a tight list, which
+// has no blank lines between its items.
+func tightItem(paragraph *blackfriday.Node) bool {
+	item := paragraph.Parent
+	return item.Type == blackfriday.Item && item.Parent.Tight
+}
+
+func (r *markdownRenderer) blank() {
+	if len(r.lines) > 0 && r.lines[len(r.lines)-1] != "" {
+		r.lines = append(r.lines, "")
+	}
+}
+
+// wrap fills lines with words, each already styled, prefixing the first
+// line with first and the others with rest.
+func (r *markdownRenderer) wrap(words []string, first, rest string) {
+	if len(words) == 0 {
+		return
+	}
+	prefix := first
+	line := ""
+	for _, word := range words {
+		if word == "\n" {
+			r.lines = append(r.lines, prefix+line)
+			prefix, line = rest, ""
+			continue
+		}
+		if line != "" && ansi.StringWidth(prefix+line)+1+ansi.StringWidth(word) > r.width {
+			r.lines = append(r.lines, prefix+line)
+			prefix, line = rest, ""
+		}
+		if line != "" {
+			line += " "
+		}
+		line += word
+	}
+	if line != "" || prefix == first {
+		r.lines = append(r.lines, prefix+line)
+	}
+}
+
+type inlineStyle struct {
+	bold, italic, strike, code, link, heading bool
+}
+
+func (s inlineStyle) render(text string) string {
+	if s == (inlineStyle{}) {
+		return text
+	}
+	style := lipgloss.NewStyle().Foreground(palette.text).Background(palette.panelBg).
+		Bold(s.bold).Italic(s.italic).Strikethrough(s.strike)
+	switch {
+	case s.heading:
+		style = style.Foreground(palette.accent)
+	case s.code:
+		style = style.Foreground(palette.accentAlt)
+	case s.link:
+		style = style.Underline(true)
+	}
+	return style.Render(text)
+}
+
+// words splits node's inline content into words for wrapping, styling
+// each piece, so a word made of differently styled parts stays whole and
+// a hard line break becomes a "\n" word.
+func (r *markdownRenderer) words(node *blackfriday.Node, style inlineStyle) []string {
+	var words []string
+	word := ""
+	flush := func() {
+		if word != "" {
+			words = append(words, word)
+			word = ""
+		}
+	}
+	add := func(text string, style inlineStyle) {
+		start := 0
+		for i, ch := range text {
+			if ch == ' ' || ch == '\t' || ch == '\n' {
+				if i > start {
+					word += style.render(text[start:i])
+				}
+				flush()
+				start = i + 1
+			}
+		}
+		if start < len(text) {
+			word += style.render(text[start:])
+		}
+	}
+	var walk func(node *blackfriday.Node, style inlineStyle)
+	walk = func(node *blackfriday.Node, style inlineStyle) {
+		for child := node.FirstChild; child != nil; child = child.Next {
+			switch child.Type {
+			case blackfriday.Text, blackfriday.HTMLSpan:
+				add(string(child.Literal), style)
+			case blackfriday.Code:
+				s := style
+				s.code = true
+				add(string(child.Literal), s)
+			case blackfriday.Softbreak:
+				flush()
+			case blackfriday.Hardbreak:
+				flush()
+				words = append(words, "\n")
+			case blackfriday.Emph:
+				s := style
+				s.italic = true
+				walk(child, s)
+			case blackfriday.Strong:
+				s := style
+				s.bold = true
+				walk(child, s)
+			case blackfriday.Del:
+				s := style
+				s.strike = true
+				walk(child, s)
+			case blackfriday.Link, blackfriday.Image:
+				s := style
+				s.link = true
+				dest := string(child.Destination)
+				if linkShowsDestination(child, dest) {
+					// Written as <address>, which is how it reads best.
+					add("<", style)
+					walk(child, s)
+					add(">", style)
+					continue
+				}
+				walk(child, s)
+				if dest != "" {
+					flush()
+					add("("+dest+")", style)
+				}
+			default:
+				walk(child, style)
+			}
+		}
+	}
+	walk(node, style)
+	flush()
+	return words
+}
+
+// linkShowsDestination reports whether a link's text is its address, as
+// in <https://example.com>, so it need not be repeated.
+func linkShowsDestination(link *blackfriday.Node, dest string) bool {
+	text := ""
+	for child := link.FirstChild; child != nil; child = child.Next {
+		text += string(child.Literal)
+	}
+	return text == dest || "mailto:"+text == dest
+}
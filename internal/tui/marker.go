@@ -0,0 +1,38 @@
+package tui
+
+import (
+	"strings"
+	"time"
+
+	"arbor/internal/config"
+	"arbor/internal/gitops"
+
+	tea "github.com/charmbracelet/bubbletea"
+)
+
+func (m *model) startMarker() tea.Cmd {
+	const title = "Marker commit"
+	if !m.worktreeAvailable(title) || !m.allowed(title, gitops.OpCommit, "") {
+		return nil
+	}
+	head := ""
+	if ref, err := m.provider.Repo().Head(); err == nil {
+		head = m.short(ref.Hash().String())
+	}
+	now := time.Now()
+	message := config.ExpandTemplate(m.markerTemplate, map[string]string{
+		"branch": m.headName,
+		"head":   head,
+		"date":   now.Format("2006-01-02"),
+		"time":   now.Format("15:04"),
+	})
+	return m.openCommitInput(title, message, func(message string) tea.Cmd {
+		if strings.TrimSpace(message) == "" {
+			return nil
+		}
+		git := m.committer()
+		return runGitOp(title, func() (string, error) {
+			return git.CommitEmpty(message)
+		})
+	})
+}
@@ -0,0 +1,82 @@
+package tui
+
+import (
+	"errors"
+	"strings"
+
+	"arbor/internal/gitgraph"
+
+	"github.com/go-git/go-git/v5/config"
+)
+
+// mineFilter lists only the commits authored as "me": git's own identity
+// or any configured one, matched after the mailmap, so commits made under
+// an old or work address it maps to the same person count too.
+type mineFilter struct {
+	mailmap *gitgraph.Mailmap
+	emails  map[string]bool
+}
+
+// toggleMine layers the mine filter under the others, keeping the selected
+// commit in view when it still matches.
+func (m *model) toggleMine() {
+	selected := m.selectedCommit()
+	if m.mine != nil {
+		m.mine = nil
+		m.status = "showing every author"
+	} else {
+		mine, err := m.loadMine()
+		if err != nil {
+			m.openPopup("My commits", err.Error())
+			return
+		}
+		m.mine = mine
+		m.status = "showing only my commits"
+	}
+	m.applyFilter(m.filter)
+	if selected != nil && m.passesFilters(selected) {
+		m.selectHash(selected.Hash)
+	}
+	m.ensureVisible()
+	m.normalizePosition()
+}
+
+func (m *model) loadMine() (*mineFilter, error) {
+	repo := m.provider.Repo()
+	mailmap, err := gitgraph.LoadMailmap(repo)
+	if err != nil {
+		return nil, err
+	}
+	var ids []gitgraph.Identity
+	if m.git != nil {
+		if id, err := m.git.DefaultIdentity(); err == nil {
+			ids = append(ids, gitgraph.Identity{Name: id.Name, Email: id.Email})
+		}
+	} else if cfg, err := repo.ConfigScoped(config.SystemScope); err == nil && cfg.User.Email != "" {
+		ids = append(ids, gitgraph.Identity{Name: cfg.User.Name, Email: cfg.User.Email})
+	}
+	for _, id := range m.identities {
+		ids = append(ids, gitgraph.Identity{Name: id.Name, Email: id.Email})
+	}
+	mine := &mineFilter{mailmap: mailmap, emails: make(map[string]bool)}
+	for _, id := range ids {
+		if email := mailmap.Map(id).Email; email != "" {
+			mine.emails[strings.ToLower(email)] = true
+		}
+	}
+	if len(mine.emails) == 0 {
+		return nil, errors.New("no identity to match: set user.email in git config or add [[identities]]")
+	}
+	return mine, nil
+}
+
+func (m *model) isMine(commit *gitgraph.CommitInfo) bool {
+	if m.mine == nil {
+		return true
+	}
+	if commit.Commit == nil {
+		return false
+	}
+	author := gitgraph.Identity{Name: commit.Commit.Author.Name, Email: commit.Commit.Author.Email}
+	return m.mine.emails[strings.ToLower(m.mine.mailmap.Map(author).Email)]
+}
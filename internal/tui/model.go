@@ -2,22 +2,51 @@ package tui
 
 import (
 	"fmt"
-	"sort"
 	"strings"
 	"time"
 
+	"arbor/internal/config"
+	"arbor/internal/debuglog"
+	"arbor/internal/doctor"
 	"arbor/internal/gitgraph"
+	"arbor/internal/gitops"
+	"arbor/internal/review"
 
 	tea "github.com/charmbracelet/bubbletea"
 	"github.com/charmbracelet/lipgloss"
 	"github.com/charmbracelet/x/ansi"
+	"github.com/go-git/go-git/v5/plumbing"
 	"github.com/go-git/go-git/v5/plumbing/object"
 )
 
 type model struct {
 	repoPath string
 	provider *gitgraph.CommitProvider
+	git      *gitops.Repo
 	headName string
+	keys     keyMap
+	columns  []string
+	// shrink are the columns that narrow, in order, when a row is too wide.
+	shrink []string
+	// watch reloads when refs move outside arbor; follow then keeps the
+	// current branch's newest commit selected. watchState and watchHead
+	// are the refs and HEAD's commit as of the last load.
+	watch      bool
+	follow     bool
+	watchState string
+	watchHead  plumbing.Hash
+	// decorations picks the refs that name lanes and pull requests.
+	decorations gitgraph.DecorationFilter
+	// prefetch is how many commits past the list to walk in the
+	// background (see CommitProvider.Prefetch).
+	prefetch int
+
+	authorColors   bool
+	headHistory    bool
+	markerTemplate string
+	useEditor      bool
+	identities     []config.Identity
+	identityIndex  int
 
 	width     int
 	height    int
@@ -26,33 +55,288 @@ type model struct {
 	cursor int
 	offset int
 
-	showSidebar bool
-	showFiles   bool
-
-	searchActive  bool
-	searchQuery   string
-	filter        string
-	filtered      []int
-	filterScanned int
-
+	showSidebar  bool
+	sidebarFocus bool
+	// filesFocus moves the focused sidebar's cursor to its changed files.
+	filesFocus    bool
+	sidebarScroll int
+	sidebarHash   plumbing.Hash
+	showFiles     bool
+	density       string
+	altRows       bool
+	padding       int
+	// graphWidth is the width the graph takes at the right edge of each
+	// row, or 0 when it is not drawn there; see rightGraphWidth.
+	graphPosition string
+	graphWidth    int
+	markdown      bool
+	// markdownCache holds rendered commit bodies until the next reload,
+	// as the sidebar is drawn on every frame.
+	markdownCache map[markdownKey][]string
+	accelerate    bool
+	repeat        keyRepeat
+	// now times held keys; tests stop it.
+	now func() time.Time
+	// releaseTags is the glob naming release tags; releases maps commits
+	// to the first one containing them, once computed.
+	releaseTags     string
+	releases        map[plumbing.Hash]string
+	releasesPending bool
+
+	// operation is a merge, rebase, cherry-pick, revert, or bisect left
+	// partway, and staleLocks the locks a crashed git left; the banner
+	// names them until they are dealt with.
+	operation  *gitops.Operation
+	staleLocks []*gitops.LockError
+
+	// mine, when set, filters history to commits authored as the user.
+	mine *mineFilter
+
+	// trailer filters history to commits carrying it; chip is the trailer
+	// picked in the focused sidebar for the commit chipHash.
+	trailer  *gitgraph.Trailer
+	chip     int
+	chipHash plumbing.Hash
+	// file is the changed file picked in the focused sidebar for the
+	// commit fileHash.
+	file     int
+	fileHash plumbing.Hash
+
+	// fuzzy is the mode the search prompt opens in; filterFuzzy is the
+	// one the current filter was applied with, ranking filtered by
+	// filterScores while relevance is on. rankedGraph keeps the graph
+	// drawn in that order.
+	fuzzy        bool
+	filterFuzzy  bool
+	filterScores map[int]int
+	relevance    bool
+	rankedGraph  bool
+
+	searchActive   bool
+	searchQuery    string
+	filter         string
+	folder         string
+	filtered       []int
+	filterScanned  int
+	filterGen      int
+	filterScanning bool
+	filterStopped  bool
+	// exporting keeps the filter scan going through all of history, then
+	// prompts for where to export the matches.
+	exporting  bool
+	searchJump bool
+	jump       string
+	seeking    *seek
+	seekGen    int
+
+	mark      *gitgraph.CommitInfo
+	popup     *popup
+	scrubber  *scrubber
+	pruner    *pruner
+	input     *textInput
+	branches  *branchTable
+	rangeView *rangeView
+	heatmap   *heatmap
+	pager     *pager
+	stage     *stageView
+
+	advisories bool
+	advisory   []doctor.Issue
+
+	branchStatus    []gitgraph.BranchStatus
+	branchErr       error
+	branchesPending bool
+	// remoteTips is the current branch on each remote that has it.
+	remoteTips []gitgraph.RemoteTip
+	// remoteAliases are the names remotes are shown under, by remote.
+	remoteAliases map[string]string
+	remotes       *remoteView
+
+	palette *commandPalette
+
+	deployRefPrefix string
+	deployNotesRef  string
+	deploys         map[plumbing.Hash][]string
+
+	tagIndex        *gitgraph.TagIndex
+	describes       map[plumbing.Hash]string
+	headLog         []gitgraph.ReflogEntry
+	headVisits      map[plumbing.Hash][]int
+	pullHeads       map[plumbing.Hash][]gitgraph.Ref
+	contains        map[plumbing.Hash][]gitgraph.Ref
+	containsPending map[plumbing.Hash]bool
+
+	annotateCommand string
+	annotations     map[plumbing.Hash]string
+	annotatePending map[plumbing.Hash]bool
+	annotateErr     error
+
+	significanceRules   []gitgraph.SignificanceRule
+	significance        map[plumbing.Hash][]string
+	significancePending map[plumbing.Hash]bool
+	diffStats           map[plumbing.Hash]gitgraph.DiffStat
+	diffStatPending     map[plumbing.Hash]bool
+
+	amendPublished bool
+
+	// start is the ref the cursor opens on; see selectStart.
+	start string
+
+	lintBadges bool
+	lintOpts   gitgraph.LintOptions
+
+	auditBadges  bool
+	auditOpts    gitgraph.AuditOptions
+	audits       map[plumbing.Hash][]gitgraph.Finding
+	auditPending map[plumbing.Hash]bool
+
+	// policy is the audit.policy file, or nil; policyProblems are how
+	// checked commits break it.
+	policy         *gitgraph.Policy
+	policyProblems map[plumbing.Hash][]string
+	policyPending  map[plumbing.Hash]bool
+
+	review *review.Session
+
+	status     string
 	filesCache map[string][]string
 	err        error
 }
 
-func NewModel(path string, provider *gitgraph.CommitProvider, headName string) tea.Model {
+func NewModel(path string, provider *gitgraph.CommitProvider, headName string, cfg *config.Config) tea.Model {
+	UseTheme(cfg)
+	return LoadModel(path, provider, headName, cfg)
+}
+
+// UseTheme sets the styles View draws with to cfg's theme. Call it before
+// the program starts or on its UI goroutine, as View reads them there.
+func UseTheme(cfg *config.Config) {
+	setTheme(cfg.Theme)
+}
+
+// LoadModel is NewModel without UseTheme: it loads the first screen, the
+// first commits and the refs, tags and visits drawn on them, which takes a
+// while on a large repository, and touches nothing View reads, so it can
+// run off the UI as a boot step.
+func LoadModel(path string, provider *gitgraph.CommitProvider, headName string, cfg *config.Config) tea.Model {
 	m := &model{
-		repoPath:    path,
-		provider:    provider,
-		headName:    headName,
-		showSidebar: true,
-		filesCache:  make(map[string][]string),
+		repoPath:            path,
+		provider:            provider,
+		headName:            headName,
+		keys:                newKeyMap(cfg.Keymap),
+		columns:             cfg.Columns,
+		shrink:              cfg.Shrink,
+		remoteAliases:       cfg.RemoteAliases(),
+		watch:               cfg.Watch,
+		prefetch:            cfg.Prefetch,
+		follow:              cfg.Watch,
+		authorColors:        cfg.AuthorColors,
+		headHistory:         cfg.HeadHistory,
+		amendPublished:      cfg.Protect.AmendPublished,
+		markerTemplate:      cfg.MarkerTemplate,
+		useEditor:           cfg.UseEditor,
+		identities:          cfg.Identities,
+		identityIndex:       identityIndex(cfg.Identities, cfg.Identity),
+		advisories:          cfg.Advisories,
+		showSidebar:         true,
+		density:             cfg.Density,
+		altRows:             cfg.AltRows,
+		padding:             cfg.RowPadding,
+		releaseTags:         cfg.ReleaseTags,
+		accelerate:          cfg.KeyAcceleration,
+		now:                 time.Now,
+		markdown:            cfg.Markdown,
+		graphPosition:       cfg.GraphPosition,
+		fuzzy:               cfg.Search == "fuzzy",
+		relevance:           cfg.SearchSort == "relevance",
+		rankedGraph:         cfg.SearchGraph,
+		audits:              make(map[plumbing.Hash][]gitgraph.Finding),
+		auditPending:        make(map[plumbing.Hash]bool),
+		policyProblems:      make(map[plumbing.Hash][]string),
+		policyPending:       make(map[plumbing.Hash]bool),
+		deployRefPrefix:     cfg.Deploys.RefPrefix,
+		deployNotesRef:      cfg.Deploys.NotesRef,
+		annotateCommand:     strings.TrimSpace(cfg.Annotations.Command),
+		annotations:         make(map[plumbing.Hash]string),
+		contains:            make(map[plumbing.Hash][]gitgraph.Ref),
+		containsPending:     make(map[plumbing.Hash]bool),
+		annotatePending:     make(map[plumbing.Hash]bool),
+		filesCache:          make(map[string][]string),
+		markdownCache:       make(map[markdownKey][]string),
+		significance:        make(map[plumbing.Hash][]string),
+		significancePending: make(map[plumbing.Hash]bool),
+		diffStats:           make(map[plumbing.Hash]gitgraph.DiffStat),
+		diffStatPending:     make(map[plumbing.Hash]bool),
+	}
+	if cfg.Lint.Badges {
+		m.lintBadges = true
+		m.lintOpts = cfg.Lint.Options()
+	}
+	if rules, err := cfg.SignificanceRules(); err == nil {
+		m.significanceRules = rules
+	}
+	if opts, err := cfg.Audit.Options(); err == nil {
+		m.auditBadges = cfg.Audit.Badges
+		m.auditOpts = opts
+	}
+	root := path
+	if root == "" {
+		root = gitgraph.GitDir(provider.Repo())
+	}
+	if policy, err := cfg.Audit.LoadPolicy(root); err != nil {
+		m.status = err.Error()
+	} else {
+		m.policy = policy
+	}
+	if path != "" {
+		m.git = gitops.New(path)
+		m.git.ReadOnly = cfg.ReadOnly
+		m.git.Protected = cfg.Protect.Branches
+		m.git.RemoteAuth = cfg.RemoteAuth()
+	}
+	m.start = strings.TrimSpace(cfg.Start)
+	if m.watch {
+		m.noteRefs()
 	}
+	provider.Prefetch(m.prefetch)
+	m.decorations = gitgraph.LoadDecorationFilter(provider.Repo(), cfg.Decorations.Include, cfg.Decorations.Exclude)
+	m.provider.IndexChildren()
 	_ = m.provider.Ensure(0)
+	if cfg.Mine {
+		if mine, err := m.loadMine(); err != nil {
+			m.status = "mine: " + err.Error()
+		} else {
+			m.mine = mine
+			m.applyFilter("")
+		}
+	}
+	m.loadDeploys()
+	m.loadTags()
+	m.loadHeadVisits()
+	m.loadPullHeads()
+	m.showInProgress()
 	return m
 }
 
+// Close stops the provider's prefetcher, once the program has quit.
+func (m *model) Close() {
+	m.provider.Close()
+}
+
 func (m *model) Init() tea.Cmd {
-	return nil
+	return tea.Batch(m.checkRepo(), m.computeBranches(), m.watchRefs(), m.awaitAbbrev())
+}
+
+// abbrevReadyMsg says the abbreviation index is read, so the hashes on
+// screen are redrawn as long as they need to be.
+type abbrevReadyMsg struct{}
+
+func (m *model) awaitAbbrev() tea.Cmd {
+	abbrev := m.provider.Abbrev()
+	return func() tea.Msg {
+		abbrev.Wait()
+		return abbrevReadyMsg{}
+	}
 }
 
 func (m *model) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
@@ -64,55 +348,309 @@ func (m *model) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
 			m.cursor = 0
 			m.offset = 0
 			m.didLayout = true
+			m.selectStart()
 		}
 		m.ensureVisible()
 		m.normalizePosition()
+		return m, tea.Batch(m.loadVisible(), m.scanFilter(), m.fillDiff(false))
+	case filterScanMsg:
+		return m, m.stepFilterScan(msg)
+	case abbrevReadyMsg:
+		return m, nil
+	case copiedMsg:
+		m.applyCopied(msg)
+		return m, nil
+	case fixupCommittedMsg:
+		return m, m.applyFixupCommitted(msg)
+	case seekMsg:
+		return m, m.stepSeek(msg)
+	case auditMsg:
+		m.applyAudit(msg)
+		return m, nil
+	case policyMsg:
+		m.applyPolicy(msg)
+		return m, nil
+	case diffStatMsg:
+		m.applyDiffStats(msg)
+		return m, nil
+	case significanceMsg:
+		m.applySignificance(msg)
+		return m, nil
+	case annotationMsg:
+		m.applyAnnotations(msg)
+		return m, nil
+	case containsMsg:
+		m.applyContains(msg)
+		return m, nil
+	case describeMsg:
+		m.describes[msg.hash] = msg.text
+		return m, nil
+	case releasesMsg:
+		m.applyReleases(msg)
+		return m, nil
+	case diffConvertedMsg:
+		return m, m.applyDiffConverted(msg)
+	case rangePatchMsg:
+		m.applyRangePatch(msg)
+		return m, nil
+	case gitOpMsg:
+		m.applyGitOp(msg)
+		return m, tea.Batch(m.loadVisible(), m.computeBranches(), m.scanFilter())
+	case remoteOpMsg:
+		cmd := m.applyRemoteOp(msg)
+		return m, tea.Batch(cmd, m.loadVisible(), m.computeBranches(), m.scanFilter())
+	case branchesMsg:
+		m.applyBranches(msg)
+		return m, nil
+	case remotesMsg:
+		m.applyRemotes(msg)
+		return m, nil
+	case heatmapMsg:
+		m.applyHeatmap(msg)
+		return m, nil
+	case editorMsg:
+		return m, m.applyEditor(msg)
+	case stageMsg:
+		m.applyStage(msg)
+		return m, nil
+	case pruneMsg:
+		m.applyPrune(msg)
+		return m, tea.Batch(m.loadVisible(), m.computeBranches(), m.scanFilter())
+	case watchMsg:
+		return m, m.applyWatch(msg)
+	case advisoryMsg:
+		m.advisory = msg.issues
+		m.normalizePosition()
 		return m, nil
 	case tea.KeyMsg:
+		if m.popup != nil {
+			return m.handlePopupKey(msg)
+		}
+		if m.pager != nil {
+			return m.handlePagerKey(msg)
+		}
+		if m.stage != nil {
+			return m.handleStageKey(msg)
+		}
+		if m.pruner != nil {
+			return m.handlePrunerKey(msg)
+		}
+		if m.input != nil {
+			return m.handleInputKey(msg)
+		}
+		if m.branches != nil {
+			return m.handleBranchesKey(msg)
+		}
+		if m.rangeView != nil {
+			return m.handleRangeKey(msg)
+		}
+		if m.heatmap != nil {
+			return m.handleHeatmapKey(msg)
+		}
+		if m.remotes != nil {
+			return m.handleRemotesKey(msg)
+		}
+		if m.palette != nil {
+			return m.handlePaletteKey(msg)
+		}
 		if m.searchActive {
 			next, cmd := m.handleSearchKey(msg)
 			if mm, ok := next.(*model); ok {
 				mm.ensureVisible()
 				mm.normalizePosition()
+				cmd = tea.Batch(cmd, mm.loadVisible(), mm.scanFilter())
 			}
 			return next, cmd
 		}
-		switch msg.String() {
-		case "ctrl+c", "q":
+		if m.scrubber != nil {
+			next, cmd := m.handleScrubberKey(msg)
+			return next, tea.Batch(cmd, m.loadVisible())
+		}
+		if msg.String() == "ctrl+c" {
 			return m, tea.Quit
-		case "up", "k":
-			m.moveCursor(-1)
-		case "down", "j":
-			m.moveCursor(1)
-		case "enter":
-			m.showFiles = !m.showFiles
-		case "/":
-			m.searchActive = true
-			m.searchQuery = m.filter
-			m.normalizePosition()
-		case "tab":
-			m.showSidebar = !m.showSidebar
 		}
-		m.ensureVisible()
-		m.normalizePosition()
-		return m, nil
+		if m.sidebarFocus {
+			if handled, cmd := m.handleSidebarKey(msg); handled {
+				return m, tea.Batch(cmd, m.loadVisible(), m.scanFilter())
+			}
+		}
+		if msg.String() == "esc" && m.filterScanning {
+			m.stopFilterScan()
+			return m, nil
+		}
+		if msg.String() == "esc" && m.seeking != nil {
+			m.stopSeek()
+			m.status = "search stopped"
+			return m, nil
+		}
+		m.status = ""
+		debuglog.Debug("key", "key", msg.String(), "action", m.keys.action(msg.String()))
+		return m.runAction(m.keys.action(msg.String()))
 	}
 	return m, nil
 }
 
+// runAction performs a keymap action, whether it came from its key or from
+// the command palette.
+func (m *model) runAction(action string) (tea.Model, tea.Cmd) {
+	var cmd tea.Cmd
+	switch action {
+	case actionQuit:
+		return m, tea.Quit
+	case actionUp:
+		m.moveCursor(-m.repeatStep(action, m.now()))
+	case actionDown:
+		m.moveCursor(m.repeatStep(action, m.now()))
+	case actionFiles:
+		m.showFiles = !m.showFiles
+	case actionSearch:
+		m.searchActive = true
+		m.searchJump = false
+		m.searchQuery = m.filter
+		m.normalizePosition()
+	case actionFind:
+		m.searchActive = true
+		m.searchJump = true
+		m.searchQuery = m.jump
+		m.normalizePosition()
+	case actionNextMatch:
+		cmd = m.findMatch(true)
+	case actionPrevMatch:
+		cmd = m.findMatch(false)
+	case actionSidebar:
+		m.showSidebar = !m.showSidebar
+		m.sidebarFocus, m.filesFocus = false, false
+	case actionFocus:
+		m.focusSidebar()
+	case actionDensity:
+		m.toggleDensity()
+	case actionMine:
+		m.toggleMine()
+	case actionLegend:
+		m.openLegend()
+	case actionOperation:
+		if !m.showInProgress() {
+			m.status = "no operation in progress"
+		}
+	case actionStage:
+		return m, m.openStage()
+	case actionBundle:
+		return m, m.startBundle()
+	case actionFollow:
+		return m, m.toggleFollow()
+	case actionExport:
+		return m, m.startExport()
+	case actionSort:
+		m.toggleSort()
+	case actionGoto:
+		return m, m.startGoto()
+	case actionGraph:
+		m.cycleGraphPosition()
+	case actionMarkdown:
+		m.markdown = !m.markdown
+		if m.markdown {
+			m.status = "commit bodies rendered as markdown"
+		} else {
+			m.status = "commit bodies as written"
+		}
+	case actionReview:
+		m.toggleReviewed()
+	case actionCombined:
+		return m, m.openCombinedDiff()
+	case actionMark:
+		m.toggleMark()
+	case actionAncestry:
+		m.showAncestry()
+	case actionScrubber:
+		m.openScrubber()
+	case actionDismiss:
+		m.advisory = nil
+	case actionFixup:
+		m.startFixup()
+	case actionReset:
+		m.startReset()
+	case actionUndo:
+		m.startUndo()
+	case actionTag:
+		m.showTagNotes()
+	case actionVisits:
+		m.toggleHeadHistory()
+	case actionFolder:
+		m.pickFolder()
+	case actionHeatmap:
+		cmd = m.openHeatmap()
+	case actionScope:
+		m.cycleScope()
+	case actionPull:
+		m.checkoutPullRequest()
+	case actionRemotes:
+		return m, m.openRemotes()
+	case actionAmend:
+		m.startAmend()
+	case actionFetch:
+		cmd = m.startFetch()
+	case actionPruneRemotes:
+		cmd = m.startRemotePrune()
+	case actionPush:
+		m.startPush()
+	case actionSquash:
+		cmd = m.startSquash()
+	case actionPrune:
+		m.openPruner()
+	case actionMarker:
+		cmd = m.startMarker()
+	case actionCopy:
+		cmd = m.copyHash()
+	case actionOpen:
+		m.openCommit()
+	case actionBranches:
+		m.openBranches()
+	case actionRangeDiff:
+		m.openRangeDiff()
+	case actionIdentity:
+		m.cycleIdentity()
+	case actionPalette:
+		m.openPalette()
+	case actionLog:
+		m.openDebugLog()
+	case actionRebase:
+		m.startRebase()
+	}
+	m.ensureVisible()
+	m.normalizePosition()
+	return m, tea.Batch(cmd, m.loadVisible(), m.scanFilter())
+}
+
 func (m *model) View() string {
-	header := m.headerView(m.width)
+	header := m.topView(m.width)
 
 	mainWidth := m.width
-	sidebarWidth := 0
-	if m.showSidebar && m.width >= 60 {
-		sidebarWidth = max(30, m.width/3)
+	sidebarWidth := m.sidebarWidth()
+	if sidebarWidth > 0 {
 		mainWidth = m.width - sidebarWidth - 1
 	}
 
 	listView := m.renderList(mainWidth)
 	var row string
-	if sidebarWidth == 0 {
+	if m.popup != nil {
+		row = m.renderPopup(m.width, m.viewportHeight())
+	} else if m.pager != nil {
+		row = m.renderPager(m.width, m.viewportHeight())
+	} else if m.stage != nil {
+		row = m.renderStage(m.width, m.viewportHeight())
+	} else if m.pruner != nil {
+		row = m.renderPruner(m.width, m.viewportHeight())
+	} else if m.branches != nil {
+		row = m.renderBranches(m.width, m.viewportHeight())
+	} else if m.rangeView != nil {
+		row = m.renderRangeView(m.width, m.viewportHeight())
+	} else if m.heatmap != nil {
+		row = m.renderHeatmap(m.width, m.viewportHeight())
+	} else if m.remotes != nil {
+		row = m.renderRemotes(m.width, m.viewportHeight())
+	} else if m.palette != nil {
+		row = m.renderPalette(m.width, m.viewportHeight())
+	} else if sidebarWidth == 0 {
 		row = listView
 	} else {
 		sidebar := m.renderSidebar(sidebarWidth)
@@ -120,8 +658,8 @@ func (m *model) View() string {
 	}
 
 	footer := m.footerView(m.width)
-	if m.searchActive {
-		return lipgloss.JoinVertical(lipgloss.Left, header, row, footer, m.searchView(m.width))
+	if bar := m.bottomBar(m.width); bar != "" {
+		return lipgloss.JoinVertical(lipgloss.Left, header, row, footer, bar)
 	}
 	return lipgloss.JoinVertical(lipgloss.Left, header, row, footer)
 }
@@ -134,11 +672,11 @@ func (m *model) renderList(width int) string {
 	lines := make([]string, 0, viewport)
 	listLen := m.listLength()
 	start := min(m.offset, max(0, listLen-1))
-	end := min(start+viewport, listLen)
 
-	for i := start; i < end; i++ {
+	m.graphWidth = m.rightGraphWidth(start, viewport, width)
+	for i := start; i < listLen && len(lines) < viewport; i++ {
 		rowIndex := i
-		if m.filter != "" {
+		if m.filtering() {
 			if i >= len(m.filtered) {
 				break
 			}
@@ -148,16 +686,30 @@ func (m *model) renderList(width int) string {
 			break
 		}
 		commit := m.provider.Commits[rowIndex]
-		line := m.renderRow(commit, i == m.cursor, width, i%2 == 1)
-		lines = append(lines, line)
+		if i > start && len(lines)+m.linesAt(i) > viewport {
+			break
+		}
+		if release, ok := m.releaseAt(i); ok {
+			lines = append(lines, m.releaseSeparator(i, release, width))
+		}
+		alt := m.altRows && i%2 == 1
+		lines = append(lines, m.renderRow(commit, i == m.cursor, width, alt))
+		if m.density != densityCompact {
+			var next *gitgraph.CommitInfo
+			if rowIndex+1 < len(m.provider.Commits) {
+				next = m.provider.Commits[rowIndex+1]
+			}
+			lines = append(lines, m.renderDetailLines(commit, next, i == m.cursor, width, alt)...)
+		}
 	}
+	lines = lines[:min(len(lines), viewport)]
 
 	if len(lines) == 0 {
 		lines = append(lines, m.emptyRow(width))
 	}
 	for i := len(lines); i < viewport; i++ {
 		rowIndex := start + i
-		lines = append(lines, m.blankRow(width, rowIndex%2 == 1))
+		lines = append(lines, m.blankRow(width, m.altRows && rowIndex%2 == 1))
 	}
 	return strings.Join(lines, "\n")
 }
@@ -175,47 +727,182 @@ func (m *model) renderRow(commit *gitgraph.CommitInfo, selected bool, width int,
 		authorColor = palette.highlightText
 	}
 
-	graph := renderGraph(commit.Graph, bg)
 	space := rowSpacerStyle.Background(bg).Render(" ")
 	sep := rowSeparatorStyle.Foreground(palette.textDim).Background(bg).Render(" - ")
-	hash := hashStyle.Foreground(palette.accent).Background(bg).Render(commit.ShortHash)
-	subject := subjectStyle.Foreground(subjectColor).Background(bg).Render(commit.Subject)
-	author := authorStyle.Foreground(authorColor).Background(bg).Render(commit.Author)
-	meta := hash + space + subject + sep + author
-	row := graph + space + meta
-	return fitLine(row, width, bg)
+	badges := m.reviewBadge(commit, bg)
+	if m.mark != nil && m.mark.Hash == commit.Hash {
+		badges += markStyle.Background(bg).Render("◆") + space
+	}
+	if len(m.audits[commit.Hash]) > 0 {
+		badges += warningStyle.Background(bg).Render("⚠") + space
+	}
+	if len(m.policyProblems[commit.Hash]) > 0 {
+		badges += warningStyle.Background(bg).Render("✗") + space
+	}
+	if len(m.lintProblems(commit)) > 0 {
+		badges += emptyStyle.Background(bg).Render("✎") + space
+	}
+	badges += m.visitBadge(commit, bg)
+	badges += m.deployBadges(commit, bg)
+	columns := m.rowColumns()
+	texts := make(map[string]string, len(columns))
+	for _, column := range columns {
+		switch column {
+		case "subject":
+			texts[column] = sanitizeLine(commit.Subject)
+		case "author":
+			texts[column] = sanitizeLine(commit.Author)
+		case "date":
+			texts[column] = commit.When.Format("2006-01-02")
+		case "annotation":
+			texts[column] = truncateText(sanitizeLine(m.annotations[commit.Hash]), maxAnnotationWidth)
+		}
+	}
+	build := func() string {
+		row := m.rowPadding(bg)
+		for i, column := range columns {
+			var cell string
+			switch column {
+			case "graph":
+				cell = renderGraph(commit.Graph, bg)
+			case "hash":
+				cell = hashStyle.Foreground(palette.accent).Background(bg).Render(commit.ShortHash())
+			case "subject":
+				cell = badges + m.highlightSearch(texts[column], subjectStyle.Foreground(subjectColor).Background(bg))
+			case "author":
+				var color lipgloss.TerminalColor = authorColor
+				if m.authorColors && !selected {
+					color = colorForAuthor(commit)
+				}
+				cell = m.highlightSearch(texts[column], authorStyle.Foreground(color).Background(bg))
+			case "date":
+				cell = authorStyle.Foreground(authorColor).Background(bg).Render(texts[column])
+			case "annotation":
+				if texts[column] == "" {
+					continue
+				}
+				cell = hashStyle.Foreground(palette.accentAlt).Background(bg).Render(texts[column])
+			case "significance":
+				cell = m.significanceCell(commit, bg)
+			case "diffstat":
+				cell = m.diffStatCell(commit, bg)
+			}
+			if i > 0 {
+				if column == "author" || column == "date" {
+					row += sep
+				} else {
+					row += space
+				}
+			}
+			row += cell
+		}
+		return row
+	}
+	row := build()
+	room := width
+	if m.graphWidth > 0 {
+		room -= m.graphWidth + 2
+	}
+	if over := lipgloss.Width(row) - room; over > 0 && m.shrinkColumns(texts, over) {
+		row = build()
+	}
+	return m.fitRow(row, commit.Graph, width, bg)
 }
 
-func (m *model) renderSidebar(width int) string {
-	commit := m.selectedCommit()
-	if commit == nil {
-		return sidebarStyle.Width(width).MaxHeight(m.viewportHeight()).Render("No commit selected")
-	}
+// sidebarLines is the sidebar content for commit, before wrapping to the
+// panel and scrolling.
+func (m *model) sidebarLines(commit *gitgraph.CommitInfo, width int) []string {
 	lines := []string{
-		sidebarTitleStyle.Render(commit.ShortHash),
-		commit.Author,
+		sidebarTitleStyle.Render(commit.ShortHash()),
+		sanitizeLine(commit.Author),
 		commit.When.Format(time.RFC1123),
-		"",
 	}
-	message := strings.TrimSpace(commit.Commit.Message)
-	lines = append(lines, wrapText(message, width-2)...)
+	if desc := m.describes[commit.Hash]; desc != "" {
+		lines = append(lines, "Describe "+desc)
+	}
+	if tags := m.tagLines(commit); len(tags) > 0 {
+		for _, tag := range tags {
+			lines = append(lines, wrapText(tag, width-2)...)
+		}
+		lines = append(lines, fmt.Sprintf("(%s for the tag message)", m.keys.first(actionTag)))
+	}
+	if pulls := m.pullLabels(commit); pulls != "" {
+		lines = append(lines, fmt.Sprintf("Head of %s (%s to check out)", pulls, m.keys.first(actionPull)))
+	}
+	if envs := m.deploys[commit.Hash]; len(envs) > 0 {
+		lines = append(lines, "Deployed to "+strings.Join(envs, ", "))
+	}
+	if names := m.significance[commit.Hash]; len(names) > 0 {
+		lines = append(lines, "Significant: "+strings.Join(names, ", "))
+	}
+	lines = append(lines, "")
+	message := strings.TrimSpace(commit.Message())
+	if m.markdown {
+		lines = append(lines, m.markdownBody(commit.Hash, message, width-2)...)
+	} else {
+		lines = append(lines, rawMessageLines(message, width-2)...)
+	}
+	lines = append(lines, m.trailerLines(commit)...)
+	lines = append(lines, m.childLines(commit, width)...)
 
-	if m.showFiles {
-		lines = append(lines, "", sidebarSubtitleStyle.Render("Changed files"))
-		files := m.changedFiles(commit)
-		for _, f := range files {
-			lines = append(lines, fmt.Sprintf("- %s", f))
+	lines = append(lines, "", sidebarSubtitleStyle.Render("Contained in"))
+	lines = append(lines, m.containsLines(commit.Hash)...)
+
+	if visits := m.visitLines(commit); len(visits) > 0 {
+		lines = append(lines, "", sidebarSubtitleStyle.Render("HEAD was here"))
+		for _, visit := range visits {
+			lines = append(lines, wrapText(visit, width-2)...)
+		}
+	}
+
+	if findings := m.audits[commit.Hash]; len(findings) > 0 {
+		lines = append(lines, "", warningStyle.Background(palette.panelBg).Render("Warnings"))
+		for _, finding := range findings {
+			lines = append(lines, wrapText("⚠ "+finding.String(), width-2)...)
 		}
 	}
 
-	return sidebarStyle.Width(width).MaxHeight(m.viewportHeight()).Render(strings.Join(lines, "\n"))
+	if problems := m.policyProblems[commit.Hash]; len(problems) > 0 {
+		lines = append(lines, "", warningStyle.Background(palette.panelBg).Render("Policy"))
+		for _, problem := range problems {
+			lines = append(lines, wrapText("✗ "+problem, width-2)...)
+		}
+	}
+
+	if problems := m.lintProblems(commit); len(problems) > 0 {
+		lines = append(lines, "", sidebarSubtitleStyle.Render("Message lint"))
+		for _, problem := range problems {
+			lines = append(lines, wrapText("✎ "+problem.String(), width-2)...)
+		}
+	}
+
+	if m.showFiles {
+		lines = append(lines, m.fileLines(commit, width)...)
+	}
+
+	return lines
+}
+
+// lintProblems checks the message on the spot; it is cheap next to the
+// diffing the audit badges need.
+func (m *model) lintProblems(commit *gitgraph.CommitInfo) []gitgraph.LintProblem {
+	if !m.lintBadges || commit.Commit == nil || commit.Commit.NumParents() > 1 {
+		return nil
+	}
+	return gitgraph.LintMessage(commit.Message(), m.lintOpts)
 }
 
 func (m *model) searchView(width int) string {
 	if width <= 0 {
 		width = m.width
 	}
-	input := searchStyle.Width(width).Render(fmt.Sprintf("/%s", m.searchQuery))
+	prompt := "/"
+	if m.searchJump {
+		prompt = m.keys.first(actionFind)
+	} else if m.fuzzy {
+		prompt = "fuzzy /"
+	}
+	input := searchStyle.Width(width).Render(prompt + m.searchQuery)
 	return input
 }
 
@@ -227,7 +914,18 @@ func (m *model) handleSearchKey(msg tea.KeyMsg) (tea.Model, tea.Cmd) {
 		return m, nil
 	case tea.KeyEnter:
 		m.searchActive = false
+		if m.searchJump {
+			return m, m.applyJump(m.searchQuery)
+		}
+		m.jump = ""
+		m.stopSeek()
+		m.filterFuzzy = m.fuzzy
 		m.applyFilter(m.searchQuery)
+		return m, m.scanFilter()
+	case tea.KeyCtrlF:
+		if !m.searchJump {
+			m.fuzzy = !m.fuzzy
+		}
 		return m, nil
 	case tea.KeyBackspace, tea.KeyDelete:
 		if len(m.searchQuery) > 0 {
@@ -248,27 +946,60 @@ func (m *model) applyFilter(query string) {
 	m.filter = strings.TrimSpace(query)
 	m.filtered = nil
 	m.filterScanned = 0
+	m.filterScores = make(map[int]int)
+	m.filterGen++
+	m.filterScanning = false
+	m.filterStopped = false
+	m.exporting = false
 	m.cursor = 0
 	m.offset = 0
-	if m.filter == "" {
+	if !m.filtering() {
 		return
 	}
 	m.refreshFilter()
 }
 
 func (m *model) refreshFilter() {
-	if m.filter == "" {
+	if !m.filtering() {
 		return
 	}
-	filterLower := strings.ToLower(m.filter)
+	added := false
 	for m.filterScanned < len(m.provider.Commits) {
 		commit := m.provider.Commits[m.filterScanned]
-		if strings.Contains(strings.ToLower(commit.Subject), filterLower) ||
-			strings.Contains(strings.ToLower(commit.Author), filterLower) {
+		if score, ok := m.matchesFilter(commit); ok && m.touchesFolder(commit) && m.hasTrailer(commit) && m.isMine(commit) {
 			m.filtered = append(m.filtered, m.filterScanned)
+			m.filterScores[m.filterScanned] = score
+			added = true
 		}
 		m.filterScanned++
 	}
+	if added && m.ranked() {
+		m.sortFiltered()
+	}
+}
+
+// passesFilters reports whether commit is listed under every active
+// filter.
+func (m *model) passesFilters(commit *gitgraph.CommitInfo) bool {
+	_, ok := m.matchesFilter(commit)
+	return ok && m.touchesFolder(commit) && m.hasTrailer(commit) && m.isMine(commit)
+}
+
+// matchesFilter applies the search filter, scoring fuzzy matches; a
+// substring match scores nothing, keeping history order.
+func (m *model) matchesFilter(commit *gitgraph.CommitInfo) (int, bool) {
+	if !m.filterFuzzy {
+		return 0, matchesQuery(commit, strings.ToLower(m.filter))
+	}
+	subject, inSubject := fuzzyScore(m.filter, commit.Subject)
+	author, inAuthor := fuzzyScore(m.filter, commit.Author)
+	switch {
+	case inSubject && inAuthor:
+		return max(subject, author), true
+	case inSubject:
+		return subject, true
+	}
+	return author, inAuthor
 }
 
 func (m *model) ensureVisible() {
@@ -277,16 +1008,16 @@ func (m *model) ensureVisible() {
 	if viewport <= 0 {
 		return
 	}
-	if m.filter == "" {
+	if !m.filtering() {
 		target := m.offset + viewport + buffer
 		_ = m.provider.Ensure(target)
+		m.provider.Want(target)
 		return
 	}
-
-	for len(m.filtered) <= m.offset+viewport+buffer && m.provider.HasMore() {
-		_ = m.provider.Ensure(len(m.provider.Commits))
-		m.refreshFilter()
-	}
+	m.provider.Want(len(m.provider.Commits))
+	// Filtered views load more history through scanFilter instead, so a
+	// rare match does not block the UI.
+	m.refreshFilter()
 }
 
 func (m *model) moveCursor(delta int) {
@@ -294,12 +1025,7 @@ func (m *model) moveCursor(delta int) {
 		return
 	}
 	m.cursor = clamp(m.cursor+delta, 0, m.listLength()-1)
-	if m.cursor < m.offset {
-		m.offset = m.cursor
-	}
-	if m.cursor >= m.offset+m.viewportHeight() {
-		m.offset = m.cursor - m.viewportHeight() + 1
-	}
+	m.scrollToCursor()
 	if delta > 0 {
 		m.ensureVisible()
 		if m.cursor >= m.listLength()-1 && m.provider.HasMore() {
@@ -309,7 +1035,7 @@ func (m *model) moveCursor(delta int) {
 }
 
 func (m *model) listLength() int {
-	if m.filter != "" {
+	if m.filtering() {
 		return len(m.filtered)
 	}
 	return len(m.provider.Commits)
@@ -329,7 +1055,7 @@ func (m *model) selectedCommit() *gitgraph.CommitInfo {
 		return nil
 	}
 	index := m.cursor
-	if m.filter != "" {
+	if m.filtering() {
 		if m.cursor >= len(m.filtered) {
 			return nil
 		}
@@ -341,6 +1067,39 @@ func (m *model) selectedCommit() *gitgraph.CommitInfo {
 	return m.provider.Commits[index]
 }
 
+// selectHash moves the cursor to hash, loading history until it is found.
+// An active filter is cleared when it hides the commit.
+func (m *model) selectHash(hash plumbing.Hash) bool {
+	for i := 0; ; i++ {
+		if err := m.provider.Ensure(i); err != nil || i >= len(m.provider.Commits) {
+			return false
+		}
+		if m.provider.Commits[i].Hash != hash {
+			continue
+		}
+		listIndex := i
+		if m.filtering() {
+			listIndex = -1
+			m.refreshFilter()
+			for j, index := range m.filtered {
+				if index == i {
+					listIndex = j
+					break
+				}
+			}
+			if listIndex < 0 {
+				m.clearFilters()
+				listIndex = i
+			}
+		}
+		m.cursor = listIndex
+		m.centerOn(listIndex)
+		m.ensureVisible()
+		m.normalizePosition()
+		return true
+	}
+}
+
 func (m *model) changedFiles(commit *gitgraph.CommitInfo) []string {
 	key := commit.Hash.String()
 	if cached, ok := m.filesCache[key]; ok {
@@ -348,7 +1107,7 @@ func (m *model) changedFiles(commit *gitgraph.CommitInfo) []string {
 	}
 	files, err := filesForCommit(commit.Commit)
 	if err != nil {
-		m.filesCache[key] = []string{"(unable to load files)"}
+		m.filesCache[key] = []string{filesUnavailable}
 		return m.filesCache[key]
 	}
 	m.filesCache[key] = files
@@ -363,15 +1122,8 @@ func (m *model) normalizePosition() {
 		return
 	}
 	m.cursor = clamp(m.cursor, 0, listLen-1)
-	viewport := m.viewportHeight()
-	maxOffset := max(0, listLen-viewport)
-	m.offset = clamp(m.offset, 0, maxOffset)
-	if m.cursor < m.offset {
-		m.offset = m.cursor
-	}
-	if m.cursor >= m.offset+viewport {
-		m.offset = m.cursor - viewport + 1
-	}
+	m.offset = clamp(m.offset, 0, m.maxOffset())
+	m.scrollToCursor()
 }
 
 func renderGraph(cells []gitgraph.GraphCell, bg lipgloss.TerminalColor) string {
@@ -384,32 +1136,13 @@ func renderGraph(cells []gitgraph.GraphCell, bg lipgloss.TerminalColor) string {
 }
 
 func filesForCommit(commit *object.Commit) ([]string, error) {
-	var parent *object.Commit
-	if commit.NumParents() > 0 {
-		p, err := commit.Parent(0)
-		if err == nil {
-			parent = p
-		}
-	}
-	patch, err := commit.Patch(parent)
+	paths, err := gitgraph.ChangedFiles(commit)
 	if err != nil {
 		return nil, err
 	}
-	paths := make([]string, 0, len(patch.FilePatches()))
-	for _, filePatch := range patch.FilePatches() {
-		from, to := filePatch.Files()
-		if to != nil {
-			paths = append(paths, to.Path())
-			continue
-		}
-		if from != nil {
-			paths = append(paths, from.Path())
-		}
-	}
 	if len(paths) == 0 {
-		return []string{"(no file changes)"}, nil
+		return []string{noFileChanges}, nil
 	}
-	sort.Strings(paths)
 	return paths, nil
 }
 
@@ -427,9 +1160,39 @@ func (m *model) headerView(width int) string {
 	if m.filter != "" {
 		leftParts = append(leftParts, headerFilterStyle.Render(fmt.Sprintf("/%s", m.filter)))
 	}
+	if m.folder != "" {
+		leftParts = append(leftParts, headerFilterStyle.Render("in "+folderBreadcrumb(m.folder)))
+	}
+	if m.trailer != nil {
+		leftParts = append(leftParts, headerFilterStyle.Render(m.trailer.String()))
+	}
+	if m.jump != "" {
+		leftParts = append(leftParts, headerFilterStyle.Render(m.keys.first(actionFind)+m.jump))
+	}
 	if m.headName != "" {
 		leftParts = append(leftParts, headerBadgeStyle.Render(fmt.Sprintf("branch %s", m.headName)))
 	}
+	if markers := m.remoteMarkers(); markers != "" {
+		leftParts = append(leftParts, headerMetaStyle.Render(markers))
+	}
+	if m.provider.Scope() == gitgraph.ScopeHead {
+		leftParts = append(leftParts, headerBadgeStyle.Render(gitgraph.ScopeHead.String()))
+	}
+	if filter := m.provider.ParentFilter(); !filter.Any() {
+		leftParts = append(leftParts, headerBadgeStyle.Render(filter.String()))
+	}
+	if paths := m.provider.PathFilter(); paths != nil {
+		leftParts = append(leftParts, headerBadgeStyle.Render("-- "+sanitizeLine(paths.String())))
+	}
+	if m.review != nil {
+		leftParts = append(leftParts, headerBadgeStyle.Render(m.reviewHeader()))
+	}
+	if m.git != nil && m.git.ReadOnly {
+		leftParts = append(leftParts, headerBadgeStyle.Render("read-only"))
+	}
+	if badge := m.watchBadge(); badge != "" {
+		leftParts = append(leftParts, headerBadgeStyle.Render(badge))
+	}
 	left := strings.Join(leftParts, " ")
 
 	visible := m.listLength()
@@ -466,7 +1229,8 @@ func (m *model) footerView(width int) string {
 		return ""
 	}
 	contentWidth := max(0, width-2)
-	hints := footerHintStyle.Render("up/down k/j move | enter files | / search | tab sidebar | q quit")
+	hintText := m.keys.hints()
+	hints := footerHintStyle.Render(hintText)
 
 	total := m.listLength()
 	position := 0
@@ -480,8 +1244,28 @@ func (m *model) footerView(width int) string {
 	}
 
 	statusParts := []string{fmt.Sprintf("%d/%d", position, total), fmt.Sprintf("loaded %d%s", loaded, more)}
-	if m.filter != "" {
-		statusParts = append([]string{fmt.Sprintf("filter %q", m.filter)}, statusParts...)
+	if m.filtering() {
+		filter := m.filterLabel()
+		switch {
+		case m.filterScanning && m.exporting:
+			filter = fmt.Sprintf("exporting: matched %s of %s scanned… esc stop", formatCount(len(m.filtered)), formatCount(m.filterScanned))
+		case m.filterScanning:
+			filter = fmt.Sprintf("matched %s of %s scanned… esc stop", formatCount(len(m.filtered)), formatCount(m.filterScanned))
+		case m.filterStopped && m.provider.HasMore():
+			filter = fmt.Sprintf("%s stopped after %s", filter, formatCount(m.filterScanned))
+		}
+		statusParts = append([]string{filter}, statusParts...)
+	}
+	if m.filesFocus {
+		statusParts = append([]string{fmt.Sprintf("files: %s/%s pick, esc back", m.keys.first(actionUp), m.keys.first(actionDown))}, statusParts...)
+	} else if m.sidebarFocus {
+		statusParts = append([]string{fmt.Sprintf("sidebar: %s/%s scroll, esc back", m.keys.first(actionUp), m.keys.first(actionDown))}, statusParts...)
+	}
+	if m.seeking != nil {
+		statusParts = append([]string{fmt.Sprintf("searching %s loaded… esc stop", formatCount(len(m.provider.Commits)))}, statusParts...)
+	}
+	if m.status != "" {
+		statusParts = append([]string{m.status}, statusParts...)
 	}
 	status := footerStatusStyle.Render(strings.Join(statusParts, " | "))
 
@@ -491,7 +1275,7 @@ func (m *model) footerView(width int) string {
 		if maxHints < 0 {
 			maxHints = 0
 		}
-		hints = footerHintStyle.Render(truncateText("up/down k/j move | enter files | / search | tab sidebar | q quit", maxHints))
+		hints = footerHintStyle.Render(truncateText(hintText, maxHints))
 		space = contentWidth - lipgloss.Width(hints) - lipgloss.Width(status)
 		if space < 1 {
 			space = 1
@@ -506,17 +1290,37 @@ func (m *model) layoutHeights() (int, int, int) {
 	if width <= 0 {
 		return 1, 1, 0
 	}
-	header := m.headerView(width)
+	header := m.topView(width)
 	footer := m.footerView(width)
 	headerHeight := max(1, lipgloss.Height(header))
 	footerHeight := max(1, lipgloss.Height(footer))
 	searchHeight := 0
-	if m.searchActive {
-		searchHeight = max(1, lipgloss.Height(m.searchView(width)))
+	if bar := m.bottomBar(width); bar != "" {
+		searchHeight = max(1, lipgloss.Height(bar))
 	}
 	return headerHeight, footerHeight, searchHeight
 }
 
+func (m *model) topView(width int) string {
+	header := m.headerView(width)
+	if banner := m.bannerView(width); banner != "" {
+		return lipgloss.JoinVertical(lipgloss.Left, header, banner)
+	}
+	return header
+}
+
+func (m *model) bottomBar(width int) string {
+	switch {
+	case m.searchActive:
+		return m.searchView(width)
+	case m.scrubber != nil:
+		return m.scrubberView(width)
+	case m.input != nil:
+		return m.inputView(width)
+	}
+	return ""
+}
+
 func (m *model) emptyRow(width int) string {
 	bg := palette.bg
 	msg := emptyStyle.Foreground(palette.textDim).Background(bg).Render("No commits")
@@ -542,7 +1346,7 @@ func wrapText(text string, width int) []string {
 	lines := []string{}
 	line := ""
 	for _, word := range words {
-		if len(line)+len(word)+1 > width {
+		if ansi.StringWidth(line)+ansi.StringWidth(word)+1 > width {
 			lines = append(lines, line)
 			line = word
 			continue
@@ -563,13 +1367,53 @@ func truncateText(text string, maxWidth int) string {
 	if maxWidth <= 0 {
 		return ""
 	}
-	if len(text) <= maxWidth {
+	if ansi.StringWidth(text) <= maxWidth {
 		return text
 	}
 	if maxWidth <= 3 {
-		return text[:maxWidth]
+		return ansi.Truncate(text, maxWidth, "")
+	}
+	return ansi.Truncate(text, maxWidth, "...")
+}
+
+// sanitizeLine makes text from commits, refs, and hooks safe to lay out on
+// one row: escape sequences are dropped, tabs and line breaks become
+// spaces, other control and bidi override characters become U+FFFD, so
+// nothing can move the cursor, recolor the screen, or change a row's width.
+func sanitizeLine(text string) string {
+	clean := true
+	for i := 0; i < len(text); i++ {
+		if c := text[i]; c < 0x20 || c >= 0x7f {
+			clean = false
+			break
+		}
+	}
+	if clean {
+		return text
+	}
+	text = ansi.Strip(strings.ToValidUTF8(text, "\uFFFD"))
+	var b strings.Builder
+	for _, r := range text {
+		switch {
+		case r == '\t' || r == '\n' || r == '\r' || r == '\v' || r == '\f':
+			b.WriteByte(' ')
+		case r < 0x20 || (r >= 0x7f && r < 0xa0),
+			r >= 0x202a && r <= 0x202e, r >= 0x2066 && r <= 0x2069:
+			b.WriteRune('\uFFFD')
+		default:
+			b.WriteRune(r)
+		}
+	}
+	return b.String()
+}
+
+// sanitizeText is sanitizeLine for each line of a multi-line text.
+func sanitizeText(text string) string {
+	lines := strings.Split(text, "\n")
+	for i, line := range lines {
+		lines[i] = sanitizeLine(strings.TrimSuffix(line, "\r"))
 	}
-	return text[:maxWidth-3] + "..."
+	return strings.Join(lines, "\n")
 }
 
 func fitLine(text string, width int, bg lipgloss.TerminalColor) string {
@@ -612,79 +1456,82 @@ func max(a, b int) int {
 }
 
 var (
-	palette = struct {
-		bg            lipgloss.AdaptiveColor
-		bgAlt         lipgloss.AdaptiveColor
-		panelBg       lipgloss.AdaptiveColor
-		panelBorder   lipgloss.AdaptiveColor
-		text          lipgloss.AdaptiveColor
-		textMuted     lipgloss.AdaptiveColor
-		textDim       lipgloss.AdaptiveColor
-		accent        lipgloss.AdaptiveColor
-		accentAlt     lipgloss.AdaptiveColor
-		highlightBg   lipgloss.AdaptiveColor
-		highlightText lipgloss.AdaptiveColor
-		headerBg      lipgloss.AdaptiveColor
-		searchBg      lipgloss.AdaptiveColor
-		footerBg      lipgloss.AdaptiveColor
-	}{
-		bg:            lipgloss.AdaptiveColor{Light: "#f7f4ee", Dark: "#0f1411"},
-		bgAlt:         lipgloss.AdaptiveColor{Light: "#efe9df", Dark: "#141b16"},
-		panelBg:       lipgloss.AdaptiveColor{Light: "#f2eee6", Dark: "#141c18"},
-		panelBorder:   lipgloss.AdaptiveColor{Light: "#c9bda8", Dark: "#2c3a32"},
-		text:          lipgloss.AdaptiveColor{Light: "#2a271f", Dark: "#e6f0e6"},
-		textMuted:     lipgloss.AdaptiveColor{Light: "#5e5648", Dark: "#a6b4a6"},
-		textDim:       lipgloss.AdaptiveColor{Light: "#8a8171", Dark: "#7b887f"},
-		accent:        lipgloss.AdaptiveColor{Light: "#2f6d4b", Dark: "#6fd08a"},
-		accentAlt:     lipgloss.AdaptiveColor{Light: "#7a5a2a", Dark: "#d2a76a"},
-		highlightBg:   lipgloss.AdaptiveColor{Light: "#d8efe2", Dark: "#264c37"},
-		highlightText: lipgloss.AdaptiveColor{Light: "#1f3b2a", Dark: "#eaf6ee"},
-		headerBg:      lipgloss.AdaptiveColor{Light: "#e9efe6", Dark: "#18221d"},
-		searchBg:      lipgloss.AdaptiveColor{Light: "#e9efe6", Dark: "#18221d"},
-		footerBg:      lipgloss.AdaptiveColor{Light: "#e9efe6", Dark: "#18221d"},
-	}
-
-	branchColors = []lipgloss.TerminalColor{
-		lipgloss.AdaptiveColor{Light: "#2f6d4b", Dark: "#6fd08a"},
-		lipgloss.AdaptiveColor{Light: "#4f8a5b", Dark: "#7ee1a0"},
-		lipgloss.AdaptiveColor{Light: "#7a5a2a", Dark: "#d2a76a"},
-		lipgloss.AdaptiveColor{Light: "#4d7f75", Dark: "#7fd3c5"},
-		lipgloss.AdaptiveColor{Light: "#4f6f8a", Dark: "#8fb9e0"},
-		lipgloss.AdaptiveColor{Light: "#9a6b2f", Dark: "#f0c07a"},
-		lipgloss.AdaptiveColor{Light: "#6e8b3d", Dark: "#a8e063"},
-		lipgloss.AdaptiveColor{Light: "#3f5a4a", Dark: "#6cb08a"},
-		lipgloss.AdaptiveColor{Light: "#6c7a74", Dark: "#a9b6b0"},
-	}
-
-	branchStyles = func() []lipgloss.Style {
-		styles := make([]lipgloss.Style, 0, len(branchColors))
-		for _, color := range branchColors {
-			styles = append(styles, lipgloss.NewStyle().Foreground(color))
-		}
-		return styles
-	}()
-
-	headerStyle       = lipgloss.NewStyle().Foreground(palette.text).Background(palette.headerBg).Padding(0, 1)
-	headerTitleStyle  = lipgloss.NewStyle().Bold(true).Foreground(palette.accent).Background(palette.headerBg)
-	headerRepoStyle   = lipgloss.NewStyle().Foreground(palette.text).Background(palette.headerBg)
+	branchStyles []lipgloss.Style
+
+	headerStyle, headerTitleStyle, headerRepoStyle, headerFilterStyle lipgloss.Style
+	headerSepStyle, headerMetaStyle, headerBadgeStyle                 lipgloss.Style
+
+	rowSeparatorStyle, rowSpacerStyle, hashStyle, subjectStyle, authorStyle lipgloss.Style
+
+	sidebarStyle, sidebarTitleStyle, sidebarSubtitleStyle, sidebarHintStyle, searchStyle, emptyStyle lipgloss.Style
+	scrubberLabelStyle, scrubberHintStyle, scrubberTrackStyle, scrubberKnobStyle                     lipgloss.Style
+	markStyle, matchStyle, deployStyle, warningStyle, chipStyle                                      lipgloss.Style
+	markdownCodeStyle, markdownQuoteStyle                                                            lipgloss.Style
+
+	popupStyle, popupTitleStyle, popupHintStyle, heatBarStyle lipgloss.Style
+
+	diffAddStyle, diffDelStyle, diffHunkStyle, listCursorStyle lipgloss.Style
+
+	bannerStyle           lipgloss.Style
+	inProgressBannerStyle lipgloss.Style
+
+	footerStyle, footerHintStyle, footerStatusStyle lipgloss.Style
+)
+
+// setStyles derives every style from the current palette; setTheme calls it
+// after swapping the palette.
+func setStyles() {
+	branchStyles = make([]lipgloss.Style, 0, len(branchColors))
+	for _, color := range branchColors {
+		branchStyles = append(branchStyles, lipgloss.NewStyle().Foreground(color))
+	}
+
+	headerStyle = lipgloss.NewStyle().Foreground(palette.text).Background(palette.headerBg).Padding(0, 1)
+	headerTitleStyle = lipgloss.NewStyle().Bold(true).Foreground(palette.accent).Background(palette.headerBg)
+	headerRepoStyle = lipgloss.NewStyle().Foreground(palette.text).Background(palette.headerBg)
 	headerFilterStyle = lipgloss.NewStyle().Foreground(palette.accentAlt).Background(palette.headerBg)
-	headerSepStyle    = lipgloss.NewStyle().Foreground(palette.textDim).Background(palette.headerBg)
-	headerMetaStyle   = lipgloss.NewStyle().Foreground(palette.textDim).Background(palette.headerBg)
-	headerBadgeStyle  = lipgloss.NewStyle().Foreground(palette.highlightText).Background(palette.accent).Padding(0, 1)
+	headerSepStyle = lipgloss.NewStyle().Foreground(palette.textDim).Background(palette.headerBg)
+	headerMetaStyle = lipgloss.NewStyle().Foreground(palette.textDim).Background(palette.headerBg)
+	headerBadgeStyle = lipgloss.NewStyle().Foreground(palette.highlightText).Background(palette.accent).Padding(0, 1)
 
 	rowSeparatorStyle = lipgloss.NewStyle()
-	rowSpacerStyle    = lipgloss.NewStyle()
-	hashStyle         = lipgloss.NewStyle().Foreground(palette.accent).Bold(true)
-	subjectStyle      = lipgloss.NewStyle().Foreground(palette.text).Bold(true)
-	authorStyle       = lipgloss.NewStyle().Foreground(palette.textMuted)
+	rowSpacerStyle = lipgloss.NewStyle()
+	hashStyle = lipgloss.NewStyle().Foreground(palette.accent).Bold(true)
+	subjectStyle = lipgloss.NewStyle().Foreground(palette.text).Bold(true)
+	authorStyle = lipgloss.NewStyle().Foreground(palette.textMuted)
 
-	sidebarStyle         = lipgloss.NewStyle().Border(lipgloss.RoundedBorder()).BorderForeground(palette.panelBorder).Padding(0, 1).Background(palette.panelBg).Foreground(palette.text)
-	sidebarTitleStyle    = lipgloss.NewStyle().Bold(true).Foreground(palette.accentAlt).Background(palette.panelBg)
+	sidebarStyle = lipgloss.NewStyle().Border(lipgloss.RoundedBorder()).BorderForeground(palette.panelBorder).Padding(0, 1).Background(palette.panelBg).Foreground(palette.text)
+	sidebarTitleStyle = lipgloss.NewStyle().Bold(true).Foreground(palette.accentAlt).Background(palette.panelBg)
 	sidebarSubtitleStyle = lipgloss.NewStyle().Bold(true).Foreground(palette.accent).Background(palette.panelBg)
-	searchStyle          = lipgloss.NewStyle().Foreground(palette.text).Background(palette.searchBg).Padding(0, 1)
-	emptyStyle           = lipgloss.NewStyle().Foreground(palette.textDim)
-
-	footerStyle       = lipgloss.NewStyle().Foreground(palette.text).Background(palette.footerBg).Padding(0, 1)
-	footerHintStyle   = lipgloss.NewStyle().Foreground(palette.textMuted).Background(palette.footerBg)
+	sidebarHintStyle = lipgloss.NewStyle().Foreground(palette.textDim).Background(palette.panelBg)
+	markdownCodeStyle = lipgloss.NewStyle().Foreground(palette.accentAlt).Background(palette.panelBg)
+	markdownQuoteStyle = lipgloss.NewStyle().Foreground(palette.textDim).Background(palette.panelBg)
+	searchStyle = lipgloss.NewStyle().Foreground(palette.text).Background(palette.searchBg).Padding(0, 1)
+	emptyStyle = lipgloss.NewStyle().Foreground(palette.textDim)
+	scrubberLabelStyle = lipgloss.NewStyle().Bold(true).Foreground(palette.accentAlt).Background(palette.searchBg)
+	scrubberHintStyle = lipgloss.NewStyle().Foreground(palette.textDim).Background(palette.searchBg)
+	scrubberTrackStyle = lipgloss.NewStyle().Foreground(palette.panelBorder).Background(palette.searchBg)
+	scrubberKnobStyle = lipgloss.NewStyle().Foreground(palette.accent).Background(palette.searchBg)
+	markStyle = lipgloss.NewStyle().Foreground(palette.accentAlt).Bold(true)
+	matchStyle = lipgloss.NewStyle().Foreground(palette.highlightText).Background(palette.accentAlt)
+	deployStyle = lipgloss.NewStyle().Foreground(palette.highlightText).Background(palette.accentAlt).Padding(0, 1)
+	warningStyle = lipgloss.NewStyle().Foreground(palette.warning).Bold(true)
+	chipStyle = lipgloss.NewStyle().Foreground(palette.accentAlt).Background(palette.bgAlt)
+
+	popupStyle = lipgloss.NewStyle().Border(lipgloss.RoundedBorder()).BorderForeground(palette.accent).Padding(0, 1).Background(palette.panelBg).Foreground(palette.text)
+	popupTitleStyle = lipgloss.NewStyle().Bold(true).Foreground(palette.accent).Background(palette.panelBg)
+	popupHintStyle = lipgloss.NewStyle().Foreground(palette.textDim).Background(palette.panelBg)
+	heatBarStyle = lipgloss.NewStyle().Foreground(palette.accent).Background(palette.panelBg)
+
+	diffAddStyle = lipgloss.NewStyle().Foreground(palette.accent).Background(palette.panelBg)
+	diffDelStyle = lipgloss.NewStyle().Foreground(palette.warning).Background(palette.panelBg)
+	diffHunkStyle = lipgloss.NewStyle().Foreground(palette.accentAlt).Background(palette.panelBg)
+	listCursorStyle = lipgloss.NewStyle().Foreground(palette.highlightText).Background(palette.highlightBg)
+
+	bannerStyle = lipgloss.NewStyle().Foreground(palette.warning).Background(palette.bgAlt).Padding(0, 1)
+	inProgressBannerStyle = lipgloss.NewStyle().Foreground(palette.highlightText).Background(palette.warning).Bold(true).Padding(0, 1)
+
+	footerStyle = lipgloss.NewStyle().Foreground(palette.text).Background(palette.footerBg).Padding(0, 1)
+	footerHintStyle = lipgloss.NewStyle().Foreground(palette.textMuted).Background(palette.footerBg)
 	footerStatusStyle = lipgloss.NewStyle().Foreground(palette.accent).Background(palette.footerBg)
-)
+}
@@ -0,0 +1,171 @@
+package tui
+
+import (
+	"fmt"
+	"strings"
+
+	"arbor/internal/debuglog"
+	"arbor/internal/gitgraph"
+
+	tea "github.com/charmbracelet/bubbletea"
+	"github.com/charmbracelet/lipgloss"
+)
+
+// pager shows long read-only text such as diffs in a scrollable box. It sits
+// above every other overlay, so closing it returns to whatever opened it.
+type pager struct {
+	title  string
+	lines  []string
+	offset int
+	// diff, for a diff shown file by file, renders lines and expands the
+	// files it truncated.
+	diff *diffView
+}
+
+func (m *model) openPager(title string, lines []string) {
+	m.pager = &pager{title: title, lines: lines}
+}
+
+// openDiffPager shows diffs file by file, truncating long and large ones.
+// Files with a textconv driver fill in as the returned command converts
+// them.
+func (m *model) openDiffPager(title string, diffs []*gitgraph.FileDiff) tea.Cmd {
+	view := newDiffView(gitgraph.LoadDiffFormat(m.provider.Repo()), m.provider.Attributes(), diffs)
+	m.pager = &pager{title: title, lines: view.render(), diff: view}
+	return m.fillDiff(false)
+}
+
+func (m *model) applyDiffConverted(msg diffConvertedMsg) tea.Cmd {
+	if p := m.pager; p != nil && p.diff != nil && p.diff.applyConverted(msg) {
+		p.lines = p.diff.render()
+		return m.fillDiff(false)
+	}
+	return nil
+}
+
+// fillDiff diffs the files a diff pager has in view; toEnd keeps it at
+// the bottom as they grow.
+func (m *model) fillDiff(toEnd bool) tea.Cmd {
+	p := m.pager
+	if p == nil || p.diff == nil {
+		return nil
+	}
+	page := max(1, m.pagerRows())
+	var cmds []tea.Cmd
+	for {
+		if toEnd {
+			p.offset = len(p.lines)
+		}
+		p.offset = clamp(p.offset, 0, max(0, len(p.lines)-page))
+		lines, cmd := p.diff.fill(p.offset, page)
+		cmds = append(cmds, cmd)
+		grew := len(lines) != len(p.lines)
+		p.lines = lines
+		if !toEnd || !grew {
+			break
+		}
+	}
+	p.offset = clamp(p.offset, 0, max(0, len(p.lines)-page))
+	return tea.Batch(cmds...)
+}
+
+// openDebugLog shows the latest lines of the --debug log, newest at the
+// bottom.
+func (m *model) openDebugLog() {
+	const title = "Debug log"
+	if !debuglog.Enabled() {
+		m.openPopup(title, "Nothing is being logged. Start arbor with --debug to record git commands, history loading, and key presses.")
+		return
+	}
+	lines := debuglog.Recent()
+	m.openPager(title+" "+debuglog.Path(), lines)
+	m.pager.offset = max(0, len(lines)-max(1, m.pagerRows()))
+}
+
+func (m *model) handlePagerKey(msg tea.KeyMsg) (tea.Model, tea.Cmd) {
+	p := m.pager
+	page := max(1, m.pagerRows())
+	var cmd tea.Cmd
+	switch msg.String() {
+	case "ctrl+c":
+		return m, tea.Quit
+	case "esc", "q":
+		m.pager = nil
+		return m, nil
+	case "up", "k":
+		p.offset--
+	case "down", "j":
+		p.offset++
+	case "pgup", "b":
+		p.offset -= page
+	case "pgdown", " ", "f":
+		p.offset += page
+	case "g", "home":
+		p.offset = 0
+	case "G", "end":
+		p.offset = len(p.lines)
+	case "]", "[", "}", "{":
+		if p.diff != nil {
+			starts := p.diff.hunkStarts
+			if key := msg.String(); key == "}" || key == "{" {
+				starts = p.diff.fileStarts
+			}
+			if line, ok := jump(starts, p.diff.current(p.offset, page), msg.String() == "]" || msg.String() == "}"); ok {
+				p.offset, p.diff.at = line, line
+			}
+		}
+	case "+", "a":
+		if p.diff != nil {
+			var ok bool
+			if ok, cmd = p.diff.expand(p.offset, page, msg.String() == "a"); ok {
+				p.lines = p.diff.render()
+			}
+		}
+	}
+	p.offset = clamp(p.offset, 0, max(0, len(p.lines)-page))
+	if p.diff != nil {
+		key := msg.String()
+		cmd = tea.Batch(cmd, m.fillDiff(key == "G" || key == "end"))
+	}
+	return m, cmd
+}
+
+func (m *model) pagerRows() int {
+	return m.viewportHeight() - 6
+}
+
+func (m *model) renderPager(width, height int) string {
+	p := m.pager
+	boxWidth := max(1, width-4)
+	rows := max(1, m.pagerRows())
+	header := ""
+	if p.diff != nil {
+		header = popupHintStyle.Render(truncateText(p.diff.outline(p.diff.current(p.offset, rows)), boxWidth-4))
+	}
+	lines := []string{popupTitleStyle.Render(p.title), header}
+	end := min(p.offset+rows, len(p.lines))
+	for _, line := range p.lines[p.offset:end] {
+		line = truncateText(line, boxWidth-4)
+		switch {
+		case strings.HasPrefix(line, "+"):
+			line = diffAddStyle.Render(line)
+		case strings.HasPrefix(line, "-"):
+			line = diffDelStyle.Render(line)
+		case strings.HasPrefix(line, "@@"):
+			line = diffHunkStyle.Render(line)
+		}
+		lines = append(lines, line)
+	}
+	hint := fmt.Sprintf("%d-%d of %d | j/k scroll | space/b page | esc close", min(p.offset+1, len(p.lines)), end, len(p.lines))
+	if p.diff != nil {
+		more := ""
+		if len(p.diff.markers) > 0 {
+			more = " | +/a expand the file cut short in view"
+		}
+		hint = fmt.Sprintf("%d-%d of %d | j/k scroll | space/b page | ]/[ hunk | }/{ file%s | esc close", min(p.offset+1, len(p.lines)), end, len(p.lines), more)
+	}
+	lines = append(lines, "", popupHintStyle.Render(hint))
+	box := popupStyle.Width(boxWidth).Render(strings.Join(lines, "\n"))
+	return lipgloss.Place(width, height, lipgloss.Center, lipgloss.Center, box,
+		lipgloss.WithWhitespaceBackground(palette.bg))
+}
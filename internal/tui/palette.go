@@ -0,0 +1,172 @@
+package tui
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+
+	"arbor/internal/config"
+
+	tea "github.com/charmbracelet/bubbletea"
+	"github.com/charmbracelet/lipgloss"
+)
+
+// actionTitles describe keymap actions in the command palette.
+var actionTitles = map[string]string{
+	actionQuit:         "Quit",
+	actionUp:           "Move up",
+	actionDown:         "Move down",
+	actionFiles:        "Toggle changed files",
+	actionSearch:       "Search and filter commits",
+	actionFind:         "Search and jump to matches",
+	actionNextMatch:    "Next match",
+	actionPrevMatch:    "Previous match",
+	actionSidebar:      "Toggle sidebar",
+	actionFocus:        "Focus sidebar",
+	actionDensity:      "Cycle row density",
+	actionMark:         "Mark or unmark commit",
+	actionAncestry:     "Ancestry of marked vs. selected",
+	actionScrubber:     "Time scrubber",
+	actionDismiss:      "Dismiss maintenance banner",
+	actionFixup:        "Fixup commit for selected",
+	actionPrune:        "Prune assistant",
+	actionMarker:       "Empty marker commit",
+	actionCopy:         "Copy hash",
+	actionOpen:         "Open commit on the web",
+	actionBranches:     "Branch table",
+	actionRangeDiff:    "Range diff vs. previous position",
+	actionIdentity:     "Cycle commit identity",
+	actionReview:       "Mark reviewed (arbor review)",
+	actionCombined:     "Combined diff (arbor review)",
+	actionSquash:       "Squash marked through selected",
+	actionFolder:       "Folder history",
+	actionVisits:       "HEAD visits",
+	actionReset:        "Reset branch to selected",
+	actionUndo:         "Undo last operation",
+	actionTag:          "Tag notes",
+	actionHeatmap:      "Path heatmap",
+	actionScope:        "Cycle branch scope",
+	actionPull:         "Check out pull request",
+	actionFetch:        "Fetch all remotes",
+	actionPush:         "Push current branch",
+	actionAmend:        "Amend HEAD",
+	actionRemotes:      "Compare remotes",
+	actionPalette:      "Command palette",
+	actionLog:          "Debug log",
+	actionRebase:       "Rebase onto selected",
+	actionPruneRemotes: "Prune deleted remote branches",
+	actionMarkdown:     "Toggle markdown commit bodies",
+	actionGraph:        "Cycle graph position",
+	actionMine:         "Toggle only my commits",
+	actionLegend:       "Show the graph legend",
+	actionOperation:    "Continue or abort the operation in progress",
+	actionStage:        "Stage and unstage hunks of the working tree",
+	actionBundle:       "Write the selected branch, or the marked range, to a bundle file",
+	actionFollow:       "Watch for new commits and follow HEAD, or stop following",
+	actionExport:       "Export the filtered commits to CSV or JSON, or copy them",
+	actionGoto:         "Go to a branch, tag, or commit (tab completes)",
+	actionSort:         "Sort fuzzy matches by relevance or history",
+}
+
+// commandPalette lists every keymap action, narrowed by a fuzzy query, so
+// one can be run without knowing (or having) its key.
+type commandPalette struct {
+	query  string
+	cursor int
+}
+
+type paletteEntry struct {
+	action string
+	title  string
+	key    string
+	score  int
+}
+
+func (m *model) openPalette() {
+	m.palette = &commandPalette{}
+}
+
+// paletteEntries are the actions matching the query, best first, in keymap order
+// otherwise. Moving the cursor and opening the palette again are left out.
+func (m *model) paletteEntries() []paletteEntry {
+	var entries []paletteEntry
+	for _, action := range config.ActionNames {
+		if action == actionUp || action == actionDown || action == actionPalette {
+			continue
+		}
+		title := actionTitles[action]
+		if title == "" {
+			title = action
+		}
+		score, ok := fuzzyScore(m.palette.query, title+" "+action)
+		if !ok {
+			continue
+		}
+		entries = append(entries, paletteEntry{action: action, title: title, key: m.keys.first(action), score: score})
+	}
+	sort.SliceStable(entries, func(i, j int) bool { return entries[i].score > entries[j].score })
+	return entries
+}
+
+func (m *model) handlePaletteKey(msg tea.KeyMsg) (tea.Model, tea.Cmd) {
+	p := m.palette
+	entries := m.paletteEntries()
+	switch msg.Type {
+	case tea.KeyCtrlC:
+		return m, tea.Quit
+	case tea.KeyEsc:
+		m.palette = nil
+	case tea.KeyUp, tea.KeyShiftTab, tea.KeyCtrlK:
+		p.cursor = max(0, p.cursor-1)
+	case tea.KeyDown, tea.KeyTab, tea.KeyCtrlJ:
+		p.cursor = min(max(0, len(entries)-1), p.cursor+1)
+	case tea.KeyEnter:
+		if p.cursor >= len(entries) {
+			return m, nil
+		}
+		m.palette = nil
+		m.status = ""
+		return m.runAction(entries[p.cursor].action)
+	case tea.KeyBackspace:
+		if runes := []rune(p.query); len(runes) > 0 {
+			p.query = string(runes[:len(runes)-1])
+			p.cursor = 0
+		}
+	case tea.KeySpace:
+		p.query += " "
+		p.cursor = 0
+	case tea.KeyRunes:
+		p.query += string(msg.Runes)
+		p.cursor = 0
+	}
+	return m, nil
+}
+
+func (m *model) renderPalette(width, height int) string {
+	p := m.palette
+	boxWidth := min(max(30, width/2), max(1, width-4))
+	entries := m.paletteEntries()
+	lines := []string{popupTitleStyle.Render("Command palette"), "", "> " + p.query + "█", ""}
+
+	rows := max(1, height-10)
+	start := max(0, min(p.cursor-rows/2, len(entries)-rows))
+	if len(entries) == 0 {
+		lines = append(lines, popupHintStyle.Render("No matching action"))
+	}
+	for i := start; i < len(entries) && i < start+rows; i++ {
+		entry := entries[i]
+		key := entry.key
+		if key == "" {
+			key = "unbound"
+		}
+		line := truncateText(fmt.Sprintf("%-*s %s", boxWidth-16, entry.title, key), boxWidth-4)
+		if i == p.cursor {
+			line = listCursorStyle.Width(boxWidth - 2).Render(line)
+		}
+		lines = append(lines, line)
+	}
+	lines = append(lines, "", popupHintStyle.Render("type to narrow | ↑/↓ choose | enter run | esc close"))
+	box := popupStyle.Width(boxWidth).Render(strings.Join(lines, "\n"))
+	return lipgloss.Place(width, height, lipgloss.Center, lipgloss.Center, box,
+		lipgloss.WithWhitespaceBackground(palette.bg))
+}
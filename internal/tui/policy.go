@@ -0,0 +1,61 @@
+package tui
+
+import (
+	"arbor/internal/gitgraph"
+	"arbor/internal/gitops"
+
+	tea "github.com/charmbracelet/bubbletea"
+	"github.com/go-git/go-git/v5/plumbing"
+)
+
+type policyMsg struct {
+	results map[plumbing.Hash][]string
+}
+
+// policyVisible checks the rows on screen against the audit.policy file,
+// as auditVisible does for findings. Signatures are verified only with a
+// working tree, through git; without one, or when git fails, a policy
+// with keys flags every commit as not verified.
+func (m *model) policyVisible() tea.Cmd {
+	if m.policy == nil {
+		return nil
+	}
+	var pending []*gitgraph.CommitInfo
+	for _, commit := range m.visibleCommits() {
+		if _, done := m.policyProblems[commit.Hash]; done || m.policyPending[commit.Hash] || commit.Commit == nil {
+			continue
+		}
+		m.policyPending[commit.Hash] = true
+		pending = append(pending, commit)
+	}
+	if len(pending) == 0 {
+		return nil
+	}
+	policy, git := m.policy, m.git
+	return func() tea.Msg {
+		var sigs map[string]gitops.Signature
+		if policy.ChecksKeys() && git != nil {
+			hashes := make([]string, len(pending))
+			for i, commit := range pending {
+				hashes[i] = commit.Hash.String()
+			}
+			sigs, _ = git.Signatures(hashes)
+		}
+		results := make(map[plumbing.Hash][]string, len(pending))
+		for _, commit := range pending {
+			var signer *gitgraph.Signer
+			if sig, ok := sigs[commit.Hash.String()]; ok {
+				signer = &gitgraph.Signer{Signed: sig.Signed(), Good: sig.Good(), Keys: sig.Keys}
+			}
+			results[commit.Hash] = policy.Check(commit.Commit, signer)
+		}
+		return policyMsg{results: results}
+	}
+}
+
+func (m *model) applyPolicy(msg policyMsg) {
+	for hash, problems := range msg.results {
+		m.policyProblems[hash] = problems
+		delete(m.policyPending, hash)
+	}
+}
@@ -0,0 +1,83 @@
+package tui
+
+import (
+	"fmt"
+	"strings"
+
+	tea "github.com/charmbracelet/bubbletea"
+	"github.com/charmbracelet/lipgloss"
+)
+
+type popup struct {
+	title   string
+	lines   []string
+	actions []popupAction
+	// hint replaces the listed actions in the bottom line, for prompts
+	// that list their choices in the body.
+	hint string
+}
+
+// popupAction turns a popup into a prompt: pressing key closes it and runs
+// the action.
+type popupAction struct {
+	key   string
+	label string
+	run   func() tea.Cmd
+}
+
+func (m *model) openPopup(title string, lines ...string) {
+	m.popup = &popup{title: title, lines: lines}
+}
+
+func (m *model) openPrompt(title string, lines []string, actions ...popupAction) {
+	m.popup = &popup{title: title, lines: lines, actions: actions}
+}
+
+func (m *model) handlePopupKey(msg tea.KeyMsg) (tea.Model, tea.Cmd) {
+	key := msg.String()
+	if key == "ctrl+c" {
+		return m, tea.Quit
+	}
+	for _, action := range m.popup.actions {
+		if action.key == key {
+			m.popup = nil
+			return m, action.run()
+		}
+	}
+	if m.popup.title == legendTitle && m.keys.action(key) == actionLegend {
+		m.popup = nil
+		return m, nil
+	}
+	switch key {
+	case "esc", "q":
+		m.popup = nil
+	case "enter":
+		if len(m.popup.actions) == 0 {
+			m.popup = nil
+		}
+	}
+	return m, nil
+}
+
+func (m *model) renderPopup(width, height int) string {
+	boxWidth := min(max(30, width*2/3), max(1, width-4))
+	lines := []string{popupTitleStyle.Render(m.popup.title), ""}
+	for _, line := range m.popup.lines {
+		lines = append(lines, wrapText(line, boxWidth-4)...)
+	}
+	hint := "esc/enter close"
+	if len(m.popup.actions) > 0 {
+		parts := make([]string, 0, len(m.popup.actions)+1)
+		for _, action := range m.popup.actions {
+			parts = append(parts, fmt.Sprintf("%s %s", action.key, action.label))
+		}
+		hint = strings.Join(append(parts, "esc close"), " | ")
+	}
+	if m.popup.hint != "" {
+		hint = m.popup.hint
+	}
+	lines = append(lines, "", popupHintStyle.Render(hint))
+	box := popupStyle.Width(boxWidth).Render(strings.Join(lines, "\n"))
+	return lipgloss.Place(width, height, lipgloss.Center, lipgloss.Center, box,
+		lipgloss.WithWhitespaceBackground(palette.bg))
+}
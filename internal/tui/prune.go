@@ -0,0 +1,178 @@
+package tui
+
+import (
+	"fmt"
+	"strings"
+
+	"arbor/internal/gitgraph"
+	"arbor/internal/gitops"
+
+	tea "github.com/charmbracelet/bubbletea"
+	"github.com/charmbracelet/lipgloss"
+)
+
+const pruneTitle = "Prune branches"
+
+type pruner struct {
+	candidates []gitgraph.PruneCandidate
+	selected   map[int]bool
+	// protected candidates are listed but cannot be selected.
+	protected map[int]error
+	cursor    int
+}
+
+type prunedBranch struct {
+	name string
+	tip  string
+}
+
+type pruneMsg struct {
+	deleted []prunedBranch
+	err     error
+}
+
+func (m *model) openPruner() {
+	if !m.worktreeAvailable(pruneTitle) || !m.allowed(pruneTitle, gitops.OpDelete, "") {
+		return
+	}
+	candidates, err := gitgraph.PruneCandidates(m.provider.Repo(), m.provider.Generations())
+	if err != nil {
+		m.openPopup(pruneTitle, err.Error())
+		return
+	}
+	if len(candidates) == 0 {
+		m.openPopup(pruneTitle, "No local branches are merged or have a missing upstream.")
+		return
+	}
+	p := &pruner{candidates: candidates, selected: make(map[int]bool), protected: make(map[int]error)}
+	for i, candidate := range candidates {
+		if err := m.git.Allow(gitops.OpDelete, candidate.Branch); err != nil {
+			p.protected[i] = err
+		}
+	}
+	m.pruner = p
+}
+
+func (m *model) handlePrunerKey(msg tea.KeyMsg) (tea.Model, tea.Cmd) {
+	p := m.pruner
+	switch msg.String() {
+	case "ctrl+c":
+		return m, tea.Quit
+	case "esc", "q":
+		m.pruner = nil
+	case "up", "k":
+		p.cursor = max(0, p.cursor-1)
+	case "down", "j":
+		p.cursor = min(len(p.candidates)-1, p.cursor+1)
+	case " ":
+		if p.protected[p.cursor] == nil {
+			p.selected[p.cursor] = !p.selected[p.cursor]
+		}
+	case "a":
+		all := len(p.selectedBranches()) == len(p.candidates)-len(p.protected)
+		for i := range p.candidates {
+			p.selected[i] = !all && p.protected[i] == nil
+		}
+	case "d", "enter":
+		branches := p.selectedBranches()
+		if len(branches) == 0 {
+			return m, nil
+		}
+		m.pruner = nil
+		return m, deleteBranches(m.git, branches)
+	}
+	return m, nil
+}
+
+func (p *pruner) selectedBranches() []prunedBranch {
+	var branches []prunedBranch
+	for i, candidate := range p.candidates {
+		if p.selected[i] {
+			branches = append(branches, prunedBranch{name: candidate.Branch, tip: candidate.Tip.Hash.String()})
+		}
+	}
+	return branches
+}
+
+func deleteBranches(git *gitops.Repo, branches []prunedBranch) tea.Cmd {
+	return func() tea.Msg {
+		var deleted []prunedBranch
+		_, err := git.Journaled("prune", func(git *gitops.Repo) (string, error) {
+			for _, branch := range branches {
+				if err := git.DeleteBranch(branch.name, branch.tip); err != nil {
+					return "", err
+				}
+				deleted = append(deleted, branch)
+			}
+			return "", nil
+		})
+		return pruneMsg{deleted: deleted, err: err}
+	}
+}
+
+// applyPrune reports the deletions and keeps an undo action open until the
+// popup is dismissed. The bookmarks under refs/arbor/pruned/ outlive it.
+func (m *model) applyPrune(msg pruneMsg) {
+	var lines []string
+	for _, branch := range msg.deleted {
+		lines = append(lines, fmt.Sprintf("Deleted %s (was %s)", branch.name, m.short(branch.tip)))
+	}
+	if msg.err != nil {
+		lines = append(lines, msg.err.Error())
+	}
+	if err := m.reload(); err != nil {
+		lines = append(lines, fmt.Sprintf("Reload failed: %v", err))
+	}
+	if len(msg.deleted) == 0 {
+		m.openPopup(pruneTitle, lines...)
+		return
+	}
+	lines = append(lines, "", "Old tips are kept under "+gitops.PrunedRefPrefix+".")
+	git, deleted := m.git, msg.deleted
+	m.openPrompt(pruneTitle, lines, popupAction{key: "u", label: "undo", run: func() tea.Cmd {
+		return runGitOp(pruneTitle, func() (string, error) {
+			return git.Journaled("restore pruned branches", func(git *gitops.Repo) (string, error) {
+				var restored []string
+				for _, branch := range deleted {
+					if err := git.RestoreBranch(branch.name, branch.tip); err != nil {
+						return "", err
+					}
+					restored = append(restored, branch.name)
+				}
+				return "Restored " + strings.Join(restored, ", "), nil
+			})
+		})
+	}})
+}
+
+func (m *model) renderPruner(width, height int) string {
+	p := m.pruner
+	boxWidth := min(max(30, width*2/3), max(1, width-4))
+	lines := []string{popupTitleStyle.Render(pruneTitle), ""}
+
+	rows := max(1, height-6)
+	start := clamp(p.cursor-rows/2, 0, max(0, len(p.candidates)-rows))
+	end := min(start+rows, len(p.candidates))
+	for i := start; i < end; i++ {
+		candidate := p.candidates[i]
+		check, reason := "[ ]", candidate.Reason
+		if p.selected[i] {
+			check = "[x]"
+		}
+		if err := p.protected[i]; err != nil {
+			check, reason = "[-]", "protected"
+		}
+		line := fmt.Sprintf("%s %s  %s  %s %s", check, candidate.Branch, reason,
+			m.short(candidate.Tip.Hash.String()), gitgraph.FirstLine(candidate.Tip.Message))
+		line = truncateText(line, boxWidth-4)
+		if i == p.cursor {
+			line = listCursorStyle.Width(boxWidth - 2).Render(line)
+		}
+		lines = append(lines, line)
+	}
+	hint := fmt.Sprintf("%d selected | space toggle | a all | d delete | esc close", len(p.selectedBranches()))
+	lines = append(lines, "", popupHintStyle.Render(hint))
+	box := popupStyle.Width(boxWidth).Render(strings.Join(lines, "\n"))
+	return lipgloss.Place(width, height, lipgloss.Center, lipgloss.Center, box,
+		lipgloss.WithWhitespaceBackground(palette.bg))
+}
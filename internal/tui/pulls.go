@@ -0,0 +1,86 @@
+package tui
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+
+	"arbor/internal/gitgraph"
+	"arbor/internal/gitops"
+
+	tea "github.com/charmbracelet/bubbletea"
+	"github.com/go-git/go-git/v5/plumbing"
+)
+
+// loadPullHeads indexes fetched pull request heads by commit; redone on
+// every reload like the tags.
+func (m *model) loadPullHeads() {
+	refs, err := gitgraph.ListRefs(m.provider.Repo())
+	if err != nil {
+		m.status = "pull requests: " + err.Error()
+		return
+	}
+	m.pullHeads = make(map[plumbing.Hash][]gitgraph.Ref)
+	for _, ref := range m.decorations.Refs(refs) {
+		if ref.Kind == gitgraph.RefPull {
+			m.pullHeads[ref.Target] = append(m.pullHeads[ref.Target], ref)
+		}
+	}
+}
+
+// pullLabels names the pull requests headed at commit, e.g. "PR #12, PR #15".
+func (m *model) pullLabels(commit *gitgraph.CommitInfo) string {
+	var labels []string
+	for _, ref := range m.pullHeads[commit.Hash] {
+		labels = append(labels, ref.Short)
+	}
+	return strings.Join(labels, ", ")
+}
+
+// checkoutPullRequest offers to check out the pull requests whose head is
+// the selected commit as local pr/<number> branches.
+func (m *model) checkoutPullRequest() {
+	const title = "Check out pull request"
+	commit := m.selectedCommit()
+	if commit == nil || !m.worktreeAvailable(title) || !m.allowed(title, gitops.OpCommit, "") {
+		return
+	}
+	pulls := m.pullHeads[commit.Hash]
+	if len(pulls) == 0 {
+		m.status = "no pull request head at " + commit.ShortHash()
+		return
+	}
+	git, hash := m.git, commit.Hash.String()
+	lines := []string{fmt.Sprintf("%s %s is the head of:", commit.ShortHash(), commit.Subject), ""}
+	actions := make([]popupAction, 0, len(pulls))
+	for i, ref := range pulls {
+		if i == 9 {
+			lines = append(lines, fmt.Sprintf("… %d more", len(pulls)-9))
+			break
+		}
+		number := strings.TrimPrefix(ref.Short, "PR #")
+		key := strconv.Itoa(i + 1)
+		lines = append(lines, fmt.Sprintf("%s  %s (%s) → branch pr/%s", key, ref.Short, ref.Name, number))
+		actions = append(actions, popupAction{key: key, label: ref.Short, run: func() tea.Cmd {
+			return runGitOp(title, func() (string, error) {
+				out, err := git.CheckoutPullRequest(number, hash)
+				if err == nil && out == "" {
+					out = fmt.Sprintf("Switched to pr/%s.", number)
+				}
+				return out, err
+			})
+		}})
+	}
+	hint := fmt.Sprintf("1-%d check out | esc close", len(actions))
+	if dirty, err := m.git.DirtyFiles(); err == nil && len(dirty) > 0 {
+		number := strings.TrimPrefix(pulls[0].Short, "PR #")
+		lines = append(lines, "", fmt.Sprintf("%d files have uncommitted changes, which can stop the switch; %s stashes them and checks out %s.",
+			len(dirty), stashKey, pulls[0].Short))
+		actions = append(actions, m.stashAndContinue(title, "checkout", "stash & check out", func() (string, error) {
+			return git.CheckoutPullRequest(number, hash)
+		}))
+		hint = fmt.Sprintf("1-%d check out | %s stash & check out | esc close", len(actions)-1, stashKey)
+	}
+	m.openPrompt(title, lines, actions...)
+	m.popup.hint = hint
+}
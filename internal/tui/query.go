@@ -0,0 +1,55 @@
+package tui
+
+import (
+	"fmt"
+
+	"arbor/internal/gitgraph"
+)
+
+func (m *model) toggleMark() {
+	commit := m.selectedCommit()
+	if commit == nil {
+		return
+	}
+	if m.mark != nil && m.mark.Hash == commit.Hash {
+		m.mark = nil
+		return
+	}
+	m.mark = commit
+}
+
+func (m *model) showAncestry() {
+	commit := m.selectedCommit()
+	if commit == nil {
+		return
+	}
+	if m.mark == nil {
+		m.openPopup("Ancestry", fmt.Sprintf("Mark a commit with %s first, then select another commit and press %s.",
+			m.keys.first(actionMark), m.keys.first(actionAncestry)))
+		return
+	}
+	result, err := gitgraph.QueryAncestry(m.provider.Repo(), m.provider.Generations(), m.mark.Commit, commit.Commit)
+	if err != nil {
+		m.openPopup("Ancestry", fmt.Sprintf("Query failed: %v", err))
+		return
+	}
+	a, b := m.mark.ShortHash(), commit.ShortHash()
+	verdict := fmt.Sprintf("%s is NOT an ancestor of %s", a, b)
+	if result.IsAncestor {
+		verdict = fmt.Sprintf("%s is an ancestor of %s", a, b)
+	}
+	lines := []string{
+		fmt.Sprintf("A  %s %s", a, m.mark.Subject),
+		fmt.Sprintf("B  %s %s", b, commit.Subject),
+		"",
+		verdict,
+		"",
+	}
+	if len(result.MergeBases) == 0 {
+		lines = append(lines, "No merge base (unrelated histories)")
+	}
+	for _, base := range result.MergeBases {
+		lines = append(lines, fmt.Sprintf("merge-base %s %s", m.short(base.Hash.String()), gitgraph.FirstLine(base.Message)))
+	}
+	m.openPopup("Ancestry", lines...)
+}
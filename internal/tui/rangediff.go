@@ -0,0 +1,155 @@
+package tui
+
+import (
+	"fmt"
+	"strings"
+
+	"arbor/internal/gitgraph"
+
+	tea "github.com/charmbracelet/bubbletea"
+	"github.com/charmbracelet/lipgloss"
+	"github.com/go-git/go-git/v5/plumbing/object"
+)
+
+const rangeDiffTitle = "Range diff"
+
+type rangeView struct {
+	title  string
+	pairs  []gitgraph.RangePair
+	cursor int
+}
+
+// openRangeDiff compares the current branch before and after its last
+// update, which after a rebase shows what the rebase changed.
+func (m *model) openRangeDiff() {
+	repo := m.provider.Repo()
+	old, err := gitgraph.ResolveCommit(repo, "@{1}")
+	if err != nil {
+		m.openPopup(rangeDiffTitle, "The current branch has no previous position in its reflog.", err.Error())
+		return
+	}
+	head, err := gitgraph.ResolveCommit(repo, "HEAD")
+	if err != nil {
+		m.openPopup(rangeDiffTitle, err.Error())
+		return
+	}
+	pairs, err := gitgraph.RangeDiff(repo, m.provider.Generations(), old.Hash, head.Hash)
+	if err != nil {
+		m.openPopup(rangeDiffTitle, err.Error())
+		return
+	}
+	if len(pairs) == 0 {
+		m.openPopup(rangeDiffTitle, "The branch points at the same history as before its last update.")
+		return
+	}
+	title := fmt.Sprintf("%s: %s@{1} %s → %s", rangeDiffTitle, m.headName, m.short(old.Hash.String()), m.short(head.Hash.String()))
+	m.rangeView = &rangeView{title: title, pairs: pairs}
+}
+
+func (m *model) handleRangeKey(msg tea.KeyMsg) (tea.Model, tea.Cmd) {
+	r := m.rangeView
+	switch msg.String() {
+	case "ctrl+c":
+		return m, tea.Quit
+	case "esc", "q":
+		m.rangeView = nil
+	case "up", "k":
+		r.cursor = max(0, r.cursor-1)
+	case "down", "j":
+		r.cursor = min(len(r.pairs)-1, r.cursor+1)
+	case "enter":
+		return m, m.drillRangePair(r.pairs[r.cursor])
+	}
+	return m, nil
+}
+
+// rangePatchMsg is the whole patch of a range-diff commit, diffed in the
+// background since textconv drivers can take long.
+type rangePatchMsg struct {
+	title string
+	lines []string
+	err   error
+}
+
+// drillRangePair shows how a modified commit changed, or the whole patch of
+// an added, dropped, or unchanged one.
+func (m *model) drillRangePair(pair gitgraph.RangePair) tea.Cmd {
+	title := gitgraph.FormatRangePair(pair)
+	if pair.Status == gitgraph.RangeModified {
+		lines, err := gitgraph.Interdiff(pair.Old, pair.New, 3)
+		if err != nil {
+			m.openPopup(rangeDiffTitle, err.Error())
+			return nil
+		}
+		m.openPager(title, lines)
+		return nil
+	}
+	commit := pair.New
+	if commit == nil {
+		commit = pair.Old
+	}
+	format, attrs := gitgraph.LoadDiffFormat(m.provider.Repo()), m.provider.Attributes()
+	m.status = "diffing…"
+	return func() tea.Msg {
+		lines, err := patchLines(format, attrs, commit)
+		return rangePatchMsg{title: title, lines: lines, err: err}
+	}
+}
+
+// applyRangePatch opens the patch over the range diff, unless that was
+// closed while it was diffed.
+func (m *model) applyRangePatch(msg rangePatchMsg) {
+	m.status = ""
+	if m.rangeView == nil {
+		return
+	}
+	if msg.err != nil {
+		m.openPopup(rangeDiffTitle, msg.err.Error())
+		return
+	}
+	m.openPager(msg.title, msg.lines)
+}
+
+func patchLines(format gitgraph.DiffFormat, attrs *gitgraph.Attributes, commit *object.Commit) ([]string, error) {
+	changes, err := gitgraph.CommitChanges(commit)
+	if err != nil {
+		return nil, err
+	}
+	patch, err := attrs.Patch(changes)
+	if err != nil {
+		return nil, err
+	}
+	text := strings.TrimSuffix(format.Format(patch), "\n")
+	if text == "" {
+		return []string{"(no file changes)"}, nil
+	}
+	return strings.Split(text, "\n"), nil
+}
+
+func (m *model) renderRangeView(width, height int) string {
+	r := m.rangeView
+	boxWidth := min(max(30, width*4/5), max(1, width-4))
+	lines := []string{popupTitleStyle.Render(r.title), ""}
+	rows := max(1, height-6)
+	start := clamp(r.cursor-rows/2, 0, max(0, len(r.pairs)-rows))
+	end := min(start+rows, len(r.pairs))
+	for i := start; i < end; i++ {
+		pair := r.pairs[i]
+		line := truncateText(gitgraph.FormatRangePair(pair), boxWidth-4)
+		switch {
+		case i == r.cursor:
+			line = listCursorStyle.Width(boxWidth - 2).Render(line)
+		case pair.Status == gitgraph.RangeAdded:
+			line = diffAddStyle.Render(line)
+		case pair.Status == gitgraph.RangeDropped:
+			line = diffDelStyle.Render(line)
+		case pair.Status == gitgraph.RangeModified:
+			line = diffHunkStyle.Render(line)
+		}
+		lines = append(lines, line)
+	}
+	lines = append(lines, "", popupHintStyle.Render("= same | ! modified | < dropped | > added | enter diff | esc close"))
+	box := popupStyle.Width(boxWidth).Render(strings.Join(lines, "\n"))
+	return lipgloss.Place(width, height, lipgloss.Center, lipgloss.Center, box,
+		lipgloss.WithWhitespaceBackground(palette.bg))
+}
@@ -0,0 +1,144 @@
+package tui
+
+import (
+	"fmt"
+
+	"arbor/internal/gitops"
+
+	tea "github.com/charmbracelet/bubbletea"
+)
+
+// rebasePreview caps the commits listed before a rebase runs.
+const rebasePreview = 12
+
+// startRebase replays the current branch onto the selected commit. The
+// commits replayed are those since the branch left the selected commit's
+// line, or since the marked commit when one is marked. While a rebase is
+// stopped, the same key shows its status instead.
+func (m *model) startRebase() {
+	const title = "Rebase onto"
+	if !m.worktreeAvailable(title) {
+		return
+	}
+	if m.showRebaseStatus(nil) {
+		return
+	}
+	commit := m.selectedCommit()
+	if commit == nil {
+		return
+	}
+	branch := m.git.CurrentBranch()
+	if !m.allowed(title, gitops.OpRewrite, branch) {
+		return
+	}
+	onto := commit.Hash.String()
+	upstream := ""
+	if m.mark != nil && m.mark.Hash != commit.Hash {
+		upstream = m.mark.Hash.String()
+	} else {
+		base, err := m.git.MergeBase("HEAD", onto)
+		if err != nil {
+			m.openPopup(title, err.Error())
+			return
+		}
+		upstream = base
+	}
+	replayed, merges, err := m.git.Replayed(upstream)
+	if err != nil {
+		m.openPopup(title, err.Error())
+		return
+	}
+	name := branch
+	if name == "" {
+		name = "detached HEAD"
+	}
+	if upstream == onto {
+		m.openPopup(title, fmt.Sprintf("%s already starts from %s %s.", name, commit.ShortHash(), commit.Subject))
+		return
+	}
+	if len(replayed) == 0 {
+		m.openPopup(title, fmt.Sprintf("%s has no commits after %s to replay.", name, m.short(upstream)),
+			fmt.Sprintf("Mark the last commit to leave behind with %s to choose them.", m.keys.first(actionMark)))
+		return
+	}
+	dirty, err := m.git.DirtyFiles()
+	if err != nil {
+		m.openPopup(title, err.Error())
+		return
+	}
+
+	lines := []string{
+		fmt.Sprintf("Replay %d commits of %s after %s onto %s %s:", len(replayed), name, m.short(upstream), commit.ShortHash(), commit.Subject),
+		"",
+	}
+	for i, line := range replayed {
+		if i == rebasePreview {
+			lines = append(lines, fmt.Sprintf("  … and %d more", len(replayed)-rebasePreview))
+			break
+		}
+		lines = append(lines, "  "+line)
+	}
+	lines = append(lines, "")
+	if merges > 0 {
+		lines = append(lines, fmt.Sprintf("%d merge commits are dropped: rebase flattens them.", merges))
+	}
+	lines = append(lines, fmt.Sprintf("If a commit does not apply, the rebase stops and %s shows where it is; %s undoes it.",
+		m.keys.first(actionRebase), m.keys.first(actionUndo)))
+	git := m.git
+	rebase := func() (string, error) {
+		return git.RebaseOnto(onto, upstream)
+	}
+	if len(dirty) > 0 {
+		lines = append(lines, "", fmt.Sprintf("%d files have uncommitted changes, which git will not rebase over; %s stashes them first.", len(dirty), stashKey))
+		m.openPrompt(title, lines, m.stashAndContinue(title, "rebase", "stash & rebase", rebase))
+		return
+	}
+	m.openPrompt(title, lines, popupAction{key: "y", label: "rebase", run: func() tea.Cmd {
+		return runGitOp(title, rebase)
+	}})
+}
+
+// showRebaseStatus opens the status of a stopped rebase, after output
+// from the command that stopped it, and reports whether one is in
+// progress.
+func (m *model) showRebaseStatus(output []string) bool {
+	const title = "Rebase in progress"
+	if m.git == nil {
+		return false
+	}
+	state, err := m.git.RebaseState()
+	if err != nil || state == nil {
+		return false
+	}
+	lines := append([]string(nil), output...)
+	if len(lines) > 0 {
+		lines = append(lines, "")
+	}
+	name := state.Branch
+	if name == "" {
+		name = "detached HEAD"
+	}
+	lines = append(lines, fmt.Sprintf("Rebasing %s onto %s: commit %d of %d.", name, m.short(state.Onto), state.Step, state.Total))
+	if state.Stopped != "" {
+		lines = append(lines, fmt.Sprintf("Stopped at %s.", m.short(state.Stopped)))
+	}
+	if len(state.Conflicts) > 0 {
+		lines = append(lines, "", "Conflicts:")
+		for _, path := range state.Conflicts {
+			lines = append(lines, "  "+path)
+		}
+		lines = append(lines, "", "Resolve them in your editor and stage them with git add, then continue.")
+	}
+	git := m.git
+	m.openPrompt(title, lines,
+		popupAction{key: "c", label: "continue", run: func() tea.Cmd {
+			return runGitOp("Rebase", git.RebaseContinue)
+		}},
+		popupAction{key: "s", label: "skip commit", run: func() tea.Cmd {
+			return runGitOp("Rebase", git.RebaseSkip)
+		}},
+		popupAction{key: "a", label: "abort", run: func() tea.Cmd {
+			return runGitOp("Rebase", git.RebaseAbort)
+		}})
+	return true
+}
@@ -0,0 +1,83 @@
+package tui
+
+import (
+	"path"
+
+	"arbor/internal/gitgraph"
+
+	tea "github.com/charmbracelet/bubbletea"
+	"github.com/go-git/go-git/v5/plumbing"
+)
+
+type releasesMsg struct {
+	ix       *gitgraph.TagIndex
+	releases map[plumbing.Hash]string
+}
+
+// computeReleases works out which release tag first shipped each commit,
+// in the background, once per tag index.
+func (m *model) computeReleases() tea.Cmd {
+	if m.releaseTags == "" || m.tagIndex == nil || m.releases != nil || m.releasesPending {
+		return nil
+	}
+	m.releasesPending = true
+	ix, pattern := m.tagIndex, m.releaseTags
+	return func() tea.Msg {
+		return releasesMsg{ix: ix, releases: ix.Releases(func(tag string) bool {
+			ok, _ := path.Match(pattern, tag)
+			return ok
+		})}
+	}
+}
+
+// applyReleases keeps the cursor on screen now that separators take up
+// lines. Results for a tag index since replaced are dropped.
+func (m *model) applyReleases(msg releasesMsg) {
+	if msg.ix != m.tagIndex {
+		return
+	}
+	m.releases, m.releasesPending = msg.releases, false
+	m.normalizePosition()
+}
+
+// releaseAt reports the separator above the row at listIndex: the release
+// its commit shipped in, when that differs from the row above, or
+// "unreleased" for commits no release tag contains yet.
+func (m *model) releaseAt(listIndex int) (string, bool) {
+	if m.releases == nil {
+		return "", false
+	}
+	commit := m.commitAt(listIndex)
+	if commit == nil {
+		return "", false
+	}
+	release := m.releases[commit.Hash]
+	if listIndex > 0 {
+		if above := m.commitAt(listIndex - 1); above != nil && m.releases[above.Hash] == release {
+			return "", false
+		}
+	}
+	if release == "" {
+		release = "unreleased"
+	}
+	return release, true
+}
+
+// releaseSeparator draws the separator above the row at listIndex, with the
+// lanes of the row above carried through it.
+func (m *model) releaseSeparator(listIndex int, release string, width int) string {
+	bg := palette.bg
+	prefix := m.rowPadding(bg)
+	var lanes []gitgraph.GraphCell
+	if listIndex > 0 {
+		if above := m.commitAt(listIndex - 1); above != nil {
+			lanes = continueGraph(above, m.commitAt(listIndex))
+		}
+	}
+	if m.graphInline() && lanes != nil {
+		prefix += renderGraph(lanes, bg) + rowSpacerStyle.Background(bg).Render(" ")
+	}
+	rule := rowSeparatorStyle.Foreground(palette.textDim).Background(bg)
+	name := hashStyle.Foreground(palette.accentAlt).Background(bg).Render(sanitizeLine(release))
+	return m.fitRow(prefix+rule.Render("── ")+name+rule.Render(" ──"), lanes, width, bg)
+}
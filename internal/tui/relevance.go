@@ -0,0 +1,49 @@
+package tui
+
+import "sort"
+
+// ranked reports whether the list is in relevance order: a fuzzy filter
+// whose matches are sorted best first.
+func (m *model) ranked() bool {
+	return m.relevance && m.filterFuzzy && m.filter != ""
+}
+
+// sortFiltered puts the matches best first, newest first among equals,
+// or back in history order.
+func (m *model) sortFiltered() {
+	if !m.ranked() {
+		sort.Ints(m.filtered)
+		return
+	}
+	sort.Slice(m.filtered, func(i, j int) bool {
+		a, b := m.filtered[i], m.filtered[j]
+		if m.filterScores[a] != m.filterScores[b] {
+			return m.filterScores[a] > m.filterScores[b]
+		}
+		return a < b
+	})
+}
+
+// toggleSort lists fuzzy matches by relevance or in history order,
+// keeping the selected commit selected.
+func (m *model) toggleSort() {
+	m.relevance = !m.relevance
+	order := "history"
+	if m.relevance {
+		order = "relevance"
+	}
+	m.status = "fuzzy matches by " + order
+	if !m.filterFuzzy || m.filter == "" || m.cursor >= len(m.filtered) {
+		return
+	}
+	selected := m.filtered[m.cursor]
+	m.sortFiltered()
+	for i, index := range m.filtered {
+		if index == selected {
+			m.cursor = i
+			break
+		}
+	}
+	m.centerOn(m.cursor)
+	m.normalizePosition()
+}
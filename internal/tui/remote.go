@@ -0,0 +1,189 @@
+package tui
+
+import (
+	"errors"
+	"fmt"
+	"strings"
+
+	"arbor/internal/gitops"
+
+	tea "github.com/charmbracelet/bubbletea"
+)
+
+// remoteOpMsg is a fetch or push finishing. auth is set when it stopped at
+// a prompt, so the prompt can be asked and op run again with the answer.
+type remoteOpMsg struct {
+	gitOpMsg
+	auth    *gitops.AuthError
+	answers gitops.Answers
+	op      func(gitops.Answers) (string, error)
+	// then, when set, takes over from the result popup once op succeeds.
+	then func()
+}
+
+// runRemoteOp runs op in the background with the answers given so far.
+// Git never prompts on the terminal; an unanswered prompt comes back here.
+func runRemoteOp(title string, answers gitops.Answers, op func(gitops.Answers) (string, error)) tea.Cmd {
+	return func() tea.Msg {
+		out, err := op(answers)
+		msg := remoteOpMsg{gitOpMsg: gitOpMsg{title: title, output: out, err: err}, answers: answers, op: op}
+		errors.As(err, &msg.auth)
+		return msg
+	}
+}
+
+// runRemoteQuery is runRemoteOp for a read that calls then when it
+// succeeds, instead of showing its output.
+func runRemoteQuery(title string, answers gitops.Answers, op func(gitops.Answers) (string, error), then func()) tea.Cmd {
+	return func() tea.Msg {
+		msg := runRemoteOp(title, answers, op)().(remoteOpMsg)
+		msg.then = then
+		return msg
+	}
+}
+
+// applyRemoteOp asks for a missing credential in the bottom bar, hiding
+// what is typed for passphrases and tokens, and retries with it. Answers
+// are kept only as long as the operation.
+func (m *model) applyRemoteOp(msg remoteOpMsg) tea.Cmd {
+	m.status = ""
+	if msg.auth == nil {
+		if msg.then != nil && msg.err == nil {
+			msg.then()
+			return nil
+		}
+		m.applyGitOp(msg.gitOpMsg)
+		return nil
+	}
+	if _, asked := msg.answers[msg.auth.Prompt]; asked {
+		m.applyGitOp(gitOpMsg{title: msg.title, err: fmt.Errorf("%w (the answer was not accepted)", msg.auth)})
+		return nil
+	}
+	m.input = &textInput{label: strings.TrimRight(msg.auth.Error(), ": "), secret: msg.auth.Secret(), submit: func(value string) tea.Cmd {
+		next := gitops.Answers{msg.auth.Prompt: value}
+		for prompt, answer := range msg.answers {
+			next[prompt] = answer
+		}
+		m.status = msg.title + "…"
+		if msg.then != nil {
+			return runRemoteQuery(msg.title, next, msg.op, msg.then)
+		}
+		return runRemoteOp(msg.title, next, msg.op)
+	}}
+	return nil
+}
+
+// startFetch fetches every remote and prunes deleted branches.
+func (m *model) startFetch() tea.Cmd {
+	const title = "Fetch"
+	if !m.worktreeAvailable(title) || !m.allowed(title, gitops.OpDelete, "") {
+		return nil
+	}
+	m.status = "fetching…"
+	git := m.git
+	return runRemoteOp(title, nil, git.Fetch)
+}
+
+// startRemotePrune asks the remotes which branches they have deleted and,
+// after listing the remote-tracking refs that would go and the commits that
+// would leave an --all view with them, deletes those refs.
+func (m *model) startRemotePrune() tea.Cmd {
+	const title = "Prune remote branches"
+	if !m.worktreeAvailable(title) || !m.allowed(title, gitops.OpDelete, "") {
+		return nil
+	}
+	m.status = "checking remotes…"
+	git := m.git
+	// find fills these in the background, counting what leaves the
+	// --all view there too, as that walks the history.
+	var (
+		stale    []gitops.StaleRef
+		count    int
+		countErr error
+	)
+	find := func(answers gitops.Answers) (string, error) {
+		refs, err := git.StaleRemoteRefs(answers)
+		if err != nil || len(refs) == 0 {
+			return "", err
+		}
+		stale = refs
+		count, countErr = git.OnlyReachableFrom(refs)
+		return "", nil
+	}
+	return runRemoteQuery(title, nil, find, func() {
+		if len(stale) == 0 {
+			m.openPopup(title, "Every remote-tracking branch still exists on its remote.")
+			return
+		}
+		lines := []string{fmt.Sprintf("%d remote-tracking branches were deleted on their remote:", len(stale)), ""}
+		for _, ref := range stale {
+			lines = append(lines, fmt.Sprintf("%s  %s %s", m.remoteLabel(ref.Short()), m.short(ref.Hash), sanitizeLine(ref.Subject)))
+		}
+		lines = append(lines, "")
+		if countErr == nil && count > 0 {
+			lines = append(lines, fmt.Sprintf("%d commits only they reach leave the --all view.", count))
+		} else if countErr == nil {
+			lines = append(lines, "Their commits stay reachable from other refs.")
+		}
+		lines = append(lines, fmt.Sprintf("Local branches tracking them are kept; %s lists them afterwards.", m.keys.first(actionPrune)))
+		m.openPrompt(title, lines, popupAction{key: "enter", label: "prune", run: func() tea.Cmd {
+			return runGitOp(title, func() (string, error) {
+				return git.DeleteRemoteRefs(stale)
+			})
+		}})
+	})
+}
+
+// startPush pushes the current branch to its upstream, or to the first
+// remote under the same name when it has none, after showing what goes.
+func (m *model) startPush() {
+	const title = "Push"
+	if !m.worktreeAvailable(title) {
+		return
+	}
+	branch := m.git.CurrentBranch()
+	if branch == "" {
+		m.openPopup(title, "HEAD is detached; check out a branch to push it.")
+		return
+	}
+	if !m.allowed(title, gitops.OpCommit, branch) {
+		return
+	}
+	remote, merge := m.git.Upstream(branch)
+	lines := []string{}
+	if remote == "" {
+		remotes, err := m.git.Remotes()
+		if err != nil || len(remotes) == 0 {
+			m.openPopup(title, "No remotes configured.")
+			return
+		}
+		remote, merge = remotes[0], branch
+		for _, name := range remotes {
+			if name == "origin" {
+				remote = name
+			}
+		}
+		lines = append(lines, fmt.Sprintf("Push %s to %s and track it.", branch, m.remoteLabel(remote+"/"+merge)))
+	} else {
+		count, err := m.git.CountCommits(remote + "/" + merge + ".." + branch)
+		if err != nil {
+			count = -1
+		}
+		switch {
+		case count == 0:
+			lines = append(lines, fmt.Sprintf("%s already has everything on %s, as of the last fetch.", m.remoteLabel(remote+"/"+merge), branch))
+		case count > 0:
+			lines = append(lines, fmt.Sprintf("Push %d commits from %s to %s.", count, branch, m.remoteLabel(remote+"/"+merge)))
+		default:
+			lines = append(lines, fmt.Sprintf("Push %s to %s.", branch, m.remoteLabel(remote+"/"+merge)))
+		}
+	}
+	lines = append(lines, "Rejected if the remote has moved on; arbor never forces.")
+	git := m.git
+	m.openPrompt(title, lines, popupAction{key: "enter", label: "push", run: func() tea.Cmd {
+		m.status = "pushing…"
+		return runRemoteOp(title, nil, func(answers gitops.Answers) (string, error) {
+			return git.Push(branch, remote, merge, answers)
+		})
+	}})
+}
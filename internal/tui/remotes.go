@@ -0,0 +1,189 @@
+package tui
+
+import (
+	"fmt"
+	"strings"
+
+	"arbor/internal/gitgraph"
+
+	tea "github.com/charmbracelet/bubbletea"
+	"github.com/charmbracelet/lipgloss"
+)
+
+const remotesTitle = "Remotes"
+
+type remotesMsg struct {
+	view     *remoteView
+	sections []remoteSection
+	err      error
+}
+
+// remoteView compares where every remote has the default branch, and the
+// current branch when that is another one, with the local branch.
+type remoteView struct {
+	sections []remoteSection
+	err      error
+	loaded   bool
+	cursor   int
+}
+
+type remoteSection struct {
+	branch string
+	tips   []gitgraph.RemoteTip
+}
+
+// remoteMarkers is the header's compact summary of the current branch on
+// each remote, as in "origin/main ✓ upstream/main ↑3". It is left out with
+// fewer than two remotes, where the branch table already says as much.
+func (m *model) remoteMarkers() string {
+	if len(m.remoteTips) < 2 {
+		return ""
+	}
+	parts := make([]string, 0, len(m.remoteTips))
+	for _, tip := range m.remoteTips {
+		parts = append(parts, m.remoteLabel(tip.Name())+" "+tip.Marker())
+	}
+	return strings.Join(parts, " ")
+}
+
+// remoteLabel shows a remote, or a remote-tracking branch by its short
+// name ("origin/main"), under the remote's configured alias, if any.
+// Remote names may have slashes, so the longest that fits wins.
+func (m *model) remoteLabel(name string) string {
+	best := ""
+	for remote := range m.remoteAliases {
+		if (name == remote || strings.HasPrefix(name, remote+"/")) && len(remote) > len(best) {
+			best = remote
+		}
+	}
+	if best == "" {
+		return name
+	}
+	return m.remoteAliases[best] + name[len(best):]
+}
+
+// refLabel is ref's short name, under its remote's alias for a
+// remote-tracking branch.
+func (m *model) refLabel(ref gitgraph.Ref) string {
+	if ref.Kind == gitgraph.RefRemote {
+		return m.remoteLabel(ref.Short)
+	}
+	return ref.Short
+}
+
+func (m *model) openRemotes() tea.Cmd {
+	view := &remoteView{}
+	m.remotes = view
+	repo, gens, head := m.provider.Repo(), m.provider.Generations(), m.headName
+	return func() tea.Msg {
+		var branches []string
+		if base, _, ok := gitgraph.DefaultBranch(repo); ok {
+			branches = append(branches, base)
+		}
+		if !strings.HasPrefix(head, "detached") && head != "" && (len(branches) == 0 || branches[0] != head) {
+			branches = append(branches, head)
+		}
+		msg := remotesMsg{view: view}
+		for _, branch := range branches {
+			tips, err := gitgraph.RemoteTips(repo, gens, branch)
+			if err != nil {
+				msg.err = err
+				break
+			}
+			msg.sections = append(msg.sections, remoteSection{branch: branch, tips: tips})
+		}
+		return msg
+	}
+}
+
+func (m *model) applyRemotes(msg remotesMsg) {
+	msg.view.sections, msg.view.err, msg.view.loaded = msg.sections, msg.err, true
+}
+
+func (v *remoteView) tips() []gitgraph.RemoteTip {
+	var tips []gitgraph.RemoteTip
+	for _, section := range v.sections {
+		tips = append(tips, section.tips...)
+	}
+	return tips
+}
+
+func (m *model) handleRemotesKey(msg tea.KeyMsg) (tea.Model, tea.Cmd) {
+	v := m.remotes
+	tips := v.tips()
+	switch msg.String() {
+	case "ctrl+c":
+		return m, tea.Quit
+	case "esc", "q":
+		m.remotes = nil
+	case "up", "k":
+		v.cursor = max(0, v.cursor-1)
+	case "down", "j":
+		v.cursor = min(max(0, len(tips)-1), v.cursor+1)
+	case "enter":
+		if v.cursor >= len(tips) {
+			return m, nil
+		}
+		tip := tips[v.cursor]
+		m.remotes = nil
+		if !m.selectHash(tip.Hash) {
+			m.openPopup(remotesTitle, fmt.Sprintf("%s is not part of the current graph; press %s for all branches.", m.remoteLabel(tip.Name()), m.keys.first(actionScope)))
+		}
+		return m, m.loadVisible()
+	}
+	return m, nil
+}
+
+func (m *model) renderRemotes(width, height int) string {
+	v := m.remotes
+	boxWidth := min(max(30, width*4/5), max(1, width-4))
+	lines := []string{popupTitleStyle.Render(remotesTitle + " compared with local branches"), ""}
+
+	tips := v.tips()
+	switch {
+	case v.err != nil:
+		lines = append(lines, v.err.Error())
+	case !v.loaded:
+		lines = append(lines, "Comparing remote branches…")
+	case len(tips) == 0:
+		lines = append(lines, "No remote has the default or the current branch; fetch first?")
+	default:
+		nameWidth, markerWidth := len("REMOTE"), 0
+		for _, section := range v.sections {
+			markerWidth = max(markerWidth, len("LOCAL "+section.branch))
+		}
+		for _, tip := range tips {
+			nameWidth = max(nameWidth, len(m.remoteLabel(tip.Name())))
+		}
+		nameWidth = min(nameWidth, max(8, boxWidth/3))
+		row := func(name, marker, tip string) string {
+			return truncateText(fmt.Sprintf("%-*s  %-*s  %s", nameWidth, truncateText(name, nameWidth), markerWidth, marker, tip), boxWidth-4)
+		}
+		i := 0
+		for _, section := range v.sections {
+			if len(section.tips) == 0 {
+				continue
+			}
+			if i > 0 {
+				lines = append(lines, "")
+			}
+			lines = append(lines, popupHintStyle.Render(row("REMOTE", "LOCAL "+section.branch, "TIP")))
+			for _, tip := range section.tips {
+				tipLabel := m.short(tip.Hash.String())
+				if commit, err := m.provider.Repo().CommitObject(tip.Hash); err == nil {
+					tipLabel += " " + commit.Committer.When.Format("2006-01-02") + " " + gitgraph.FirstLine(commit.Message)
+				}
+				line := row(m.remoteLabel(tip.Name()), tip.Marker(), tipLabel)
+				if i == v.cursor {
+					line = listCursorStyle.Width(boxWidth - 2).Render(line)
+				}
+				lines = append(lines, line)
+				i++
+			}
+		}
+	}
+	lines = append(lines, "", popupHintStyle.Render("↑ local commits the remote lacks | ↓ remote commits not local | enter jump | esc close"))
+	box := popupStyle.Width(boxWidth).Render(strings.Join(lines, "\n"))
+	return lipgloss.Place(width, height, lipgloss.Center, lipgloss.Center, box,
+		lipgloss.WithWhitespaceBackground(palette.bg))
+}
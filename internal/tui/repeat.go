@@ -0,0 +1,44 @@
+package tui
+
+import "time"
+
+// repeatGap is the longest pause between key events that still counts as
+// the key being held; terminals repeat every 30-50ms.
+const repeatGap = 150 * time.Millisecond
+
+// repeatSteps is how many rows one repeat of a held up or down key moves,
+// by how long the key has been held.
+var repeatSteps = []struct {
+	held time.Duration
+	step int
+}{
+	{3 * time.Second, 50},
+	{1500 * time.Millisecond, 10},
+	{500 * time.Millisecond, 3},
+}
+
+// keyRepeat tracks the navigation key being held, if any.
+type keyRepeat struct {
+	action      string
+	start, last time.Time
+}
+
+// repeatStep is how far one press of action moves at now: one row, or
+// more the longer the key has been held, unless acceleration is off.
+func (m *model) repeatStep(action string, now time.Time) int {
+	r := &m.repeat
+	if r.action != action || now.Sub(r.last) > repeatGap {
+		r.action, r.start = action, now
+	}
+	r.last = now
+	if !m.accelerate {
+		return 1
+	}
+	held := now.Sub(r.start)
+	for _, s := range repeatSteps {
+		if held >= s.held {
+			return s.step
+		}
+	}
+	return 1
+}
@@ -0,0 +1,110 @@
+package tui
+
+import (
+	"fmt"
+
+	"arbor/internal/gitops"
+
+	tea "github.com/charmbracelet/bubbletea"
+)
+
+var resetModeHelp = map[string]string{
+	"soft":  "keep the index and working tree; the undone commits show as staged",
+	"mixed": "keep the working tree and unstage everything",
+	"hard":  "make the index and working tree match the target",
+}
+
+// startReset moves the current branch to the selected commit, after showing
+// what that drops and asking for the mode.
+func (m *model) startReset() {
+	const title = "Reset"
+	commit := m.selectedCommit()
+	if commit == nil || !m.worktreeAvailable(title) {
+		return
+	}
+	branch := m.git.CurrentBranch()
+	if !m.allowed(title, gitops.OpRewrite, branch) {
+		return
+	}
+	ref, err := m.provider.Repo().Head()
+	if err != nil {
+		m.openPopup(title, err.Error())
+		return
+	}
+	from, to := ref.Hash().String(), commit.Hash.String()
+	if from == to {
+		m.openPopup(title, fmt.Sprintf("HEAD is already at %s.", commit.ShortHash()))
+		return
+	}
+	removed, err := m.git.CountCommits(to + ".." + from)
+	if err != nil {
+		m.openPopup(title, err.Error())
+		return
+	}
+	added, err := m.git.CountCommits(from + ".." + to)
+	if err != nil {
+		m.openPopup(title, err.Error())
+		return
+	}
+	dirty, err := m.git.DirtyFiles()
+	if err != nil {
+		m.openPopup(title, err.Error())
+		return
+	}
+	name := branch
+	if name == "" {
+		name = "detached HEAD"
+	}
+	lines := []string{
+		fmt.Sprintf("Move %s from %s to %s %s", name, m.short(from), commit.ShortHash(), commit.Subject),
+		"",
+		fmt.Sprintf("%d commits will no longer be on %s; %s undoes the reset.", removed, name, m.keys.first(actionUndo)),
+	}
+	if added > 0 {
+		lines = append(lines, fmt.Sprintf("%d commits not on %s come along: the target is on another line of history.", added, name))
+	}
+	if len(dirty) == 0 {
+		lines = append(lines, "The working tree is clean.")
+	} else {
+		lines = append(lines, fmt.Sprintf("%d files have uncommitted changes; hard discards them.", len(dirty)))
+	}
+	lines = append(lines, "")
+	actions := make([]popupAction, 0, len(gitops.ResetModes)+1)
+	for _, mode := range gitops.ResetModes {
+		lines = append(lines, mode+": "+resetModeHelp[mode])
+		actions = append(actions, popupAction{key: mode[:1], label: mode, run: func() tea.Cmd {
+			return m.confirmReset(title, to, mode, len(dirty))
+		}})
+	}
+	if len(dirty) > 0 {
+		lines = append(lines, stashKey+": stash the changes, then reset hard")
+		git := m.git
+		actions = append(actions, m.stashAndContinue(title, "reset", "stash & reset hard", func() (string, error) {
+			return git.Reset(to, "hard")
+		}))
+	}
+	m.openPrompt(title, lines, actions...)
+}
+
+// confirmReset runs reset, asking once more when a hard reset would
+// discard uncommitted changes.
+func (m *model) confirmReset(title, target, mode string, dirty int) tea.Cmd {
+	git, short := m.git, m.short(target)
+	run := func() tea.Cmd {
+		return runGitOp(title, func() (string, error) {
+			out, err := git.Reset(target, mode)
+			if err == nil && out == "" {
+				out = fmt.Sprintf("Reset (%s) to %s.", mode, short)
+			}
+			return out, err
+		})
+	}
+	if mode != "hard" || dirty == 0 {
+		return run()
+	}
+	m.openPrompt(title, []string{
+		fmt.Sprintf("Discard the uncommitted changes in %d files?", dirty),
+		fmt.Sprintf("They are saved under %s first, and %s restores them.", gitops.JournalRefPrefix, m.keys.first(actionUndo)),
+	}, popupAction{key: "y", label: "discard and reset", run: run})
+	return nil
+}
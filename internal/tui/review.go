@@ -0,0 +1,80 @@
+package tui
+
+import (
+	"fmt"
+
+	"arbor/internal/config"
+	"arbor/internal/gitgraph"
+	"arbor/internal/review"
+
+	tea "github.com/charmbracelet/bubbletea"
+	"github.com/charmbracelet/lipgloss"
+)
+
+// NewReviewModel is the TUI for `arbor review`: provider lists the commits
+// under review, and the review key checks them off.
+func NewReviewModel(path string, provider *gitgraph.CommitProvider, headName string, cfg *config.Config, session *review.Session) tea.Model {
+	m := NewModel(path, provider, headName, cfg).(*model)
+	m.review = session
+	return m
+}
+
+func (m *model) toggleReviewed() {
+	if m.review == nil {
+		m.status = "checking off commits needs `arbor review <base>`"
+		return
+	}
+	commit := m.selectedCommit()
+	if commit == nil {
+		return
+	}
+	id, ok := m.review.PatchIDs[commit.Hash]
+	if !ok {
+		return
+	}
+	reviewed := m.review.State.Toggle(id, commit.Hash)
+	if err := m.review.State.Save(); err != nil {
+		m.status = "review: " + err.Error()
+		return
+	}
+	done, total := m.review.Progress()
+	if done == total {
+		m.status = "every commit is reviewed"
+		return
+	}
+	if reviewed {
+		m.moveCursor(1)
+	}
+}
+
+// openCombinedDiff shows everything the branch changes since it left the
+// base, as a pull request would.
+func (m *model) openCombinedDiff() tea.Cmd {
+	if m.review == nil {
+		m.status = "the combined diff needs `arbor review <base>`"
+		return nil
+	}
+	changes, err := gitgraph.TreeChanges(m.review.MergeBase, m.review.Tip)
+	if err != nil {
+		m.openPopup("Combined diff", err.Error())
+		return nil
+	}
+	title := fmt.Sprintf("Combined diff: %s...%s", m.review.State.Base, m.review.State.Head)
+	return m.openDiffPager(title, gitgraph.FileDiffs(m.provider.Repo().Storer, changes))
+}
+
+func (m *model) reviewBadge(commit *gitgraph.CommitInfo, bg lipgloss.TerminalColor) string {
+	if m.review == nil {
+		return ""
+	}
+	space := rowSpacerStyle.Background(bg).Render(" ")
+	if m.review.IsReviewed(commit.Hash) {
+		return markStyle.Background(bg).Render("✓") + space
+	}
+	return emptyStyle.Background(bg).Render("○") + space
+}
+
+func (m *model) reviewHeader() string {
+	done, total := m.review.Progress()
+	return fmt.Sprintf("review %s %d/%d", m.review.State.Base, done, total)
+}
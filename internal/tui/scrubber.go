@@ -0,0 +1,152 @@
+package tui
+
+import (
+	"fmt"
+	"strings"
+	"time"
+
+	tea "github.com/charmbracelet/bubbletea"
+	"github.com/charmbracelet/lipgloss"
+)
+
+const day = 24 * time.Hour
+
+type scrubber struct {
+	target     time.Time
+	origCursor int
+	origOffset int
+}
+
+func (m *model) openScrubber() {
+	commit := m.selectedCommit()
+	if commit == nil {
+		return
+	}
+	m.scrubber = &scrubber{target: commit.When, origCursor: m.cursor, origOffset: m.offset}
+}
+
+func (m *model) handleScrubberKey(msg tea.KeyMsg) (tea.Model, tea.Cmd) {
+	s := m.scrubber
+	if m.keys.action(msg.String()) == actionScrubber {
+		m.scrubber = nil
+		return m, nil
+	}
+	switch msg.String() {
+	case "ctrl+c":
+		return m, tea.Quit
+	case "esc":
+		m.cursor, m.offset = s.origCursor, s.origOffset
+		m.scrubber = nil
+		return m, nil
+	case "enter":
+		m.scrubber = nil
+		return m, nil
+	case "left", "h":
+		m.scrubTo(s.target.Add(-day))
+	case "right", "l":
+		m.scrubTo(s.target.Add(day))
+	case "H":
+		m.scrubTo(s.target.Add(-7 * day))
+	case "L":
+		m.scrubTo(s.target.Add(7 * day))
+	case "[":
+		m.scrubTo(s.target.AddDate(0, -1, 0))
+	case "]":
+		m.scrubTo(s.target.AddDate(0, 1, 0))
+	}
+	return m, nil
+}
+
+// scrubTo selects the commit closest in time to t, loading older history
+// until the list reaches past t or runs out.
+func (m *model) scrubTo(t time.Time) {
+	newest, _ := m.timeBounds()
+	if t.After(newest) {
+		t = newest
+	}
+	m.scrubber.target = t
+	for m.provider.HasMore() {
+		last := m.commitAt(m.listLength() - 1)
+		if last != nil && last.When.Before(t) {
+			break
+		}
+		_ = m.provider.Ensure(len(m.provider.Commits) + 100)
+		m.refreshFilter()
+	}
+	if _, oldest := m.timeBounds(); !m.provider.HasMore() && t.Before(oldest) {
+		m.scrubber.target = oldest
+	}
+	best, bestDist := -1, time.Duration(0)
+	for i := 0; i < m.listLength(); i++ {
+		commit := m.commitAt(i)
+		if commit == nil {
+			continue
+		}
+		dist := commit.When.Sub(t)
+		if dist < 0 {
+			dist = -dist
+		}
+		if best == -1 || dist < bestDist {
+			best, bestDist = i, dist
+		}
+	}
+	if best == -1 {
+		return
+	}
+	m.cursor = best
+	m.centerOn(best)
+	m.normalizePosition()
+}
+
+func (m *model) timeBounds() (time.Time, time.Time) {
+	var newest, oldest time.Time
+	for i := 0; i < m.listLength(); i++ {
+		commit := m.commitAt(i)
+		if commit == nil {
+			continue
+		}
+		if newest.IsZero() || commit.When.After(newest) {
+			newest = commit.When
+		}
+		if oldest.IsZero() || commit.When.Before(oldest) {
+			oldest = commit.When
+		}
+	}
+	return newest, oldest
+}
+
+func (m *model) scrubberView(width int) string {
+	if width <= 0 {
+		width = m.width
+	}
+	newest, oldest := m.timeBounds()
+	left := oldest.Format("2006-01-02")
+	if m.provider.HasMore() {
+		left = "…" + left
+	}
+	right := newest.Format("2006-01-02")
+	label := scrubberLabelStyle.Render(m.scrubber.target.Format("Mon 2006-01-02"))
+	hints := scrubberHintStyle.Render("h/l day  H/L week  [/] month  enter keep  esc back")
+
+	barWidth := width - 2 - lipgloss.Width(left) - lipgloss.Width(right) - lipgloss.Width(label) - lipgloss.Width(hints) - 6
+	if barWidth < 10 {
+		hints = ""
+		barWidth = width - 2 - lipgloss.Width(left) - lipgloss.Width(right) - lipgloss.Width(label) - 4
+	}
+	bar := ""
+	if barWidth > 0 {
+		pos := barWidth - 1
+		if span := newest.Sub(oldest); span > 0 {
+			pos = int(float64(barWidth-1) * float64(m.scrubber.target.Sub(oldest)) / float64(span))
+		}
+		pos = clamp(pos, 0, barWidth-1)
+		bar = scrubberTrackStyle.Render(strings.Repeat("━", pos)) +
+			scrubberKnobStyle.Render("●") +
+			scrubberTrackStyle.Render(strings.Repeat("━", barWidth-1-pos))
+	}
+	line := fmt.Sprintf("%s %s %s  %s", left, bar, right, label)
+	if hints != "" {
+		line += "  " + hints
+	}
+	return searchStyle.Width(width).Render(line)
+}
@@ -0,0 +1,152 @@
+package tui
+
+import (
+	"fmt"
+	"strings"
+
+	tea "github.com/charmbracelet/bubbletea"
+	"github.com/charmbracelet/lipgloss"
+)
+
+// sidebarWidth is 0 when the sidebar is hidden or the terminal is too
+// narrow for it.
+func (m *model) sidebarWidth() int {
+	if !m.showSidebar || m.width < 60 {
+		return 0
+	}
+	return max(30, m.width/3)
+}
+
+// sidebarContent wraps the sidebar to its panel so scrolling counts screen
+// lines rather than logical ones.
+func (m *model) sidebarContent(width int) []string {
+	commit := m.selectedCommit()
+	if commit == nil {
+		return []string{"No commit selected"}
+	}
+	text := strings.Join(m.sidebarLines(commit, width), "\n")
+	return strings.Split(lipgloss.NewStyle().Width(max(1, width-2)).Render(text), "\n")
+}
+
+// sidebarRows is how many content lines fit inside the border.
+func (m *model) sidebarRows() int {
+	return max(1, m.viewportHeight()-2)
+}
+
+// scrollOffset is the scroll position for the selected commit; a new
+// selection starts at the top.
+func (m *model) scrollOffset() int {
+	if commit := m.selectedCommit(); commit == nil || commit.Hash != m.sidebarHash {
+		return 0
+	}
+	return m.sidebarScroll
+}
+
+func (m *model) renderSidebar(width int) string {
+	lines := m.sidebarContent(width)
+	rows := m.sidebarRows()
+	style := sidebarStyle
+	if m.sidebarFocus {
+		style = style.BorderForeground(palette.accent)
+	}
+	if len(lines) <= rows {
+		return style.Width(width).Render(strings.Join(lines, "\n"))
+	}
+
+	// Indicators take a content row each, so the last page starts one
+	// line later than it would without them.
+	scroll := clamp(m.scrollOffset(), 0, len(lines)-rows+1)
+	body := rows
+	if scroll > 0 {
+		body--
+	}
+	below := len(lines) - scroll - body
+	if below > 0 {
+		body--
+		below++
+	}
+	visible := make([]string, 0, rows)
+	if scroll > 0 {
+		visible = append(visible, sidebarHintStyle.Render(fmt.Sprintf("▲ %d more", scroll)))
+	}
+	visible = append(visible, lines[scroll:scroll+body]...)
+	if below > 0 {
+		hint := fmt.Sprintf("▼ %d more", below)
+		if key := m.keys.first(actionFocus); key != "" && !m.sidebarFocus {
+			if long := fmt.Sprintf("%s (%s to scroll)", hint, key); lipgloss.Width(long) <= width-2 {
+				hint = long
+			}
+		}
+		visible = append(visible, sidebarHintStyle.Render(hint))
+	}
+	return style.Width(width).Render(strings.Join(visible, "\n"))
+}
+
+// focusSidebar moves focus from the list to the sidebar, then to its changed
+// files when they are shown, then back.
+func (m *model) focusSidebar() {
+	if m.sidebarFocus {
+		m.filesFocus = !m.filesFocus && m.showFiles
+		m.sidebarFocus = m.filesFocus
+		if m.filesFocus {
+			m.scrollToFile()
+		}
+		return
+	}
+	m.showSidebar = true
+	if m.sidebarWidth() == 0 {
+		m.status = "the sidebar needs a wider terminal"
+		return
+	}
+	m.sidebarFocus = true
+}
+
+// handleSidebarKey scrolls the focused sidebar and picks trailer chips and
+// changed files. Keys it does not use fall through to the list, so
+// quitting, toggling files, and so on still work.
+func (m *model) handleSidebarKey(msg tea.KeyMsg) (bool, tea.Cmd) {
+	if m.sidebarWidth() == 0 {
+		m.sidebarFocus, m.filesFocus = false, false
+		return false, nil
+	}
+	rows := m.sidebarRows()
+	delta := 0
+	switch msg.String() {
+	case "esc":
+		m.sidebarFocus, m.filesFocus = false, false
+		return true, nil
+	case "pgup":
+		delta = -rows
+	case "pgdown":
+		delta = rows
+	case "home":
+		delta = -len(m.sidebarContent(m.sidebarWidth()))
+	case "end":
+		delta = len(m.sidebarContent(m.sidebarWidth()))
+	case "left", "h", "right", "l", "enter":
+		return m.pickChip(msg.String()), nil
+	default:
+		if handled, cmd := m.handleFileKey(msg.String()); handled {
+			return true, cmd
+		}
+		switch m.keys.action(msg.String()) {
+		case actionUp:
+			delta = -1
+		case actionDown:
+			delta = 1
+		default:
+			return false, nil
+		}
+	}
+	commit := m.selectedCommit()
+	if commit == nil {
+		return true, nil
+	}
+	maxScroll := 0
+	if lines := len(m.sidebarContent(m.sidebarWidth())); lines > rows {
+		maxScroll = lines - rows + 1
+	}
+	m.sidebarScroll = clamp(m.scrollOffset()+delta, 0, maxScroll)
+	m.sidebarHash = commit.Hash
+	return true, nil
+}
@@ -0,0 +1,68 @@
+package tui
+
+import (
+	"slices"
+
+	"arbor/internal/gitgraph"
+
+	tea "github.com/charmbracelet/bubbletea"
+	"github.com/charmbracelet/lipgloss"
+	"github.com/go-git/go-git/v5/plumbing"
+)
+
+type significanceMsg struct {
+	results map[plumbing.Hash][]string
+}
+
+// significanceVisible matches the rows on screen against the significance
+// rules; path rules diff the commit, so this runs off the UI goroutine.
+func (m *model) significanceVisible() tea.Cmd {
+	if len(m.significanceRules) == 0 {
+		return nil
+	}
+	var pending []*gitgraph.CommitInfo
+	for _, commit := range m.visibleCommits() {
+		if _, done := m.significance[commit.Hash]; done || m.significancePending[commit.Hash] {
+			continue
+		}
+		m.significancePending[commit.Hash] = true
+		pending = append(pending, commit)
+	}
+	if len(pending) == 0 {
+		return nil
+	}
+	rules := m.significanceRules
+	return func() tea.Msg {
+		results := make(map[plumbing.Hash][]string, len(pending))
+		for _, commit := range pending {
+			names, err := gitgraph.Significance(commit.Commit, rules)
+			if err != nil {
+				names = nil
+			}
+			results[commit.Hash] = names
+		}
+		return significanceMsg{results: results}
+	}
+}
+
+func (m *model) applySignificance(msg significanceMsg) {
+	for hash, names := range msg.results {
+		m.significance[hash] = names
+		delete(m.significancePending, hash)
+	}
+}
+
+// significanceCell gives every rule its own slot, so a symbol stays in the
+// same place from row to row like a graph lane.
+func (m *model) significanceCell(commit *gitgraph.CommitInfo, bg lipgloss.TerminalColor) string {
+	names := m.significance[commit.Hash]
+	cell := ""
+	for _, rule := range m.significanceRules {
+		symbol := " "
+		if slices.Contains(names, rule.Name) {
+			symbol = rule.Symbol
+		}
+		cell += markStyle.Background(bg).Render(symbol)
+	}
+	return cell
+}
@@ -0,0 +1,159 @@
+package tui
+
+import (
+	"errors"
+	"fmt"
+	"strings"
+
+	"arbor/internal/gitgraph"
+	"arbor/internal/gitops"
+
+	tea "github.com/charmbracelet/bubbletea"
+	"github.com/go-git/go-git/v5/plumbing/object"
+)
+
+// squashLimit bounds the first-parent walks that check a squash range.
+const squashLimit = 1000
+
+// squashPlan is a contiguous run of commits to fold into one, newest first,
+// and the commits between it and HEAD that the rebase rewrites.
+type squashPlan struct {
+	commits []*object.Commit
+	above   []*object.Commit
+}
+
+func (p squashPlan) oldest() *object.Commit { return p.commits[len(p.commits)-1] }
+func (p squashPlan) newest() *object.Commit { return p.commits[0] }
+
+// startSquash folds the range between the marked and the selected commit
+// into one: it asks for the message, previews the resulting history, and
+// only then rewrites.
+func (m *model) startSquash() tea.Cmd {
+	const title = "Squash"
+	commit := m.selectedCommit()
+	if commit == nil || !m.worktreeAvailable(title) || !m.allowed(title, gitops.OpRewrite, m.git.CurrentBranch()) {
+		return nil
+	}
+	if m.mark == nil || m.mark.Hash == commit.Hash {
+		m.openPopup(title, fmt.Sprintf("Mark one end of the range with %s, then select the other end and press %s.",
+			m.keys.first(actionMark), m.keys.first(actionSquash)))
+		return nil
+	}
+	plan, err := m.planSquash(m.mark.Commit, commit.Commit)
+	if err != nil {
+		m.openPopup(title, err.Error())
+		return nil
+	}
+	value := gitgraph.FirstLine(plan.oldest().Message)
+	if m.useEditor {
+		messages := make([]string, 0, len(plan.commits))
+		for i := len(plan.commits) - 1; i >= 0; i-- {
+			messages = append(messages, strings.TrimSpace(plan.commits[i].Message))
+		}
+		value = strings.Join(messages, "\n\n")
+	}
+	label := fmt.Sprintf("Squash %d commits", len(plan.commits))
+	return m.openCommitInput(label, value, func(message string) tea.Cmd {
+		if strings.TrimSpace(message) == "" {
+			return nil
+		}
+		m.previewSquash(title, plan, message)
+		return nil
+	})
+}
+
+// planSquash orders the two ends and checks that the run between them is a
+// linear stretch of the current branch's first-parent history.
+func (m *model) planSquash(a, b *object.Commit) (squashPlan, error) {
+	var plan squashPlan
+	// Walked commits keep only their subjects; the message editor and the
+	// preview need whole ones.
+	repo := m.provider.Repo()
+	a, err := repo.CommitObject(a.Hash)
+	if err != nil {
+		return plan, err
+	}
+	if b, err = repo.CommitObject(b.Hash); err != nil {
+		return plan, err
+	}
+	commits, err := gitgraph.FirstParentPath(a, b, squashLimit)
+	if errors.Is(err, gitgraph.ErrNotOnPath) {
+		commits, err = gitgraph.FirstParentPath(b, a, squashLimit)
+	}
+	if errors.Is(err, gitgraph.ErrNotOnPath) {
+		return plan, errors.New("the marked and selected commits are not on one first-parent line; only a contiguous run can be squashed")
+	}
+	if err != nil {
+		return plan, err
+	}
+	for _, c := range commits {
+		if c.NumParents() > 1 {
+			return plan, fmt.Errorf("%s is a merge; ranges with merges cannot be squashed", m.short(c.Hash.String()))
+		}
+	}
+	ref, err := m.provider.Repo().Head()
+	if err != nil {
+		return plan, err
+	}
+	head, err := m.provider.Repo().CommitObject(ref.Hash())
+	if err != nil {
+		return plan, err
+	}
+	above, err := gitgraph.FirstParentPath(head, commits[0], squashLimit)
+	if errors.Is(err, gitgraph.ErrNotOnPath) {
+		return plan, fmt.Errorf("%s is not in the first-parent history of HEAD; check out the branch that holds the range first", m.short(commits[0].Hash.String()))
+	}
+	if err != nil {
+		return plan, err
+	}
+	return squashPlan{commits: commits, above: above[:len(above)-1]}, nil
+}
+
+// previewSquash shows the history as it will look after the squash and asks
+// for confirmation.
+func (m *model) previewSquash(title string, plan squashPlan, message string) {
+	short := func(c *object.Commit) string {
+		return m.short(c.Hash.String()) + " " + gitgraph.FirstLine(c.Message)
+	}
+	lines := []string{"Resulting history, newest first:", ""}
+	const shown = 8
+	for i, c := range plan.above {
+		if i == shown {
+			lines = append(lines, fmt.Sprintf("~ … %d more", len(plan.above)-shown))
+			break
+		}
+		lines = append(lines, "~ "+short(c))
+	}
+	lines = append(lines, "● "+gitgraph.FirstLine(message))
+	for _, c := range plan.commits {
+		lines = append(lines, "┆ "+short(c))
+	}
+	oldest := plan.oldest()
+	hasParent := oldest.NumParents() > 0
+	if hasParent {
+		if parent, err := oldest.Parent(0); err == nil {
+			lines = append(lines, "· "+short(parent))
+		}
+	} else {
+		lines = append(lines, "· (root)")
+	}
+	lines = append(lines, "",
+		fmt.Sprintf("● replaces %d commits, keeping %s as author.", len(plan.commits), oldest.Author.Name))
+	if len(plan.above) > 0 {
+		lines = append(lines, fmt.Sprintf("~ %d later commits are rebased onto it.", len(plan.above)))
+	}
+	git := m.committer()
+	newest, base := plan.newest().Hash.String(), oldest.Hash.String()
+	m.openPrompt(title, lines, popupAction{key: "enter", label: "squash", run: func() tea.Cmd {
+		return runGitOp(title, func() (string, error) {
+			out, err := git.SquashRange(base, newest, message, hasParent)
+			if err != nil {
+				return out, fmt.Errorf("%w\n%s", err, rebaseAdvice)
+			}
+			if out == "" {
+				out = "Squash complete."
+			}
+			return out, nil
+		})
+	}})
+}
@@ -0,0 +1,302 @@
+package tui
+
+import (
+	"fmt"
+	"slices"
+	"strings"
+
+	"arbor/internal/gitops"
+
+	tea "github.com/charmbracelet/bubbletea"
+	"github.com/charmbracelet/lipgloss"
+)
+
+const stageTitle = "Working tree"
+
+// stageView stages the working tree's changes to tracked files hunk by
+// hunk, or with staged, unstages the index's.
+type stageView struct {
+	staged bool
+	files  []gitops.FilePatch
+	err    error
+	// loading is set while the diff is read, or a change staged, in the
+	// background; keys that change the index wait for it.
+	loading bool
+	// pieces are the hunks the user split, by pieceKey, so a hunk that
+	// still holds some of them after a reload comes back split.
+	pieces map[string]bool
+	// cursor is the picked item, counted across files.
+	cursor int
+	offset int
+}
+
+// stageMsg is the diff read for view after an operation on the index,
+// if any, ran; opErr is the operation's failure, which leaves the diff
+// unread.
+type stageMsg struct {
+	view   *stageView
+	staged bool
+	files  []gitops.FilePatch
+	err    error
+	status string
+	opErr  error
+}
+
+// stageItem is one piece that stages on its own: a hunk, or a whole file
+// when it has none (hunk -1).
+type stageItem struct {
+	file, hunk int
+}
+
+func (v *stageView) items() []stageItem {
+	var items []stageItem
+	for f, file := range v.files {
+		if file.Whole {
+			items = append(items, stageItem{file: f, hunk: -1})
+			continue
+		}
+		for h := range file.Hunks {
+			items = append(items, stageItem{file: f, hunk: h})
+		}
+	}
+	return items
+}
+
+// load runs op, when given, then reads the diff again, in the
+// background; status is what to say once op succeeded.
+func (v *stageView) load(git *gitops.Repo, op func() error, status string) tea.Cmd {
+	v.loading = true
+	staged := v.staged
+	return func() tea.Msg {
+		if op != nil {
+			if err := op(); err != nil {
+				return stageMsg{view: v, staged: staged, opErr: err}
+			}
+		}
+		files, err := git.WorktreeDiff(staged)
+		return stageMsg{view: v, staged: staged, files: files, err: err, status: status}
+	}
+}
+
+func (m *model) applyStage(msg stageMsg) {
+	v := msg.view
+	if m.stage != v || v.staged != msg.staged {
+		return
+	}
+	v.loading = false
+	if msg.opErr != nil {
+		m.openPopup(stageTitle, msg.opErr.Error())
+		return
+	}
+	v.files, v.err = msg.files, msg.err
+	v.resplit()
+	v.cursor = clamp(v.cursor, 0, max(0, len(v.items())-1))
+	if msg.status != "" {
+		m.status = msg.status
+	}
+}
+
+// pieceKey names a hunk by its file and changed lines, which staging the
+// rest of the file leaves as they are.
+func pieceKey(path string, hunk gitops.Hunk) string {
+	var b strings.Builder
+	b.WriteString(path)
+	for _, line := range hunk.Lines {
+		if line[0] == '-' || line[0] == '+' {
+			b.WriteString("\n" + line)
+		}
+	}
+	return b.String()
+}
+
+// resplit splits again the hunks holding a piece the user split before.
+func (v *stageView) resplit() {
+	for f := range v.files {
+		file := &v.files[f]
+		var hunks []gitops.Hunk
+		for _, hunk := range file.Hunks {
+			parts := hunk.Split()
+			if len(parts) > 1 && slices.ContainsFunc(parts, func(part gitops.Hunk) bool { return v.pieces[pieceKey(file.Path, part)] }) {
+				hunks = append(hunks, parts...)
+			} else {
+				hunks = append(hunks, hunk)
+			}
+		}
+		file.Hunks = hunks
+	}
+}
+
+func (m *model) openStage() tea.Cmd {
+	if !m.worktreeAvailable(stageTitle) || !m.allowed(stageTitle, gitops.OpCommit, "") {
+		return nil
+	}
+	m.stage = &stageView{pieces: make(map[string]bool)}
+	return m.stage.load(m.git, nil, "")
+}
+
+func (m *model) handleStageKey(msg tea.KeyMsg) (tea.Model, tea.Cmd) {
+	v := m.stage
+	items := v.items()
+	var item *stageItem
+	if v.cursor < len(items) {
+		item = &items[v.cursor]
+	}
+	switch msg.String() {
+	case "ctrl+c":
+		return m, tea.Quit
+	case "esc", "q":
+		m.stage = nil
+	case "up", "k":
+		v.cursor = max(0, v.cursor-1)
+	case "down", "j":
+		v.cursor = min(max(0, len(items)-1), v.cursor+1)
+	case "tab":
+		v.staged, v.cursor, v.offset = !v.staged, 0, 0
+		v.files, v.err = nil, nil
+		return m, v.load(m.git, nil, "")
+	case "s":
+		if item == nil || item.hunk < 0 || v.loading {
+			return m, nil
+		}
+		file := &v.files[item.file]
+		parts := file.Hunks[item.hunk].Split()
+		if len(parts) == 1 {
+			m.status = "this hunk has one run of changes; it does not split"
+			return m, nil
+		}
+		for _, part := range parts {
+			v.pieces[pieceKey(file.Path, part)] = true
+		}
+		file.Hunks = append(file.Hunks[:item.hunk], append(parts, file.Hunks[item.hunk+1:]...)...)
+		m.status = fmt.Sprintf("split into %d hunks", len(parts))
+	case " ", "enter", "a":
+		if item == nil || v.loading {
+			return m, nil
+		}
+		git, file, whole := m.git, v.files[item.file], msg.String() == "a" || item.hunk < 0
+		var hunk gitops.Hunk
+		if !whole {
+			hunk = file.Hunks[item.hunk]
+		}
+		verb := "staged"
+		if v.staged {
+			verb = "unstaged"
+		}
+		status := fmt.Sprintf("%s a hunk of %s", verb, file.Path)
+		if whole {
+			status = fmt.Sprintf("%s %s", verb, file.Path)
+		}
+		staged := v.staged
+		return m, v.load(git, func() error {
+			switch {
+			case whole && staged:
+				return git.UnstageFile(file.Path)
+			case whole:
+				return git.StageFile(file.Path)
+			case staged:
+				return git.UnstageHunk(file, hunk)
+			}
+			return git.StageHunk(file, hunk)
+		}, status)
+	}
+	return m, nil
+}
+
+func (m *model) renderStage(width, height int) string {
+	v := m.stage
+	boxWidth := max(1, width-4)
+	rows := max(1, m.pagerRows())
+	items := v.items()
+
+	side, verb, other := "Unstaged changes", "stage", "staged"
+	if v.staged {
+		side, verb, other = "Staged changes", "unstage", "unstaged"
+	}
+	hunks := 0
+	for _, file := range v.files {
+		hunks += len(file.Hunks)
+	}
+	title := fmt.Sprintf("%s: %s, %d files, %d hunks", stageTitle, side, len(v.files), hunks)
+
+	// body holds every line, and start where each item's lines begin.
+	var body []string
+	start := make([]int, 0, len(items))
+	i := 0
+	for _, file := range v.files {
+		heading := sidebarSubtitleStyle.Render(sanitizeLine(file.Path))
+		if file.Whole {
+			heading += sidebarHintStyle.Render(" (no hunks: binary or a mode change; stages whole)")
+		}
+		if len(body) > 0 {
+			body = append(body, "")
+		}
+		body = append(body, heading)
+		var pieces [][]string
+		if file.Whole {
+			pieces = [][]string{nil}
+		}
+		for _, hunk := range file.Hunks {
+			pieces = append(pieces, append([]string{hunk.Header()}, hunk.Lines...))
+		}
+		for _, piece := range pieces {
+			gutter := "  "
+			if i == v.cursor {
+				gutter = listCursorStyle.Render("▌") + " "
+			}
+			if piece == nil {
+				start = append(start, len(body)-1)
+				body[len(body)-1] = gutter + body[len(body)-1]
+			} else {
+				start = append(start, len(body))
+				for _, line := range piece {
+					body = append(body, gutter+stageLine(sanitizeLine(line), boxWidth-6))
+				}
+			}
+			i++
+		}
+	}
+
+	lines := []string{popupTitleStyle.Render(title), ""}
+	switch {
+	case v.err != nil:
+		lines = append(lines, v.err.Error())
+	case v.loading && v.files == nil:
+		lines = append(lines, "Reading the diff…")
+	case len(items) == 0 && v.staged:
+		lines = append(lines, "Nothing is staged.")
+	case len(items) == 0:
+		lines = append(lines, "The tracked files match the index; there is nothing to stage. (New files need git add -N to show here.)")
+	default:
+		// Keep the picked item in view, from its first line when it fits.
+		first, last := start[v.cursor], len(body)
+		if v.cursor+1 < len(start) {
+			last = start[v.cursor+1]
+		}
+		if first < v.offset {
+			v.offset = first
+		} else if last > v.offset+rows {
+			v.offset = min(first, last-rows)
+		}
+		v.offset = clamp(v.offset, 0, max(0, len(body)-rows))
+		lines = append(lines, body[v.offset:min(len(body), v.offset+rows)]...)
+	}
+	hint := fmt.Sprintf("j/k hunk | space %s | s split | a whole file | tab %s | esc close", verb, other)
+	lines = append(lines, "", popupHintStyle.Render(hint))
+	box := popupStyle.Width(boxWidth).Render(strings.Join(lines, "\n"))
+	return lipgloss.Place(width, height, lipgloss.Center, lipgloss.Center, box,
+		lipgloss.WithWhitespaceBackground(palette.bg))
+}
+
+// stageLine colors one patch line as the diff pager does.
+func stageLine(line string, width int) string {
+	line = truncateText(strings.ReplaceAll(line, "\t", "    "), max(1, width))
+	switch {
+	case strings.HasPrefix(line, "+"):
+		return diffAddStyle.Render(line)
+	case strings.HasPrefix(line, "-"):
+		return diffDelStyle.Render(line)
+	case strings.HasPrefix(line, "@@"):
+		return diffHunkStyle.Render(line)
+	}
+	return line
+}
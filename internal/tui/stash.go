@@ -0,0 +1,54 @@
+package tui
+
+import (
+	"fmt"
+	"strings"
+
+	tea "github.com/charmbracelet/bubbletea"
+)
+
+// stashKey stashes uncommitted changes in the prompts of operations they
+// would block or be lost to.
+const stashKey = "z"
+
+// stashAndContinue is the prompt action that sets uncommitted changes aside
+// with a quick stash, then runs op on the clean working tree. The result
+// offers to re-apply them.
+func (m *model) stashAndContinue(title, action, label string, op func() (string, error)) popupAction {
+	git := m.git
+	return popupAction{key: stashKey, label: label, run: func() tea.Cmd {
+		return func() tea.Msg {
+			stash, err := git.QuickStash(action)
+			if err != nil {
+				return gitOpMsg{title: title, err: fmt.Errorf("stash: %w", err)}
+			}
+			out, err := op()
+			return gitOpMsg{title: title, output: out, err: err, stash: stash}
+		}
+	}}
+}
+
+// stashedLines say where the changes stashedAndContinue set aside are, and
+// how to get them back.
+func (m *model) stashedLines(title, stash string) []string {
+	return []string{
+		"",
+		fmt.Sprintf("Your uncommitted changes are stashed as %s (see git stash list).", m.short(stash)),
+		fmt.Sprintf("%s re-applies them now; or %s undoes the %s first, and %s again brings them back.",
+			stashKey, m.keys.first(actionUndo), strings.ToLower(title), m.keys.first(actionUndo)),
+	}
+}
+
+// reapplyStash is the action offered after an operation that stashed.
+func (m *model) reapplyStash(stash string) popupAction {
+	git := m.git
+	return popupAction{key: stashKey, label: "re-apply stash", run: func() tea.Cmd {
+		return runGitOp("Re-apply stash", func() (string, error) {
+			out, err := git.ApplyStash(stash)
+			if err == nil {
+				out = "Re-applied the stashed changes."
+			}
+			return out, err
+		})
+	}}
+}
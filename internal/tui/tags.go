@@ -0,0 +1,84 @@
+package tui
+
+import (
+	"fmt"
+	"slices"
+	"strings"
+
+	"arbor/internal/gitgraph"
+)
+
+// showTagNotes opens the annotated tags on the selected commit: tagger,
+// signature status, and the full message.
+func (m *model) showTagNotes() {
+	commit := m.selectedCommit()
+	if commit == nil || m.tagIndex == nil {
+		return
+	}
+	names := m.tagIndex.Tags(commit.Hash)
+	if len(names) == 0 {
+		m.status = "no tags on " + commit.ShortHash()
+		return
+	}
+	notes := m.tagIndex.Notes(commit.Hash)
+	if len(notes) == 0 {
+		m.openPopup("Tags", fmt.Sprintf("%s: lightweight, with no tagger or message.", strings.Join(names, ", ")))
+		return
+	}
+	title := "Tag " + notes[0].Name
+	if len(notes) > 1 {
+		title = "Tags"
+	}
+	var lines []string
+	for i, note := range notes {
+		if i > 0 {
+			lines = append(lines, "")
+		}
+		if len(notes) > 1 {
+			lines = append(lines, sidebarTitleStyle.Render(note.Name))
+		}
+		lines = append(lines,
+			fmt.Sprintf("Tagger: %s <%s>, %s", note.Tagger.Name, note.Tagger.Email, note.Tagger.When.Format("2006-01-02 15:04")),
+			"Signature: "+m.tagSignature(note.Signed, note.Hash.String()),
+			"",
+		)
+		lines = append(lines, strings.Split(strings.TrimSpace(note.Message), "\n")...)
+	}
+	if len(notes) < len(names) {
+		var light []string
+		for _, name := range names {
+			if !slices.ContainsFunc(notes, func(n gitgraph.TagNote) bool { return n.Name == name }) {
+				light = append(light, name)
+			}
+		}
+		lines = append(lines, "", "Lightweight: "+strings.Join(light, ", "))
+	}
+	m.openPopup(title, lines...)
+}
+
+func (m *model) tagSignature(signed bool, object string) string {
+	switch {
+	case !signed:
+		return "none"
+	case m.git == nil:
+		return "signed (not verified: the repository is bare)"
+	}
+	status, err := m.git.VerifyTag(object)
+	if err != nil {
+		return "signed (not verified: " + err.Error() + ")"
+	}
+	return status
+}
+
+// tagLines sums up the annotated tags on commit for the sidebar, e.g.
+// "Tag v1.2.0 by Jane: Release 1.2.0".
+func (m *model) tagLines(commit *gitgraph.CommitInfo) []string {
+	if m.tagIndex == nil {
+		return nil
+	}
+	var lines []string
+	for _, note := range m.tagIndex.Notes(commit.Hash) {
+		lines = append(lines, fmt.Sprintf("Tag %s by %s: %s", note.Name, note.Tagger.Name, note.Subject()))
+	}
+	return lines
+}
@@ -0,0 +1,136 @@
+package tui
+
+import "github.com/charmbracelet/lipgloss"
+
+type theme struct {
+	bg            lipgloss.AdaptiveColor
+	bgAlt         lipgloss.AdaptiveColor
+	panelBg       lipgloss.AdaptiveColor
+	panelBorder   lipgloss.AdaptiveColor
+	text          lipgloss.AdaptiveColor
+	textMuted     lipgloss.AdaptiveColor
+	textDim       lipgloss.AdaptiveColor
+	accent        lipgloss.AdaptiveColor
+	accentAlt     lipgloss.AdaptiveColor
+	highlightBg   lipgloss.AdaptiveColor
+	highlightText lipgloss.AdaptiveColor
+	headerBg      lipgloss.AdaptiveColor
+	searchBg      lipgloss.AdaptiveColor
+	footerBg      lipgloss.AdaptiveColor
+	warning       lipgloss.AdaptiveColor
+	// lanes color graph columns in turn, so neighbors in the list are
+	// neighbors in the graph (the last one next to the first).
+	lanes []lipgloss.TerminalColor
+}
+
+var themes = map[string]theme{
+	"forest": {
+		bg:            lipgloss.AdaptiveColor{Light: "#f7f4ee", Dark: "#0f1411"},
+		bgAlt:         lipgloss.AdaptiveColor{Light: "#efe9df", Dark: "#141b16"},
+		panelBg:       lipgloss.AdaptiveColor{Light: "#f2eee6", Dark: "#141c18"},
+		panelBorder:   lipgloss.AdaptiveColor{Light: "#c9bda8", Dark: "#2c3a32"},
+		text:          lipgloss.AdaptiveColor{Light: "#2a271f", Dark: "#e6f0e6"},
+		textMuted:     lipgloss.AdaptiveColor{Light: "#5e5648", Dark: "#a6b4a6"},
+		textDim:       lipgloss.AdaptiveColor{Light: "#8a8171", Dark: "#7b887f"},
+		accent:        lipgloss.AdaptiveColor{Light: "#2f6d4b", Dark: "#6fd08a"},
+		accentAlt:     lipgloss.AdaptiveColor{Light: "#7a5a2a", Dark: "#d2a76a"},
+		highlightBg:   lipgloss.AdaptiveColor{Light: "#d8efe2", Dark: "#264c37"},
+		highlightText: lipgloss.AdaptiveColor{Light: "#1f3b2a", Dark: "#eaf6ee"},
+		headerBg:      lipgloss.AdaptiveColor{Light: "#e9efe6", Dark: "#18221d"},
+		searchBg:      lipgloss.AdaptiveColor{Light: "#e9efe6", Dark: "#18221d"},
+		footerBg:      lipgloss.AdaptiveColor{Light: "#e9efe6", Dark: "#18221d"},
+		warning:       lipgloss.AdaptiveColor{Light: "#a8482a", Dark: "#e8875f"},
+		lanes: []lipgloss.TerminalColor{
+			lipgloss.AdaptiveColor{Light: "#2f6d4b", Dark: "#6fd08a"},
+			lipgloss.AdaptiveColor{Light: "#4f8a5b", Dark: "#7ee1a0"},
+			lipgloss.AdaptiveColor{Light: "#7a5a2a", Dark: "#d2a76a"},
+			lipgloss.AdaptiveColor{Light: "#4d7f75", Dark: "#7fd3c5"},
+			lipgloss.AdaptiveColor{Light: "#4f6f8a", Dark: "#8fb9e0"},
+			lipgloss.AdaptiveColor{Light: "#9a6b2f", Dark: "#f0c07a"},
+			lipgloss.AdaptiveColor{Light: "#6e8b3d", Dark: "#a8e063"},
+			lipgloss.AdaptiveColor{Light: "#3f5a4a", Dark: "#6cb08a"},
+			lipgloss.AdaptiveColor{Light: "#6c7a74", Dark: "#a9b6b0"},
+		},
+	},
+	// colorblind is built on the Okabe-Ito colors, with blue for additions
+	// and orange for deletions instead of green and red. The lanes are
+	// ordered so each pair of neighbors stays at least ΔE 45 apart under
+	// simulated deuteranopia and protanopia (Machado et al., full severity).
+	"colorblind": {
+		bg:            lipgloss.AdaptiveColor{Light: "#f7f7f7", Dark: "#111214"},
+		bgAlt:         lipgloss.AdaptiveColor{Light: "#ececec", Dark: "#18191c"},
+		panelBg:       lipgloss.AdaptiveColor{Light: "#f0f0f0", Dark: "#17181b"},
+		panelBorder:   lipgloss.AdaptiveColor{Light: "#b8b8b8", Dark: "#3a3c42"},
+		text:          lipgloss.AdaptiveColor{Light: "#1d1d1f", Dark: "#ececec"},
+		textMuted:     lipgloss.AdaptiveColor{Light: "#4d4d52", Dark: "#b4b4b8"},
+		textDim:       lipgloss.AdaptiveColor{Light: "#7a7a80", Dark: "#85858b"},
+		accent:        lipgloss.AdaptiveColor{Light: "#0072b2", Dark: "#56b4e9"},
+		accentAlt:     lipgloss.AdaptiveColor{Light: "#b86e00", Dark: "#e69f00"},
+		highlightBg:   lipgloss.AdaptiveColor{Light: "#d4e6f3", Dark: "#1f3f57"},
+		highlightText: lipgloss.AdaptiveColor{Light: "#0b2a40", Dark: "#f2f7fb"},
+		headerBg:      lipgloss.AdaptiveColor{Light: "#e6e9ee", Dark: "#1b1e23"},
+		searchBg:      lipgloss.AdaptiveColor{Light: "#e6e9ee", Dark: "#1b1e23"},
+		footerBg:      lipgloss.AdaptiveColor{Light: "#e6e9ee", Dark: "#1b1e23"},
+		warning:       lipgloss.AdaptiveColor{Light: "#c24e00", Dark: "#e69f00"},
+		lanes: []lipgloss.TerminalColor{
+			lipgloss.AdaptiveColor{Light: "#0072b2", Dark: "#56b4e9"},
+			lipgloss.AdaptiveColor{Light: "#b86e00", Dark: "#e69f00"},
+			lipgloss.AdaptiveColor{Light: "#555555", Dark: "#cc79a7"},
+			lipgloss.AdaptiveColor{Light: "#7a6e00", Dark: "#f0e442"},
+			lipgloss.AdaptiveColor{Light: "#a0527f", Dark: "#bbbbbb"},
+			lipgloss.AdaptiveColor{Light: "#c24e00", Dark: "#d55e00"},
+			lipgloss.AdaptiveColor{Light: "#1f4e99", Dark: "#0072b2"},
+			lipgloss.AdaptiveColor{Light: "#007d5b", Dark: "#009e73"},
+		},
+	},
+	// high-contrast uses pure black or white backgrounds and saturated
+	// lanes, for low vision and washed-out projectors.
+	"high-contrast": {
+		bg:            lipgloss.AdaptiveColor{Light: "#ffffff", Dark: "#000000"},
+		bgAlt:         lipgloss.AdaptiveColor{Light: "#ffffff", Dark: "#000000"},
+		panelBg:       lipgloss.AdaptiveColor{Light: "#ffffff", Dark: "#000000"},
+		panelBorder:   lipgloss.AdaptiveColor{Light: "#000000", Dark: "#ffffff"},
+		text:          lipgloss.AdaptiveColor{Light: "#000000", Dark: "#ffffff"},
+		textMuted:     lipgloss.AdaptiveColor{Light: "#000000", Dark: "#ffffff"},
+		textDim:       lipgloss.AdaptiveColor{Light: "#3a3a3a", Dark: "#d0d0d0"},
+		accent:        lipgloss.AdaptiveColor{Light: "#0000d7", Dark: "#00d7ff"},
+		accentAlt:     lipgloss.AdaptiveColor{Light: "#af5f00", Dark: "#ffd700"},
+		highlightBg:   lipgloss.AdaptiveColor{Light: "#000000", Dark: "#ffffff"},
+		highlightText: lipgloss.AdaptiveColor{Light: "#ffffff", Dark: "#000000"},
+		headerBg:      lipgloss.AdaptiveColor{Light: "#e0e0e0", Dark: "#262626"},
+		searchBg:      lipgloss.AdaptiveColor{Light: "#e0e0e0", Dark: "#262626"},
+		footerBg:      lipgloss.AdaptiveColor{Light: "#e0e0e0", Dark: "#262626"},
+		warning:       lipgloss.AdaptiveColor{Light: "#d70000", Dark: "#ff5f5f"},
+		lanes: []lipgloss.TerminalColor{
+			lipgloss.AdaptiveColor{Light: "#0000d7", Dark: "#00d7ff"},
+			lipgloss.AdaptiveColor{Light: "#af5f00", Dark: "#ffd700"},
+			lipgloss.AdaptiveColor{Light: "#000000", Dark: "#ffffff"},
+			lipgloss.AdaptiveColor{Light: "#008700", Dark: "#ff8700"},
+			lipgloss.AdaptiveColor{Light: "#af00af", Dark: "#ff5fff"},
+			lipgloss.AdaptiveColor{Light: "#d70000", Dark: "#ff5f5f"},
+			lipgloss.AdaptiveColor{Light: "#005f87", Dark: "#87afff"},
+			lipgloss.AdaptiveColor{Light: "#5f5f00", Dark: "#5fff5f"},
+		},
+	},
+}
+
+var (
+	palette      = themes["forest"]
+	branchColors = palette.lanes
+)
+
+func init() {
+	setStyles()
+}
+
+// setTheme switches every style to the named theme; unknown names (which
+// config validation rejects) keep the current one.
+func setTheme(name string) {
+	t, ok := themes[name]
+	if !ok {
+		return
+	}
+	palette = t
+	branchColors = t.lanes
+	setStyles()
+}
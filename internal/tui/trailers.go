@@ -0,0 +1,101 @@
+package tui
+
+import (
+	"fmt"
+
+	"arbor/internal/gitgraph"
+
+	"github.com/go-git/go-git/v5/plumbing"
+)
+
+func commitTrailers(commit *gitgraph.CommitInfo) []gitgraph.Trailer {
+	if commit == nil || commit.Commit == nil {
+		return nil
+	}
+	return gitgraph.Trailers(commit.Message())
+}
+
+// chipIndex is the trailer chip picked in the focused sidebar; a new
+// selection starts at the first.
+func (m *model) chipIndex(hash plumbing.Hash, chips int) int {
+	if hash != m.chipHash {
+		return 0
+	}
+	return clamp(m.chip, 0, max(0, chips-1))
+}
+
+// trailerLines renders the selected commit's trailers as chips. With the
+// sidebar focused one is picked, and enter filters history by it.
+func (m *model) trailerLines(commit *gitgraph.CommitInfo) []string {
+	trailers := commitTrailers(commit)
+	if len(trailers) == 0 {
+		return nil
+	}
+	title := sidebarSubtitleStyle.Render("Trailers")
+	if m.sidebarFocus {
+		title += sidebarHintStyle.Render(" ←/→ pick, enter filter")
+	} else if key := m.keys.first(actionFocus); key != "" {
+		title += sidebarHintStyle.Render(fmt.Sprintf(" (%s to filter)", key))
+	}
+	lines := []string{"", title}
+	picked := m.chipIndex(commit.Hash, len(trailers))
+	for i, trailer := range trailers {
+		chip := " " + trailer.String() + " "
+		if m.trailer != nil && m.trailer.Matches(trailer) {
+			chip = " ✓" + chip
+		}
+		if m.sidebarFocus && i == picked {
+			lines = append(lines, matchStyle.Render(chip))
+			continue
+		}
+		lines = append(lines, chipStyle.Render(chip))
+	}
+	return lines
+}
+
+// pickChip moves between trailer chips, or toggles the filter for the
+// picked one; it reports false when the commit has no trailers.
+func (m *model) pickChip(key string) bool {
+	commit := m.selectedCommit()
+	trailers := commitTrailers(commit)
+	if len(trailers) == 0 {
+		return false
+	}
+	picked := m.chipIndex(commit.Hash, len(trailers))
+	m.chipHash = commit.Hash
+	switch key {
+	case "left", "h":
+		m.chip = max(0, picked-1)
+	case "right", "l":
+		m.chip = min(len(trailers)-1, picked+1)
+	case "enter":
+		trailer := trailers[picked]
+		if m.trailer != nil && m.trailer.Matches(trailer) {
+			m.setTrailer(nil)
+			m.status = "trailer filter cleared"
+		} else {
+			m.setTrailer(&trailer)
+		}
+	}
+	return true
+}
+
+// setTrailer layers a trailer under the search and folder filters, keeping
+// the selected commit in view when it still matches.
+func (m *model) setTrailer(trailer *gitgraph.Trailer) {
+	selected := m.selectedCommit()
+	m.trailer = trailer
+	m.applyFilter(m.filter)
+	if selected != nil && m.passesFilters(selected) {
+		m.selectHash(selected.Hash)
+	}
+	m.ensureVisible()
+	m.normalizePosition()
+}
+
+func (m *model) hasTrailer(commit *gitgraph.CommitInfo) bool {
+	if m.trailer == nil {
+		return true
+	}
+	return commit.Commit != nil && gitgraph.HasTrailer(commit.Message(), *m.trailer)
+}
@@ -0,0 +1,47 @@
+package tui
+
+import (
+	"fmt"
+
+	"arbor/internal/gitops"
+
+	tea "github.com/charmbracelet/bubbletea"
+)
+
+// startUndo shows the last journaled operation and what undoing it moves
+// back, then reverts it on confirmation.
+func (m *model) startUndo() {
+	const title = "Undo"
+	if !m.worktreeAvailable(title) || !m.allowed(title, gitops.OpRewrite, "") {
+		return
+	}
+	last, err := m.git.LastOperation()
+	if err != nil {
+		m.openPopup(title, err.Error())
+		return
+	}
+	if last == nil {
+		m.status = "nothing to undo"
+		return
+	}
+	lines := []string{fmt.Sprintf("Undo %q from %s:", last.Title, last.Time.Format("2006-01-02 15:04")), ""}
+	for _, change := range last.Refs {
+		lines = append(lines, change.Reversed().String())
+	}
+	switch {
+	case last.Stash != "" && len(last.Refs) == 0:
+		lines = append(lines, "The stashed changes are applied back to the working tree.")
+	case last.Stash != "":
+		lines = append(lines, "", "The working tree saved before it is restored too.")
+	}
+	git := m.git
+	m.openPrompt(title, lines, popupAction{key: "enter", label: "undo", run: func() tea.Cmd {
+		return runGitOp(title, func() (string, error) {
+			undone, err := git.Undo()
+			if err != nil {
+				return "", err
+			}
+			return fmt.Sprintf("Undid %q.", undone.Title), nil
+		})
+	}})
+}
@@ -0,0 +1,309 @@
+package tui
+
+import (
+	"flag"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"testing"
+	"time"
+
+	"arbor/internal/config"
+	"arbor/internal/gitgraph"
+
+	tea "github.com/charmbracelet/bubbletea"
+	"github.com/charmbracelet/x/ansi"
+	git "github.com/go-git/go-git/v5"
+	"github.com/go-git/go-git/v5/plumbing"
+	"github.com/go-git/go-git/v5/plumbing/filemode"
+	"github.com/go-git/go-git/v5/plumbing/object"
+	"github.com/go-git/go-git/v5/storage/memory"
+)
+
+var update = flag.Bool("update", false, "rewrite the golden files in testdata/")
+
+// repoBuilder writes commits straight into an in-memory repository with a
+// fixed clock, so hashes and dates are the same on every run.
+type repoBuilder struct {
+	t     *testing.T
+	repo  *git.Repository
+	clock time.Time
+}
+
+func newRepoBuilder(t *testing.T) *repoBuilder {
+	t.Helper()
+	repo, err := git.Init(memory.NewStorage(), nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	head := plumbing.NewSymbolicReference(plumbing.HEAD, plumbing.NewBranchReferenceName("main"))
+	if err := repo.Storer.SetReference(head); err != nil {
+		t.Fatal(err)
+	}
+	return &repoBuilder{t: t, repo: repo, clock: time.Date(2024, 3, 1, 9, 0, 0, 0, time.UTC)}
+}
+
+// commit records a commit whose tree holds one file per subject word, so
+// the files view has something to list.
+func (b *repoBuilder) commit(author, subject string, parents ...plumbing.Hash) plumbing.Hash {
+	b.t.Helper()
+	words := strings.Fields(subject)
+	sort.Strings(words)
+	var entries []object.TreeEntry
+	for i, word := range words {
+		if i > 0 && word == words[i-1] {
+			continue
+		}
+		entries = append(entries, object.TreeEntry{Name: word + ".txt", Mode: filemode.Regular, Hash: b.blob(subject)})
+	}
+	tree := &object.Tree{Entries: entries}
+	treeHash := b.store(tree)
+	b.clock = b.clock.Add(90 * time.Minute)
+	sig := object.Signature{Name: author, Email: strings.ToLower(author) + "@example.com", When: b.clock}
+	return b.store(&object.Commit{
+		Author:       sig,
+		Committer:    sig,
+		Message:      subject + "\n",
+		TreeHash:     treeHash,
+		ParentHashes: parents,
+	})
+}
+
+func (b *repoBuilder) blob(content string) plumbing.Hash {
+	b.t.Helper()
+	obj := b.repo.Storer.NewEncodedObject()
+	obj.SetType(plumbing.BlobObject)
+	w, err := obj.Writer()
+	if err != nil {
+		b.t.Fatal(err)
+	}
+	if _, err := w.Write([]byte(content)); err != nil {
+		b.t.Fatal(err)
+	}
+	w.Close()
+	hash, err := b.repo.Storer.SetEncodedObject(obj)
+	if err != nil {
+		b.t.Fatal(err)
+	}
+	return hash
+}
+
+func (b *repoBuilder) store(o interface {
+	Encode(plumbing.EncodedObject) error
+}) plumbing.Hash {
+	b.t.Helper()
+	obj := b.repo.Storer.NewEncodedObject()
+	if err := o.Encode(obj); err != nil {
+		b.t.Fatal(err)
+	}
+	hash, err := b.repo.Storer.SetEncodedObject(obj)
+	if err != nil {
+		b.t.Fatal(err)
+	}
+	return hash
+}
+
+func (b *repoBuilder) branch(name string, hash plumbing.Hash) {
+	b.t.Helper()
+	ref := plumbing.NewHashReference(plumbing.NewBranchReferenceName(name), hash)
+	if err := b.repo.Storer.SetReference(ref); err != nil {
+		b.t.Fatal(err)
+	}
+}
+
+func linearRepo(t *testing.T) *git.Repository {
+	b := newRepoBuilder(t)
+	var tip plumbing.Hash
+	for _, subject := range []string{"initial commit", "add parser", "fix parser edge case", "document usage", "release v1"} {
+		if tip.IsZero() {
+			tip = b.commit("Ada", subject)
+			continue
+		}
+		tip = b.commit("Ada", subject, tip)
+	}
+	b.branch("main", tip)
+	return b.repo
+}
+
+// mergeRepo has a merged feature branch, an open one, and a side branch off
+// the root commit.
+func mergeRepo(t *testing.T) *git.Repository {
+	return mergeRepoBuilder(t).repo
+}
+
+func mergeRepoBuilder(t *testing.T) *repoBuilder {
+	b := newRepoBuilder(t)
+	root := b.commit("Ada", "initial commit")
+	base := b.commit("Ada", "add config loader", root)
+	feat := b.commit("Grace", "feature flags", base)
+	feat = b.commit("Grace", "feature flags tests", feat)
+	main := b.commit("Ada", "tidy readme", base)
+	main = b.commit("Ada", "merge feature flags", main, feat)
+	open := b.commit("Linus", "wip theme", main)
+	main = b.commit("Ada", "bump version", main)
+	side := b.commit("Grace", "experiment", root)
+	b.branch("main", main)
+	b.branch("theme", open)
+	b.branch("experiment", side)
+	return b
+}
+
+func TestViewGolden(t *testing.T) {
+	tests := []struct {
+		name    string
+		repo    func(*testing.T) *git.Repository
+		all     bool
+		width   int
+		height  int
+		keys    string
+		columns []string
+	}{
+		{name: "linear_80x20", repo: linearRepo, width: 80, height: 20},
+		{name: "linear_120x30", repo: linearRepo, width: 120, height: 30},
+		{name: "linear_files", repo: linearRepo, width: 100, height: 20, keys: "j\r"},
+		{name: "merge_100x24", repo: mergeRepo, width: 100, height: 24},
+		{name: "merge_all_120x24", repo: mergeRepo, all: true, width: 120, height: 24},
+		{name: "merge_all_narrow", repo: mergeRepo, all: true, width: 50, height: 16},
+		{name: "merge_jump", repo: mergeRepo, all: true, width: 100, height: 16, keys: "?feature\rn"},
+		{name: "merge_sidebar_scroll", repo: mergeRepo, width: 90, height: 12, keys: "jjj\r⇤jj"},
+		{name: "merge_comfortable", repo: mergeRepo, all: true, width: 100, height: 16, keys: "Djjjjjj"},
+		{name: "merge_no_sidebar", repo: mergeRepo, all: true, width: 100, height: 16, keys: "\t"},
+		{name: "merge_heatmap", repo: mergeRepo, all: true, width: 90, height: 16, keys: "Mj"},
+		{name: "merge_diffstat", repo: mergeRepo, all: true, width: 100, height: 16, columns: []string{"graph", "hash", "diffstat", "subject", "author"}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			provider, err := gitgraph.NewCommitProvider(tt.repo(t), tt.all, 0)
+			if err != nil {
+				t.Fatal(err)
+			}
+			cfg := config.Default()
+			if tt.columns != nil {
+				cfg.Columns = tt.columns
+			}
+			var m tea.Model = newTestModel(provider, cfg)
+			m = send(m, tea.WindowSizeMsg{Width: tt.width, Height: tt.height})
+			for _, key := range tt.keys {
+				m = send(m, keyMsg(key))
+			}
+			checkGolden(t, tt.name, ansi.Strip(m.View()))
+		})
+	}
+}
+
+// TestViewGoldenReload commits on top of two tips and starts a new branch
+// deep in history, then reloads: existing lanes and the selected commit
+// must stay where they were.
+func TestViewGoldenReload(t *testing.T) {
+	b := mergeRepoBuilder(t)
+	provider, err := gitgraph.NewCommitProvider(b.repo, true, 0)
+	if err != nil {
+		t.Fatal(err)
+	}
+	var m tea.Model = newTestModel(provider, config.Default())
+	m = send(m, tea.WindowSizeMsg{Width: 100, Height: 20})
+	for _, key := range "jjj" {
+		m = send(m, keyMsg(key))
+	}
+	checkGolden(t, "reload_before", ansi.Strip(m.View()))
+
+	head, err := b.repo.Reference(plumbing.NewBranchReferenceName("main"), false)
+	if err != nil {
+		t.Fatal(err)
+	}
+	theme, err := b.repo.Reference(plumbing.NewBranchReferenceName("theme"), false)
+	if err != nil {
+		t.Fatal(err)
+	}
+	b.branch("main", b.commit("Ada", "release notes", head.Hash()))
+	b.branch("theme", b.commit("Linus", "theme colors", theme.Hash()))
+	root := provider.Commits[len(provider.Commits)-1].Hash
+	b.branch("hotfix", b.commit("Grace", "hotfix", root))
+	if err := m.(*model).reload(); err != nil {
+		t.Fatal(err)
+	}
+	m = send(m, m.(*model).loadVisible()())
+	checkGolden(t, "reload_after", ansi.Strip(m.View()))
+}
+
+// newTestModel is NewModel with its clock stopped, so keys sent in quick
+// succession are never taken for a held key and accelerated.
+func newTestModel(provider *gitgraph.CommitProvider, cfg *config.Config) tea.Model {
+	m := NewModel("", provider, "main", cfg).(*model)
+	m.now = func() time.Time { return time.Unix(0, 0) }
+	return m
+}
+
+// send delivers msg and then, synchronously, every message the resulting
+// commands produce, so background work lands before the view is rendered.
+func send(m tea.Model, msg tea.Msg) tea.Model {
+	var cmd tea.Cmd
+	switch msg := msg.(type) {
+	case nil:
+		return m
+	case tea.BatchMsg:
+		for _, c := range msg {
+			if c != nil {
+				m = send(m, c())
+			}
+		}
+		return m
+	}
+	m, cmd = m.Update(msg)
+	if cmd != nil {
+		m = send(m, cmd())
+	}
+	return m
+}
+
+func keyMsg(key rune) tea.KeyMsg {
+	switch key {
+	case '\r':
+		return tea.KeyMsg{Type: tea.KeyEnter}
+	case '\t':
+		return tea.KeyMsg{Type: tea.KeyTab}
+	case '⇤':
+		return tea.KeyMsg{Type: tea.KeyShiftTab}
+	case 0x1b:
+		return tea.KeyMsg{Type: tea.KeyEsc}
+	}
+	return tea.KeyMsg{Type: tea.KeyRunes, Runes: []rune{key}}
+}
+
+// checkGolden compares got with testdata/<name>.golden; run
+// `go test ./internal/tui -update` to accept a deliberate change.
+func checkGolden(t *testing.T, name, got string) {
+	t.Helper()
+	path := filepath.Join("testdata", name+".golden")
+	if *update {
+		if err := os.MkdirAll("testdata", 0o755); err != nil {
+			t.Fatal(err)
+		}
+		if err := os.WriteFile(path, []byte(got), 0o644); err != nil {
+			t.Fatal(err)
+		}
+		return
+	}
+	want, err := os.ReadFile(path)
+	if err != nil {
+		t.Fatalf("%v (run with -update to create it)", err)
+	}
+	if got == string(want) {
+		return
+	}
+	gotLines, wantLines := strings.Split(got, "\n"), strings.Split(string(want), "\n")
+	for i := 0; i < max(len(gotLines), len(wantLines)); i++ {
+		var g, w string
+		if i < len(gotLines) {
+			g = gotLines[i]
+		}
+		if i < len(wantLines) {
+			w = wantLines[i]
+		}
+		if g != w {
+			t.Errorf("%s: first difference at line %d\nwant: %q\n got: %q", path, i+1, w, g)
+			return
+		}
+	}
+}
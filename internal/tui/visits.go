@@ -0,0 +1,67 @@
+package tui
+
+import (
+	"fmt"
+
+	"arbor/internal/gitgraph"
+
+	"github.com/charmbracelet/lipgloss"
+	"github.com/go-git/go-git/v5/plumbing"
+)
+
+// maxVisitLines caps the HEAD history listed in the sidebar per commit.
+const maxVisitLines = 5
+
+// loadHeadVisits indexes HEAD's reflog by the commit each entry moved to,
+// so the list can mark where HEAD has been. Redone on every reload.
+func (m *model) loadHeadVisits() {
+	repo := m.provider.Repo()
+	if gitgraph.GitDir(repo) == "" {
+		return
+	}
+	entries, err := gitgraph.ReadReflog(repo, "HEAD")
+	if err != nil {
+		m.status = err.Error()
+		return
+	}
+	m.headLog = entries
+	m.headVisits = make(map[plumbing.Hash][]int)
+	for i, entry := range entries {
+		m.headVisits[entry.New] = append(m.headVisits[entry.New], i)
+	}
+}
+
+func (m *model) toggleHeadHistory() {
+	m.headHistory = !m.headHistory
+	if !m.headHistory {
+		m.status = "HEAD history off"
+		return
+	}
+	m.status = fmt.Sprintf("HEAD history on: %d reflog entries", len(m.headLog))
+}
+
+func (m *model) visitBadge(commit *gitgraph.CommitInfo, bg lipgloss.TerminalColor) string {
+	if !m.headHistory || len(m.headVisits[commit.Hash]) == 0 {
+		return ""
+	}
+	return emptyStyle.Background(bg).Render("↺") + rowSpacerStyle.Background(bg).Render(" ")
+}
+
+// visitLines lists when HEAD arrived at commit, newest first, as
+// "HEAD@{n} 2024-03-01 09:00 checkout: moving from main to topic".
+func (m *model) visitLines(commit *gitgraph.CommitInfo) []string {
+	if !m.headHistory {
+		return nil
+	}
+	visits := m.headVisits[commit.Hash]
+	lines := make([]string, 0, min(len(visits), maxVisitLines)+1)
+	for i, n := range visits {
+		if i == maxVisitLines {
+			lines = append(lines, fmt.Sprintf("… %d more", len(visits)-maxVisitLines))
+			break
+		}
+		entry := m.headLog[n]
+		lines = append(lines, fmt.Sprintf("HEAD@{%d} %s %s", n, entry.When.Format("2006-01-02 15:04"), entry.Message))
+	}
+	return lines
+}
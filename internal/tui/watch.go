@@ -0,0 +1,99 @@
+package tui
+
+import (
+	"fmt"
+	"time"
+
+	"arbor/internal/gitgraph"
+
+	tea "github.com/charmbracelet/bubbletea"
+	"github.com/go-git/go-git/v5/plumbing"
+)
+
+// watchInterval is how often watch mode checks whether refs moved.
+const watchInterval = 2 * time.Second
+
+// watchMsg is the refs' state at one check; see gitgraph.RefState.
+type watchMsg struct{ state string }
+
+// watchRefs checks the refs again after watchInterval, while watching.
+func (m *model) watchRefs() tea.Cmd {
+	if !m.watch {
+		return nil
+	}
+	repo := m.provider.Repo()
+	return tea.Tick(watchInterval, func(time.Time) tea.Msg {
+		return watchMsg{state: gitgraph.RefState(repo)}
+	})
+}
+
+// applyWatch reloads the graph when refs moved since the last check. With
+// following on, a selection on the current branch's tip, or on the top
+// row, moves to the new tip, as tail -f keeps to the end of a file. It
+// waits while a prompt or input is open, whose commits a reload would
+// pull out from under it.
+func (m *model) applyWatch(msg watchMsg) tea.Cmd {
+	next := m.watchRefs()
+	if msg.state == m.watchState || m.popup != nil || m.input != nil || m.pruner != nil || m.stage != nil || m.palette != nil {
+		return next
+	}
+	oldHead, follow := m.watchHead, m.following()
+	if err := m.reload(); err != nil {
+		m.watchState = msg.state
+		m.status = "watch: " + err.Error()
+		return next
+	}
+	if head := m.watchHead; follow && !head.IsZero() && head != oldHead && m.selectHash(head) {
+		m.status = "following " + m.headName
+	}
+	return tea.Batch(next, m.loadVisible(), m.computeBranches(), m.scanFilter())
+}
+
+// noteRefs records the refs and HEAD just loaded.
+func (m *model) noteRefs() {
+	repo := m.provider.Repo()
+	m.watchState, m.watchHead = gitgraph.RefState(repo), plumbing.ZeroHash
+	if head, err := repo.Head(); err == nil {
+		m.watchHead = head.Hash()
+	}
+}
+
+// following reports whether new commits on HEAD would be selected: the
+// selection is on its tip or the top row.
+func (m *model) following() bool {
+	selected := m.selectedCommit()
+	return m.follow && (m.cursor == 0 || selected != nil && selected.Hash == m.watchHead)
+}
+
+// toggleFollow switches following HEAD, watching first if need be; on, it
+// selects the current tip right away.
+func (m *model) toggleFollow() tea.Cmd {
+	var cmd tea.Cmd
+	if !m.watch {
+		m.watch, m.follow = true, false
+		m.noteRefs()
+		cmd = m.watchRefs()
+	}
+	m.follow = !m.follow
+	if !m.follow {
+		m.status = "watching; not following HEAD"
+		return cmd
+	}
+	if !m.watchHead.IsZero() {
+		m.selectHash(m.watchHead)
+	}
+	m.status = fmt.Sprintf("watching; following HEAD (%s to stop)", m.keys.first(actionFollow))
+	return tea.Batch(cmd, m.loadVisible())
+}
+
+// watchBadge is the header's indicator: "⇣ following" while new commits
+// on the current branch would be selected as they arrive, else "watching".
+func (m *model) watchBadge() string {
+	if !m.watch {
+		return ""
+	}
+	if m.following() {
+		return "⇣ following"
+	}
+	return "watching"
+}